@@ -0,0 +1,5 @@
+// Package evaluationv1 holds the gRPC client stubs of rules-evaluation-service, generated from
+// rules-evaluation-service/api/proto/evaluation.v1.proto.
+package evaluationv1
+
+//go:generate protoc -I ../../../../../rules-evaluation-service/api/proto --go_out=. --go_opt=paths=source_relative,Mevaluation.v1.proto=github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/api/proto/gen/evaluationv1;evaluationv1 --go-grpc_out=. --go-grpc_opt=paths=source_relative,Mevaluation.v1.proto=github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/api/proto/gen/evaluationv1;evaluationv1 evaluation.v1.proto
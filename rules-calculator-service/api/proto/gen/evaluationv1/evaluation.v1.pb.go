@@ -0,0 +1,616 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: evaluation.v1.proto
+
+package evaluationv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EvaluateRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuleCategory  string                 `protobuf:"bytes,1,opt,name=rule_category,json=ruleCategory,proto3" json:"rule_category,omitempty"`
+	DslContent    string                 `protobuf:"bytes,2,opt,name=dsl_content,json=dslContent,proto3" json:"dsl_content,omitempty"`
+	Context       *structpb.Struct       `protobuf:"bytes,3,opt,name=context,proto3" json:"context,omitempty"`
+	RuleId        string                 `protobuf:"bytes,4,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	RuleVersion   int32                  `protobuf:"varint,5,opt,name=rule_version,json=ruleVersion,proto3" json:"rule_version,omitempty"`
+	Candidate     *Candidate             `protobuf:"bytes,6,opt,name=candidate,proto3" json:"candidate,omitempty"`
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	DryRun        bool                   `protobuf:"varint,8,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvaluateRuleRequest) Reset() {
+	*x = EvaluateRuleRequest{}
+	mi := &file_evaluation_v1_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvaluateRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateRuleRequest) ProtoMessage() {}
+
+func (x *EvaluateRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_evaluation_v1_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateRuleRequest.ProtoReflect.Descriptor instead.
+func (*EvaluateRuleRequest) Descriptor() ([]byte, []int) {
+	return file_evaluation_v1_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *EvaluateRuleRequest) GetRuleCategory() string {
+	if x != nil {
+		return x.RuleCategory
+	}
+	return ""
+}
+
+func (x *EvaluateRuleRequest) GetDslContent() string {
+	if x != nil {
+		return x.DslContent
+	}
+	return ""
+}
+
+func (x *EvaluateRuleRequest) GetContext() *structpb.Struct {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+func (x *EvaluateRuleRequest) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *EvaluateRuleRequest) GetRuleVersion() int32 {
+	if x != nil {
+		return x.RuleVersion
+	}
+	return 0
+}
+
+func (x *EvaluateRuleRequest) GetCandidate() *Candidate {
+	if x != nil {
+		return x.Candidate
+	}
+	return nil
+}
+
+func (x *EvaluateRuleRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+func (x *EvaluateRuleRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type Candidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuleVersion   int32                  `protobuf:"varint,1,opt,name=rule_version,json=ruleVersion,proto3" json:"rule_version,omitempty"`
+	DslContent    string                 `protobuf:"bytes,2,opt,name=dsl_content,json=dslContent,proto3" json:"dsl_content,omitempty"`
+	Mode          string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	Percentage    float64                `protobuf:"fixed64,4,opt,name=percentage,proto3" json:"percentage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candidate) Reset() {
+	*x = Candidate{}
+	mi := &file_evaluation_v1_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candidate) ProtoMessage() {}
+
+func (x *Candidate) ProtoReflect() protoreflect.Message {
+	mi := &file_evaluation_v1_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candidate.ProtoReflect.Descriptor instead.
+func (*Candidate) Descriptor() ([]byte, []int) {
+	return file_evaluation_v1_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Candidate) GetRuleVersion() int32 {
+	if x != nil {
+		return x.RuleVersion
+	}
+	return 0
+}
+
+func (x *Candidate) GetDslContent() string {
+	if x != nil {
+		return x.DslContent
+	}
+	return ""
+}
+
+func (x *Candidate) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Candidate) GetPercentage() float64 {
+	if x != nil {
+		return x.Percentage
+	}
+	return 0
+}
+
+type EvaluateRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *structpb.Struct       `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvaluateRuleResponse) Reset() {
+	*x = EvaluateRuleResponse{}
+	mi := &file_evaluation_v1_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvaluateRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvaluateRuleResponse) ProtoMessage() {}
+
+func (x *EvaluateRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_evaluation_v1_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvaluateRuleResponse.ProtoReflect.Descriptor instead.
+func (*EvaluateRuleResponse) Descriptor() ([]byte, []int) {
+	return file_evaluation_v1_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EvaluateRuleResponse) GetResult() *structpb.Struct {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *EvaluateRuleResponse) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+type BatchEvaluateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Rule          *EvaluateRuleRequest   `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchEvaluateRequest) Reset() {
+	*x = BatchEvaluateRequest{}
+	mi := &file_evaluation_v1_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchEvaluateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchEvaluateRequest) ProtoMessage() {}
+
+func (x *BatchEvaluateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_evaluation_v1_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchEvaluateRequest.ProtoReflect.Descriptor instead.
+func (*BatchEvaluateRequest) Descriptor() ([]byte, []int) {
+	return file_evaluation_v1_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchEvaluateRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *BatchEvaluateRequest) GetRule() *EvaluateRuleRequest {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type BatchEvaluateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Result        *structpb.Struct       `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchEvaluateResponse) Reset() {
+	*x = BatchEvaluateResponse{}
+	mi := &file_evaluation_v1_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchEvaluateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchEvaluateResponse) ProtoMessage() {}
+
+func (x *BatchEvaluateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_evaluation_v1_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchEvaluateResponse.ProtoReflect.Descriptor instead.
+func (*BatchEvaluateResponse) Descriptor() ([]byte, []int) {
+	return file_evaluation_v1_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchEvaluateResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *BatchEvaluateResponse) GetResult() *structpb.Struct {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *BatchEvaluateResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ExplainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *structpb.Struct       `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	Matched       bool                   `protobuf:"varint,2,opt,name=matched,proto3" json:"matched,omitempty"`
+	Fields        []*ContextFieldValue   `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+	Actions       *structpb.Struct       `protobuf:"bytes,4,opt,name=actions,proto3" json:"actions,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainResponse) Reset() {
+	*x = ExplainResponse{}
+	mi := &file_evaluation_v1_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainResponse) ProtoMessage() {}
+
+func (x *ExplainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_evaluation_v1_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainResponse.ProtoReflect.Descriptor instead.
+func (*ExplainResponse) Descriptor() ([]byte, []int) {
+	return file_evaluation_v1_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ExplainResponse) GetResult() *structpb.Struct {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *ExplainResponse) GetMatched() bool {
+	if x != nil {
+		return x.Matched
+	}
+	return false
+}
+
+func (x *ExplainResponse) GetFields() []*ContextFieldValue {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *ExplainResponse) GetActions() *structpb.Struct {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+func (x *ExplainResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ContextFieldValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Value         *structpb.Value        `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Found         bool                   `protobuf:"varint,3,opt,name=found,proto3" json:"found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContextFieldValue) Reset() {
+	*x = ContextFieldValue{}
+	mi := &file_evaluation_v1_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContextFieldValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextFieldValue) ProtoMessage() {}
+
+func (x *ContextFieldValue) ProtoReflect() protoreflect.Message {
+	mi := &file_evaluation_v1_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextFieldValue.ProtoReflect.Descriptor instead.
+func (*ContextFieldValue) Descriptor() ([]byte, []int) {
+	return file_evaluation_v1_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ContextFieldValue) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ContextFieldValue) GetValue() *structpb.Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *ContextFieldValue) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+var File_evaluation_v1_proto protoreflect.FileDescriptor
+
+const file_evaluation_v1_proto_rawDesc = "" +
+	"\n" +
+	"\x13evaluation.v1.proto\x12\revaluation.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xcc\x02\n" +
+	"\x13EvaluateRuleRequest\x12#\n" +
+	"\rrule_category\x18\x01 \x01(\tR\fruleCategory\x12\x1f\n" +
+	"\vdsl_content\x18\x02 \x01(\tR\n" +
+	"dslContent\x121\n" +
+	"\acontext\x18\x03 \x01(\v2\x17.google.protobuf.StructR\acontext\x12\x17\n" +
+	"\arule_id\x18\x04 \x01(\tR\x06ruleId\x12!\n" +
+	"\frule_version\x18\x05 \x01(\x05R\vruleVersion\x126\n" +
+	"\tcandidate\x18\x06 \x01(\v2\x18.evaluation.v1.CandidateR\tcandidate\x12/\n" +
+	"\x05as_of\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\x12\x17\n" +
+	"\adry_run\x18\b \x01(\bR\x06dryRun\"\x83\x01\n" +
+	"\tCandidate\x12!\n" +
+	"\frule_version\x18\x01 \x01(\x05R\vruleVersion\x12\x1f\n" +
+	"\vdsl_content\x18\x02 \x01(\tR\n" +
+	"dslContent\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\x12\x1e\n" +
+	"\n" +
+	"percentage\x18\x04 \x01(\x01R\n" +
+	"percentage\"x\n" +
+	"\x14EvaluateRuleResponse\x12/\n" +
+	"\x06result\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06result\x12/\n" +
+	"\x05as_of\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"m\n" +
+	"\x14BatchEvaluateRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x126\n" +
+	"\x04rule\x18\x02 \x01(\v2\".evaluation.v1.EvaluateRuleRequestR\x04rule\"}\n" +
+	"\x15BatchEvaluateResponse\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12/\n" +
+	"\x06result\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x06result\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xdf\x01\n" +
+	"\x0fExplainResponse\x12/\n" +
+	"\x06result\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06result\x12\x18\n" +
+	"\amatched\x18\x02 \x01(\bR\amatched\x128\n" +
+	"\x06fields\x18\x03 \x03(\v2 .evaluation.v1.ContextFieldValueR\x06fields\x121\n" +
+	"\aactions\x18\x04 \x01(\v2\x17.google.protobuf.StructR\aactions\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"k\n" +
+	"\x11ContextFieldValue\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05value\x12\x14\n" +
+	"\x05found\x18\x03 \x01(\bR\x05found2\x9b\x02\n" +
+	"\x11EvaluationService\x12W\n" +
+	"\fEvaluateRule\x12\".evaluation.v1.EvaluateRuleRequest\x1a#.evaluation.v1.EvaluateRuleResponse\x12^\n" +
+	"\rBatchEvaluate\x12#.evaluation.v1.BatchEvaluateRequest\x1a$.evaluation.v1.BatchEvaluateResponse(\x010\x01\x12M\n" +
+	"\aExplain\x12\".evaluation.v1.EvaluateRuleRequest\x1a\x1e.evaluation.v1.ExplainResponseB5Z3rules-evaluation-service/api/proto/gen;evaluationv1b\x06proto3"
+
+var (
+	file_evaluation_v1_proto_rawDescOnce sync.Once
+	file_evaluation_v1_proto_rawDescData []byte
+)
+
+func file_evaluation_v1_proto_rawDescGZIP() []byte {
+	file_evaluation_v1_proto_rawDescOnce.Do(func() {
+		file_evaluation_v1_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_evaluation_v1_proto_rawDesc), len(file_evaluation_v1_proto_rawDesc)))
+	})
+	return file_evaluation_v1_proto_rawDescData
+}
+
+var file_evaluation_v1_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_evaluation_v1_proto_goTypes = []any{
+	(*EvaluateRuleRequest)(nil),   // 0: evaluation.v1.EvaluateRuleRequest
+	(*Candidate)(nil),             // 1: evaluation.v1.Candidate
+	(*EvaluateRuleResponse)(nil),  // 2: evaluation.v1.EvaluateRuleResponse
+	(*BatchEvaluateRequest)(nil),  // 3: evaluation.v1.BatchEvaluateRequest
+	(*BatchEvaluateResponse)(nil), // 4: evaluation.v1.BatchEvaluateResponse
+	(*ExplainResponse)(nil),       // 5: evaluation.v1.ExplainResponse
+	(*ContextFieldValue)(nil),     // 6: evaluation.v1.ContextFieldValue
+	(*structpb.Struct)(nil),       // 7: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+	(*structpb.Value)(nil),        // 9: google.protobuf.Value
+}
+var file_evaluation_v1_proto_depIdxs = []int32{
+	7,  // 0: evaluation.v1.EvaluateRuleRequest.context:type_name -> google.protobuf.Struct
+	1,  // 1: evaluation.v1.EvaluateRuleRequest.candidate:type_name -> evaluation.v1.Candidate
+	8,  // 2: evaluation.v1.EvaluateRuleRequest.as_of:type_name -> google.protobuf.Timestamp
+	7,  // 3: evaluation.v1.EvaluateRuleResponse.result:type_name -> google.protobuf.Struct
+	8,  // 4: evaluation.v1.EvaluateRuleResponse.as_of:type_name -> google.protobuf.Timestamp
+	0,  // 5: evaluation.v1.BatchEvaluateRequest.rule:type_name -> evaluation.v1.EvaluateRuleRequest
+	7,  // 6: evaluation.v1.BatchEvaluateResponse.result:type_name -> google.protobuf.Struct
+	7,  // 7: evaluation.v1.ExplainResponse.result:type_name -> google.protobuf.Struct
+	6,  // 8: evaluation.v1.ExplainResponse.fields:type_name -> evaluation.v1.ContextFieldValue
+	7,  // 9: evaluation.v1.ExplainResponse.actions:type_name -> google.protobuf.Struct
+	9,  // 10: evaluation.v1.ContextFieldValue.value:type_name -> google.protobuf.Value
+	0,  // 11: evaluation.v1.EvaluationService.EvaluateRule:input_type -> evaluation.v1.EvaluateRuleRequest
+	3,  // 12: evaluation.v1.EvaluationService.BatchEvaluate:input_type -> evaluation.v1.BatchEvaluateRequest
+	0,  // 13: evaluation.v1.EvaluationService.Explain:input_type -> evaluation.v1.EvaluateRuleRequest
+	2,  // 14: evaluation.v1.EvaluationService.EvaluateRule:output_type -> evaluation.v1.EvaluateRuleResponse
+	4,  // 15: evaluation.v1.EvaluationService.BatchEvaluate:output_type -> evaluation.v1.BatchEvaluateResponse
+	5,  // 16: evaluation.v1.EvaluationService.Explain:output_type -> evaluation.v1.ExplainResponse
+	14, // [14:17] is the sub-list for method output_type
+	11, // [11:14] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_evaluation_v1_proto_init() }
+func file_evaluation_v1_proto_init() {
+	if File_evaluation_v1_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_evaluation_v1_proto_rawDesc), len(file_evaluation_v1_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_evaluation_v1_proto_goTypes,
+		DependencyIndexes: file_evaluation_v1_proto_depIdxs,
+		MessageInfos:      file_evaluation_v1_proto_msgTypes,
+	}.Build()
+	File_evaluation_v1_proto = out.File
+	file_evaluation_v1_proto_goTypes = nil
+	file_evaluation_v1_proto_depIdxs = nil
+}
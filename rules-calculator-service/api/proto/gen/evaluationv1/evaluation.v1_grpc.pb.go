@@ -0,0 +1,192 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: evaluation.v1.proto
+
+package evaluationv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	EvaluationService_EvaluateRule_FullMethodName  = "/evaluation.v1.EvaluationService/EvaluateRule"
+	EvaluationService_BatchEvaluate_FullMethodName = "/evaluation.v1.EvaluationService/BatchEvaluate"
+	EvaluationService_Explain_FullMethodName       = "/evaluation.v1.EvaluationService/Explain"
+)
+
+// EvaluationServiceClient is the client API for EvaluationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EvaluationServiceClient interface {
+	EvaluateRule(ctx context.Context, in *EvaluateRuleRequest, opts ...grpc.CallOption) (*EvaluateRuleResponse, error)
+	BatchEvaluate(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[BatchEvaluateRequest, BatchEvaluateResponse], error)
+	Explain(ctx context.Context, in *EvaluateRuleRequest, opts ...grpc.CallOption) (*ExplainResponse, error)
+}
+
+type evaluationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEvaluationServiceClient(cc grpc.ClientConnInterface) EvaluationServiceClient {
+	return &evaluationServiceClient{cc}
+}
+
+func (c *evaluationServiceClient) EvaluateRule(ctx context.Context, in *EvaluateRuleRequest, opts ...grpc.CallOption) (*EvaluateRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EvaluateRuleResponse)
+	err := c.cc.Invoke(ctx, EvaluationService_EvaluateRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *evaluationServiceClient) BatchEvaluate(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[BatchEvaluateRequest, BatchEvaluateResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EvaluationService_ServiceDesc.Streams[0], EvaluationService_BatchEvaluate_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BatchEvaluateRequest, BatchEvaluateResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EvaluationService_BatchEvaluateClient = grpc.BidiStreamingClient[BatchEvaluateRequest, BatchEvaluateResponse]
+
+func (c *evaluationServiceClient) Explain(ctx context.Context, in *EvaluateRuleRequest, opts ...grpc.CallOption) (*ExplainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExplainResponse)
+	err := c.cc.Invoke(ctx, EvaluationService_Explain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EvaluationServiceServer is the server API for EvaluationService service.
+// All implementations must embed UnimplementedEvaluationServiceServer
+// for forward compatibility.
+type EvaluationServiceServer interface {
+	EvaluateRule(context.Context, *EvaluateRuleRequest) (*EvaluateRuleResponse, error)
+	BatchEvaluate(grpc.BidiStreamingServer[BatchEvaluateRequest, BatchEvaluateResponse]) error
+	Explain(context.Context, *EvaluateRuleRequest) (*ExplainResponse, error)
+	mustEmbedUnimplementedEvaluationServiceServer()
+}
+
+// UnimplementedEvaluationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEvaluationServiceServer struct{}
+
+func (UnimplementedEvaluationServiceServer) EvaluateRule(context.Context, *EvaluateRuleRequest) (*EvaluateRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvaluateRule not implemented")
+}
+func (UnimplementedEvaluationServiceServer) BatchEvaluate(grpc.BidiStreamingServer[BatchEvaluateRequest, BatchEvaluateResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method BatchEvaluate not implemented")
+}
+func (UnimplementedEvaluationServiceServer) Explain(context.Context, *EvaluateRuleRequest) (*ExplainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Explain not implemented")
+}
+func (UnimplementedEvaluationServiceServer) mustEmbedUnimplementedEvaluationServiceServer() {}
+func (UnimplementedEvaluationServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeEvaluationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EvaluationServiceServer will
+// result in compilation errors.
+type UnsafeEvaluationServiceServer interface {
+	mustEmbedUnimplementedEvaluationServiceServer()
+}
+
+func RegisterEvaluationServiceServer(s grpc.ServiceRegistrar, srv EvaluationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedEvaluationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&EvaluationService_ServiceDesc, srv)
+}
+
+func _EvaluationService_EvaluateRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EvaluationServiceServer).EvaluateRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EvaluationService_EvaluateRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EvaluationServiceServer).EvaluateRule(ctx, req.(*EvaluateRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EvaluationService_BatchEvaluate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EvaluationServiceServer).BatchEvaluate(&grpc.GenericServerStream[BatchEvaluateRequest, BatchEvaluateResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EvaluationService_BatchEvaluateServer = grpc.BidiStreamingServer[BatchEvaluateRequest, BatchEvaluateResponse]
+
+func _EvaluationService_Explain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EvaluationServiceServer).Explain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EvaluationService_Explain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EvaluationServiceServer).Explain(ctx, req.(*EvaluateRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EvaluationService_ServiceDesc is the grpc.ServiceDesc for EvaluationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EvaluationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "evaluation.v1.EvaluationService",
+	HandlerType: (*EvaluationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "EvaluateRule",
+			Handler:    _EvaluationService_EvaluateRule_Handler,
+		},
+		{
+			MethodName: "Explain",
+			Handler:    _EvaluationService_Explain_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchEvaluate",
+			Handler:       _EvaluationService_BatchEvaluate_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "evaluation.v1.proto",
+}
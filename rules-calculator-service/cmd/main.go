@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"log"
 	"net/http"
 	"os"
@@ -10,43 +11,203 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/job"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/quote"
 	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/adapters"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/cache"
 	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/config"
+	messaging "github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/messaging/nats"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/memory"
+	persistence "github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/postgres"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/postgres/migrations"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/rates"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/rules"
 
-	// "github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
 	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/interfaces/rest/handlers"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	// "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 )
 
 func main() {
 	cfg := config.DefaultConfig()
 
-	// Setup OpenTelemetry - temporarily disabled
-	// tp, err := telemetry.InitTracer(cfg.Telemetry.ServiceName, cfg.Telemetry.Exporter)
-	// if err != nil {
-	// 	log.Fatalf("failed to initialize tracer: %v", err)
-	// }
-	// defer func() {
-	// 	if err := tp.Shutdown(context.Background()); err != nil {
-	// 		log.Printf("Error shutting down tracer provider: %v", err)
-	// 	}
-	// }()
+	// Setup OpenTelemetry
+	tp, err := telemetry.InitTracer(cfg.Telemetry.ServiceName, cfg.Telemetry.Exporter)
+	if err != nil {
+		log.Fatalf("failed to initialize tracer: %v", err)
+	}
+	defer func() {
+		if err := tp.Shutdown(context.Background()); err != nil {
+			log.Printf("Error shutting down tracer provider: %v", err)
+		}
+	}()
 
 	// Infrastructure
-	ruleEvaluator := adapters.NewHTTPEvaluationAdapter("http://localhost:8081")
+	ruleCatalog, err := rules.NewStaticCatalog(cfg.Evaluation.RulesFile)
+	if err != nil {
+		log.Fatalf("failed to load rule catalog: %v", err)
+	}
+	if ruleCatalog.Len() == 0 {
+		log.Printf("Rule catalog is empty: every rule will fail as INVALID_RULE until RULES_CATALOG_FILE is set")
+	}
+
+	var ruleEvaluator application.RuleEvaluator
+	switch cfg.Evaluation.Transport {
+	case "grpc":
+		grpcEvaluator, err := adapters.NewGRPCEvaluationAdapter(cfg.Evaluation.GRPCAddress, ruleCatalog)
+		if err != nil {
+			log.Fatalf("failed to create gRPC evaluation adapter: %v", err)
+		}
+		defer grpcEvaluator.Close()
+		ruleEvaluator = grpcEvaluator
+	default:
+		ruleEvaluator = adapters.NewHTTPEvaluationAdapter(cfg.Evaluation.URL, ruleCatalog, adapters.ResilienceOptions{
+			MaxRetries:       cfg.Evaluation.MaxRetries,
+			BaseBackoff:      cfg.Evaluation.RetryBaseBackoff,
+			MaxBackoff:       cfg.Evaluation.RetryMaxBackoff,
+			FailureThreshold: cfg.Evaluation.BreakerFailureThreshold,
+			OpenTimeout:      cfg.Evaluation.BreakerOpenTimeout,
+		})
+	}
+
+	ratesProvider, err := rates.NewStaticProvider(cfg.Currency.RatesFile)
+	if err != nil {
+		log.Fatalf("failed to load exchange rates: %v", err)
+	}
+
+	var calculationRepository calculation.Repository
+	var quoteRepository quote.Repository
+	var jobRepository job.Repository
+	switch cfg.History.Store {
+	case "postgres":
+		// TranslateError lets the repository detect reused idempotency keys.
+		db, err := gorm.Open(postgres.Open(cfg.Database.DSN), &gorm.Config{TranslateError: true})
+		if err != nil {
+			log.Fatalf("failed to connect to database: %v", err)
+		}
+		if err := migrations.ApplyMigrations(db); err != nil {
+			log.Fatalf("failed to apply migrations: %v", err)
+		}
+		calculationRepository = persistence.NewCalculationRepository(db)
+		quoteRepository = persistence.NewQuoteRepository(db)
+		jobRepository = persistence.NewJobRepository(db)
+	default:
+		log.Println("Using in-memory calculation history")
+		calculationRepository = memory.NewCalculationRepository()
+		quoteRepository = memory.NewQuoteRepository()
+		jobRepository = memory.NewJobRepository()
+	}
 
 	// Application
-	calculateHandler := application.NewCalculateRulesHandler(ruleEvaluator)
+	calculateHandler := application.NewCalculateRulesHandler(ruleEvaluator, money.NewConverter(ratesProvider), calculationRepository)
+	calculateHandler.SetExecution(application.ExecutionOptions{
+		Concurrency: cfg.Execution.Concurrency,
+		Timeout:     cfg.Execution.Timeout,
+	})
+	loyaltyBase := calculation.LoyaltyBase(cfg.Pipeline.LoyaltyBase)
+	if !loyaltyBase.IsValid() {
+		log.Fatalf("invalid PIPELINE_LOYALTY_BASE: %s", cfg.Pipeline.LoyaltyBase)
+	}
+	calculateHandler.SetPipeline(application.PipelineOptions{LoyaltyBase: loyaltyBase})
+	switch cfg.Cache.Backend {
+	case "none", "":
+	case "memory", "redis":
+		var resultCache application.ResultCache
+		if cfg.Cache.Backend == "redis" {
+			redisClient := redis.NewClient(&redis.Options{
+				Addr:     cfg.Cache.RedisAddr,
+				Password: cfg.Cache.RedisPassword,
+				DB:       cfg.Cache.RedisDB,
+			})
+			defer redisClient.Close()
+			resultCache = cache.NewRedisCache(redisClient, "rules-calculator:cache", cfg.Cache.TTL)
+		} else {
+			resultCache = cache.NewMemoryCache(cfg.Cache.Size, cfg.Cache.TTL)
+		}
+		calculateHandler.SetCache(resultCache)
+		if cfg.NATS.URL == "" {
+			log.Printf("NATS_URL is not set: cached results expire after %s but are not invalidated by rule changes", cfg.Cache.TTL)
+		} else {
+			subscriber, err := messaging.NewRuleEventSubscriber(cfg.NATS.URL, cfg.Cache.RuleEventsSubject, resultCache)
+			if err != nil {
+				log.Fatalf("failed to subscribe to rule events: %v", err)
+			}
+			defer subscriber.Close()
+		}
+		log.Printf("Caching calculation results in %s", cfg.Cache.Backend)
+	default:
+		log.Fatalf("invalid CALCULATION_CACHE: %s", cfg.Cache.Backend)
+	}
+	signingKey := []byte(cfg.Quote.SigningKey)
+	if len(signingKey) == 0 {
+		if cfg.History.Store == "postgres" {
+			// Stored quotes outlive the process, so a random key would invalidate them on the next restart.
+			log.Fatalf("QUOTE_SIGNING_KEY is required when quotes are stored in postgres")
+		}
+		log.Println("QUOTE_SIGNING_KEY is not set: signing quotes with a random key, which other replicas and restarts do not share")
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			log.Fatalf("failed to generate quote signing key: %v", err)
+		}
+	}
+	quoteHandler := application.NewQuoteHandler(calculateHandler, quoteRepository, quote.NewSigner(signingKey), cfg.Quote.TTL)
+	compareHandler := application.NewCompareHandler(calculateHandler, ruleCatalog)
+	jobRunner := application.NewJobRunner(calculateHandler, jobRepository, application.JobOptions{
+		Workers:   cfg.Jobs.Workers,
+		QueueSize: cfg.Jobs.QueueSize,
+		MaxItems:  cfg.Jobs.MaxItems,
+	})
+	if cfg.NATS.URL == "" {
+		log.Println("NATS_URL is not set: job results are only stored, and jobs are only submitted over HTTP")
+	} else {
+		publisher, err := messaging.NewJobPublisher(cfg.NATS.URL, cfg.Jobs.Stream, cfg.Jobs.Subject)
+		if err != nil {
+			log.Fatalf("failed to create job result publisher: %v", err)
+		}
+		defer publisher.Close()
+		jobRunner.SetPublisher(publisher)
+	}
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobRunner.Start(jobsCtx)
+	if cfg.Jobs.Recover {
+		go func() {
+			recovered, err := jobRunner.Recover(jobsCtx)
+			if err != nil {
+				log.Printf("failed to recover pending jobs: %v", err)
+			}
+			if recovered > 0 {
+				log.Printf("Resuming %d pending jobs", recovered)
+			}
+		}()
+	}
+	getCalculationHandler := application.NewGetCalculationHandler(calculationRepository)
+	listCalculationsHandler := application.NewListCalculationsHandler(calculationRepository)
 
 	// Interfaces
 	httpHandler := handlers.NewCalculatorHandler(calculateHandler)
+	historyHandler := handlers.NewCalculationHistoryHandler(getCalculationHandler, listCalculationsHandler)
+	quotesHandler := handlers.NewQuoteHandler(quoteHandler)
+	jobsHandler := handlers.NewJobHandler(jobRunner)
+	comparisonsHandler := handlers.NewCompareHandler(compareHandler)
+	var submitSubscriber *messaging.JobSubmitSubscriber
+	if cfg.NATS.URL != "" {
+		submitSubscriber, err = messaging.NewJobSubmitSubscriber(cfg.NATS.URL, cfg.Jobs.Subject+".submit", jobsHandler)
+		if err != nil {
+			log.Fatalf("failed to subscribe to job submissions: %v", err)
+		}
+	}
 
 	router := gin.New()
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
-	// router.Use(otelgin.Middleware(cfg.Telemetry.ServiceName))
+	router.Use(otelgin.Middleware(cfg.Telemetry.ServiceName))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -59,12 +220,28 @@ func main() {
 	v1 := router.Group("/v1")
 	{
 		v1.POST("/calculate", httpHandler.Calculate)
+		v1.POST("/compare", comparisonsHandler.Compare)
+		v1.GET("/calculations", historyHandler.ListCalculations)
+		v1.GET("/calculations/:id", historyHandler.GetCalculation)
+		v1.POST("/quotes", quotesHandler.CreateQuote)
+		v1.POST("/quotes/:id/confirm", quotesHandler.ConfirmQuote)
+		v1.POST("/jobs", jobsHandler.SubmitJob)
+		v1.GET("/jobs/:id", jobsHandler.GetJob)
+		v1.GET("/jobs/:id/results", jobsHandler.ListJobResults)
 	}
 
 	// API Gateway routes
 	apiV1 := router.Group("/api/v1")
 	{
 		apiV1.POST("/calculate", httpHandler.Calculate)
+		apiV1.POST("/compare", comparisonsHandler.Compare)
+		apiV1.GET("/calculations", historyHandler.ListCalculations)
+		apiV1.GET("/calculations/:id", historyHandler.GetCalculation)
+		apiV1.POST("/quotes", quotesHandler.CreateQuote)
+		apiV1.POST("/quotes/:id/confirm", quotesHandler.ConfirmQuote)
+		apiV1.POST("/jobs", jobsHandler.SubmitJob)
+		apiV1.GET("/jobs/:id", jobsHandler.GetJob)
+		apiV1.GET("/jobs/:id/results", jobsHandler.ListJobResults)
 	}
 
 	srv := &http.Server{
@@ -90,6 +267,12 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
+	// Jobs interrupted here stay pending and are resumed on the next start.
+	if submitSubscriber != nil {
+		submitSubscriber.Close()
+	}
+	stopJobs()
+	jobRunner.Wait()
 
 	log.Println("Server exiting")
 }
@@ -0,0 +1,90 @@
+package application
+
+import (
+	"context"
+	"log"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
+)
+
+// ResultCache stores the results of completed calculations by the fingerprint of their request, so that
+// a checkout recalculating the same basket does not evaluate its rules again.
+//
+// Entries are invalidated when one of their rules changes. Because a rule can change while a calculation
+// is running, Put is given the generation read before the calculation started, and drops the result when
+// rules were invalidated since.
+type ResultCache interface {
+	// Get returns the cached result, or nil on a miss.
+	Get(ctx context.Context, key string) (*calculation.Result, error)
+	// Put caches the result of a calculation of the given rules, unless rules were invalidated after
+	// generation.
+	Put(ctx context.Context, key string, ruleIDs []string, result calculation.Result, generation uint64) error
+	// Generation returns the number of invalidations so far.
+	Generation(ctx context.Context) (uint64, error)
+	// InvalidateRules removes the results that depend on any of the rules.
+	InvalidateRules(ctx context.Context, ruleIDs ...string) error
+}
+
+// SetCache enables result caching. Only COMPLETED results are cached: results with failed rules are
+// calculated again.
+func (h *CalculateRulesHandler) SetCache(cache ResultCache) {
+	h.cache = cache
+}
+
+// calculateCached returns the cached result of the command when there is one, and calculates and
+// caches it otherwise. The key is the fingerprint of the normalized command: its context, rules and rule
+// versions, and every option that changes the result. The results of dry runs are not cached, as their
+// evaluations skipped side effects that a checkout needs.
+func (h *CalculateRulesHandler) calculateCached(ctx context.Context, cmd CalculateRulesCommand) (calculation.Result, bool, error) {
+	if h.cache == nil {
+		result, err := h.calculate(ctx, cmd)
+		return result, false, err
+	}
+	key, err := requestHash(cmd)
+	if err != nil {
+		return calculation.Result{}, false, err
+	}
+	generation, err := h.cache.Generation(ctx)
+	if err != nil {
+		log.Printf("calculation cache lookup failed: %v", err)
+		telemetry.CacheRequestsTotal.WithLabelValues("error").Inc()
+		result, err := h.calculate(ctx, cmd)
+		return result, false, err
+	}
+	if cached := h.cachedResult(ctx, key); cached != nil {
+		return *cached, true, nil
+	}
+
+	result, err := h.calculate(ctx, cmd)
+	if err == nil && !cmd.DryRun {
+		h.cacheResult(ctx, key, cmd.RuleIDs, result, generation)
+	}
+	return result, false, err
+}
+
+// cachedResult looks up the result of the command. Cache errors are logged and treated as misses, so
+// that an unavailable cache only costs the evaluations it would have saved.
+func (h *CalculateRulesHandler) cachedResult(ctx context.Context, key string) *calculation.Result {
+	result, err := h.cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		log.Printf("calculation cache lookup failed: %v", err)
+		telemetry.CacheRequestsTotal.WithLabelValues("error").Inc()
+	case result == nil:
+		telemetry.CacheRequestsTotal.WithLabelValues("miss").Inc()
+	default:
+		telemetry.CacheRequestsTotal.WithLabelValues("hit").Inc()
+	}
+	return result
+}
+
+// cacheResult caches a result calculated at the given cache generation.
+func (h *CalculateRulesHandler) cacheResult(ctx context.Context, key string, ruleIDs []string, result calculation.Result, generation uint64) {
+	if len(result.Errors) > 0 {
+		return
+	}
+	if err := h.cache.Put(ctx, key, ruleIDs, result, generation); err != nil {
+		log.Printf("failed to cache calculation result: %v", err)
+	}
+}
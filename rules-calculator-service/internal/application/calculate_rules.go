@@ -2,10 +2,20 @@ package application
 
 import (
 	"context"
-
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/basket"
 	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
 	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -15,39 +25,125 @@ import (
 type CalculateRulesCommand struct {
 	RuleIDs []string               `json:"rule_ids"`
 	Context map[string]interface{} `json:"context"`
+	// Currency is the optional currency of the total. Without it, all rule values must share one currency.
+	Currency string `json:"currency,omitempty"`
+	// RuleVersions are the versions of the rules, recorded in the calculation history.
+	RuleVersions map[string]int `json:"rule_versions,omitempty"`
+	// FailurePolicy decides how failing rules are handled; defaults to BEST_EFFORT.
+	FailurePolicy calculation.FailurePolicy `json:"failure_policy,omitempty"`
+	// OptionalRuleIDs are rules whose failure leaves them out of the total instead of failing the calculation.
+	// When set, all other rules are required.
+	OptionalRuleIDs []string `json:"optional_rule_ids,omitempty"`
+	// RequiredRuleIDs are rules whose failure fails the calculation. Rules the command does not classify
+	// are optional.
+	RequiredRuleIDs []string `json:"required_rule_ids,omitempty"`
+	// Pipeline, when set, calculates the checkout in ordered stages instead of summing RuleIDs, which
+	// must then be empty.
+	Pipeline *calculation.Pipeline `json:"pipeline,omitempty"`
+	// Basket is the line items of a pipeline calculation. Its subtotal is the context's order.amount and
+	// its items the context's items.
+	Basket *basket.Basket `json:"basket,omitempty"`
+	// ItemRuleIDs are pipeline rules evaluated once per basket line, whose values apply to that line.
+	ItemRuleIDs []string `json:"item_rule_ids,omitempty"`
+	// Allocations decide how the values of basket-level discount and tax rules are allocated to lines;
+	// rules without one are allocated pro rata over all lines.
+	Allocations map[string]basket.Allocation `json:"allocations,omitempty"`
+	// IdempotencyKey makes retries of the same request return the first calculation instead of calculating again.
+	IdempotencyKey string `json:"-"`
+	// DryRun calculates without storing the calculation, caching its result or causing side effects in
+	// the evaluation service, such as coupon reservations.
+	DryRun bool `json:"-"`
 }
 
 // CalculateRulesResult is the result of calculating rules.
 type CalculateRulesResult struct {
-	CalculationID string             `json:"calculation_id"`
-	Value         float64            `json:"value"`
-	Breakdown     map[string]float64 `json:"breakdown"`
+	CalculationID string                             `json:"calculation_id"`
+	Status        calculation.Status                 `json:"status"`
+	Value         money.Money                        `json:"value"`
+	Breakdown     map[string]calculation.RuleOutcome `json:"breakdown"`
+	Errors        []calculation.RuleFailure          `json:"errors,omitempty"`
+	Stages        []calculation.StageResult          `json:"stages,omitempty"`
+	Lines         []calculation.LineResult           `json:"lines,omitempty"`
+	// Replayed is set when the result is the stored result of an earlier request with the same idempotency key.
+	Replayed bool `json:"-"`
+	// Cached is set when the rules were not evaluated because the result was in the cache.
+	Cached bool `json:"-"`
 }
 
 // RuleEvaluator is an interface for an external service that evaluates rules.
 type RuleEvaluator interface {
-	Evaluate(ctx context.Context, ruleID string, context map[string]interface{}) (float64, error)
+	// Evaluate returns the value of the rule. The currency is empty when the rule does not report one.
+	Evaluate(ctx context.Context, ruleID string, context map[string]interface{}) (money.Money, error)
+}
+
+// ExecutionOptions bounds the evaluation of the rules of a calculation.
+type ExecutionOptions struct {
+	// Concurrency is the number of rules evaluated at the same time.
+	Concurrency int
+	// Timeout is the time budget of a calculation, split across its rules. Zero leaves only the
+	// caller's deadline.
+	Timeout time.Duration
+}
+
+// DefaultExecutionOptions evaluates up to eight rules at a time, without a budget of its own.
+func DefaultExecutionOptions() ExecutionOptions {
+	return ExecutionOptions{Concurrency: 8}
+}
+
+// PipelineOptions configures pipeline calculations.
+type PipelineOptions struct {
+	// LoyaltyBase is the amount loyalty points are computed on, unless a request chooses otherwise.
+	LoyaltyBase calculation.LoyaltyBase
+}
+
+// DefaultPipelineOptions computes loyalty points on the amount including taxes.
+func DefaultPipelineOptions() PipelineOptions {
+	return PipelineOptions{LoyaltyBase: calculation.LoyaltyPostTax}
 }
 
 // CalculateRulesHandler is the handler for the CalculateRulesCommand.
 type CalculateRulesHandler struct {
-	evaluator RuleEvaluator
+	evaluator  RuleEvaluator
+	converter  *money.Converter
+	repository calculation.Repository
+	execution  ExecutionOptions
+	pipeline   PipelineOptions
+	cache      ResultCache
 }
 
-// NewCalculateRulesHandler creates a new CalculateRulesHandler.
-func NewCalculateRulesHandler(evaluator RuleEvaluator) *CalculateRulesHandler {
+// NewCalculateRulesHandler creates a new CalculateRulesHandler. The converter is used when a target
+// currency is requested; it may be nil, in which case such requests fail. Every calculation is stored
+// in the repository.
+func NewCalculateRulesHandler(evaluator RuleEvaluator, converter *money.Converter, repository calculation.Repository) *CalculateRulesHandler {
 	return &CalculateRulesHandler{
-		evaluator: evaluator,
+		evaluator:  evaluator,
+		converter:  converter,
+		repository: repository,
+		execution:  DefaultExecutionOptions(),
+		pipeline:   DefaultPipelineOptions(),
 	}
 }
 
+// SetExecution replaces the execution options of the handler.
+func (h *CalculateRulesHandler) SetExecution(opts ExecutionOptions) {
+	h.execution = opts
+}
+
+// SetPipeline replaces the pipeline options of the handler.
+func (h *CalculateRulesHandler) SetPipeline(opts PipelineOptions) {
+	h.pipeline = opts
+}
+
 // Handle handles the CalculateRulesCommand.
 func (h *CalculateRulesHandler) Handle(ctx context.Context, cmd CalculateRulesCommand) (*CalculateRulesResult, error) {
 	tr := otel.Tracer("application")
 	ctx, span := tr.Start(ctx, "CalculateRulesHandler.Handle")
 	defer span.End()
 
-	span.SetAttributes(attribute.Int("rules.count", len(cmd.RuleIDs)))
+	span.SetAttributes(
+		attribute.Int("rules.count", len(cmd.RuleIDs)),
+		attribute.Int("rules.concurrency", h.execution.Concurrency),
+	)
 
 	telemetry.CalculationsTotal.Inc()
 	startTime := time.Now()
@@ -55,36 +151,464 @@ func (h *CalculateRulesHandler) Handle(ctx context.Context, cmd CalculateRulesCo
 		telemetry.CalculationDuration.Observe(time.Since(startTime).Seconds())
 	}()
 
+	if cmd.FailurePolicy == "" {
+		cmd.FailurePolicy = calculation.PolicyBestEffort
+	}
+	if !cmd.FailurePolicy.IsValid() {
+		return nil, &shared.ValidationError{Field: "failure_policy", Message: "must be FAIL_FAST or BEST_EFFORT"}
+	}
+	if cmd.Currency != "" {
+		currency, err := money.ParseCurrency(cmd.Currency)
+		if err != nil {
+			return nil, &shared.ValidationError{Field: "currency", Message: err.Error()}
+		}
+		cmd.Currency = currency
+	}
+	for _, ruleID := range cmd.RequiredRuleIDs {
+		if contains(cmd.OptionalRuleIDs, ruleID) {
+			return nil, &shared.ValidationError{Field: "required_rule_ids", Message: fmt.Sprintf("rule %s is also optional", ruleID)}
+		}
+	}
+	if err := validateBasket(&cmd); err != nil {
+		return nil, err
+	}
+	if cmd.Pipeline != nil {
+		if len(cmd.RuleIDs) > 0 {
+			return nil, &shared.ValidationError{Field: "rule_ids", Message: "must be empty when a pipeline is given"}
+		}
+		pipeline, err := cmd.Pipeline.Normalize()
+		if err != nil {
+			return nil, &shared.ValidationError{Field: "pipeline", Message: err.Error()}
+		}
+		if _, ok := orderAmount(cmd.Context); !ok {
+			return nil, &shared.ValidationError{Field: "context.order.amount", Message: "is required for a pipeline"}
+		}
+		if cmd.Currency == "" && currencyOf(cmd.Context) == "" {
+			return nil, &shared.ValidationError{Field: "currency", Message: "is required for a pipeline, in the request or the context"}
+		}
+		for _, stage := range pipeline.Stages {
+			if stage.Category == calculation.StageDiscounts || stage.Category == calculation.StageTaxes {
+				continue
+			}
+			for _, ruleID := range stage.RuleIDs {
+				if contains(cmd.ItemRuleIDs, ruleID) {
+					return nil, &shared.ValidationError{Field: "item_rule_ids", Message: "item rules must be discount or tax rules"}
+				}
+			}
+		}
+		cmd.Pipeline = &pipeline
+		cmd.RuleIDs = pipeline.RuleIDs()
+	}
+
 	calc, err := calculation.NewCalculation(cmd.RuleIDs, cmd.Context)
 	if err != nil {
 		return nil, err
 	}
-
-	totalValue := 0.0
-	breakdown := make(map[string]float64)
-
-	for _, ruleID := range cmd.RuleIDs {
-		// This is a placeholder for calling the rule evaluation service.
-		// In a real implementation, this would make an RPC or HTTP call.
-		ruleValue, err := h.evaluator.Evaluate(ctx, ruleID, cmd.Context)
+	calc.WithRuleVersions(cmd.RuleVersions).WithCurrency(cmd.Currency)
+	if cmd.IdempotencyKey != "" {
+		hash, err := requestHash(cmd)
 		if err != nil {
-			// Decide on error handling: fail the whole calculation or skip the rule?
-			// For now, we'll skip the failing rule.
-			continue
+			return nil, err
 		}
-		totalValue += ruleValue
-		breakdown[ruleID] = ruleValue
+		calc.WithIdempotencyKey(cmd.IdempotencyKey, hash)
 	}
 
-	result := calculation.Result{
-		Value:     totalValue,
-		Breakdown: breakdown,
+	if cmd.DryRun {
+		ctx = calculation.WithDryRun(ctx)
+	} else if replayed, err := h.claim(ctx, calc); replayed != nil || err != nil {
+		// Creating the calculation claims the idempotency key; a retry finds the first calculation instead.
+		return replayed, err
+	}
+	span.SetAttributes(attribute.String("calculation.id", calc.ID().String()))
+
+	result, cached, err := h.calculateCached(ctx, cmd)
+	span.SetAttributes(attribute.Bool("calculation.cached", cached))
+	if requiredFailures(result.Errors) {
+		err := &calculation.RequiredRuleError{Failures: result.Errors}
+		calc.FailWithResult(result, err.Error())
+		h.save(ctx, calc)
+		return nil, err
+	}
+	if err != nil {
+		calc.FailWithResult(result, err.Error())
+		h.save(ctx, calc)
+		var mismatch *money.CurrencyMismatchError
+		if errors.As(err, &mismatch) {
+			telemetry.CurrencyMismatchesTotal.Inc()
+		}
+		return nil, err
 	}
+
 	calc.Complete(result)
+	h.save(ctx, calc)
 
-	return &CalculateRulesResult{
+	calculated := &CalculateRulesResult{
 		CalculationID: calc.ID().String(),
+		Status:        calc.Status(),
 		Value:         result.Value,
 		Breakdown:     result.Breakdown,
+		Errors:        result.Errors,
+		Stages:        result.Stages,
+		Lines:         result.Lines,
+		Cached:        cached,
+	}
+	if cmd.DryRun {
+		calculated.CalculationID = "" // never stored, so there is nothing to look up
+	}
+	return calculated, nil
+}
+
+// calculate evaluates the rules of the command within the calculation's time budget and totals them,
+// either as a pipeline or as the sum of the rule values.
+func (h *CalculateRulesHandler) calculate(ctx context.Context, cmd CalculateRulesCommand) (calculation.Result, error) {
+	if h.execution.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.execution.Timeout)
+		defer cancel()
+	}
+	evaluations := len(cmd.RuleIDs)
+	if cmd.Basket != nil {
+		for _, ruleID := range cmd.RuleIDs {
+			if contains(cmd.ItemRuleIDs, ruleID) {
+				evaluations += len(cmd.Basket.Items) - 1
+			}
+		}
+	}
+	budget := newBudget(evaluations, max(1, min(h.execution.Concurrency, evaluations)))
+	if cmd.Pipeline != nil {
+		return h.runPipeline(ctx, cmd, budget)
+	}
+
+	result := calculation.Result{Breakdown: make(map[string]calculation.RuleOutcome, len(cmd.RuleIDs))}
+	calls := basketCalls(cmd.RuleIDs, cmd.Context)
+	outcomes := h.evaluateRules(ctx, cmd, calls, budget)
+	record(&result, calls, outcomes)
+
+	total, err := money.Sum(ctx, okValues(outcomes), cmd.Currency, h.converter)
+	if err != nil {
+		return result, err
+	}
+	result.Value = total
+	return result, nil
+}
+
+// ruleCall is the evaluation of a rule in a context. Item rules are evaluated once per basket line.
+type ruleCall struct {
+	ruleID  string
+	lineID  string
+	context map[string]interface{}
+}
+
+// basketCalls returns the calls of basket-level rules, which are evaluated once.
+func basketCalls(ruleIDs []string, evalContext map[string]interface{}) []ruleCall {
+	calls := make([]ruleCall, len(ruleIDs))
+	for i, ruleID := range ruleIDs {
+		calls[i] = ruleCall{ruleID: ruleID, context: evalContext}
+	}
+	return calls
+}
+
+// evaluateRules makes the calls on a pool of ExecutionOptions.Concurrency workers under the command's
+// failure policy, each within its share of the budget. Under FAIL_FAST the first failing required rule
+// cancels the evaluations in flight, and the rules not evaluated by then are reported as NOT_EVALUATED.
+// The outcomes are in the order of the calls, however the evaluations interleave.
+func (h *CalculateRulesHandler) evaluateRules(ctx context.Context, cmd CalculateRulesCommand, calls []ruleCall, budget *budget) []calculation.RuleOutcome {
+	optional := make(map[string]bool, len(calls))
+	for _, call := range calls {
+		optional[call.ruleID] = cmd.optional(call.ruleID)
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	workers := max(1, min(h.execution.Concurrency, len(calls)))
+	outcomes := make([]calculation.RuleOutcome, len(calls))
+	var stopped atomic.Bool
+
+	evaluate := func(call ruleCall) calculation.RuleOutcome {
+		outcome := calculation.RuleOutcome{Optional: optional[call.ruleID]}
+		if stopped.Load() {
+			return notEvaluated(outcome)
+		}
+		ruleCtx, cancel := budget.ruleContext(ctx)
+		defer cancel()
+		value, err := h.evaluator.Evaluate(ruleCtx, call.ruleID, call.context)
+		if err != nil {
+			if stopped.Load() && errors.Is(ctx.Err(), context.Canceled) {
+				return notEvaluated(outcome)
+			}
+			outcome.Status = calculation.RuleStatusFailed
+			outcome.Error = classify(ruleCtx, err)
+			telemetry.RuleFailuresTotal.WithLabelValues(string(outcome.Error.Code)).Inc()
+			if cmd.FailurePolicy == calculation.PolicyFailFast && !outcome.Optional {
+				stopped.Store(true)
+				stop()
+			}
+			return outcome
+		}
+		// Rules that do not report a currency are assumed to be in the currency of the context.
+		if value.Currency == "" {
+			value.Currency = currencyOf(call.context)
+		}
+		outcome.Money = value
+		outcome.Status = calculation.RuleStatusOK
+		return outcome
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				outcomes[i] = evaluate(calls[i])
+			}
+		}()
+	}
+	for i := range calls {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return outcomes
+}
+
+// record adds the outcomes of the calls to the breakdown and the errors of the result. The breakdown of
+// an item rule adds up its values on all lines; it reports the first failure on any line.
+func record(result *calculation.Result, calls []ruleCall, outcomes []calculation.RuleOutcome) {
+	for i, call := range calls {
+		outcome := outcomes[i]
+		if existing, ok := result.Breakdown[call.ruleID]; ok && call.lineID != "" {
+			if existing.Status == calculation.RuleStatusOK && outcome.Status == calculation.RuleStatusOK {
+				if sum, err := existing.Money.Add(outcome.Money); err == nil {
+					outcome.Money = sum
+				}
+			} else if existing.Status != calculation.RuleStatusOK {
+				outcome = existing
+			}
+		}
+		result.Breakdown[call.ruleID] = outcome
+		if outcomes[i].Error != nil {
+			result.Errors = append(result.Errors, calculation.RuleFailure{
+				RuleID:   call.ruleID,
+				LineID:   call.lineID,
+				Optional: outcomes[i].Optional,
+				Code:     outcomes[i].Error.Code,
+				Message:  outcomes[i].Error.Message,
+			})
+		}
+	}
+}
+
+// okValues returns the values of the rules that were evaluated, in order.
+func okValues(outcomes []calculation.RuleOutcome) []money.Money {
+	values := make([]money.Money, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		if outcome.Status == calculation.RuleStatusOK {
+			values = append(values, outcome.Money)
+		}
+	}
+	return values
+}
+
+func notEvaluated(outcome calculation.RuleOutcome) calculation.RuleOutcome {
+	outcome.Status = calculation.RuleStatusNotEvaluated
+	outcome.Error = calculation.NewRuleError(calculation.ErrorNotEvaluated, "a required rule failed first")
+	return outcome
+}
+
+// budget splits the time left until the deadline of a calculation across its rules. A rule gets the
+// time left divided by the number of rounds the workers still need for the rules not yet started, so
+// that a slow rule cannot use up the time of the rules after it.
+type budget struct {
+	mu        sync.Mutex
+	remaining int
+	workers   int
+}
+
+func newBudget(rules, workers int) *budget {
+	return &budget{remaining: rules, workers: workers}
+}
+
+// ruleContext returns the context for evaluating the next rule.
+func (b *budget) ruleContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	b.mu.Lock()
+	rounds := (b.remaining + b.workers - 1) / b.workers
+	b.remaining--
+	b.mu.Unlock()
+
+	deadline, ok := ctx.Deadline()
+	if !ok || rounds < 1 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/time.Duration(rounds))
+}
+
+// classify returns the RuleError of a failed evaluation. Errors not classified by the evaluator are
+// classified by the state of ctx.
+func classify(ctx context.Context, err error) *calculation.RuleError {
+	var ruleErr *calculation.RuleError
+	switch {
+	case errors.As(err, &ruleErr):
+		return ruleErr
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return calculation.NewRuleError(calculation.ErrorTimeout, "%v", err)
+	}
+	return calculation.NewRuleError(calculation.ErrorEvaluationFailed, "%v", err)
+}
+
+// optional reports whether a failure of the rule leaves it out of the total instead of failing the
+// calculation. Rules are optional unless marked required: listed in RequiredRuleIDs, or left out of
+// OptionalRuleIDs when the command lists optional rules.
+func (cmd CalculateRulesCommand) optional(ruleID string) bool {
+	if contains(cmd.RequiredRuleIDs, ruleID) {
+		return false
+	}
+	return len(cmd.OptionalRuleIDs) == 0 || contains(cmd.OptionalRuleIDs, ruleID)
+}
+
+// requiredFailures reports whether a required rule failed.
+func requiredFailures(failures []calculation.RuleFailure) bool {
+	for _, f := range failures {
+		if !f.Optional && f.Code != calculation.ErrorNotEvaluated {
+			return true
+		}
+	}
+	return false
+}
+
+// validateBasket validates the basket of a command and adds it to the command's context.
+func validateBasket(cmd *CalculateRulesCommand) error {
+	if cmd.Basket == nil {
+		if len(cmd.ItemRuleIDs) > 0 || len(cmd.Allocations) > 0 {
+			return &shared.ValidationError{Field: "basket", Message: "is required for item rules and allocations"}
+		}
+		return nil
+	}
+	if cmd.Pipeline == nil {
+		return &shared.ValidationError{Field: "pipeline", Message: "is required with a basket"}
+	}
+	b, err := cmd.Basket.Normalize()
+	if err != nil {
+		return &shared.ValidationError{Field: "basket", Message: err.Error()}
+	}
+	for ruleID, allocation := range cmd.Allocations {
+		if contains(cmd.ItemRuleIDs, ruleID) {
+			return &shared.ValidationError{Field: "allocations." + ruleID, Message: "item rules are not allocated"}
+		}
+		if err := allocation.Validate(b); err != nil {
+			return &shared.ValidationError{Field: "allocations." + ruleID, Message: err.Error()}
+		}
+	}
+	cmd.Basket = &b
+	cmd.Context = withBasket(cmd.Context, b)
+	return nil
+}
+
+// claim stores a new calculation, claiming its idempotency key. When a retry finds the key taken, it
+// gets the outcome of the calculation holding the key, unless that calculation can be superseded: the
+// key is then released and claimed again, so that the request is calculated anew.
+func (h *CalculateRulesHandler) claim(ctx context.Context, calc *calculation.Calculation) (*CalculateRulesResult, error) {
+	for released := false; ; released = true {
+		err := h.repository.Create(ctx, calc)
+		if !errors.Is(err, calculation.ErrDuplicateIdempotencyKey) {
+			return nil, err
+		}
+		existing, err := h.repository.FindByIdempotencyKey(ctx, calc.IdempotencyKey())
+		if errors.Is(err, calculation.ErrNotFound) && !released {
+			continue // released by a concurrent retry
+		}
+		if err != nil {
+			return nil, err
+		}
+		if released || existing.RequestHash() != calc.RequestHash() || !h.supersedable(existing) {
+			return replay(existing, calc)
+		}
+		if err := h.repository.ReleaseIdempotencyKey(ctx, existing.ID()); err != nil {
+			return nil, err
+		}
+		log.Printf("calculation %s of idempotency key %s is %s; calculating again", existing.ID(), calc.IdempotencyKey(), existing.Status())
+	}
+}
+
+// supersedable reports whether a retry may calculate again instead of replaying the calculation: its
+// required rules failed only because the evaluation service was unavailable, or it has been pending
+// for longer than a calculation may take, so the instance running it must have stopped.
+func (h *CalculateRulesHandler) supersedable(existing *calculation.Calculation) bool {
+	switch existing.Status() {
+	case calculation.StatusFailed:
+		result := existing.Result()
+		return result != nil && (&calculation.RequiredRuleError{Failures: result.Errors}).Unavailable()
+	case calculation.StatusPending:
+		abandonAfter := h.execution.Timeout
+		if abandonAfter <= 0 {
+			abandonAfter = defaultAbandonAfter
+		}
+		return time.Since(existing.CreatedAt()) > abandonAfter
+	}
+	return false
+}
+
+// defaultAbandonAfter is how long a calculation may stay pending when calculations have no time budget.
+const defaultAbandonAfter = time.Minute
+
+// replay returns the outcome of the calculation that first claimed the idempotency key of calc.
+func replay(existing, calc *calculation.Calculation) (*CalculateRulesResult, error) {
+	if existing.RequestHash() != calc.RequestHash() {
+		return nil, calculation.ErrIdempotencyKeyMismatch
+	}
+	switch existing.Status() {
+	case calculation.StatusPending:
+		return nil, calculation.ErrInProgress
+	case calculation.StatusFailed:
+		return nil, &calculation.FailedError{ID: existing.ID(), Reason: existing.Failure()}
+	}
+	telemetry.IdempotentReplaysTotal.Inc()
+	return &CalculateRulesResult{
+		CalculationID: existing.ID().String(),
+		Status:        existing.Status(),
+		Value:         existing.Result().Value,
+		Breakdown:     existing.Result().Breakdown,
+		Errors:        existing.Result().Errors,
+		Stages:        existing.Result().Stages,
+		Lines:         existing.Result().Lines,
+		Replayed:      true,
 	}, nil
 }
+
+// save stores the outcome of a calculation. The outcome is returned to the caller even when it cannot be
+// stored, so that a history outage does not fail checkouts. Dry runs are not stored.
+func (h *CalculateRulesHandler) save(ctx context.Context, calc *calculation.Calculation) {
+	if calculation.IsDryRun(ctx) {
+		return
+	}
+	if err := h.repository.Update(ctx, calc); err != nil {
+		log.Printf("failed to store calculation %s: %v", calc.ID(), err)
+	}
+}
+
+// requestHash identifies the content of a request, so that a reused idempotency key can be told apart
+// from a retry.
+func requestHash(cmd CalculateRulesCommand) (string, error) {
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode calculation request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// currencyOf returns the valid currency code of the context, read from "currency" or "order.currency".
+func currencyOf(context map[string]interface{}) string {
+	currency, _ := context["currency"].(string)
+	if currency == "" {
+		if order, ok := context["order"].(map[string]interface{}); ok {
+			currency, _ = order["currency"].(string)
+		}
+	}
+	if code, err := money.ParseCurrency(currency); err == nil {
+		return code
+	}
+	return ""
+}
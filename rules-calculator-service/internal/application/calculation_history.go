@@ -0,0 +1,93 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+// GetCalculationHandler returns a stored calculation.
+type GetCalculationHandler struct {
+	repository calculation.Repository
+}
+
+// NewGetCalculationHandler creates a new GetCalculationHandler.
+func NewGetCalculationHandler(repository calculation.Repository) *GetCalculationHandler {
+	return &GetCalculationHandler{repository: repository}
+}
+
+// Handle returns the calculation with the given ID, or calculation.ErrNotFound.
+func (h *GetCalculationHandler) Handle(ctx context.Context, id string) (*calculation.Calculation, error) {
+	ctx, span := otel.Tracer("application").Start(ctx, "GetCalculationHandler.Handle")
+	defer span.End()
+
+	calculationID, err := calculation.ParseCalculationID(id)
+	if err != nil {
+		return nil, &shared.ValidationError{Field: "id", Message: "must be a UUID"}
+	}
+	return h.repository.FindByID(ctx, calculationID)
+}
+
+// ListCalculationsQuery selects calculations from the history.
+type ListCalculationsQuery struct {
+	CustomerID string
+	From       *time.Time // inclusive
+	To         *time.Time // exclusive
+	Limit      int        // defaults to 50, at most 500
+	Offset     int
+}
+
+// CalculationPage is a page of the calculation history.
+type CalculationPage struct {
+	Calculations []*calculation.Calculation
+	Limit        int
+	Offset       int
+}
+
+// ListCalculationsHandler lists stored calculations, most recent first.
+type ListCalculationsHandler struct {
+	repository calculation.Repository
+}
+
+// NewListCalculationsHandler creates a new ListCalculationsHandler.
+func NewListCalculationsHandler(repository calculation.Repository) *ListCalculationsHandler {
+	return &ListCalculationsHandler{repository: repository}
+}
+
+// Handle lists the calculations matching the query.
+func (h *ListCalculationsHandler) Handle(ctx context.Context, query ListCalculationsQuery) (*CalculationPage, error) {
+	ctx, span := otel.Tracer("application").Start(ctx, "ListCalculationsHandler.Handle")
+	defer span.End()
+
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return nil, &shared.ValidationError{Field: "from", Message: "must be before to"}
+	}
+	if query.Limit < 0 || query.Limit > maxHistoryLimit {
+		return nil, &shared.ValidationError{Field: "limit", Message: "must be between 1 and 500"}
+	}
+	if query.Offset < 0 {
+		return nil, &shared.ValidationError{Field: "offset", Message: "must not be negative"}
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultHistoryLimit
+	}
+	calculations, err := h.repository.List(ctx, calculation.Filter{
+		CustomerID: query.CustomerID,
+		From:       query.From,
+		To:         query.To,
+		Limit:      query.Limit,
+		Offset:     query.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CalculationPage{Calculations: calculations, Limit: query.Limit, Offset: query.Offset}, nil
+}
@@ -0,0 +1,192 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxScenarios is the largest number of scenarios in a comparison.
+const maxScenarios = 10
+
+// Scenario is a selection of rules calculated in a comparison: a rule set of the catalog, or the rules
+// of the RuleSet it embeds.
+type Scenario struct {
+	// Name identifies the scenario in the result; it defaults to the rule set ID, or to its position.
+	Name      string
+	RuleSetID string
+	calculation.RuleSet
+}
+
+// CompareCommand is the command for calculating the same request under several selections of rules.
+type CompareCommand struct {
+	// Request holds what the scenarios share: the context, basket, currency, failure policy,
+	// allocations and rule versions. Its rules are replaced by those of each scenario.
+	Request CalculateRulesCommand
+	// Scenarios are the selections of rules to compare; the first one is the baseline.
+	Scenarios []Scenario
+}
+
+// ScenarioResult is the calculation of a scenario and its difference from the baseline.
+type ScenarioResult struct {
+	Name      string
+	RuleSetID string
+	// Result is nil when the calculation failed; Error then says why, and Errors lists the failed
+	// rules when required rules failed.
+	Result *CalculateRulesResult
+	Error  string
+	Errors []calculation.RuleFailure
+	// Difference is the difference from the baseline. It is nil for the baseline itself and when
+	// either calculation failed.
+	Difference *calculation.Difference
+}
+
+// CompareResult is the outcome of a comparison, with the scenarios in request order.
+type CompareResult struct {
+	Baseline  string
+	Scenarios []ScenarioResult
+}
+
+// CompareHandler calculates a request under several selections of rules side by side, e.g. the rules
+// in force and proposed ones.
+type CompareHandler struct {
+	calculator *CalculateRulesHandler
+	ruleSets   calculation.RuleSetCatalog
+}
+
+// NewCompareHandler creates a new CompareHandler resolving the rule sets of scenarios in ruleSets.
+func NewCompareHandler(calculator *CalculateRulesHandler, ruleSets calculation.RuleSetCatalog) *CompareHandler {
+	return &CompareHandler{calculator: calculator, ruleSets: ruleSets}
+}
+
+// Handle calculates every scenario of the command at the same time and compares each with the
+// baseline. The scenarios are calculated as dry runs: they are not stored in the calculation history
+// and have no side effects, such as coupon reservations. A scenario failing validation
+// fails the comparison; any other failure of a scenario is reported in its result.
+func (h *CompareHandler) Handle(ctx context.Context, cmd CompareCommand) (*CompareResult, error) {
+	ctx, span := otel.Tracer("application").Start(ctx, "CompareHandler.Handle")
+	defer span.End()
+
+	if len(cmd.Scenarios) < 2 || len(cmd.Scenarios) > maxScenarios {
+		return nil, &shared.ValidationError{Field: "scenarios", Message: fmt.Sprintf("must have between 2 and %d scenarios", maxScenarios)}
+	}
+	scenarios := make([]Scenario, len(cmd.Scenarios))
+	names := make(map[string]bool, len(cmd.Scenarios))
+	for i, scenario := range cmd.Scenarios {
+		resolved, err := h.resolve(i, scenario)
+		if err != nil {
+			return nil, err
+		}
+		if names[resolved.Name] {
+			return nil, &shared.ValidationError{Field: fmt.Sprintf("scenarios[%d].name", i), Message: "must be unique"}
+		}
+		names[resolved.Name] = true
+		scenarios[i] = resolved
+	}
+	span.SetAttributes(attribute.Int("comparison.scenarios", len(scenarios)))
+
+	results := make([]ScenarioResult, len(scenarios))
+	errs := make([]error, len(scenarios))
+	var wg sync.WaitGroup
+	for i, scenario := range scenarios {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = h.calculate(ctx, cmd.Request, scenario)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		var validationErr *shared.ValidationError
+		if errors.As(err, &validationErr) {
+			return nil, &shared.ValidationError{Field: fmt.Sprintf("scenarios[%d].%s", i, validationErr.Field), Message: validationErr.Message}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	baseline := results[0].Result
+	for i := 1; i < len(results); i++ {
+		if baseline == nil || results[i].Result == nil {
+			continue
+		}
+		difference := calculation.Compare(toResult(baseline), toResult(results[i].Result))
+		results[i].Difference = &difference
+	}
+	telemetry.ComparisonsTotal.Inc()
+	return &CompareResult{Baseline: results[0].Name, Scenarios: results}, nil
+}
+
+// resolve names a scenario and replaces its rule set ID with the rules of the rule set.
+func (h *CompareHandler) resolve(index int, scenario Scenario) (Scenario, error) {
+	field := fmt.Sprintf("scenarios[%d]", index)
+	hasRules := scenario.RuleIDs != nil || scenario.Pipeline != nil
+	switch {
+	case scenario.RuleSetID != "" && hasRules:
+		return Scenario{}, &shared.ValidationError{Field: field, Message: "must have either a rule_set_id or rules, not both"}
+	case scenario.RuleSetID != "":
+		ruleSet, ok := h.ruleSets.RuleSet(scenario.RuleSetID)
+		if !ok {
+			return Scenario{}, &shared.ValidationError{Field: field + ".rule_set_id", Message: fmt.Sprintf("unknown rule set %s", scenario.RuleSetID)}
+		}
+		scenario.RuleSet = ruleSet
+		if scenario.Name == "" {
+			scenario.Name = scenario.RuleSetID
+		}
+	case !hasRules:
+		return Scenario{}, &shared.ValidationError{Field: field, Message: "must have a rule_set_id, rule_ids or a pipeline"}
+	}
+	if scenario.Name == "" {
+		scenario.Name = fmt.Sprintf("scenario-%d", index+1)
+	}
+	return scenario, nil
+}
+
+// calculate runs the calculation of a scenario. Only validation errors are returned; other failures
+// are reported in the result.
+func (h *CompareHandler) calculate(ctx context.Context, request CalculateRulesCommand, scenario Scenario) (ScenarioResult, error) {
+	result := ScenarioResult{Name: scenario.Name, RuleSetID: scenario.RuleSetID}
+
+	cmd := request
+	cmd.RuleIDs = scenario.RuleIDs
+	cmd.Pipeline = scenario.Pipeline
+	cmd.ItemRuleIDs = scenario.ItemRuleIDs
+	cmd.OptionalRuleIDs = scenario.OptionalRuleIDs
+	cmd.RequiredRuleIDs = scenario.RequiredRuleIDs
+	cmd.IdempotencyKey = ""
+	cmd.DryRun = true
+
+	calculated, err := h.calculator.Handle(ctx, cmd)
+	var validationErr *shared.ValidationError
+	if errors.As(err, &validationErr) {
+		return result, err
+	}
+	if err != nil {
+		result.Error = err.Error()
+		var required *calculation.RequiredRuleError
+		if errors.As(err, &required) {
+			result.Errors = required.Failures
+		}
+		return result, nil
+	}
+	result.Result = calculated
+	return result, nil
+}
+
+func toResult(r *CalculateRulesResult) calculation.Result {
+	return calculation.Result{
+		Value:     r.Value,
+		Breakdown: r.Breakdown,
+		Errors:    r.Errors,
+		Stages:    r.Stages,
+		Lines:     r.Lines,
+	}
+}
@@ -0,0 +1,331 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/job"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrQueueFull is returned when a job is submitted while the job queue is full.
+var ErrQueueFull = errors.New("job queue is full")
+
+// JobOptions bounds the background processing of calculation jobs.
+type JobOptions struct {
+	// Workers is the number of calculations of a job run at the same time.
+	Workers int
+	// QueueSize is the number of jobs that can wait for the running job to complete.
+	QueueSize int
+	// MaxItems is the largest number of calculations in a job.
+	MaxItems int
+}
+
+// JobPublisher publishes the results of jobs as they are calculated.
+type JobPublisher interface {
+	PublishResult(ctx context.Context, result job.Result) error
+	// PublishCompleted announces that every calculation of a job has a result.
+	PublishCompleted(ctx context.Context, j *job.Job) error
+}
+
+// JobItem is a calculation of a job.
+type JobItem struct {
+	Reference string
+	Command   CalculateRulesCommand
+}
+
+// SubmitJobCommand is the command for submitting a calculation job.
+type SubmitJobCommand struct {
+	Reference string
+	Items     []JobItem
+}
+
+// JobResultPage is a page of the results of a job.
+type JobResultPage struct {
+	Results []job.Result
+	Limit   int
+	Offset  int
+}
+
+// JobRunner calculates jobs in the background. Jobs run one at a time in submission order, and the
+// calculations of the running job are spread over the workers. A job interrupted by a shutdown stays
+// pending and is resumed by Recover.
+type JobRunner struct {
+	calculator *CalculateRulesHandler
+	repository job.Repository
+	publisher  JobPublisher
+	options    JobOptions
+	// slots reserves a place in the queue before a job is stored, so that a full queue rejects a job
+	// rather than leaving it stored and unprocessed.
+	slots chan struct{}
+	queue chan *job.Job
+	wg    sync.WaitGroup
+}
+
+// NewJobRunner creates a new JobRunner. Call Start to process the submitted jobs.
+func NewJobRunner(calculator *CalculateRulesHandler, repository job.Repository, options JobOptions) *JobRunner {
+	if options.Workers <= 0 {
+		options.Workers = 1
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = 1
+	}
+	return &JobRunner{
+		calculator: calculator,
+		repository: repository,
+		options:    options,
+		slots:      make(chan struct{}, options.QueueSize),
+		queue:      make(chan *job.Job, options.QueueSize),
+	}
+}
+
+// SetPublisher sets the publisher of job results. Without one, results are only stored.
+func (r *JobRunner) SetPublisher(publisher JobPublisher) {
+	r.publisher = publisher
+}
+
+// Start processes queued jobs until ctx is cancelled.
+func (r *JobRunner) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case j := <-r.queue:
+				<-r.slots
+				telemetry.JobQueueDepth.Set(float64(len(r.queue)))
+				r.run(ctx, j)
+			}
+		}
+	}()
+}
+
+// Wait blocks until the runner has stopped after its context was cancelled.
+func (r *JobRunner) Wait() {
+	r.wg.Wait()
+}
+
+// Submit stores a job for the calculations of the command and queues it. It returns ErrQueueFull when
+// the queue has no room for the job.
+func (r *JobRunner) Submit(ctx context.Context, cmd SubmitJobCommand) (*job.Job, error) {
+	ctx, span := otel.Tracer("application").Start(ctx, "JobRunner.Submit")
+	defer span.End()
+
+	if len(cmd.Items) == 0 {
+		return nil, &shared.ValidationError{Field: "items", Message: "must not be empty"}
+	}
+	if r.options.MaxItems > 0 && len(cmd.Items) > r.options.MaxItems {
+		return nil, &shared.ValidationError{Field: "items", Message: fmt.Sprintf("must not have more than %d items", r.options.MaxItems)}
+	}
+	items := make([]job.Item, len(cmd.Items))
+	for i, item := range cmd.Items {
+		request, err := json.Marshal(item.Command)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode job item %d: %w", i, err)
+		}
+		items[i] = job.Item{Reference: item.Reference, Request: request}
+	}
+
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		return nil, ErrQueueFull
+	}
+	j := job.NewJob(cmd.Reference, items)
+	if err := r.repository.Create(ctx, j); err != nil {
+		<-r.slots
+		return nil, err
+	}
+	r.queue <- j
+	telemetry.JobQueueDepth.Set(float64(len(r.queue)))
+	telemetry.JobsTotal.WithLabelValues("submitted").Inc()
+
+	span.SetAttributes(
+		attribute.String("job.id", j.ID().String()),
+		attribute.Int("job.items", j.Total()),
+	)
+	return j, nil
+}
+
+// Recover queues the jobs left pending by an earlier run of the service, waiting for room in the
+// queue. Replicas sharing a database must not recover at the same time, or they would both resume
+// the same jobs.
+func (r *JobRunner) Recover(ctx context.Context) (int, error) {
+	jobs, err := r.repository.FindPending(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i, j := range jobs {
+		select {
+		case r.slots <- struct{}{}:
+		case <-ctx.Done():
+			return i, ctx.Err()
+		}
+		r.queue <- j
+		telemetry.JobQueueDepth.Set(float64(len(r.queue)))
+	}
+	return len(jobs), nil
+}
+
+// Get returns the job with the given ID, or job.ErrNotFound.
+func (r *JobRunner) Get(ctx context.Context, id string) (*job.Job, error) {
+	ctx, span := otel.Tracer("application").Start(ctx, "JobRunner.Get")
+	defer span.End()
+
+	jobID, err := job.ParseJobID(id)
+	if err != nil {
+		return nil, &shared.ValidationError{Field: "id", Message: "must be a UUID"}
+	}
+	return r.repository.FindByID(ctx, jobID)
+}
+
+// Results returns a page of the results of the job with the given ID, in item order. The limit
+// defaults to 50 and is at most 500.
+func (r *JobRunner) Results(ctx context.Context, id string, limit, offset int) (*JobResultPage, error) {
+	ctx, span := otel.Tracer("application").Start(ctx, "JobRunner.Results")
+	defer span.End()
+
+	jobID, err := job.ParseJobID(id)
+	if err != nil {
+		return nil, &shared.ValidationError{Field: "id", Message: "must be a UUID"}
+	}
+	if limit < 0 || limit > maxHistoryLimit {
+		return nil, &shared.ValidationError{Field: "limit", Message: "must be between 1 and 500"}
+	}
+	if offset < 0 {
+		return nil, &shared.ValidationError{Field: "offset", Message: "must not be negative"}
+	}
+	if limit == 0 {
+		limit = defaultHistoryLimit
+	}
+	results, err := r.repository.ListResults(ctx, jobID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &JobResultPage{Results: results, Limit: limit, Offset: offset}, nil
+}
+
+// run calculates the items of a job that have no result yet. Results calculated after ctx was
+// cancelled are dropped: the job stays pending and those items are calculated again when it resumes.
+func (r *JobRunner) run(ctx context.Context, j *job.Job) {
+	ctx, span := otel.Tracer("application").Start(ctx, "JobRunner.run")
+	defer span.End()
+	span.SetAttributes(attribute.String("job.id", j.ID().String()))
+
+	stored, err := r.repository.ListResults(ctx, j.ID(), 0, 0)
+	if err != nil {
+		log.Printf("failed to load the results of job %s: %v", j.ID(), err)
+		return
+	}
+	done := make(map[int]bool, len(stored))
+	for _, result := range stored {
+		done[result.Index] = true
+	}
+	j.Resume(stored)
+	j.Start()
+	r.update(ctx, j)
+
+	indexes := make(chan int)
+	results := make(chan job.Result)
+	go func() {
+		defer close(indexes)
+		for index := range j.Items() {
+			if done[index] {
+				continue
+			}
+			select {
+			case indexes <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var workers sync.WaitGroup
+	for i := 0; i < r.options.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for index := range indexes {
+				results <- r.calculate(ctx, j, index)
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		if ctx.Err() != nil {
+			continue
+		}
+		r.record(ctx, j, result)
+	}
+	if j.Status() == calculation.StatusPending {
+		return
+	}
+
+	telemetry.JobsTotal.WithLabelValues(string(j.Status())).Inc()
+	if r.publisher != nil {
+		if err := r.publisher.PublishCompleted(ctx, j); err != nil {
+			log.Printf("failed to publish the completion of job %s: %v", j.ID(), err)
+		}
+	}
+}
+
+// calculate runs the calculation of an item. A request the calculator rejects is a failed result, not
+// an error of the job.
+func (r *JobRunner) calculate(ctx context.Context, j *job.Job, index int) job.Result {
+	item := j.Items()[index]
+	result := job.Result{JobID: j.ID(), Index: index, Reference: item.Reference}
+
+	var cmd CalculateRulesCommand
+	if err := json.Unmarshal(item.Request, &cmd); err != nil {
+		result.Status = calculation.StatusFailed
+		result.Error = fmt.Sprintf("invalid request: %v", err)
+		return result
+	}
+	calculated, err := r.calculator.Handle(ctx, cmd)
+	if err != nil {
+		result.Status = calculation.StatusFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.CalculationID = calculated.CalculationID
+	result.Status = calculated.Status
+	result.Value = &calculated.Value
+	result.Errors = calculated.Errors
+	return result
+}
+
+// record stores and publishes the result of an item, and the progress of its job.
+func (r *JobRunner) record(ctx context.Context, j *job.Job, result job.Result) {
+	if err := r.repository.SaveResult(ctx, result); err != nil {
+		log.Printf("failed to store result %d of job %s: %v", result.Index, j.ID(), err)
+		return
+	}
+	telemetry.JobItemsTotal.WithLabelValues(string(result.Status)).Inc()
+	if r.publisher != nil {
+		if err := r.publisher.PublishResult(ctx, result); err != nil {
+			log.Printf("failed to publish result %d of job %s: %v", result.Index, j.ID(), err)
+		}
+	}
+	j.Record(result)
+	r.update(ctx, j)
+}
+
+func (r *JobRunner) update(ctx context.Context, j *job.Job) {
+	if err := r.repository.Update(ctx, j); err != nil {
+		log.Printf("failed to store the progress of job %s: %v", j.ID(), err)
+	}
+}
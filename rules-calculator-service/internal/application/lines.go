@@ -0,0 +1,166 @@
+package application
+
+import (
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/basket"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/shopspring/decimal"
+)
+
+// allocationPlaces is the precision amounts are allocated to lines with.
+const allocationPlaces = 2
+
+// ledger keeps the discounts and taxes applied to the lines of a basket during a pipeline.
+type ledger struct {
+	currency string
+	lines    []*lineState
+}
+
+type lineState struct {
+	item        basket.Item
+	discount    decimal.Decimal
+	tax         decimal.Decimal
+	adjustments []calculation.Adjustment
+}
+
+func newLedger(b basket.Basket) *ledger {
+	l := &ledger{currency: b.Currency}
+	for _, item := range b.Items {
+		l.lines = append(l.lines, &lineState{item: item, discount: decimal.Zero, tax: decimal.Zero})
+	}
+	return l
+}
+
+func (s *lineState) net() decimal.Decimal {
+	return s.item.Amount().Sub(s.discount)
+}
+
+// index returns the position of the line with the given ID.
+func (l *ledger) index(lineID string) int {
+	for i, line := range l.lines {
+		if line.item.LineID == lineID {
+			return i
+		}
+	}
+	return -1
+}
+
+// net returns the amount of the basket after discounts.
+func (l *ledger) net() decimal.Decimal {
+	total := decimal.Zero
+	for _, line := range l.lines {
+		total = total.Add(line.net())
+	}
+	return total
+}
+
+// apply applies a rule's value to a line and returns the amount applied. Discounts are capped at the
+// line's net amount.
+func (l *ledger) apply(i int, stage calculation.StageCategory, ruleID string, amount decimal.Decimal) decimal.Decimal {
+	line := l.lines[i]
+	switch stage {
+	case calculation.StageDiscounts:
+		amount = decimal.Min(amount, line.net())
+		line.discount = line.discount.Add(amount)
+	case calculation.StageTaxes:
+		line.tax = line.tax.Add(amount)
+	default:
+		return amount
+	}
+	line.adjustments = append(line.adjustments, calculation.Adjustment{
+		RuleID: ruleID,
+		Stage:  stage,
+		Amount: money.Money{Amount: amount, Currency: l.currency},
+	})
+	return amount
+}
+
+// allocate spreads a basket-level rule value over the lines the allocation targets, in proportion to
+// their net amounts, and returns the amount applied.
+func (l *ledger) allocate(stage calculation.StageCategory, ruleID string, amount decimal.Decimal, allocation basket.Allocation) decimal.Decimal {
+	weights := make([]decimal.Decimal, len(l.lines))
+	var targets []int
+	for i, line := range l.lines {
+		if allocation.Matches(line.item) {
+			weights[len(targets)] = line.net()
+			targets = append(targets, i)
+		}
+	}
+	applied := decimal.Zero
+	for j, share := range basket.Allocate(amount, weights[:len(targets)], allocationPlaces) {
+		applied = applied.Add(l.apply(targets[j], stage, ruleID, share))
+	}
+	return applied
+}
+
+// items returns the lines as the context's items, priced at their net unit prices, so that the rules
+// of a stage see the discounts of the stages before it.
+func (l *ledger) items() []interface{} {
+	items := make([]interface{}, len(l.lines))
+	for i, line := range l.lines {
+		items[i] = line.contextItem()
+	}
+	return items
+}
+
+func (s *lineState) contextItem() map[string]interface{} {
+	return map[string]interface{}{
+		"line_id":  s.item.LineID,
+		"sku":      s.item.SKU,
+		"category": s.item.Category,
+		"quantity": float64(s.item.Quantity),
+		"price":    s.unitNet().InexactFloat64(),
+	}
+}
+
+func (s *lineState) unitNet() decimal.Decimal {
+	return s.net().DivRound(decimal.NewFromInt(int64(s.item.Quantity)), 4)
+}
+
+// results returns the lines with their adjustments.
+func (l *ledger) results() []calculation.LineResult {
+	results := make([]calculation.LineResult, len(l.lines))
+	for i, line := range l.lines {
+		m := func(amount decimal.Decimal) money.Money {
+			return money.Money{Amount: amount, Currency: l.currency}
+		}
+		results[i] = calculation.LineResult{
+			LineID:            line.item.LineID,
+			SKU:               line.item.SKU,
+			Category:          line.item.Category,
+			Quantity:          line.item.Quantity,
+			UnitPrice:         m(line.item.UnitPrice),
+			Amount:            m(line.item.Amount()),
+			Discount:          m(line.discount),
+			Net:               m(line.net()),
+			AdjustedUnitPrice: m(line.unitNet()),
+			Tax:               m(line.tax),
+			Gross:             m(line.net().Add(line.tax)),
+			Adjustments:       line.adjustments,
+		}
+	}
+	return results
+}
+
+// withBasket returns a copy of the context with the basket as items, its subtotal as order.amount and
+// its currency as the context's currency.
+func withBasket(evalContext map[string]interface{}, b basket.Basket) map[string]interface{} {
+	basketCtx := make(map[string]interface{}, len(evalContext)+1)
+	for key, value := range evalContext {
+		basketCtx[key] = value
+	}
+	order := make(map[string]interface{})
+	if original, ok := evalContext["order"].(map[string]interface{}); ok {
+		for key, value := range original {
+			order[key] = value
+		}
+	}
+	order["amount"] = b.Subtotal().Amount.InexactFloat64()
+	order["currency"] = b.Currency
+	basketCtx["order"] = order
+	if _, ok := evalContext["currency"]; ok {
+		basketCtx["currency"] = b.Currency
+	}
+	basketCtx["items"] = newLedger(b).items()
+	return basketCtx
+}
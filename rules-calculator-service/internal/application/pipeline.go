@@ -0,0 +1,263 @@
+package application
+
+import (
+	"context"
+	"strings"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/basket"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// runPipeline calculates the checkout total stage by stage, starting from the context's order.amount.
+// Each stage's rules see the amount left by the stages before it as order.amount, and the totals of
+// those stages under pipeline.subtotal, pipeline.discounts, pipeline.fees and pipeline.taxes.
+//
+// The stages run in the currency of the context; rule values in other currencies are converted, and
+// the final amount is converted to the requested currency. Under FAIL_FAST, the stages after a failed
+// required rule are not evaluated.
+//
+// With a basket, item rules are evaluated for each line and their values apply to that line, while the
+// values of basket-level discount and tax rules are allocated to the lines. The amount after discounts
+// is then the sum of the lines' net amounts, and discounts exceeding the net amount of their lines are
+// reported at the amount applied.
+func (h *CalculateRulesHandler) runPipeline(ctx context.Context, cmd CalculateRulesCommand, budget *budget) (calculation.Result, error) {
+	ctx, span := otel.Tracer("application").Start(ctx, "CalculateRulesHandler.runPipeline")
+	defer span.End()
+	span.SetAttributes(attribute.Int("pipeline.stages", len(cmd.Pipeline.Stages)))
+
+	result := calculation.Result{Breakdown: make(map[string]calculation.RuleOutcome, len(cmd.RuleIDs))}
+
+	currency := currencyOf(cmd.Context)
+	if currency == "" {
+		currency = cmd.Currency
+	}
+	subtotal, _ := orderAmount(cmd.Context)
+	amount := money.Money{Amount: subtotal, Currency: currency}
+	totals := map[string]interface{}{"subtotal": subtotal.InexactFloat64()}
+
+	loyaltyBase := cmd.Pipeline.LoyaltyBase
+	if loyaltyBase == "" {
+		loyaltyBase = h.pipeline.LoyaltyBase
+	}
+	preTax := amount
+	skip := false
+
+	var lines *ledger
+	itemRules := make(map[string]bool, len(cmd.ItemRuleIDs))
+	if cmd.Basket != nil {
+		lines = newLedger(*cmd.Basket)
+		for _, ruleID := range cmd.ItemRuleIDs {
+			itemRules[ruleID] = true
+		}
+	}
+
+	for _, stage := range cmd.Pipeline.Stages {
+		base := amount
+		switch stage.Category {
+		case calculation.StageTaxes:
+			preTax = amount
+		case calculation.StageLoyalty:
+			if loyaltyBase == calculation.LoyaltyPreTax {
+				base = preTax
+			}
+		}
+
+		stageCtx := stageContext(cmd.Context, base, totals, lines)
+		var calls []ruleCall
+		for _, ruleID := range stage.RuleIDs {
+			if lines == nil || !itemRules[ruleID] {
+				calls = append(calls, ruleCall{ruleID: ruleID, context: stageCtx})
+				continue
+			}
+			for _, line := range lines.lines {
+				calls = append(calls, ruleCall{ruleID: ruleID, lineID: line.item.LineID, context: lineContext(stageCtx, line)})
+			}
+		}
+		var outcomes []calculation.RuleOutcome
+		if skip {
+			for _, call := range calls {
+				outcomes = append(outcomes, notEvaluated(calculation.RuleOutcome{Optional: cmd.optional(call.ruleID)}))
+			}
+		} else {
+			outcomes = h.evaluateRules(ctx, cmd, calls, budget)
+		}
+
+		stageResult := calculation.StageResult{Category: stage.Category, Base: base}
+		if stage.Category == calculation.StageLoyalty {
+			points := decimal.Zero
+			for _, value := range okValues(outcomes) {
+				points = points.Add(value.Amount)
+			}
+			stageResult.Points = &points
+		} else {
+			total, err := h.applyStage(ctx, stage.Category, calls, outcomes, currency, lines, cmd.Allocations)
+			if err != nil {
+				record(&result, calls, outcomes)
+				return result, err
+			}
+			switch {
+			case stage.Category == calculation.StageDiscounts && lines != nil:
+				amount.Amount = lines.net()
+			case stage.Category == calculation.StageDiscounts:
+				amount.Amount = decimal.Max(amount.Amount.Sub(total.Amount), decimal.Zero)
+			default:
+				amount.Amount = amount.Amount.Add(total.Amount)
+			}
+			stageResult.Total = &total
+			totals[strings.ToLower(string(stage.Category))] = total.Amount.InexactFloat64()
+		}
+		record(&result, calls, outcomes)
+		for _, ruleID := range stage.RuleIDs {
+			stageResult.Rules = append(stageResult.Rules, calculation.RuleLine{RuleID: ruleID, RuleOutcome: result.Breakdown[ruleID]})
+		}
+		stageResult.Amount = amount
+		result.Stages = append(result.Stages, stageResult)
+
+		if cmd.FailurePolicy == calculation.PolicyFailFast && requiredFailures(result.Errors) {
+			skip = true
+		}
+	}
+
+	result.Value = amount
+	if lines != nil {
+		result.Lines = lines.results()
+	}
+	if cmd.Currency != "" && cmd.Currency != currency {
+		value, err := money.Sum(ctx, []money.Money{amount}, cmd.Currency, h.converter)
+		if err != nil {
+			return result, err
+		}
+		result.Value = value
+	}
+	return result, nil
+}
+
+// applyStage totals the values of a stage's rules in the stage currency and, with a basket, applies them
+// to the lines. Discounts are capped at the net amount of the lines they apply to; the outcome of a
+// capped rule is then the amount applied, so that the stage total and the breakdown add up to the
+// discounts of the lines.
+func (h *CalculateRulesHandler) applyStage(ctx context.Context, stage calculation.StageCategory, calls []ruleCall, outcomes []calculation.RuleOutcome, currency string, lines *ledger, allocations map[string]basket.Allocation) (money.Money, error) {
+	total := money.Money{Amount: decimal.Zero, Currency: currency}
+	for i, call := range calls {
+		if outcomes[i].Status != calculation.RuleStatusOK {
+			continue
+		}
+		value, err := h.sumIn(ctx, []money.Money{outcomes[i].Money}, currency)
+		if err != nil {
+			return total, err
+		}
+		if lines != nil {
+			var applied decimal.Decimal
+			if call.lineID != "" {
+				applied = lines.apply(lines.index(call.lineID), stage, call.ruleID, value.Amount)
+			} else {
+				applied = lines.allocate(stage, call.ruleID, value.Amount, allocations[call.ruleID])
+			}
+			if !applied.Equal(value.Amount) {
+				value.Amount = applied
+				outcomes[i].Money = value
+			}
+		}
+		if total, err = total.Add(value); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// sumIn adds up the values in the given currency, converting values in other currencies.
+func (h *CalculateRulesHandler) sumIn(ctx context.Context, values []money.Money, currency string) (money.Money, error) {
+	total := money.Money{Amount: decimal.Zero, Currency: currency}
+	for _, value := range values {
+		if value.Currency != currency && currency != "" {
+			converted, err := money.Sum(ctx, []money.Money{value}, currency, h.converter)
+			if err != nil {
+				return money.Money{}, err
+			}
+			value = converted
+		}
+		var err error
+		if total, err = total.Add(value); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// stageContext returns a copy of the context for a stage, with order.amount set to the stage's base
+// amount, the totals of the previous stages under pipeline and, for a basket, the lines at their net
+// prices as items.
+func stageContext(evalContext map[string]interface{}, base money.Money, totals map[string]interface{}, lines *ledger) map[string]interface{} {
+	stageCtx := make(map[string]interface{}, len(evalContext)+1)
+	for key, value := range evalContext {
+		stageCtx[key] = value
+	}
+	order := make(map[string]interface{})
+	if original, ok := evalContext["order"].(map[string]interface{}); ok {
+		for key, value := range original {
+			order[key] = value
+		}
+	}
+	order["amount"] = base.Amount.InexactFloat64()
+	stageCtx["order"] = order
+
+	if lines != nil {
+		stageCtx["items"] = lines.items()
+	}
+	pipeline := make(map[string]interface{}, len(totals))
+	for key, value := range totals {
+		pipeline[key] = value
+	}
+	stageCtx["pipeline"] = pipeline
+	return stageCtx
+}
+
+// lineContext returns the context of an item rule for a line: the line as item and as the only item of
+// the basket, with its net amount as order.amount.
+func lineContext(stageCtx map[string]interface{}, line *lineState) map[string]interface{} {
+	lineCtx := make(map[string]interface{}, len(stageCtx)+1)
+	for key, value := range stageCtx {
+		lineCtx[key] = value
+	}
+	order := make(map[string]interface{})
+	for key, value := range stageCtx["order"].(map[string]interface{}) {
+		order[key] = value
+	}
+	order["amount"] = line.net().InexactFloat64()
+	lineCtx["order"] = order
+	item := line.contextItem()
+	lineCtx["item"] = item
+	lineCtx["items"] = []interface{}{item}
+	return lineCtx
+}
+
+// orderAmount returns the context's order.amount.
+func orderAmount(evalContext map[string]interface{}) (decimal.Decimal, bool) {
+	order, ok := evalContext["order"].(map[string]interface{})
+	if !ok {
+		return decimal.Decimal{}, false
+	}
+	switch amount := order["amount"].(type) {
+	case float64:
+		return decimal.NewFromFloat(amount), true
+	case int:
+		return decimal.NewFromInt(int64(amount)), true
+	case string:
+		d, err := decimal.NewFromString(amount)
+		return d, err == nil
+	}
+	return decimal.Decimal{}, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/quote"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// QuoteHandler creates quotes, which guarantee the result of a calculation until they expire, and
+// confirms them.
+type QuoteHandler struct {
+	calculator *CalculateRulesHandler
+	repository quote.Repository
+	signer     *quote.Signer
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+// NewQuoteHandler creates a new QuoteHandler whose quotes are valid for ttl.
+func NewQuoteHandler(calculator *CalculateRulesHandler, repository quote.Repository, signer *quote.Signer, ttl time.Duration) *QuoteHandler {
+	return &QuoteHandler{
+		calculator: calculator,
+		repository: repository,
+		signer:     signer,
+		ttl:        ttl,
+		now:        time.Now,
+	}
+}
+
+// SetClock replaces the clock quotes expire by.
+func (h *QuoteHandler) SetClock(now func() time.Time) {
+	h.now = now
+}
+
+// ConfirmQuoteResult is the outcome of confirming a quote.
+type ConfirmQuoteResult struct {
+	// Quote is the confirmed quote, or the expired one.
+	Quote *quote.Quote
+	// Offer is set when the quote had expired: a new quote for the same request, at the current rules.
+	Offer *quote.Quote
+}
+
+// Expired reports whether the quote expired before it was confirmed.
+func (r *ConfirmQuoteResult) Expired() bool {
+	return r.Offer != nil
+}
+
+// Create calculates the command and stores the result as a signed quote, together with the rule
+// versions it was calculated with.
+func (h *QuoteHandler) Create(ctx context.Context, cmd CalculateRulesCommand) (*quote.Quote, error) {
+	ctx, span := otel.Tracer("application").Start(ctx, "QuoteHandler.Create")
+	defer span.End()
+
+	request, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode quote request: %w", err)
+	}
+	result, err := h.calculator.Handle(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	q := quote.NewQuote(result.CalculationID, request, calculation.Result{
+		Value:     result.Value,
+		Breakdown: result.Breakdown,
+		Errors:    result.Errors,
+		Stages:    result.Stages,
+		Lines:     result.Lines,
+	}, cmd.RuleVersions, h.now(), h.ttl)
+	if err := h.signer.Sign(q); err != nil {
+		return nil, err
+	}
+	if err := h.repository.Create(ctx, q); err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("quote.id", q.ID().String()))
+	telemetry.QuotesTotal.WithLabelValues("created").Inc()
+	return q, nil
+}
+
+// Confirm locks in the quote with the given ID and returns its guaranteed result. A quote that expired
+// before it was confirmed is not honoured; the result offers a new quote for the same request instead.
+// A quote whose signature does not match its content returns quote.ErrInvalidSignature, which is logged:
+// it means storage was tampered with or the signing key is wrong.
+func (h *QuoteHandler) Confirm(ctx context.Context, id string) (*ConfirmQuoteResult, error) {
+	ctx, span := otel.Tracer("application").Start(ctx, "QuoteHandler.Confirm")
+	defer span.End()
+
+	quoteID, err := quote.ParseQuoteID(id)
+	if err != nil {
+		return nil, &shared.ValidationError{Field: "id", Message: "must be a UUID"}
+	}
+	q, err := h.repository.FindByID(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.signer.Verify(q); err != nil {
+		if errors.Is(err, quote.ErrInvalidSignature) {
+			log.Printf("SECURITY: quote %s does not match its signature; it was altered in storage or signed with another key", q.ID())
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+
+	now := h.now()
+	wasConfirmed := q.Status(now) == quote.StatusConfirmed
+	err = q.Confirm(now)
+	if errors.Is(err, quote.ErrExpired) {
+		telemetry.QuotesTotal.WithLabelValues("expired").Inc()
+		var cmd CalculateRulesCommand
+		if err := json.Unmarshal(q.Request(), &cmd); err != nil {
+			return nil, fmt.Errorf("failed to decode quote request: %w", err)
+		}
+		offer, err := h.Create(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		return &ConfirmQuoteResult{Quote: q, Offer: offer}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !wasConfirmed {
+		if err := h.repository.Update(ctx, q); err != nil {
+			return nil, err
+		}
+		telemetry.QuotesTotal.WithLabelValues("confirmed").Inc()
+	}
+	return &ConfirmQuoteResult{Quote: q}, nil
+}
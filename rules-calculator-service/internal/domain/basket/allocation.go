@@ -0,0 +1,115 @@
+package basket
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// AllocationMethod decides which lines a basket-level amount is allocated to.
+type AllocationMethod string
+
+const (
+	// AllocationProRata spreads the amount over all lines in proportion to their amounts.
+	AllocationProRata AllocationMethod = "PRO_RATA"
+	// AllocationTargeted spreads the amount over the lines with the given SKUs or categories only, in
+	// proportion to their amounts.
+	AllocationTargeted AllocationMethod = "TARGETED"
+)
+
+// Allocation is how the value of a basket-level rule is allocated to the lines of the basket.
+type Allocation struct {
+	Method     AllocationMethod `json:"method"`
+	SKUs       []string         `json:"skus,omitempty"`
+	Categories []string         `json:"categories,omitempty"`
+}
+
+// Validate checks the allocation against the basket. A targeted allocation must match at least one line.
+func (a Allocation) Validate(b Basket) error {
+	switch a.Method {
+	case "", AllocationProRata:
+		return nil
+	case AllocationTargeted:
+		for _, item := range b.Items {
+			if a.Matches(item) {
+				return nil
+			}
+		}
+		return fmt.Errorf("targeted allocation matches no line of the basket")
+	}
+	return fmt.Errorf("unsupported allocation method: %s", a.Method)
+}
+
+// Matches reports whether the amount is allocated to the item.
+func (a Allocation) Matches(item Item) bool {
+	if a.Method != AllocationTargeted {
+		return true
+	}
+	for _, sku := range a.SKUs {
+		if sku == item.SKU {
+			return true
+		}
+	}
+	for _, category := range a.Categories {
+		if category == item.Category {
+			return true
+		}
+	}
+	return false
+}
+
+// Allocate splits amount in proportion to the weights, rounded to the given number of decimal places.
+// Rounding leftovers go to the shares with the largest remainders, so that the shares always add up to
+// the rounded amount. When all weights are zero, the amount is split evenly.
+func Allocate(amount decimal.Decimal, weights []decimal.Decimal, places int32) []decimal.Decimal {
+	shares := make([]decimal.Decimal, len(weights))
+	if len(weights) == 0 {
+		return shares
+	}
+	if amount.IsNegative() {
+		for i, share := range Allocate(amount.Neg(), weights, places) {
+			shares[i] = share.Neg()
+		}
+		return shares
+	}
+
+	totalWeight := decimal.Zero
+	for _, weight := range weights {
+		totalWeight = totalWeight.Add(weight)
+	}
+	if totalWeight.IsZero() {
+		weights = make([]decimal.Decimal, len(weights))
+		for i := range weights {
+			weights[i] = decimal.NewFromInt(1)
+		}
+		totalWeight = decimal.NewFromInt(int64(len(weights)))
+	}
+
+	// Work in units of the last decimal place, e.g. cents.
+	units := amount.Shift(places).Round(0)
+	remainders := make([]decimal.Decimal, len(weights))
+	allocated := decimal.Zero
+	for i, weight := range weights {
+		exact := units.Mul(weight).DivRound(totalWeight, 16)
+		shares[i] = exact.Floor()
+		remainders[i] = exact.Sub(shares[i])
+		allocated = allocated.Add(shares[i])
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]].GreaterThan(remainders[order[b]])
+	})
+	for left, i := units.Sub(allocated).IntPart(), 0; left > 0; left, i = left-1, i+1 {
+		shares[order[i%len(order)]] = shares[order[i%len(order)]].Add(decimal.NewFromInt(1))
+	}
+
+	for i := range shares {
+		shares[i] = shares[i].Shift(-places)
+	}
+	return shares
+}
@@ -0,0 +1,71 @@
+package basket
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/shopspring/decimal"
+)
+
+// Item is a line of a basket.
+type Item struct {
+	// LineID identifies the line in the result; it defaults to the line's position, starting at 1.
+	LineID    string          `json:"line_id,omitempty"`
+	SKU       string          `json:"sku"`
+	Category  string          `json:"category,omitempty"`
+	Quantity  int             `json:"quantity"`
+	UnitPrice decimal.Decimal `json:"unit_price"`
+}
+
+// Amount returns the price of the line before adjustments.
+func (i Item) Amount() decimal.Decimal {
+	return i.UnitPrice.Mul(decimal.NewFromInt(int64(i.Quantity)))
+}
+
+// Basket is the set of items a calculation is made for. All prices are in the basket's currency.
+type Basket struct {
+	Currency string `json:"currency"`
+	Items    []Item `json:"items"`
+}
+
+// Normalize validates the basket and returns it with its currency normalized and line IDs assigned.
+func (b Basket) Normalize() (Basket, error) {
+	currency, err := money.ParseCurrency(b.Currency)
+	if err != nil {
+		return Basket{}, err
+	}
+	if len(b.Items) == 0 {
+		return Basket{}, fmt.Errorf("a basket needs at least one item")
+	}
+
+	normalized := Basket{Currency: currency, Items: make([]Item, len(b.Items))}
+	seen := make(map[string]bool, len(b.Items))
+	for i, item := range b.Items {
+		if item.LineID == "" {
+			item.LineID = strconv.Itoa(i + 1)
+		}
+		switch {
+		case seen[item.LineID]:
+			return Basket{}, fmt.Errorf("duplicate line_id: %s", item.LineID)
+		case item.SKU == "":
+			return Basket{}, fmt.Errorf("line %s has no sku", item.LineID)
+		case item.Quantity < 1:
+			return Basket{}, fmt.Errorf("line %s has a quantity below 1", item.LineID)
+		case item.UnitPrice.IsNegative():
+			return Basket{}, fmt.Errorf("line %s has a negative unit price", item.LineID)
+		}
+		seen[item.LineID] = true
+		normalized.Items[i] = item
+	}
+	return normalized, nil
+}
+
+// Subtotal returns the sum of the line amounts.
+func (b Basket) Subtotal() money.Money {
+	total := decimal.Zero
+	for _, item := range b.Items {
+		total = total.Add(item.Amount())
+	}
+	return money.Money{Amount: total, Currency: b.Currency}
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
 )
 
 // CalculationID represents the unique identifier for a Calculation.
@@ -14,10 +16,24 @@ func (id CalculationID) String() string {
 	return uuid.UUID(id).String()
 }
 
-// Result represents the outcome of a calculation.
+// ParseCalculationID parses the string representation of a CalculationID.
+func ParseCalculationID(s string) (CalculationID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return CalculationID{}, err
+	}
+	return CalculationID(id), nil
+}
+
+// Result represents the outcome of a calculation: the total of the rules that were evaluated, the
+// outcome of each rule with its value in the currency the rule reported, and the rules that failed.
+// Pipeline calculations also report the outcome of each stage, and of each line of their basket.
 type Result struct {
-	Value     float64            `json:"value"`
-	Breakdown map[string]float64 `json:"breakdown"`
+	Value     money.Money            `json:"value"`
+	Breakdown map[string]RuleOutcome `json:"breakdown"`
+	Errors    []RuleFailure          `json:"errors,omitempty"`
+	Stages    []StageResult          `json:"stages,omitempty"`
+	Lines     []LineResult           `json:"lines,omitempty"`
 }
 
 // Status represents the status of a calculation.
@@ -26,36 +42,100 @@ type Status string
 const (
 	StatusPending   Status = "PENDING"
 	StatusCompleted Status = "COMPLETED"
-	StatusFailed    Status = "FAILED"
+	// StatusPartial is a completed calculation whose total leaves out failed optional rules.
+	StatusPartial Status = "PARTIAL"
+	StatusFailed  Status = "FAILED"
 )
 
 // Calculation represents the core aggregate for a calculation process.
 type Calculation struct {
-	id          CalculationID
-	ruleIDs     []string
-	context     map[string]interface{}
-	result      *Result
-	status      Status
-	createdAt   time.Time
-	completedAt *time.Time
+	id             CalculationID
+	ruleIDs        []string
+	ruleVersions   map[string]int
+	context        map[string]interface{}
+	customerID     string
+	currency       string
+	idempotencyKey string
+	requestHash    string
+	result         *Result
+	status         Status
+	failure        string
+	createdAt      time.Time
+	completedAt    *time.Time
 }
 
-// NewCalculation creates a new Calculation.
+// NewCalculation creates a new Calculation. The customer is taken from the context's customer.id
+// or customer_id field, when present.
 func NewCalculation(ruleIDs []string, context map[string]interface{}) (*Calculation, error) {
 	return &Calculation{
-		id:        CalculationID(uuid.New()),
-		ruleIDs:   ruleIDs,
-		context:   context,
-		status:    StatusPending,
-		createdAt: time.Now().UTC(),
+		id:         CalculationID(uuid.New()),
+		ruleIDs:    ruleIDs,
+		context:    context,
+		customerID: customerIDOf(context),
+		status:     StatusPending,
+		createdAt:  time.Now().UTC(),
 	}, nil
 }
 
+// WithRuleVersions records the versions of the rules used by the calculation.
+func (c *Calculation) WithRuleVersions(versions map[string]int) *Calculation {
+	c.ruleVersions = versions
+	return c
+}
+
+// WithCurrency records the requested currency of the total.
+func (c *Calculation) WithCurrency(currency string) *Calculation {
+	c.currency = currency
+	return c
+}
+
+// WithIdempotencyKey records the client's idempotency key and a hash of the request it was sent with.
+func (c *Calculation) WithIdempotencyKey(key, requestHash string) *Calculation {
+	c.idempotencyKey = key
+	c.requestHash = requestHash
+	return c
+}
+
 // ID returns the calculation's ID.
 func (c *Calculation) ID() CalculationID {
 	return c.id
 }
 
+// RuleIDs returns the IDs of the rules the calculation was requested for.
+func (c *Calculation) RuleIDs() []string {
+	return c.ruleIDs
+}
+
+// RuleVersions returns the versions of the rules used, keyed by rule ID.
+func (c *Calculation) RuleVersions() map[string]int {
+	return c.ruleVersions
+}
+
+// Context returns the input context of the calculation.
+func (c *Calculation) Context() map[string]interface{} {
+	return c.context
+}
+
+// CustomerID returns the customer the calculation was made for, if known.
+func (c *Calculation) CustomerID() string {
+	return c.customerID
+}
+
+// Currency returns the requested currency of the total, if any.
+func (c *Calculation) Currency() string {
+	return c.currency
+}
+
+// IdempotencyKey returns the client's idempotency key, if any.
+func (c *Calculation) IdempotencyKey() string {
+	return c.idempotencyKey
+}
+
+// RequestHash returns the hash of the request submitted with the idempotency key.
+func (c *Calculation) RequestHash() string {
+	return c.requestHash
+}
+
 // Status returns the calculation's status.
 func (c *Calculation) Status() Status {
 	return c.status
@@ -66,22 +146,115 @@ func (c *Calculation) Result() *Result {
 	return c.result
 }
 
+// Failure returns the reason the calculation failed.
+func (c *Calculation) Failure() string {
+	return c.failure
+}
+
+// CreatedAt returns the calculation's creation time.
+func (c *Calculation) CreatedAt() time.Time {
+	return c.createdAt
+}
+
 // CompletedAt returns the calculation's completion time.
 func (c *Calculation) CompletedAt() *time.Time {
 	return c.completedAt
 }
 
-// Complete marks the calculation as completed.
+// Duration returns how long the calculation took, or zero while it is pending.
+func (c *Calculation) Duration() time.Duration {
+	if c.completedAt == nil {
+		return 0
+	}
+	return c.completedAt.Sub(c.createdAt)
+}
+
+// Complete marks the calculation as completed, or as partial when some rules failed.
 func (c *Calculation) Complete(result Result) {
 	c.status = StatusCompleted
+	if len(result.Errors) > 0 {
+		c.status = StatusPartial
+	}
 	c.result = &result
 	now := time.Now().UTC()
 	c.completedAt = &now
 }
 
-// Fail marks the calculation as failed.
-func (c *Calculation) Fail() {
+// Fail marks the calculation as failed for the given reason.
+func (c *Calculation) Fail(reason string) {
 	c.status = StatusFailed
+	c.failure = reason
 	now := time.Now().UTC()
 	c.completedAt = &now
 }
+
+// FailWithResult marks the calculation as failed for the given reason, keeping the outcome of each rule.
+func (c *Calculation) FailWithResult(result Result, reason string) {
+	c.Fail(reason)
+	c.result = &result
+}
+
+// Snapshot is the persisted state of a Calculation.
+type Snapshot struct {
+	ID             CalculationID
+	RuleIDs        []string
+	RuleVersions   map[string]int
+	Context        map[string]interface{}
+	CustomerID     string
+	Currency       string
+	IdempotencyKey string
+	RequestHash    string
+	Result         *Result
+	Status         Status
+	Failure        string
+	CreatedAt      time.Time
+	CompletedAt    *time.Time
+}
+
+// Snapshot returns the state of the calculation for persistence.
+func (c *Calculation) Snapshot() Snapshot {
+	return Snapshot{
+		ID:             c.id,
+		RuleIDs:        c.ruleIDs,
+		RuleVersions:   c.ruleVersions,
+		Context:        c.context,
+		CustomerID:     c.customerID,
+		Currency:       c.currency,
+		IdempotencyKey: c.idempotencyKey,
+		RequestHash:    c.requestHash,
+		Result:         c.result,
+		Status:         c.status,
+		Failure:        c.failure,
+		CreatedAt:      c.createdAt,
+		CompletedAt:    c.completedAt,
+	}
+}
+
+// Restore rebuilds a calculation from its persisted state.
+func Restore(s Snapshot) *Calculation {
+	return &Calculation{
+		id:             s.ID,
+		ruleIDs:        s.RuleIDs,
+		ruleVersions:   s.RuleVersions,
+		context:        s.Context,
+		customerID:     s.CustomerID,
+		currency:       s.Currency,
+		idempotencyKey: s.IdempotencyKey,
+		requestHash:    s.RequestHash,
+		result:         s.Result,
+		status:         s.Status,
+		failure:        s.Failure,
+		createdAt:      s.CreatedAt,
+		completedAt:    s.CompletedAt,
+	}
+}
+
+func customerIDOf(context map[string]interface{}) string {
+	if customer, ok := context["customer"].(map[string]interface{}); ok {
+		if id, ok := customer["id"].(string); ok {
+			return id
+		}
+	}
+	id, _ := context["customer_id"].(string)
+	return id
+}
@@ -0,0 +1,182 @@
+package calculation
+
+import (
+	"sort"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+)
+
+// RuleSet is a named selection of rules that can be calculated as a whole: summed as RuleIDs, or
+// calculated in the stages of a Pipeline.
+type RuleSet struct {
+	RuleIDs         []string  `json:"rule_ids,omitempty"`
+	Pipeline        *Pipeline `json:"pipeline,omitempty"`
+	ItemRuleIDs     []string  `json:"item_rule_ids,omitempty"`
+	OptionalRuleIDs []string  `json:"optional_rule_ids,omitempty"`
+	RequiredRuleIDs []string  `json:"required_rule_ids,omitempty"`
+}
+
+// RuleSetCatalog resolves the IDs of rule sets.
+type RuleSetCatalog interface {
+	RuleSet(id string) (RuleSet, bool)
+}
+
+// Change is how a rule or a line of a result differs from the baseline it is compared with.
+type Change string
+
+const (
+	ChangeAdded     Change = "ADDED"
+	ChangeRemoved   Change = "REMOVED"
+	ChangeChanged   Change = "CHANGED"
+	ChangeUnchanged Change = "UNCHANGED"
+)
+
+// RuleDifference compares the value of a rule with its value in the baseline. A value is missing when
+// the rule is not part of the result or failed.
+type RuleDifference struct {
+	RuleID   string       `json:"rule_id"`
+	Change   Change       `json:"change"`
+	Baseline *money.Money `json:"baseline,omitempty"`
+	Value    *money.Money `json:"value,omitempty"`
+	Delta    *money.Money `json:"delta,omitempty"`
+}
+
+// LineDifference compares a basket line with the same line in the baseline.
+type LineDifference struct {
+	LineID        string       `json:"line_id"`
+	SKU           string       `json:"sku"`
+	Change        Change       `json:"change"`
+	BaselineGross *money.Money `json:"baseline_gross,omitempty"`
+	Gross         *money.Money `json:"gross,omitempty"`
+	// DiscountDelta, TaxDelta and GrossDelta are the changes of the line's discount, tax and gross
+	// amount.
+	DiscountDelta *money.Money `json:"discount_delta,omitempty"`
+	TaxDelta      *money.Money `json:"tax_delta,omitempty"`
+	GrossDelta    *money.Money `json:"gross_delta,omitempty"`
+}
+
+// Difference is the difference between a result and a baseline. Deltas are only reported between
+// amounts of the same currency.
+type Difference struct {
+	TotalDelta *money.Money     `json:"total_delta,omitempty"`
+	Rules      []RuleDifference `json:"rules"`
+	Lines      []LineDifference `json:"lines,omitempty"`
+}
+
+// Compare returns the difference between result and baseline: the change of the total, of the value
+// of every rule of either result, by rule ID, and of every basket line of either result, baseline
+// lines first.
+func Compare(baseline, result Result) Difference {
+	difference := Difference{
+		TotalDelta: delta(&result.Value, &baseline.Value),
+		Rules:      []RuleDifference{},
+	}
+
+	ruleIDs := make([]string, 0, len(baseline.Breakdown)+len(result.Breakdown))
+	for ruleID := range baseline.Breakdown {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	for ruleID := range result.Breakdown {
+		if _, ok := baseline.Breakdown[ruleID]; !ok {
+			ruleIDs = append(ruleIDs, ruleID)
+		}
+	}
+	sort.Strings(ruleIDs)
+	for _, ruleID := range ruleIDs {
+		before, inBaseline := baseline.Breakdown[ruleID]
+		after, inResult := result.Breakdown[ruleID]
+		rule := RuleDifference{RuleID: ruleID, Baseline: outcomeValue(before, inBaseline), Value: outcomeValue(after, inResult)}
+		rule.Change = change(inBaseline, inResult, rule.Baseline, rule.Value)
+		rule.Delta = delta(rule.Value, rule.Baseline)
+		difference.Rules = append(difference.Rules, rule)
+	}
+
+	lines := make(map[string]LineResult, len(result.Lines))
+	for _, line := range result.Lines {
+		lines[line.LineID] = line
+	}
+	seen := make(map[string]bool, len(baseline.Lines))
+	for _, before := range baseline.Lines {
+		seen[before.LineID] = true
+		after, ok := lines[before.LineID]
+		if !ok {
+			difference.Lines = append(difference.Lines, lineDifference(&before, nil))
+			continue
+		}
+		difference.Lines = append(difference.Lines, lineDifference(&before, &after))
+	}
+	for _, after := range result.Lines {
+		if !seen[after.LineID] {
+			difference.Lines = append(difference.Lines, lineDifference(nil, &after))
+		}
+	}
+	return difference
+}
+
+func lineDifference(before, after *LineResult) LineDifference {
+	var line LineDifference
+	var beforeDiscount, afterDiscount, beforeTax, afterTax *money.Money
+	if before != nil {
+		line.LineID, line.SKU = before.LineID, before.SKU
+		line.BaselineGross, beforeDiscount, beforeTax = &before.Gross, &before.Discount, &before.Tax
+	}
+	if after != nil {
+		line.LineID, line.SKU = after.LineID, after.SKU
+		line.Gross, afterDiscount, afterTax = &after.Gross, &after.Discount, &after.Tax
+	}
+	line.Change = change(before != nil, after != nil, line.BaselineGross, line.Gross)
+	if line.Change == ChangeUnchanged && !equal(beforeDiscount, afterDiscount) {
+		line.Change = ChangeChanged
+	}
+	line.DiscountDelta = delta(afterDiscount, beforeDiscount)
+	line.TaxDelta = delta(afterTax, beforeTax)
+	line.GrossDelta = delta(line.Gross, line.BaselineGross)
+	return line
+}
+
+func outcomeValue(outcome RuleOutcome, ok bool) *money.Money {
+	if !ok || outcome.Status != RuleStatusOK {
+		return nil
+	}
+	value := outcome.Money
+	return &value
+}
+
+func change(inBaseline, inResult bool, before, after *money.Money) Change {
+	switch {
+	case !inBaseline:
+		return ChangeAdded
+	case !inResult:
+		return ChangeRemoved
+	case equal(before, after):
+		return ChangeUnchanged
+	}
+	return ChangeChanged
+}
+
+func equal(a, b *money.Money) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Currency == b.Currency && a.Amount.Equal(b.Amount)
+}
+
+// delta returns after minus before, counting a missing amount as zero, or nil when the amounts are
+// both missing or in different currencies.
+func delta(after, before *money.Money) *money.Money {
+	switch {
+	case after == nil && before == nil:
+		return nil
+	case before == nil:
+		d := *after
+		return &d
+	case after == nil:
+		d := money.Money{Amount: before.Amount.Neg(), Currency: before.Currency}
+		return &d
+	}
+	d, err := after.Sub(*before)
+	if err != nil {
+		return nil
+	}
+	return &d
+}
@@ -0,0 +1,18 @@
+package calculation
+
+import "context"
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context for a calculation whose result is never acted on, such as the scenarios
+// of a comparison. Evaluations made in a dry run ask the evaluation service to skip side effects, such
+// as reserving coupon usage.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether the calculation of the context is a dry run.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
@@ -0,0 +1,128 @@
+package calculation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+)
+
+// FailurePolicy decides how a calculation reacts to rules whose evaluation fails.
+type FailurePolicy string
+
+const (
+	// PolicyFailFast stops at the first failing required rule and fails the calculation.
+	PolicyFailFast FailurePolicy = "FAIL_FAST"
+	// PolicyBestEffort evaluates every rule and fails the calculation only if a required rule failed.
+	// Failing optional rules leave out their value and make the calculation PARTIAL.
+	PolicyBestEffort FailurePolicy = "BEST_EFFORT"
+)
+
+// IsValid reports whether the policy is one of the supported policies.
+func (p FailurePolicy) IsValid() bool {
+	return p == PolicyFailFast || p == PolicyBestEffort
+}
+
+// ErrorCode classifies why the evaluation of a rule failed.
+type ErrorCode string
+
+const (
+	ErrorEvaluationFailed ErrorCode = "EVALUATION_FAILED"
+	ErrorTimeout          ErrorCode = "TIMEOUT"
+	ErrorUnavailable      ErrorCode = "UNAVAILABLE"
+	ErrorInvalidRule      ErrorCode = "INVALID_RULE"
+	ErrorInvalidResponse  ErrorCode = "INVALID_RESPONSE"
+	// ErrorNotEvaluated marks rules skipped because a fail-fast calculation had already failed.
+	ErrorNotEvaluated ErrorCode = "NOT_EVALUATED"
+)
+
+// RuleError is the classified error of a rule evaluation.
+type RuleError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// NewRuleError creates a RuleError.
+func NewRuleError(code ErrorCode, format string, args ...interface{}) *RuleError {
+	return &RuleError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *RuleError) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+// RuleStatus is the outcome of a single rule within a calculation.
+type RuleStatus string
+
+const (
+	RuleStatusOK           RuleStatus = "OK"
+	RuleStatusFailed       RuleStatus = "FAILED"
+	RuleStatusNotEvaluated RuleStatus = "NOT_EVALUATED"
+)
+
+// RuleOutcome is the breakdown entry of a rule: its value when it was evaluated, or its error.
+type RuleOutcome struct {
+	money.Money
+	Status   RuleStatus `json:"status"`
+	Optional bool       `json:"optional,omitempty"`
+	Error    *RuleError `json:"error,omitempty"`
+}
+
+// RuleFailure reports a rule that did not contribute to the total.
+type RuleFailure struct {
+	RuleID string `json:"rule_id"`
+	// LineID is the basket line an item rule failed for.
+	LineID   string    `json:"line_id,omitempty"`
+	Optional bool      `json:"optional"`
+	Code     ErrorCode `json:"code"`
+	Message  string    `json:"message"`
+}
+
+// RequiredRuleError is returned when a required rule failed, failing the calculation.
+type RequiredRuleError struct {
+	Failures []RuleFailure
+}
+
+func (e *RequiredRuleError) Error() string {
+	var rules []string
+	for _, f := range e.required() {
+		rule := f.RuleID
+		if f.LineID != "" {
+			rule += " on line " + f.LineID
+		}
+		rules = append(rules, fmt.Sprintf("%s (%s: %s)", rule, f.Code, f.Message))
+	}
+	return "required rules failed: " + strings.Join(rules, "; ")
+}
+
+// Unavailable reports whether every required rule failed because the evaluation service was
+// unavailable or did not answer in time, so that the calculation may succeed when retried.
+func (e *RequiredRuleError) Unavailable() bool {
+	return e.all(func(code ErrorCode) bool { return code == ErrorUnavailable || code == ErrorTimeout })
+}
+
+// TimedOut reports whether every required rule failed because the evaluation service did not answer in time.
+func (e *RequiredRuleError) TimedOut() bool {
+	return e.all(func(code ErrorCode) bool { return code == ErrorTimeout })
+}
+
+// required returns the failures of the required rules that were evaluated.
+func (e *RequiredRuleError) required() []RuleFailure {
+	var failures []RuleFailure
+	for _, f := range e.Failures {
+		if !f.Optional && f.Code != ErrorNotEvaluated {
+			failures = append(failures, f)
+		}
+	}
+	return failures
+}
+
+func (e *RequiredRuleError) all(match func(ErrorCode) bool) bool {
+	failures := e.required()
+	for _, f := range failures {
+		if !match(f.Code) {
+			return false
+		}
+	}
+	return len(failures) > 0
+}
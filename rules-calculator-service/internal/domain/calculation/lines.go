@@ -0,0 +1,30 @@
+package calculation
+
+import (
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+)
+
+// Adjustment is the part of a rule's value applied to a basket line.
+type Adjustment struct {
+	RuleID string        `json:"rule_id"`
+	Stage  StageCategory `json:"stage"`
+	Amount money.Money   `json:"amount"`
+}
+
+// LineResult is a basket line after the pipeline, with the discounts and taxes allocated to it.
+type LineResult struct {
+	LineID    string      `json:"line_id"`
+	SKU       string      `json:"sku"`
+	Category  string      `json:"category,omitempty"`
+	Quantity  int         `json:"quantity"`
+	UnitPrice money.Money `json:"unit_price"`
+	// Amount is the price of the line before adjustments.
+	Amount   money.Money `json:"amount"`
+	Discount money.Money `json:"discount"`
+	// Net is the amount after discounts, and AdjustedUnitPrice the net price of one unit.
+	Net               money.Money  `json:"net"`
+	AdjustedUnitPrice money.Money  `json:"adjusted_unit_price"`
+	Tax               money.Money  `json:"tax"`
+	Gross             money.Money  `json:"gross"`
+	Adjustments       []Adjustment `json:"adjustments,omitempty"`
+}
@@ -0,0 +1,115 @@
+package calculation
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/shopspring/decimal"
+)
+
+// StageCategory is the category of the rules of a pipeline stage. It decides how the stage's rule
+// values change the running amount of the checkout.
+type StageCategory string
+
+const (
+	// StageDiscounts subtracts its rule values from the subtotal; the amount never drops below zero.
+	StageDiscounts StageCategory = "DISCOUNTS"
+	// StageFees adds its rule values, e.g. shipping or payment fees, to the discounted amount.
+	StageFees StageCategory = "FEES"
+	// StageTaxes adds its rule values, computed on the discounted amount including fees.
+	StageTaxes StageCategory = "TAXES"
+	// StageLoyalty awards its rule values as points; it does not change the amount.
+	StageLoyalty StageCategory = "LOYALTY"
+)
+
+// stageOrder is the order in which pipeline stages run.
+var stageOrder = map[StageCategory]int{
+	StageDiscounts: 0,
+	StageFees:      1,
+	StageTaxes:     2,
+	StageLoyalty:   3,
+}
+
+// LoyaltyBase decides which amount loyalty points are computed on.
+type LoyaltyBase string
+
+const (
+	LoyaltyPreTax  LoyaltyBase = "PRE_TAX"
+	LoyaltyPostTax LoyaltyBase = "POST_TAX"
+)
+
+// IsValid reports whether the base is one of the supported bases.
+func (b LoyaltyBase) IsValid() bool {
+	return b == LoyaltyPreTax || b == LoyaltyPostTax
+}
+
+// Stage is a step of a pipeline: the rules of one category.
+type Stage struct {
+	Category StageCategory `json:"category"`
+	RuleIDs  []string      `json:"rule_ids"`
+}
+
+// Pipeline is a calculation in ordered stages, where each stage is evaluated on the amount left by
+// the stages before it.
+type Pipeline struct {
+	Stages []Stage `json:"stages"`
+	// LoyaltyBase overrides the configured base of the loyalty stage when set.
+	LoyaltyBase LoyaltyBase `json:"loyalty_base,omitempty"`
+}
+
+// Normalize validates the pipeline and returns it with its stages in execution order.
+func (p Pipeline) Normalize() (Pipeline, error) {
+	if len(p.Stages) == 0 {
+		return Pipeline{}, fmt.Errorf("a pipeline needs at least one stage")
+	}
+	if p.LoyaltyBase != "" && !p.LoyaltyBase.IsValid() {
+		return Pipeline{}, fmt.Errorf("unsupported loyalty base: %s", p.LoyaltyBase)
+	}
+	seen := make(map[StageCategory]bool, len(p.Stages))
+	for _, stage := range p.Stages {
+		if _, ok := stageOrder[stage.Category]; !ok {
+			return Pipeline{}, fmt.Errorf("unsupported stage category: %s", stage.Category)
+		}
+		if seen[stage.Category] {
+			return Pipeline{}, fmt.Errorf("duplicate stage category: %s", stage.Category)
+		}
+		seen[stage.Category] = true
+	}
+
+	normalized := Pipeline{Stages: append([]Stage(nil), p.Stages...), LoyaltyBase: p.LoyaltyBase}
+	sort.SliceStable(normalized.Stages, func(i, j int) bool {
+		return stageOrder[normalized.Stages[i].Category] < stageOrder[normalized.Stages[j].Category]
+	})
+	return normalized, nil
+}
+
+// RuleIDs returns the rules of all stages in execution order.
+func (p Pipeline) RuleIDs() []string {
+	var ruleIDs []string
+	for _, stage := range p.Stages {
+		ruleIDs = append(ruleIDs, stage.RuleIDs...)
+	}
+	return ruleIDs
+}
+
+// RuleLine is the outcome of a rule within a stage.
+type RuleLine struct {
+	RuleID string `json:"rule_id"`
+	RuleOutcome
+}
+
+// StageResult is the outcome of a pipeline stage.
+type StageResult struct {
+	Category StageCategory `json:"category"`
+	// Base is the amount the stage's rules were evaluated on, passed to them as order.amount.
+	Base money.Money `json:"base"`
+	// Total is the sum of the stage's rule values, in the currency of the calculation. It is not set for
+	// the loyalty stage, whose values are points.
+	Total *money.Money `json:"total,omitempty"`
+	// Points is the sum of the loyalty stage's rule values.
+	Points *decimal.Decimal `json:"points,omitempty"`
+	// Amount is the running amount after the stage.
+	Amount money.Money `json:"amount"`
+	Rules  []RuleLine  `json:"rules"`
+}
@@ -0,0 +1,53 @@
+package calculation
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a calculation does not exist.
+	ErrNotFound = errors.New("calculation not found")
+	// ErrDuplicateIdempotencyKey is returned when a calculation with the same idempotency key already exists.
+	ErrDuplicateIdempotencyKey = errors.New("a calculation with this idempotency key already exists")
+)
+
+// Filter selects calculations from the history. Zero values do not filter.
+type Filter struct {
+	CustomerID string
+	From       *time.Time // inclusive
+	To         *time.Time // exclusive
+	Limit      int
+	Offset     int
+}
+
+// Repository stores calculations. Create must reject a second calculation with the same idempotency key
+// atomically, so that concurrent retries of one request are calculated only once.
+type Repository interface {
+	Create(ctx context.Context, calc *Calculation) error
+	Update(ctx context.Context, calc *Calculation) error
+	FindByID(ctx context.Context, id CalculationID) (*Calculation, error)
+	FindByIdempotencyKey(ctx context.Context, key string) (*Calculation, error)
+	// ReleaseIdempotencyKey detaches the idempotency key from a calculation, so that a retry can claim it.
+	ReleaseIdempotencyKey(ctx context.Context, id CalculationID) error
+	// List returns the matching calculations, most recent first.
+	List(ctx context.Context, filter Filter) ([]*Calculation, error)
+}
+
+var (
+	// ErrIdempotencyKeyMismatch is returned when an idempotency key is reused with a different request.
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key was already used with a different request")
+	// ErrInProgress is returned when a request is retried while its calculation is still running.
+	ErrInProgress = errors.New("a calculation with this idempotency key is in progress")
+)
+
+// FailedError is returned when a request is retried whose calculation failed.
+type FailedError struct {
+	ID     CalculationID
+	Reason string
+}
+
+func (e *FailedError) Error() string {
+	return "calculation " + e.ID.String() + " failed: " + e.Reason
+}
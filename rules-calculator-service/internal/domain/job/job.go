@@ -0,0 +1,221 @@
+package job
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+)
+
+// JobID represents the unique identifier for a Job.
+type JobID uuid.UUID
+
+// String returns the string representation of a JobID.
+func (id JobID) String() string {
+	return uuid.UUID(id).String()
+}
+
+// ParseJobID parses the string representation of a JobID.
+func ParseJobID(s string) (JobID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return JobID{}, err
+	}
+	return JobID(id), nil
+}
+
+// Item is a calculation of a job. Its request is kept encoded: the domain does not know the shape of a
+// calculation request.
+type Item struct {
+	// Reference identifies the item for the client, e.g. a basket ID.
+	Reference string          `json:"reference,omitempty"`
+	Request   json.RawMessage `json:"request"`
+}
+
+// Job is a batch of calculations processed in the background. A job is PENDING while it is queued or
+// running, COMPLETED once every item was calculated, whether or not the item succeeded, and FAILED
+// when no item could be calculated.
+type Job struct {
+	id          JobID
+	reference   string
+	items       []Item
+	status      calculation.Status
+	processed   int
+	succeeded   int
+	failed      int
+	createdAt   time.Time
+	startedAt   *time.Time
+	completedAt *time.Time
+}
+
+// NewJob creates a pending job for the items.
+func NewJob(reference string, items []Item) *Job {
+	return &Job{
+		id:        JobID(uuid.New()),
+		reference: reference,
+		items:     items,
+		status:    calculation.StatusPending,
+		createdAt: time.Now().UTC(),
+	}
+}
+
+// ID returns the job's ID.
+func (j *Job) ID() JobID {
+	return j.id
+}
+
+// Reference returns the client's reference of the job.
+func (j *Job) Reference() string {
+	return j.reference
+}
+
+// Items returns the calculations of the job.
+func (j *Job) Items() []Item {
+	return j.items
+}
+
+// Status returns the job's status.
+func (j *Job) Status() calculation.Status {
+	return j.status
+}
+
+// Total returns the number of items.
+func (j *Job) Total() int {
+	return len(j.items)
+}
+
+// Processed returns the number of items calculated so far.
+func (j *Job) Processed() int {
+	return j.processed
+}
+
+// Succeeded returns the number of items whose calculation completed.
+func (j *Job) Succeeded() int {
+	return j.succeeded
+}
+
+// Failed returns the number of items whose calculation failed.
+func (j *Job) Failed() int {
+	return j.failed
+}
+
+// Progress returns the share of the items processed, from 0 to 1.
+func (j *Job) Progress() float64 {
+	if len(j.items) == 0 {
+		return 1
+	}
+	return float64(j.processed) / float64(len(j.items))
+}
+
+// CreatedAt returns the job's submission time.
+func (j *Job) CreatedAt() time.Time {
+	return j.createdAt
+}
+
+// StartedAt returns the time a worker started the job.
+func (j *Job) StartedAt() *time.Time {
+	return j.startedAt
+}
+
+// CompletedAt returns the time the last item was processed.
+func (j *Job) CompletedAt() *time.Time {
+	return j.completedAt
+}
+
+// Start records that a worker started the job. Restarting a resumed job keeps its first start.
+func (j *Job) Start() {
+	if j.startedAt == nil {
+		now := time.Now().UTC()
+		j.startedAt = &now
+	}
+}
+
+// Record counts the result of an item, and completes the job with its last item.
+func (j *Job) Record(result Result) {
+	j.processed++
+	if result.Status == calculation.StatusFailed {
+		j.failed++
+	} else {
+		j.succeeded++
+	}
+	if j.processed < len(j.items) {
+		return
+	}
+	j.status = calculation.StatusCompleted
+	if j.succeeded == 0 && j.failed > 0 {
+		j.status = calculation.StatusFailed
+	}
+	now := time.Now().UTC()
+	j.completedAt = &now
+}
+
+// Resume recounts the progress of an interrupted job from the results stored before the interruption,
+// which may be ahead of the stored progress.
+func (j *Job) Resume(results []Result) {
+	j.processed, j.succeeded, j.failed = 0, 0, 0
+	for _, result := range results {
+		j.Record(result)
+	}
+}
+
+// Result is the outcome of an item of a job.
+type Result struct {
+	JobID     JobID  `json:"job_id"`
+	Index     int    `json:"index"`
+	Reference string `json:"reference,omitempty"`
+	// CalculationID is the item's calculation in the calculation history, when one was created.
+	CalculationID string                    `json:"calculation_id,omitempty"`
+	Status        calculation.Status        `json:"status"`
+	Value         *money.Money              `json:"value,omitempty"`
+	Errors        []calculation.RuleFailure `json:"errors,omitempty"`
+	Error         string                    `json:"error,omitempty"`
+}
+
+// Snapshot is the persisted state of a Job.
+type Snapshot struct {
+	ID          JobID
+	Reference   string
+	Items       []Item
+	Status      calculation.Status
+	Processed   int
+	Succeeded   int
+	Failed      int
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+}
+
+// Snapshot returns the state of the job for persistence.
+func (j *Job) Snapshot() Snapshot {
+	return Snapshot{
+		ID:          j.id,
+		Reference:   j.reference,
+		Items:       j.items,
+		Status:      j.status,
+		Processed:   j.processed,
+		Succeeded:   j.succeeded,
+		Failed:      j.failed,
+		CreatedAt:   j.createdAt,
+		StartedAt:   j.startedAt,
+		CompletedAt: j.completedAt,
+	}
+}
+
+// Restore rebuilds a job from its persisted state.
+func Restore(s Snapshot) *Job {
+	return &Job{
+		id:          s.ID,
+		reference:   s.Reference,
+		items:       s.Items,
+		status:      s.Status,
+		processed:   s.Processed,
+		succeeded:   s.Succeeded,
+		failed:      s.Failed,
+		createdAt:   s.CreatedAt,
+		startedAt:   s.StartedAt,
+		completedAt: s.CompletedAt,
+	}
+}
@@ -0,0 +1,23 @@
+package job
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when a job does not exist.
+var ErrNotFound = errors.New("job not found")
+
+// Repository stores jobs and the results of their items.
+type Repository interface {
+	Create(ctx context.Context, j *Job) error
+	// Update stores the status and progress of a job.
+	Update(ctx context.Context, j *Job) error
+	FindByID(ctx context.Context, id JobID) (*Job, error)
+	// FindPending returns the jobs that are queued or were interrupted, oldest first.
+	FindPending(ctx context.Context) ([]*Job, error)
+	// SaveResult stores the result of an item, replacing an earlier result of the same item.
+	SaveResult(ctx context.Context, result Result) error
+	// ListResults returns the results of a job in item order.
+	ListResults(ctx context.Context, id JobID, limit, offset int) ([]Result, error)
+}
@@ -0,0 +1,148 @@
+package money
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Money is a decimal amount in an ISO 4217 currency. It is encoded in JSON as
+// {"amount": "12.34", "currency": "EUR"}, with the amount as a string so that no precision is lost.
+// An empty currency stands for an amount whose currency was not reported.
+type Money struct {
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// New creates a Money value, validating the currency code.
+func New(amount decimal.Decimal, currency string) (Money, error) {
+	currency, err := ParseCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// ParseCurrency normalizes and validates an ISO 4217 currency code.
+func ParseCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !currencyCode.MatchString(currency) {
+		return "", fmt.Errorf("invalid currency code %q", currency)
+	}
+	return currency, nil
+}
+
+// Add adds two amounts of the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, &CurrencyMismatchError{Currencies: []string{m.Currency, other.Currency}}
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.Currency}, nil
+}
+
+// Sub subtracts an amount of the same currency.
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, &CurrencyMismatchError{Currencies: []string{m.Currency, other.Currency}}
+	}
+	return Money{Amount: m.Amount.Sub(other.Amount), Currency: m.Currency}, nil
+}
+
+// String formats the amount followed by the currency, e.g. "12.34 EUR".
+func (m Money) String() string {
+	return strings.TrimSpace(m.Amount.String() + " " + m.Currency)
+}
+
+// RatesProvider provides exchange rates between currencies.
+type RatesProvider interface {
+	// Rate returns the amount of currency to that one unit of currency from is worth.
+	Rate(ctx context.Context, from, to string) (decimal.Decimal, error)
+}
+
+// Converter converts amounts between currencies with the rates of a RatesProvider.
+type Converter struct {
+	rates RatesProvider
+}
+
+// NewConverter creates a Converter.
+func NewConverter(rates RatesProvider) *Converter {
+	return &Converter{rates: rates}
+}
+
+// Convert converts an amount to the given currency. Amounts are not rounded.
+func (c *Converter) Convert(ctx context.Context, m Money, currency string) (Money, error) {
+	if m.Currency == currency {
+		return m, nil
+	}
+	if m.Currency == "" {
+		return Money{}, fmt.Errorf("cannot convert an amount without currency to %s", currency)
+	}
+	rate, err := c.rates.Rate(ctx, m.Currency, currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Mul(rate), Currency: currency}, nil
+}
+
+// Sum adds amounts. Without a target currency all amounts must share one currency; with a target
+// currency every amount is converted to it first, which requires a converter.
+func Sum(ctx context.Context, amounts []Money, target string, converter *Converter) (Money, error) {
+	total := Money{Amount: decimal.Zero, Currency: target}
+	if target == "" {
+		currencies := make(map[string]bool)
+		for _, m := range amounts {
+			currencies[m.Currency] = true
+		}
+		if len(currencies) > 1 {
+			mismatch := &CurrencyMismatchError{}
+			for currency := range currencies {
+				mismatch.Currencies = append(mismatch.Currencies, currency)
+			}
+			sort.Strings(mismatch.Currencies)
+			return Money{}, mismatch
+		}
+		if len(amounts) > 0 {
+			total.Currency = amounts[0].Currency
+		}
+	}
+
+	for _, m := range amounts {
+		if target != "" {
+			if converter == nil {
+				return Money{}, fmt.Errorf("no currency converter to convert %s to %s", m.Currency, target)
+			}
+			converted, err := converter.Convert(ctx, m, target)
+			if err != nil {
+				return Money{}, err
+			}
+			m = converted
+		}
+		total.Amount = total.Amount.Add(m.Amount)
+	}
+	return total, nil
+}
+
+// CurrencyMismatchError is returned when amounts in different currencies are combined without a
+// target currency.
+type CurrencyMismatchError struct {
+	Currencies []string
+}
+
+func (e *CurrencyMismatchError) Error() string {
+	return fmt.Sprintf("cannot combine amounts in different currencies (%s) without a target currency", strings.Join(e.Currencies, ", "))
+}
+
+// UnknownRateError is returned when no exchange rate is known between two currencies.
+type UnknownRateError struct {
+	From, To string
+}
+
+func (e *UnknownRateError) Error() string {
+	return fmt.Sprintf("no exchange rate from %s to %s", e.From, e.To)
+}
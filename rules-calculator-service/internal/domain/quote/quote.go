@@ -0,0 +1,190 @@
+package quote
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+)
+
+var (
+	// ErrExpired is returned when a quote is confirmed after its expiry.
+	ErrExpired = errors.New("quote expired")
+	// ErrInvalidSignature is returned when a stored quote does not match its signature.
+	ErrInvalidSignature = errors.New("quote signature is invalid")
+)
+
+// QuoteID represents the unique identifier for a Quote.
+type QuoteID uuid.UUID
+
+// String returns the string representation of a QuoteID.
+func (id QuoteID) String() string {
+	return uuid.UUID(id).String()
+}
+
+// ParseQuoteID parses the string representation of a QuoteID.
+func ParseQuoteID(s string) (QuoteID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return QuoteID{}, err
+	}
+	return QuoteID(id), nil
+}
+
+// Status represents the status of a quote. An active quote past its expiry is expired.
+type Status string
+
+const (
+	StatusActive    Status = "ACTIVE"
+	StatusConfirmed Status = "CONFIRMED"
+	StatusExpired   Status = "EXPIRED"
+)
+
+// Quote is a calculation result the calculator guarantees until it expires, even if the rules it was
+// calculated with change in the meantime. The request it was calculated for is kept so that an expired
+// quote can be calculated again.
+type Quote struct {
+	id            QuoteID
+	calculationID string
+	request       json.RawMessage
+	result        calculation.Result
+	ruleVersions  map[string]int
+	status        Status
+	signature     string
+	createdAt     time.Time
+	expiresAt     time.Time
+	confirmedAt   *time.Time
+}
+
+// NewQuote creates an active quote for the result of a calculation, valid for ttl from now. Times are
+// kept to the microsecond, the precision they are stored with.
+func NewQuote(calculationID string, request json.RawMessage, result calculation.Result, ruleVersions map[string]int, now time.Time, ttl time.Duration) *Quote {
+	now = now.UTC().Truncate(time.Microsecond)
+	return &Quote{
+		id:            QuoteID(uuid.New()),
+		calculationID: calculationID,
+		request:       request,
+		result:        result,
+		ruleVersions:  ruleVersions,
+		status:        StatusActive,
+		createdAt:     now,
+		expiresAt:     now.Add(ttl),
+	}
+}
+
+// ID returns the quote's ID.
+func (q *Quote) ID() QuoteID {
+	return q.id
+}
+
+// CalculationID returns the ID of the calculation the quote was made from.
+func (q *Quote) CalculationID() string {
+	return q.calculationID
+}
+
+// Request returns the calculation request the quote was made for.
+func (q *Quote) Request() json.RawMessage {
+	return q.request
+}
+
+// Result returns the guaranteed result.
+func (q *Quote) Result() calculation.Result {
+	return q.result
+}
+
+// RuleVersions returns the versions of the rules the quote was calculated with, keyed by rule ID.
+func (q *Quote) RuleVersions() map[string]int {
+	return q.ruleVersions
+}
+
+// Status returns the status of the quote at the given time.
+func (q *Quote) Status(now time.Time) Status {
+	if q.status == StatusActive && !now.Before(q.expiresAt) {
+		return StatusExpired
+	}
+	return q.status
+}
+
+// Signature returns the quote's signature.
+func (q *Quote) Signature() string {
+	return q.signature
+}
+
+// CreatedAt returns the quote's creation time.
+func (q *Quote) CreatedAt() time.Time {
+	return q.createdAt
+}
+
+// ExpiresAt returns the time until which the quote can be confirmed.
+func (q *Quote) ExpiresAt() time.Time {
+	return q.expiresAt
+}
+
+// ConfirmedAt returns the time the quote was confirmed.
+func (q *Quote) ConfirmedAt() *time.Time {
+	return q.confirmedAt
+}
+
+// Confirm locks in the quote. Confirming a confirmed quote again has no effect, even after its expiry;
+// an active quote past its expiry returns ErrExpired.
+func (q *Quote) Confirm(now time.Time) error {
+	switch q.Status(now) {
+	case StatusConfirmed:
+		return nil
+	case StatusExpired:
+		return ErrExpired
+	}
+	now = now.UTC().Truncate(time.Microsecond)
+	q.status = StatusConfirmed
+	q.confirmedAt = &now
+	return nil
+}
+
+// Snapshot is the persisted state of a Quote.
+type Snapshot struct {
+	ID            QuoteID
+	CalculationID string
+	Request       json.RawMessage
+	Result        calculation.Result
+	RuleVersions  map[string]int
+	Status        Status
+	Signature     string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	ConfirmedAt   *time.Time
+}
+
+// Snapshot returns the state of the quote for persistence.
+func (q *Quote) Snapshot() Snapshot {
+	return Snapshot{
+		ID:            q.id,
+		CalculationID: q.calculationID,
+		Request:       q.request,
+		Result:        q.result,
+		RuleVersions:  q.ruleVersions,
+		Status:        q.status,
+		Signature:     q.signature,
+		CreatedAt:     q.createdAt,
+		ExpiresAt:     q.expiresAt,
+		ConfirmedAt:   q.confirmedAt,
+	}
+}
+
+// Restore rebuilds a quote from its persisted state.
+func Restore(s Snapshot) *Quote {
+	return &Quote{
+		id:            s.ID,
+		calculationID: s.CalculationID,
+		request:       s.Request,
+		result:        s.Result,
+		ruleVersions:  s.RuleVersions,
+		status:        s.Status,
+		signature:     s.Signature,
+		createdAt:     s.CreatedAt,
+		expiresAt:     s.ExpiresAt,
+		confirmedAt:   s.ConfirmedAt,
+	}
+}
@@ -0,0 +1,16 @@
+package quote
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when a quote does not exist.
+var ErrNotFound = errors.New("quote not found")
+
+// Repository stores quotes.
+type Repository interface {
+	Create(ctx context.Context, q *Quote) error
+	Update(ctx context.Context, q *Quote) error
+	FindByID(ctx context.Context, id QuoteID) (*Quote, error)
+}
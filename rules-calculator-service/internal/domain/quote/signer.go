@@ -0,0 +1,81 @@
+package quote
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+)
+
+// Signer signs quotes with HMAC-SHA256, so that a quote altered in storage, or a result presented as a
+// quote by a client, is not honoured. Services holding the same key can verify quotes too.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner creates a signer with the given secret key.
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// signedContent is what a signature covers: everything the guarantee is about, including the request
+// an expired quote is calculated again for.
+type signedContent struct {
+	ID            string             `json:"id"`
+	CalculationID string             `json:"calculation_id"`
+	Request       interface{}        `json:"request"`
+	Result        calculation.Result `json:"result"`
+	RuleVersions  map[string]int     `json:"rule_versions,omitempty"`
+	ExpiresAt     string             `json:"expires_at"`
+}
+
+// Sign signs the quote.
+func (s *Signer) Sign(q *Quote) error {
+	signature, err := s.signature(q)
+	if err != nil {
+		return err
+	}
+	q.signature = signature
+	return nil
+}
+
+// Verify returns ErrInvalidSignature unless the quote's signature matches its content.
+func (s *Signer) Verify(q *Quote) error {
+	signature, err := s.signature(q)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(signature), []byte(q.signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (s *Signer) signature(q *Quote) (string, error) {
+	// encoding/json sorts map keys, which makes the encoding canonical. The request is decoded first:
+	// storage may reorder its keys and change its spacing.
+	var request interface{}
+	if len(q.request) > 0 {
+		if err := json.Unmarshal(q.request, &request); err != nil {
+			return "", fmt.Errorf("failed to decode quote request for signing: %w", err)
+		}
+	}
+	content, err := json.Marshal(signedContent{
+		ID:            q.id.String(),
+		CalculationID: q.calculationID,
+		Request:       request,
+		Result:        q.result,
+		RuleVersions:  q.ruleVersions,
+		ExpiresAt:     q.expiresAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode quote for signing: %w", err)
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(content)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
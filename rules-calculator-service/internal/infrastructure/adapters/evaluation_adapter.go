@@ -3,42 +3,86 @@ package adapters
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
+	evaluationv1 "github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/api/proto/gen/evaluationv1"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
 	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 )
 
-// HTTPEvaluationAdapter is an adapter to the rule evaluation service.
+// ResilienceOptions configures the retries and the circuit breaker of the HTTPEvaluationAdapter.
+type ResilienceOptions struct {
+	// MaxRetries is the number of retries after a failed call; 0 disables retries.
+	MaxRetries int
+	// BaseBackoff and MaxBackoff bound the exponential backoff between retries. The actual wait is
+	// drawn uniformly up to the backoff ("full jitter") so that callers do not retry in lockstep.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// FailureThreshold is the number of consecutive failures that opens the circuit breaker, and
+	// OpenTimeout how long it stays open before a trial call is let through.
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+// DefaultResilienceOptions retries twice and opens the breaker after five consecutive failures.
+func DefaultResilienceOptions() ResilienceOptions {
+	return ResilienceOptions{
+		MaxRetries:       2,
+		BaseBackoff:      50 * time.Millisecond,
+		MaxBackoff:       time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+// HTTPEvaluationAdapter is an adapter to the REST API of the rule evaluation service. Requests and
+// responses are the JSON encoding of the messages of evaluation.v1.proto.
 type HTTPEvaluationAdapter struct {
 	baseURL string
 	client  *http.Client
+	catalog RuleCatalog
+	opts    ResilienceOptions
+	breaker *CircuitBreaker
 }
 
 // NewHTTPEvaluationAdapter creates a new HTTPEvaluationAdapter.
-func NewHTTPEvaluationAdapter(baseURL string) *HTTPEvaluationAdapter {
+func NewHTTPEvaluationAdapter(baseURL string, catalog RuleCatalog, opts ResilienceOptions) *HTTPEvaluationAdapter {
 	return &HTTPEvaluationAdapter{
 		baseURL: baseURL,
 		client:  &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		catalog: catalog,
+		opts:    opts,
+		breaker: NewCircuitBreaker("evaluation_http", opts.FailureThreshold, opts.OpenTimeout),
 	}
 }
 
-type evaluationRequest struct {
-	RuleID  string                 `json:"rule_id"`
-	Context map[string]interface{} `json:"context"`
+// Breaker returns the circuit breaker guarding the evaluation service.
+func (a *HTTPEvaluationAdapter) Breaker() *CircuitBreaker {
+	return a.breaker
 }
 
-type evaluationResponse struct {
-	Value float64 `json:"value"`
-}
-
-// Evaluate evaluates a rule using the rule evaluation service.
-func (a *HTTPEvaluationAdapter) Evaluate(ctx context.Context, ruleID string, context map[string]interface{}) (float64, error) {
+// Evaluate evaluates a rule using the rule evaluation service. Failures are classified as
+// calculation.RuleError: rules missing from the catalog and 4xx responses are invalid rules, 5xx
+// responses and transport errors mean the service is unavailable.
+//
+// Calls the service did not process are retried with jittered exponential backoff, or after the wait
+// the service asks for in Retry-After: failures to connect, 429, 502, 503 and 504 responses. Other
+// failures, timeouts and responses cut short included, are not retried, since evaluations such as
+// coupon reservations have side effects. A 429 means the service is rate limiting the calculator, not
+// that it is unhealthy, so it does not count towards opening the circuit breaker. While the breaker
+// is open, calls fail immediately.
+func (a *HTTPEvaluationAdapter) Evaluate(ctx context.Context, ruleID string, context map[string]interface{}) (money.Money, error) {
 	tr := otel.Tracer("adapter")
 	ctx, span := tr.Start(ctx, "HTTPEvaluationAdapter.Evaluate")
 	defer span.End()
@@ -50,49 +94,160 @@ func (a *HTTPEvaluationAdapter) Evaluate(ctx context.Context, ruleID string, con
 		telemetry.RuleEvaluationDuration.WithLabelValues(ruleID).Observe(time.Since(startTime).Seconds())
 	}()
 
-	// This is a mock implementation.
-	// It does not actually call the evaluation service.
-	// It returns a dummy value.
-	// In a real implementation, this would make an HTTP call to the evaluation service.
-
-	// Seed the random number generator
-	// rand.Seed(time.Now().UnixNano())
-
-	// Generate a random float between 10 and 100
-	// randomFloat := 10 + rand.Float64()*(100-10)
-
-	// return randomFloat, nil
-
-	reqBody := evaluationRequest{
-		RuleID:  ruleID,
-		Context: context,
+	request, definition, err := evaluateRuleRequest(ctx, a.catalog, ruleID, context)
+	if err != nil {
+		return money.Money{}, err
 	}
-
-	bodyBytes, err := json.Marshal(reqBody)
+	bodyBytes, err := requestEncoding.Marshal(request)
 	if err != nil {
-		return 0, fmt.Errorf("failed to marshal evaluation request: %w", err)
+		return money.Money{}, fmt.Errorf("failed to marshal evaluation request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", a.baseURL+"/v1/evaluate", bytes.NewBuffer(bodyBytes))
+	for attempt := 0; ; attempt++ {
+		if !a.breaker.Allow() {
+			telemetry.CircuitBreakerRejectionsTotal.Inc()
+			return money.Money{}, calculation.NewRuleError(calculation.ErrorUnavailable, "evaluation service circuit breaker is open")
+		}
+		value, outcome, err := a.call(ctx, bodyBytes, definition.ValueKey)
+		if ctx.Err() != nil || outcome.throttled {
+			// The caller gave up, or the service is healthy but throttles us; neither is a failure.
+			a.breaker.Abandon()
+		} else {
+			a.breaker.Record(!unhealthy(err))
+		}
+		if err == nil || !outcome.retryable || attempt >= a.opts.MaxRetries || ctx.Err() != nil {
+			span.SetAttributes(attribute.Int("evaluation.attempts", attempt+1))
+			return value, err
+		}
+		wait := a.backoff(attempt)
+		if outcome.retryAfter > 0 {
+			if outcome.retryAfter > a.opts.MaxBackoff {
+				// The service asked for a longer wait than we are willing to make.
+				span.SetAttributes(attribute.Int("evaluation.attempts", attempt+1))
+				return value, err
+			}
+			wait = outcome.retryAfter
+		}
+
+		telemetry.EvaluationRetriesTotal.Inc()
+		if !sleep(ctx, wait) {
+			return money.Money{}, calculation.NewRuleError(calculation.ErrorTimeout, "no time left to retry: %v", err)
+		}
+	}
+}
+
+// callOutcome tells whether and when a failed call may be retried.
+type callOutcome struct {
+	// retryable reports failures that mean the service did not process the request.
+	retryable bool
+	// throttled reports a 429: the service is healthy but rate-limits the calculator.
+	throttled bool
+	// retryAfter is the wait the service asked for in its Retry-After header, if any.
+	retryAfter time.Duration
+}
+
+// call makes a single call to the evaluation service.
+func (a *HTTPEvaluationAdapter) call(ctx context.Context, body []byte, valueKey string) (money.Money, callOutcome, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", a.baseURL+"/v1/evaluate", bytes.NewReader(body))
 	if err != nil {
-		return 0, fmt.Errorf("failed to create evaluation request: %w", err)
+		return money.Money{}, callOutcome{}, fmt.Errorf("failed to create evaluation request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := a.client.Do(req)
 	if err != nil {
-		return 0, fmt.Errorf("failed to call evaluation service: %w", err)
+		if notConnected(err) {
+			return money.Money{}, callOutcome{retryable: true}, calculation.NewRuleError(calculation.ErrorUnavailable, "failed to connect to evaluation service: %v", err)
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return money.Money{}, callOutcome{}, calculation.NewRuleError(calculation.ErrorTimeout, "evaluation service did not answer in time: %v", err)
+		}
+		return money.Money{}, callOutcome{}, calculation.NewRuleError(calculation.ErrorUnavailable, "failed to call evaluation service: %v", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("evaluation service returned non-OK status: %d", resp.StatusCode)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusTooManyRequests:
+		outcome := callOutcome{retryable: true, throttled: true, retryAfter: retryAfter(resp.Header.Get("Retry-After"))}
+		return money.Money{}, outcome, calculation.NewRuleError(calculation.ErrorUnavailable, "evaluation service is rate limiting requests: status %d", resp.StatusCode)
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		outcome := callOutcome{retryable: true, retryAfter: retryAfter(resp.Header.Get("Retry-After"))}
+		return money.Money{}, outcome, calculation.NewRuleError(calculation.ErrorUnavailable, "evaluation service returned non-OK status: %d", resp.StatusCode)
+	default:
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			return money.Money{}, callOutcome{}, calculation.NewRuleError(calculation.ErrorInvalidRule, "evaluation service rejected the rule: status %d", resp.StatusCode)
+		}
+		return money.Money{}, callOutcome{}, calculation.NewRuleError(calculation.ErrorUnavailable, "evaluation service returned non-OK status: %d", resp.StatusCode)
 	}
 
-	var evalResp evaluationResponse
-	if err := json.NewDecoder(resp.Body).Decode(&evalResp); err != nil {
-		return 0, fmt.Errorf("failed to decode evaluation response: %w", err)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return money.Money{}, callOutcome{}, calculation.NewRuleError(calculation.ErrorUnavailable, "failed to read evaluation response: %v", err)
 	}
+	var evalResp evaluationv1.EvaluateRuleResponse
+	if err := responseDecoding.Unmarshal(data, &evalResp); err != nil {
+		return money.Money{}, callOutcome{}, calculation.NewRuleError(calculation.ErrorInvalidResponse, "failed to decode evaluation response: %v", err)
+	}
+
+	value, err := resultValue(evalResp.GetResult(), valueKey)
+	return value, callOutcome{}, err
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP date. It returns 0 when the
+// header is absent or invalid.
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+// notConnected reports whether err happened while connecting to the evaluation service, before the
+// request was sent. Failures after that, timeouts included, may come after the service processed it.
+func notConnected(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// unhealthy reports whether err means the evaluation service is unavailable, as opposed to a rule or
+// request error.
+func unhealthy(err error) bool {
+	var ruleErr *calculation.RuleError
+	return errors.As(err, &ruleErr) && (ruleErr.Code == calculation.ErrorUnavailable || ruleErr.Code == calculation.ErrorTimeout)
+}
 
-	return evalResp.Value, nil
+// backoff returns the wait before the retry after the given attempt: a uniformly random duration up
+// to BaseBackoff doubled per attempt, capped at MaxBackoff.
+func (a *HTTPEvaluationAdapter) backoff(attempt int) time.Duration {
+	limit := a.opts.BaseBackoff << attempt
+	if limit <= 0 || limit > a.opts.MaxBackoff {
+		limit = a.opts.MaxBackoff
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(limit)))
+}
+
+// sleep waits for d, returning false when ctx ends first or has no time left for the wait.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
 }
@@ -0,0 +1,97 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	evaluationv1 "github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/api/proto/gen/evaluationv1"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/rules"
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The evaluation service's contract is evaluation.v1.proto, for gRPC and REST alike: REST bodies are
+// the canonical JSON encoding of its messages, with the field names of the proto file.
+var (
+	requestEncoding  = protojson.MarshalOptions{UseProtoNames: true}
+	responseDecoding = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// defaultValueKey is the result field read when a rule's definition does not name one.
+const defaultValueKey = "value"
+
+// RuleCatalog resolves the rules of a calculation to the definitions the evaluation service needs.
+type RuleCatalog interface {
+	Rule(ruleID string) (rules.Definition, bool)
+}
+
+// evaluateRuleRequest builds the evaluation request of a rule. A rule missing from the catalog is an
+// invalid rule.
+func evaluateRuleRequest(ctx context.Context, catalog RuleCatalog, ruleID string, context map[string]interface{}) (*evaluationv1.EvaluateRuleRequest, rules.Definition, error) {
+	definition, ok := catalog.Rule(ruleID)
+	if !ok {
+		return nil, rules.Definition{}, calculation.NewRuleError(calculation.ErrorInvalidRule, "rule %s is not in the rule catalog", ruleID)
+	}
+	contextStruct, err := toStruct(context)
+	if err != nil {
+		return nil, rules.Definition{}, fmt.Errorf("failed to encode evaluation context: %w", err)
+	}
+	return &evaluationv1.EvaluateRuleRequest{
+		RuleId:       ruleID,
+		RuleVersion:  int32(definition.Version),
+		RuleCategory: definition.Category,
+		DslContent:   definition.DSLContent,
+		Context:      contextStruct,
+		DryRun:       calculation.IsDryRun(ctx),
+	}, definition, nil
+}
+
+// resultValue reads the value of a rule from its evaluation result: the Money reported under
+// amounts.<key> when the service knew the currency, otherwise the number under <key> with the
+// result's currency.
+func resultValue(result *structpb.Struct, valueKey string) (money.Money, error) {
+	if valueKey == "" {
+		valueKey = defaultValueKey
+	}
+	fields := result.GetFields()
+
+	if amount, ok := fields["amounts"].GetStructValue().GetFields()[valueKey]; ok {
+		amountFields := amount.GetStructValue().GetFields()
+		value, err := decimal.NewFromString(amountFields["amount"].GetStringValue())
+		if err != nil {
+			return money.Money{}, calculation.NewRuleError(calculation.ErrorInvalidResponse, "evaluation result has an invalid amounts.%s: %v", valueKey, err)
+		}
+		return money.Money{Amount: value, Currency: strings.ToUpper(amountFields["currency"].GetStringValue())}, nil
+	}
+
+	value, ok := fields[valueKey]
+	if !ok {
+		return money.Money{}, calculation.NewRuleError(calculation.ErrorInvalidResponse, "evaluation result has no %s", valueKey)
+	}
+	if _, isNumber := value.GetKind().(*structpb.Value_NumberValue); !isNumber {
+		return money.Money{}, calculation.NewRuleError(calculation.ErrorInvalidResponse, "evaluation result %s is not a number", valueKey)
+	}
+	return money.Money{
+		Amount:   decimal.NewFromFloat(value.GetNumberValue()),
+		Currency: strings.ToUpper(fields["currency"].GetStringValue()),
+	}, nil
+}
+
+// toStruct converts a context to a Struct through JSON, which also accepts the typed slices and maps
+// structpb does not.
+func toStruct(m map[string]interface{}) (*structpb.Struct, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var generic map[string]interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(generic)
+}
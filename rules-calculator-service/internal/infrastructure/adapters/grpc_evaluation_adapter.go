@@ -0,0 +1,93 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	evaluationv1 "github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/api/proto/gen/evaluationv1"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCEvaluationAdapter is a gRPC adapter to the rule evaluation service.
+type GRPCEvaluationAdapter struct {
+	conn    *grpc.ClientConn
+	client  evaluationv1.EvaluationServiceClient
+	catalog RuleCatalog
+}
+
+// NewGRPCEvaluationAdapter creates a new GRPCEvaluationAdapter for the evaluation service at address, e.g. "localhost:9081".
+// The connection is established lazily on the first call.
+func NewGRPCEvaluationAdapter(address string, catalog RuleCatalog) (*GRPCEvaluationAdapter, error) {
+	conn, err := grpc.NewClient(address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create evaluation service client: %w", err)
+	}
+	return NewGRPCEvaluationAdapterWithClient(conn, evaluationv1.NewEvaluationServiceClient(conn), catalog), nil
+}
+
+// NewGRPCEvaluationAdapterWithClient creates a GRPCEvaluationAdapter on an existing client. conn may be nil
+// when the caller owns the connection.
+func NewGRPCEvaluationAdapterWithClient(conn *grpc.ClientConn, client evaluationv1.EvaluationServiceClient, catalog RuleCatalog) *GRPCEvaluationAdapter {
+	return &GRPCEvaluationAdapter{conn: conn, client: client, catalog: catalog}
+}
+
+// Close closes the connection to the evaluation service.
+func (a *GRPCEvaluationAdapter) Close() error {
+	if a.conn == nil {
+		return nil
+	}
+	return a.conn.Close()
+}
+
+// Evaluate evaluates a rule using the rule evaluation service. The call inherits ctx's deadline.
+// Rules missing from the catalog are invalid rules; other failures are classified as
+// calculation.RuleError by their gRPC status code.
+func (a *GRPCEvaluationAdapter) Evaluate(ctx context.Context, ruleID string, context map[string]interface{}) (money.Money, error) {
+	tr := otel.Tracer("adapter")
+	ctx, span := tr.Start(ctx, "GRPCEvaluationAdapter.Evaluate")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("rule.id", ruleID))
+
+	startTime := time.Now()
+	defer func() {
+		telemetry.RuleEvaluationDuration.WithLabelValues(ruleID).Observe(time.Since(startTime).Seconds())
+	}()
+
+	request, definition, err := evaluateRuleRequest(ctx, a.catalog, ruleID, context)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	resp, err := a.client.EvaluateRule(ctx, request)
+	if err != nil {
+		return money.Money{}, calculation.NewRuleError(errorCode(status.Code(err)), "failed to call evaluation service: %v", err)
+	}
+	return resultValue(resp.GetResult(), definition.ValueKey)
+}
+
+// errorCode classifies a gRPC status code of the evaluation service.
+func errorCode(code codes.Code) calculation.ErrorCode {
+	switch code {
+	case codes.DeadlineExceeded:
+		return calculation.ErrorTimeout
+	case codes.Unavailable, codes.ResourceExhausted:
+		return calculation.ErrorUnavailable
+	case codes.InvalidArgument, codes.NotFound, codes.FailedPrecondition:
+		return calculation.ErrorInvalidRule
+	}
+	return calculation.ErrorEvaluationFailed
+}
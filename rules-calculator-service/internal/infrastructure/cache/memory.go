@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
+)
+
+// MemoryCache is an in-process LRU cache of calculation results. Each replica has its own, so every
+// replica must receive the rule events that invalidate it.
+type MemoryCache struct {
+	mu         sync.Mutex
+	size       int
+	ttl        time.Duration
+	now        func() time.Time
+	order      *list.List // most recently used first
+	entries    map[string]*list.Element
+	byRule     map[string]map[string]struct{}
+	generation uint64
+}
+
+type memoryEntry struct {
+	key       string
+	ruleIDs   []string
+	result    calculation.Result
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates a cache of up to size results, which expire after ttl; zero keeps them until
+// they are evicted or invalidated.
+func NewMemoryCache(size int, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		size:    max(1, size),
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		byRule:  make(map[string]map[string]struct{}),
+	}
+}
+
+// SetClock replaces the clock entries expire by.
+func (c *MemoryCache) SetClock(now func() time.Time) {
+	c.now = now
+}
+
+// Get returns the cached result, or nil on a miss.
+func (c *MemoryCache) Get(_ context.Context, key string) (*calculation.Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.remove(element)
+		return nil, nil
+	}
+	c.order.MoveToFront(element)
+	result := entry.result
+	return &result, nil
+}
+
+// Put caches the result, evicting the least recently used result when the cache is full. The result is
+// dropped when rules were invalidated after generation.
+func (c *MemoryCache) Put(_ context.Context, key string, ruleIDs []string, result calculation.Result, generation uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return nil
+	}
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	entry := &memoryEntry{key: key, ruleIDs: ruleIDs, result: result}
+	if c.ttl > 0 {
+		entry.expiresAt = c.now().Add(c.ttl)
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for _, ruleID := range ruleIDs {
+		if c.byRule[ruleID] == nil {
+			c.byRule[ruleID] = make(map[string]struct{})
+		}
+		c.byRule[ruleID][key] = struct{}{}
+	}
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	telemetry.CacheEntries.Set(float64(c.order.Len()))
+	return nil
+}
+
+// Generation returns the number of invalidations so far.
+func (c *MemoryCache) Generation(_ context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation, nil
+}
+
+// InvalidateRules removes the results that depend on any of the rules.
+func (c *MemoryCache) InvalidateRules(_ context.Context, ruleIDs ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, ruleID := range ruleIDs {
+		for key := range c.byRule[ruleID] {
+			if element, ok := c.entries[key]; ok {
+				c.remove(element)
+				telemetry.CacheInvalidationsTotal.Inc()
+			}
+		}
+		delete(c.byRule, ruleID)
+	}
+	telemetry.CacheEntries.Set(float64(c.order.Len()))
+	return nil
+}
+
+// Len returns the number of cached results.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *MemoryCache) remove(element *list.Element) {
+	entry := element.Value.(*memoryEntry)
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	for _, ruleID := range entry.ruleIDs {
+		delete(c.byRule[ruleID], entry.key)
+		if len(c.byRule[ruleID]) == 0 {
+			delete(c.byRule, ruleID)
+		}
+	}
+}
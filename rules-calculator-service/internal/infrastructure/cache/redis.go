@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
+)
+
+// errStale reports a result calculated before the last invalidation.
+var errStale = errors.New("result calculated before the last invalidation")
+
+// RedisCache is a calculation result cache shared by all replicas. Each rule has a set of the keys of
+// the results that depend on it, and a generation counter is incremented on every invalidation.
+type RedisCache struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisCache creates a cache whose keys start with prefix and whose results expire after ttl; zero
+// keeps them until they are invalidated or evicted by Redis.
+func NewRedisCache(client *redis.Client, prefix string, ttl time.Duration) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix, ttl: ttl}
+}
+
+// Get returns the cached result, or nil on a miss.
+func (c *RedisCache) Get(ctx context.Context, key string) (*calculation.Result, error) {
+	data, err := c.client.Get(ctx, c.resultKey(key)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get calculation result from cache: %w", err)
+	}
+
+	var result calculation.Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal calculation result from cache: %w", err)
+	}
+	return &result, nil
+}
+
+// Put caches the result and indexes it by its rules. The result is dropped when rules were invalidated
+// after generation, including by another replica while the result was being written.
+func (c *RedisCache) Put(ctx context.Context, key string, ruleIDs []string, result calculation.Result, generation uint64) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal calculation result for cache: %w", err)
+	}
+
+	err = c.client.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, c.generationKey()).Uint64()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if current != generation {
+			return errStale
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, c.resultKey(key), data, c.ttl)
+			for _, ruleID := range ruleIDs {
+				pipe.SAdd(ctx, c.ruleKey(ruleID), key)
+				if c.ttl > 0 {
+					pipe.Expire(ctx, c.ruleKey(ruleID), c.ttl)
+				}
+			}
+			return nil
+		})
+		return err
+	}, c.generationKey())
+	if errors.Is(err, errStale) || errors.Is(err, redis.TxFailedErr) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to cache calculation result: %w", err)
+	}
+	return nil
+}
+
+// Generation returns the number of invalidations so far.
+func (c *RedisCache) Generation(ctx context.Context) (uint64, error) {
+	generation, err := c.client.Get(ctx, c.generationKey()).Uint64()
+	if err != nil && err != redis.Nil {
+		return 0, fmt.Errorf("failed to get cache generation: %w", err)
+	}
+	return generation, nil
+}
+
+// InvalidateRules removes the results that depend on any of the rules.
+func (c *RedisCache) InvalidateRules(ctx context.Context, ruleIDs ...string) error {
+	if err := c.client.Incr(ctx, c.generationKey()).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached calculation results: %w", err)
+	}
+	for _, ruleID := range ruleIDs {
+		keys, err := c.client.SMembers(ctx, c.ruleKey(ruleID)).Result()
+		if err != nil {
+			return fmt.Errorf("failed to invalidate cached calculation results: %w", err)
+		}
+		resultKeys := make([]string, len(keys))
+		for i, key := range keys {
+			resultKeys[i] = c.resultKey(key)
+		}
+		if len(resultKeys) > 0 {
+			removed, err := c.client.Del(ctx, resultKeys...).Result()
+			if err != nil {
+				return fmt.Errorf("failed to invalidate cached calculation results: %w", err)
+			}
+			telemetry.CacheInvalidationsTotal.Add(float64(removed))
+		}
+		if err := c.client.Del(ctx, c.ruleKey(ruleID)).Err(); err != nil {
+			return fmt.Errorf("failed to invalidate cached calculation results: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *RedisCache) resultKey(key string) string {
+	return c.prefix + ":result:" + key
+}
+
+func (c *RedisCache) ruleKey(ruleID string) string {
+	return c.prefix + ":rule:" + ruleID
+}
+
+func (c *RedisCache) generationKey() string {
+	return c.prefix + ":generation"
+}
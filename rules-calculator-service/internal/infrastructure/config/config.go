@@ -2,12 +2,24 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
 // Config holds the application configuration.
 type Config struct {
-	Server    ServerConfig
-	Telemetry TelemetryConfig
+	Server     ServerConfig
+	Telemetry  TelemetryConfig
+	Evaluation EvaluationConfig
+	Execution  ExecutionConfig
+	Pipeline   PipelineConfig
+	Currency   CurrencyConfig
+	History    HistoryConfig
+	Database   DatabaseConfig
+	Cache      CacheConfig
+	Quote      QuoteConfig
+	NATS       NATSConfig
+	Jobs       JobsConfig
 }
 
 // ServerConfig holds the server configuration.
@@ -21,12 +33,99 @@ type TelemetryConfig struct {
 	Exporter    string // e.g., "stdout", "jaeger", "otlp"
 }
 
+// EvaluationConfig holds the connection settings of the rules evaluation service.
+type EvaluationConfig struct {
+	Transport   string // "http" or "grpc"
+	URL         string // base URL used by the HTTP transport
+	GRPCAddress string // host:port used by the gRPC transport
+	RulesFile   string // JSON rule catalog: the category and DSL of each rule, and the named rule sets
+	// Retries and circuit breaker of the HTTP transport.
+	MaxRetries              int
+	RetryBaseBackoff        time.Duration
+	RetryMaxBackoff         time.Duration
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+}
+
+// ExecutionConfig bounds the evaluation of the rules of a calculation.
+type ExecutionConfig struct {
+	Concurrency int           // rules evaluated at the same time
+	Timeout     time.Duration // time budget of a calculation, split across its rules; 0 for none
+}
+
+// PipelineConfig holds the configuration of pipeline calculations.
+type PipelineConfig struct {
+	LoyaltyBase string // "PRE_TAX" or "POST_TAX": whether loyalty points are computed before or after taxes
+}
+
+// CurrencyConfig holds the currency conversion settings.
+type CurrencyConfig struct {
+	RatesFile string // JSON exchange rates file; the bundled rates are used when empty
+}
+
+// HistoryConfig holds the configuration of the calculation history.
+type HistoryConfig struct {
+	Store string // "memory" or "postgres"
+}
+
+// CacheConfig holds the configuration of the calculation result cache.
+type CacheConfig struct {
+	Backend string        // "none", "memory" or "redis"
+	Size    int           // results kept by the in-memory cache
+	TTL     time.Duration // lifetime of a cached result; 0 for no expiry
+	// Redis backend.
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	// Rule events that invalidate cached results; invalidation is disabled without a NATS URL.
+	RuleEventsSubject string
+}
+
+// QuoteConfig holds the configuration of quotes.
+type QuoteConfig struct {
+	TTL        time.Duration // how long a quote is guaranteed
+	SigningKey string        // HMAC key quotes are signed with; required with the postgres store, random in memory when empty
+}
+
+// NATSConfig holds the NATS configuration.
+type NATSConfig struct {
+	URL string // empty disables everything that needs NATS
+}
+
+// JobsConfig holds the configuration of asynchronous calculation jobs.
+type JobsConfig struct {
+	Workers   int // calculations of a job run at the same time
+	QueueSize int // jobs waiting for the running job
+	MaxItems  int // calculations in a job
+	// Subject is the prefix of the job subjects: jobs are submitted on <Subject>.submit and their
+	// results published to the Stream on <Subject>.results.<job_id> and <Subject>.completed.<job_id>.
+	Subject string
+	Stream  string
+	// Recover resumes the jobs left pending by a restart. Only one replica sharing a database may
+	// recover jobs.
+	Recover bool
+}
+
+// DatabaseConfig holds the database configuration.
+type DatabaseConfig struct {
+	DSN string
+}
+
 // DefaultConfig returns the default configuration.
 func DefaultConfig() *Config {
 	// Get environment variables with defaults
 	serverPort := getEnv("SERVER_PORT", "8082")
 	telemetryServiceName := getEnv("TELEMETRY_SERVICE_NAME", "rules-calculator-service")
 	telemetryExporter := getEnv("TELEMETRY_EXPORTER", "stdout")
+	dbHost := getEnv("DB_HOST", "localhost")
+	dbPort := getEnv("DB_PORT", "5432")
+	dbName := getEnv("DB_NAME", "rules_dev")
+	dbUser := getEnv("DB_USER", "user")
+	dbPassword := getEnv("DB_PASSWORD", "password")
+	dbSSLMode := getEnv("DB_SSL_MODE", "disable")
+
+	// Build DSN
+	dsn := "host=" + dbHost + " user=" + dbUser + " password=" + dbPassword + " dbname=" + dbName + " port=" + dbPort + " sslmode=" + dbSSLMode + " TimeZone=UTC"
 
 	return &Config{
 		Server: ServerConfig{
@@ -36,6 +135,58 @@ func DefaultConfig() *Config {
 			ServiceName: telemetryServiceName,
 			Exporter:    telemetryExporter,
 		},
+		Evaluation: EvaluationConfig{
+			Transport:   getEnv("EVALUATION_TRANSPORT", "http"),
+			URL:         getEnv("EVALUATION_SERVICE_URL", "http://localhost:8081"),
+			GRPCAddress: getEnv("EVALUATION_GRPC_ADDRESS", "localhost:9081"),
+			RulesFile:   getEnv("RULES_CATALOG_FILE", ""),
+
+			MaxRetries:              getEnvInt("EVALUATION_MAX_RETRIES", 2),
+			RetryBaseBackoff:        getEnvDuration("EVALUATION_RETRY_BASE_BACKOFF", 50*time.Millisecond),
+			RetryMaxBackoff:         getEnvDuration("EVALUATION_RETRY_MAX_BACKOFF", time.Second),
+			BreakerFailureThreshold: getEnvInt("EVALUATION_BREAKER_FAILURE_THRESHOLD", 5),
+			BreakerOpenTimeout:      getEnvDuration("EVALUATION_BREAKER_OPEN_TIMEOUT", 30*time.Second),
+		},
+		Execution: ExecutionConfig{
+			Concurrency: getEnvInt("CALCULATION_CONCURRENCY", 8),
+			Timeout:     getEnvDuration("CALCULATION_TIMEOUT", 2*time.Second),
+		},
+		Pipeline: PipelineConfig{
+			LoyaltyBase: getEnv("PIPELINE_LOYALTY_BASE", "POST_TAX"),
+		},
+		Currency: CurrencyConfig{
+			RatesFile: getEnv("CURRENCY_RATES_FILE", ""),
+		},
+		History: HistoryConfig{
+			Store: getEnv("CALCULATIONS_STORE", "memory"),
+		},
+		Database: DatabaseConfig{
+			DSN: dsn,
+		},
+		Cache: CacheConfig{
+			Backend:           getEnv("CALCULATION_CACHE", "none"),
+			Size:              getEnvInt("CALCULATION_CACHE_SIZE", 10000),
+			TTL:               getEnvDuration("CALCULATION_CACHE_TTL", 10*time.Minute),
+			RedisAddr:         getEnv("REDIS_ADDR", "localhost:6379"),
+			RedisPassword:     getEnv("REDIS_PASSWORD", ""),
+			RedisDB:           getEnvInt("REDIS_DB", 0),
+			RuleEventsSubject: getEnv("RULE_EVENTS_SUBJECT", "rules.>"),
+		},
+		Quote: QuoteConfig{
+			TTL:        getEnvDuration("QUOTE_TTL", 5*time.Minute),
+			SigningKey: getEnv("QUOTE_SIGNING_KEY", ""),
+		},
+		NATS: NATSConfig{
+			URL: getEnv("NATS_URL", ""),
+		},
+		Jobs: JobsConfig{
+			Workers:   getEnvInt("JOB_WORKERS", 4),
+			QueueSize: getEnvInt("JOB_QUEUE_SIZE", 100),
+			MaxItems:  getEnvInt("JOB_MAX_ITEMS", 10000),
+			Subject:   getEnv("JOB_SUBJECT", "calculator.jobs"),
+			Stream:    getEnv("JOB_STREAM", "CALCULATOR_JOBS"),
+			Recover:   getEnvBool("JOB_RECOVER", true),
+		},
 	}
 }
 
@@ -46,3 +197,27 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt gets an integer environment variable with a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvDuration gets a duration environment variable with a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvBool gets a boolean environment variable with a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
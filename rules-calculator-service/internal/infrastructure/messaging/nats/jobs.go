@@ -0,0 +1,177 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/job"
+)
+
+const (
+	// submitTimeout bounds the submission of a job received over NATS.
+	submitTimeout = 10 * time.Second
+	// submitQueueGroup spreads job submissions over the replicas, so that each job is submitted once.
+	submitQueueGroup = "rules-calculator"
+)
+
+// JobPublisher publishes the results of calculation jobs to JetStream. Each result is published to
+// <subject>.results.<job_id> and the completion of a job to <subject>.completed.<job_id>; message IDs
+// let JetStream drop the duplicates of results calculated again after a restart.
+type JobPublisher struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	subject string
+}
+
+// jobCompletedEvent is the message announcing that every calculation of a job has a result.
+type jobCompletedEvent struct {
+	JobID       string             `json:"job_id"`
+	Reference   string             `json:"reference,omitempty"`
+	Status      calculation.Status `json:"status"`
+	Total       int                `json:"total"`
+	Succeeded   int                `json:"succeeded"`
+	Failed      int                `json:"failed"`
+	CompletedAt *time.Time         `json:"completed_at,omitempty"`
+}
+
+// NewJobPublisher connects to NATS and creates the stream of the job subjects if it does not exist.
+func NewJobPublisher(url, stream, subject string) (*JobPublisher, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	_, err = js.StreamInfo(stream)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = js.AddStream(&nats.StreamConfig{
+			Name:        stream,
+			Description: "Results of calculation jobs",
+			Subjects:    []string{subject + ".results.>", subject + ".completed.>"},
+			Retention:   nats.LimitsPolicy,
+			MaxAge:      7 * 24 * time.Hour,
+			Storage:     nats.FileStorage,
+			Replicas:    1,
+		})
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create stream %s: %w", stream, err)
+	}
+	return &JobPublisher{conn: conn, js: js, subject: subject}, nil
+}
+
+func (p *JobPublisher) PublishResult(ctx context.Context, result job.Result) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode job result: %w", err)
+	}
+	jobID := result.JobID.String()
+	_, err = p.js.Publish(p.subject+".results."+jobID, data,
+		nats.Context(ctx), nats.MsgId(jobID+":"+strconv.Itoa(result.Index)))
+	return err
+}
+
+func (p *JobPublisher) PublishCompleted(ctx context.Context, j *job.Job) error {
+	data, err := json.Marshal(jobCompletedEvent{
+		JobID:       j.ID().String(),
+		Reference:   j.Reference(),
+		Status:      j.Status(),
+		Total:       j.Total(),
+		Succeeded:   j.Succeeded(),
+		Failed:      j.Failed(),
+		CompletedAt: j.CompletedAt(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode job completion: %w", err)
+	}
+	jobID := j.ID().String()
+	_, err = p.js.Publish(p.subject+".completed."+jobID, data, nats.Context(ctx), nats.MsgId(jobID))
+	return err
+}
+
+// Close closes the NATS connection.
+func (p *JobPublisher) Close() {
+	p.conn.Close()
+}
+
+// JobSubmitter submits the job of a message.
+type JobSubmitter interface {
+	SubmitMessage(ctx context.Context, data []byte) (jobID string, err error)
+}
+
+// jobSubmitReply answers a job submission that was sent as a request.
+type jobSubmitReply struct {
+	JobID string `json:"job_id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// JobSubmitSubscriber accepts calculation jobs submitted on a NATS subject. A submission sent as a
+// request is answered with the job's ID or the reason it was rejected.
+type JobSubmitSubscriber struct {
+	conn      *nats.Conn
+	sub       *nats.Subscription
+	submitter JobSubmitter
+}
+
+// NewJobSubmitSubscriber connects to NATS and subscribes to the job submissions on subject.
+func NewJobSubmitSubscriber(url, subject string, submitter JobSubmitter) (*JobSubmitSubscriber, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	s := &JobSubmitSubscriber{conn: conn, submitter: submitter}
+	s.sub, err = conn.QueueSubscribe(subject, submitQueueGroup, s.handle)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+	return s, nil
+}
+
+func (s *JobSubmitSubscriber) handle(msg *nats.Msg) {
+	ctx, cancel := context.WithTimeout(context.Background(), submitTimeout)
+	defer cancel()
+
+	var reply jobSubmitReply
+	jobID, err := s.submitter.SubmitMessage(ctx, msg.Data)
+	if err != nil {
+		log.Printf("rejected job submitted on %s: %v", msg.Subject, err)
+		reply.Error = err.Error()
+	} else {
+		reply.JobID = jobID
+	}
+	if msg.Reply == "" {
+		return
+	}
+	data, err := json.Marshal(reply)
+	if err != nil {
+		return
+	}
+	if err := msg.Respond(data); err != nil {
+		log.Printf("failed to answer job submission: %v", err)
+	}
+}
+
+// Close unsubscribes and closes the NATS connection.
+func (s *JobSubmitSubscriber) Close() {
+	if s.sub != nil {
+		_ = s.sub.Unsubscribe()
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
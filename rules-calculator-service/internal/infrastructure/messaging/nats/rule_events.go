@@ -0,0 +1,73 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// invalidationTimeout bounds the invalidation of the results of a rule event.
+const invalidationTimeout = 5 * time.Second
+
+// Invalidator removes the cached results that depend on rules.
+type Invalidator interface {
+	InvalidateRules(ctx context.Context, ruleIDs ...string) error
+}
+
+// ruleEvent is the part of the rules management events the cache needs: every rule event carries the
+// ID of the rule it is about.
+type ruleEvent struct {
+	RuleID string `json:"rule_id"`
+}
+
+// RuleEventSubscriber invalidates cached calculation results when the rules they depend on change.
+// It uses a plain subscription rather than a durable consumer, so that every replica sees every event.
+type RuleEventSubscriber struct {
+	conn        *nats.Conn
+	sub         *nats.Subscription
+	invalidator Invalidator
+}
+
+// NewRuleEventSubscriber connects to NATS and subscribes to the rule events on subject, e.g. "rules.>".
+func NewRuleEventSubscriber(url, subject string, invalidator Invalidator) (*RuleEventSubscriber, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	s := &RuleEventSubscriber{conn: conn, invalidator: invalidator}
+	s.sub, err = conn.Subscribe(subject, s.handle)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+	return s, nil
+}
+
+func (s *RuleEventSubscriber) handle(msg *nats.Msg) {
+	var event ruleEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil || event.RuleID == "" {
+		log.Printf("ignoring rule event on %s without a rule_id", msg.Subject)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), invalidationTimeout)
+	defer cancel()
+	if err := s.invalidator.InvalidateRules(ctx, event.RuleID); err != nil {
+		log.Printf("failed to invalidate cached results of rule %s: %v", event.RuleID, err)
+	}
+}
+
+// Close unsubscribes and closes the NATS connection.
+func (s *RuleEventSubscriber) Close() {
+	if s.sub != nil {
+		_ = s.sub.Unsubscribe()
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+)
+
+// CalculationRepository is an in-memory calculation.Repository for a single instance and for tests.
+// The history is lost on restart.
+type CalculationRepository struct {
+	mu           sync.RWMutex
+	calculations map[calculation.CalculationID]calculation.Snapshot
+	byKey        map[string]calculation.CalculationID
+}
+
+func NewCalculationRepository() *CalculationRepository {
+	return &CalculationRepository{
+		calculations: make(map[calculation.CalculationID]calculation.Snapshot),
+		byKey:        make(map[string]calculation.CalculationID),
+	}
+}
+
+// Create stores a new calculation, rejecting a reused idempotency key.
+func (r *CalculationRepository) Create(_ context.Context, calc *calculation.Calculation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := calc.Snapshot()
+	if key := snapshot.IdempotencyKey; key != "" {
+		if _, ok := r.byKey[key]; ok {
+			return calculation.ErrDuplicateIdempotencyKey
+		}
+		r.byKey[key] = snapshot.ID
+	}
+	r.calculations[snapshot.ID] = snapshot
+	return nil
+}
+
+// Update stores the current state of an existing calculation.
+func (r *CalculationRepository) Update(_ context.Context, calc *calculation.Calculation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := calc.Snapshot()
+	if _, ok := r.calculations[snapshot.ID]; !ok {
+		return calculation.ErrNotFound
+	}
+	r.calculations[snapshot.ID] = snapshot
+	return nil
+}
+
+func (r *CalculationRepository) FindByID(_ context.Context, id calculation.CalculationID) (*calculation.Calculation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot, ok := r.calculations[id]
+	if !ok {
+		return nil, calculation.ErrNotFound
+	}
+	return calculation.Restore(snapshot), nil
+}
+
+func (r *CalculationRepository) FindByIdempotencyKey(ctx context.Context, key string) (*calculation.Calculation, error) {
+	r.mu.RLock()
+	id, ok := r.byKey[key]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, calculation.ErrNotFound
+	}
+	return r.FindByID(ctx, id)
+}
+
+// ReleaseIdempotencyKey detaches the idempotency key from the calculation, so that a retry can claim it.
+func (r *CalculationRepository) ReleaseIdempotencyKey(_ context.Context, id calculation.CalculationID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot, ok := r.calculations[id]
+	if !ok {
+		return calculation.ErrNotFound
+	}
+	if r.byKey[snapshot.IdempotencyKey] == id {
+		delete(r.byKey, snapshot.IdempotencyKey)
+	}
+	snapshot.IdempotencyKey = ""
+	r.calculations[id] = snapshot
+	return nil
+}
+
+func (r *CalculationRepository) List(_ context.Context, filter calculation.Filter) ([]*calculation.Calculation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []calculation.Snapshot
+	for _, s := range r.calculations {
+		if filter.CustomerID != "" && s.CustomerID != filter.CustomerID {
+			continue
+		}
+		if filter.From != nil && s.CreatedAt.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !s.CreatedAt.Before(*filter.To) {
+			continue
+		}
+		matched = append(matched, s)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].CreatedAt.After(matched[j].CreatedAt) })
+
+	if filter.Offset >= len(matched) {
+		return []*calculation.Calculation{}, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+	calculations := make([]*calculation.Calculation, len(matched))
+	for i, s := range matched {
+		calculations[i] = calculation.Restore(s)
+	}
+	return calculations, nil
+}
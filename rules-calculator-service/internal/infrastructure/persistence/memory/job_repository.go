@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/job"
+)
+
+// JobRepository is an in-memory job.Repository for a single instance and for tests.
+type JobRepository struct {
+	mu      sync.RWMutex
+	jobs    map[job.JobID]job.Snapshot
+	results map[job.JobID]map[int]job.Result
+}
+
+func NewJobRepository() *JobRepository {
+	return &JobRepository{
+		jobs:    make(map[job.JobID]job.Snapshot),
+		results: make(map[job.JobID]map[int]job.Result),
+	}
+}
+
+func (r *JobRepository) Create(_ context.Context, j *job.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := j.Snapshot()
+	r.jobs[snapshot.ID] = snapshot
+	return nil
+}
+
+func (r *JobRepository) Update(_ context.Context, j *job.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := j.Snapshot()
+	if _, ok := r.jobs[snapshot.ID]; !ok {
+		return job.ErrNotFound
+	}
+	r.jobs[snapshot.ID] = snapshot
+	return nil
+}
+
+func (r *JobRepository) FindByID(_ context.Context, id job.JobID) (*job.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot, ok := r.jobs[id]
+	if !ok {
+		return nil, job.ErrNotFound
+	}
+	return job.Restore(snapshot), nil
+}
+
+func (r *JobRepository) FindPending(_ context.Context) ([]*job.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var pending []job.Snapshot
+	for _, snapshot := range r.jobs {
+		if snapshot.Status == calculation.StatusPending {
+			pending = append(pending, snapshot)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+
+	jobs := make([]*job.Job, len(pending))
+	for i, snapshot := range pending {
+		jobs[i] = job.Restore(snapshot)
+	}
+	return jobs, nil
+}
+
+func (r *JobRepository) SaveResult(_ context.Context, result job.Result) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[result.JobID]; !ok {
+		return job.ErrNotFound
+	}
+	if r.results[result.JobID] == nil {
+		r.results[result.JobID] = make(map[int]job.Result)
+	}
+	r.results[result.JobID][result.Index] = result
+	return nil
+}
+
+func (r *JobRepository) ListResults(_ context.Context, id job.JobID, limit, offset int) ([]job.Result, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.jobs[id]; !ok {
+		return nil, job.ErrNotFound
+	}
+	results := make([]job.Result, 0, len(r.results[id]))
+	for _, result := range r.results[id] {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Index < results[j].Index
+	})
+
+	if offset >= len(results) {
+		return []job.Result{}, nil
+	}
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results, nil
+}
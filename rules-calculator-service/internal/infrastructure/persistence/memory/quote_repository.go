@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/quote"
+)
+
+// QuoteRepository is an in-memory quote.Repository for a single instance and for tests.
+type QuoteRepository struct {
+	mu     sync.RWMutex
+	quotes map[quote.QuoteID]quote.Snapshot
+}
+
+func NewQuoteRepository() *QuoteRepository {
+	return &QuoteRepository{quotes: make(map[quote.QuoteID]quote.Snapshot)}
+}
+
+func (r *QuoteRepository) Create(_ context.Context, q *quote.Quote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := q.Snapshot()
+	r.quotes[snapshot.ID] = snapshot
+	return nil
+}
+
+func (r *QuoteRepository) Update(_ context.Context, q *quote.Quote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := q.Snapshot()
+	if _, ok := r.quotes[snapshot.ID]; !ok {
+		return quote.ErrNotFound
+	}
+	r.quotes[snapshot.ID] = snapshot
+	return nil
+}
+
+func (r *QuoteRepository) FindByID(_ context.Context, id quote.QuoteID) (*quote.Quote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot, ok := r.quotes[id]
+	if !ok {
+		return nil, quote.ErrNotFound
+	}
+	return quote.Restore(snapshot), nil
+}
@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/telemetry"
+)
+
+// CalculationDBModel is the GORM model for a calculation. Inputs and results are stored as JSON.
+type CalculationDBModel struct {
+	ID             string  `gorm:"primaryKey"`
+	IdempotencyKey *string `gorm:"uniqueIndex"`
+	RequestHash    string
+	CustomerID     string    `gorm:"index:idx_calculations_customer_created"`
+	RuleIDs        []byte    `gorm:"type:jsonb;not null"`
+	RuleVersions   []byte    `gorm:"type:jsonb"`
+	Context        []byte    `gorm:"type:jsonb;not null"`
+	Currency       string    `gorm:"size:3"`
+	Status         string    `gorm:"not null"`
+	Result         []byte    `gorm:"type:jsonb"`
+	Failure        string    `gorm:"type:text"`
+	CreatedAt      time.Time `gorm:"not null;index;index:idx_calculations_customer_created"`
+	CompletedAt    *time.Time
+	DurationMs     int64
+}
+
+func (CalculationDBModel) TableName() string {
+	return "calculations"
+}
+
+// CalculationRepository is a Postgres-backed calculation.Repository. The database must be opened with
+// gorm.Config.TranslateError so that reused idempotency keys are detected.
+type CalculationRepository struct {
+	db *gorm.DB
+}
+
+func NewCalculationRepository(db *gorm.DB) *CalculationRepository {
+	return &CalculationRepository{db: db}
+}
+
+// Create inserts a new calculation; the unique index on the idempotency key rejects retries.
+func (r *CalculationRepository) Create(ctx context.Context, calc *calculation.Calculation) error {
+	defer observe("CalculationCreate", time.Now())
+
+	model, err := toModel(calc)
+	if err != nil {
+		return err
+	}
+	err = r.db.WithContext(ctx).Create(model).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return calculation.ErrDuplicateIdempotencyKey
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create calculation: %w", err)
+	}
+	return nil
+}
+
+// Update stores the status, result and timings of an existing calculation.
+func (r *CalculationRepository) Update(ctx context.Context, calc *calculation.Calculation) error {
+	defer observe("CalculationUpdate", time.Now())
+
+	model, err := toModel(calc)
+	if err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(&CalculationDBModel{ID: model.ID}).
+		Select("Status", "Result", "Failure", "CompletedAt", "DurationMs").
+		Updates(model)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update calculation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return calculation.ErrNotFound
+	}
+	return nil
+}
+
+func (r *CalculationRepository) FindByID(ctx context.Context, id calculation.CalculationID) (*calculation.Calculation, error) {
+	defer observe("CalculationFindByID", time.Now())
+	return r.findOne(r.db.WithContext(ctx).Where("id = ?", id.String()))
+}
+
+func (r *CalculationRepository) FindByIdempotencyKey(ctx context.Context, key string) (*calculation.Calculation, error) {
+	defer observe("CalculationFindByIdempotencyKey", time.Now())
+	return r.findOne(r.db.WithContext(ctx).Where("idempotency_key = ?", key))
+}
+
+// ReleaseIdempotencyKey clears the idempotency key of the calculation, so that a retry can claim it.
+func (r *CalculationRepository) ReleaseIdempotencyKey(ctx context.Context, id calculation.CalculationID) error {
+	defer observe("CalculationReleaseIdempotencyKey", time.Now())
+
+	result := r.db.WithContext(ctx).Model(&CalculationDBModel{ID: id.String()}).Update("idempotency_key", nil)
+	if result.Error != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return calculation.ErrNotFound
+	}
+	return nil
+}
+
+func (r *CalculationRepository) List(ctx context.Context, filter calculation.Filter) ([]*calculation.Calculation, error) {
+	defer observe("CalculationList", time.Now())
+
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if filter.CustomerID != "" {
+		query = query.Where("customer_id = ?", filter.CustomerID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var models []CalculationDBModel
+	if err := query.Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list calculations: %w", err)
+	}
+	calculations := make([]*calculation.Calculation, len(models))
+	for i := range models {
+		calc, err := fromModel(&models[i])
+		if err != nil {
+			return nil, err
+		}
+		calculations[i] = calc
+	}
+	return calculations, nil
+}
+
+func (r *CalculationRepository) findOne(query *gorm.DB) (*calculation.Calculation, error) {
+	var model CalculationDBModel
+	err := query.First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, calculation.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find calculation: %w", err)
+	}
+	return fromModel(&model)
+}
+
+func toModel(calc *calculation.Calculation) (*CalculationDBModel, error) {
+	s := calc.Snapshot()
+	model := &CalculationDBModel{
+		ID:          s.ID.String(),
+		RequestHash: s.RequestHash,
+		CustomerID:  s.CustomerID,
+		Currency:    s.Currency,
+		Status:      string(s.Status),
+		Failure:     s.Failure,
+		CreatedAt:   s.CreatedAt,
+		CompletedAt: s.CompletedAt,
+		DurationMs:  calc.Duration().Milliseconds(),
+	}
+	if s.IdempotencyKey != "" {
+		model.IdempotencyKey = &s.IdempotencyKey
+	}
+	var err error
+	if model.RuleIDs, err = json.Marshal(s.RuleIDs); err != nil {
+		return nil, fmt.Errorf("failed to encode rule IDs: %w", err)
+	}
+	if model.RuleVersions, err = json.Marshal(s.RuleVersions); err != nil {
+		return nil, fmt.Errorf("failed to encode rule versions: %w", err)
+	}
+	if model.Context, err = json.Marshal(s.Context); err != nil {
+		return nil, fmt.Errorf("failed to encode context: %w", err)
+	}
+	if s.Result != nil {
+		if model.Result, err = json.Marshal(s.Result); err != nil {
+			return nil, fmt.Errorf("failed to encode result: %w", err)
+		}
+	}
+	return model, nil
+}
+
+func fromModel(model *CalculationDBModel) (*calculation.Calculation, error) {
+	id, err := uuid.Parse(model.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calculation ID %q: %w", model.ID, err)
+	}
+	s := calculation.Snapshot{
+		ID:          calculation.CalculationID(id),
+		RequestHash: model.RequestHash,
+		CustomerID:  model.CustomerID,
+		Currency:    model.Currency,
+		Status:      calculation.Status(model.Status),
+		Failure:     model.Failure,
+		CreatedAt:   model.CreatedAt.UTC(),
+		CompletedAt: model.CompletedAt,
+	}
+	if model.IdempotencyKey != nil {
+		s.IdempotencyKey = *model.IdempotencyKey
+	}
+	if err := decodeJSON(model.RuleIDs, &s.RuleIDs); err != nil {
+		return nil, err
+	}
+	if err := decodeJSON(model.RuleVersions, &s.RuleVersions); err != nil {
+		return nil, err
+	}
+	if err := decodeJSON(model.Context, &s.Context); err != nil {
+		return nil, err
+	}
+	if len(model.Result) > 0 && string(model.Result) != "null" {
+		s.Result = &calculation.Result{}
+		if err := decodeJSON(model.Result, s.Result); err != nil {
+			return nil, err
+		}
+	}
+	return calculation.Restore(s), nil
+}
+
+func decodeJSON(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode calculation: %w", err)
+	}
+	return nil
+}
+
+func observe(operation string, start time.Time) {
+	telemetry.DBQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
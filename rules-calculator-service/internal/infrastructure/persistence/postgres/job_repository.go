@@ -0,0 +1,249 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/job"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+)
+
+// JobDBModel is the GORM model for a calculation job. The items are stored as JSON.
+type JobDBModel struct {
+	ID          string    `gorm:"primaryKey"`
+	Reference   string    `gorm:"index"`
+	Items       []byte    `gorm:"type:jsonb;not null"`
+	Status      string    `gorm:"not null;index"`
+	Total       int       `gorm:"not null"`
+	Processed   int       `gorm:"not null"`
+	Succeeded   int       `gorm:"not null"`
+	Failed      int       `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"not null"`
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+}
+
+func (JobDBModel) TableName() string {
+	return "calculation_jobs"
+}
+
+// JobResultDBModel is the GORM model for the result of an item of a calculation job.
+type JobResultDBModel struct {
+	JobID         string `gorm:"primaryKey"`
+	Index         int    `gorm:"primaryKey;column:item_index"`
+	Reference     string
+	CalculationID string
+	Status        string `gorm:"not null"`
+	Value         []byte `gorm:"type:jsonb"`
+	Errors        []byte `gorm:"type:jsonb"`
+	Error         string
+}
+
+func (JobResultDBModel) TableName() string {
+	return "calculation_job_results"
+}
+
+// JobRepository is a Postgres-backed job.Repository.
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+func (r *JobRepository) Create(ctx context.Context, j *job.Job) error {
+	defer observe("JobCreate", time.Now())
+
+	model, err := toJobModel(j)
+	if err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+// Update stores the status and progress of an existing job.
+func (r *JobRepository) Update(ctx context.Context, j *job.Job) error {
+	defer observe("JobUpdate", time.Now())
+
+	model, err := toJobModel(j)
+	if err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(&JobDBModel{ID: model.ID}).
+		Select("Status", "Processed", "Succeeded", "Failed", "StartedAt", "CompletedAt").
+		Updates(model)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update job: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return job.ErrNotFound
+	}
+	return nil
+}
+
+func (r *JobRepository) FindByID(ctx context.Context, id job.JobID) (*job.Job, error) {
+	defer observe("JobFindByID", time.Now())
+
+	var model JobDBModel
+	err := r.db.WithContext(ctx).Where("id = ?", id.String()).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, job.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+	return fromJobModel(&model)
+}
+
+func (r *JobRepository) FindPending(ctx context.Context) ([]*job.Job, error) {
+	defer observe("JobFindPending", time.Now())
+
+	var models []JobDBModel
+	err := r.db.WithContext(ctx).
+		Where("status = ?", string(calculation.StatusPending)).
+		Order("created_at ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pending jobs: %w", err)
+	}
+
+	jobs := make([]*job.Job, 0, len(models))
+	for i := range models {
+		j, err := fromJobModel(&models[i])
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+// SaveResult stores the result of an item, replacing the result of an item calculated again after
+// the job was interrupted.
+func (r *JobRepository) SaveResult(ctx context.Context, result job.Result) error {
+	defer observe("JobSaveResult", time.Now())
+
+	model := &JobResultDBModel{
+		JobID:         result.JobID.String(),
+		Index:         result.Index,
+		Reference:     result.Reference,
+		CalculationID: result.CalculationID,
+		Status:        string(result.Status),
+		Error:         result.Error,
+	}
+	var err error
+	if result.Value != nil {
+		if model.Value, err = json.Marshal(result.Value); err != nil {
+			return fmt.Errorf("failed to encode value: %w", err)
+		}
+	}
+	if len(result.Errors) > 0 {
+		if model.Errors, err = json.Marshal(result.Errors); err != nil {
+			return fmt.Errorf("failed to encode errors: %w", err)
+		}
+	}
+	err = r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(model).Error
+	if err != nil {
+		return fmt.Errorf("failed to save job result: %w", err)
+	}
+	return nil
+}
+
+func (r *JobRepository) ListResults(ctx context.Context, id job.JobID, limit, offset int) ([]job.Result, error) {
+	defer observe("JobListResults", time.Now())
+
+	if _, err := r.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	query := r.db.WithContext(ctx).Where("job_id = ?", id.String()).Order("item_index ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	var models []JobResultDBModel
+	if err := query.Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list job results: %w", err)
+	}
+	results := make([]job.Result, 0, len(models))
+	for _, model := range models {
+		result := job.Result{
+			JobID:         id,
+			Index:         model.Index,
+			Reference:     model.Reference,
+			CalculationID: model.CalculationID,
+			Status:        calculation.Status(model.Status),
+			Error:         model.Error,
+		}
+		if len(model.Value) > 0 {
+			result.Value = &money.Money{}
+			if err := decodeJSON(model.Value, result.Value); err != nil {
+				return nil, err
+			}
+		}
+		if err := decodeJSON(model.Errors, &result.Errors); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func toJobModel(j *job.Job) (*JobDBModel, error) {
+	s := j.Snapshot()
+	items, err := json.Marshal(s.Items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job items: %w", err)
+	}
+	return &JobDBModel{
+		ID:          s.ID.String(),
+		Reference:   s.Reference,
+		Items:       items,
+		Status:      string(s.Status),
+		Total:       len(s.Items),
+		Processed:   s.Processed,
+		Succeeded:   s.Succeeded,
+		Failed:      s.Failed,
+		CreatedAt:   s.CreatedAt,
+		StartedAt:   s.StartedAt,
+		CompletedAt: s.CompletedAt,
+	}, nil
+}
+
+func fromJobModel(model *JobDBModel) (*job.Job, error) {
+	id, err := uuid.Parse(model.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job ID %q: %w", model.ID, err)
+	}
+	s := job.Snapshot{
+		ID:          job.JobID(id),
+		Reference:   model.Reference,
+		Status:      calculation.Status(model.Status),
+		Processed:   model.Processed,
+		Succeeded:   model.Succeeded,
+		Failed:      model.Failed,
+		CreatedAt:   model.CreatedAt.UTC(),
+		StartedAt:   model.StartedAt,
+		CompletedAt: model.CompletedAt,
+	}
+	if err := decodeJSON(model.Items, &s.Items); err != nil {
+		return nil, err
+	}
+	return job.Restore(s), nil
+}
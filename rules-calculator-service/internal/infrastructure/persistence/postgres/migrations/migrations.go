@@ -0,0 +1,14 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/postgres"
+)
+
+// ApplyMigrations applies database migrations
+func ApplyMigrations(db *gorm.DB) error {
+	// Auto-migrate the schema
+	return db.AutoMigrate(&postgres.CalculationDBModel{}, &postgres.QuoteDBModel{},
+		&postgres.JobDBModel{}, &postgres.JobResultDBModel{})
+}
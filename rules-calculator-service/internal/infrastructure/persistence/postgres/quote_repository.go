@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/quote"
+)
+
+// QuoteDBModel is the GORM model for a quote. The request and the result are stored as JSON.
+type QuoteDBModel struct {
+	ID            string    `gorm:"primaryKey"`
+	CalculationID string    `gorm:"index"`
+	Request       []byte    `gorm:"type:jsonb;not null"`
+	Result        []byte    `gorm:"type:jsonb;not null"`
+	RuleVersions  []byte    `gorm:"type:jsonb"`
+	Status        string    `gorm:"not null"`
+	Signature     string    `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"not null"`
+	ExpiresAt     time.Time `gorm:"not null;index"`
+	ConfirmedAt   *time.Time
+}
+
+func (QuoteDBModel) TableName() string {
+	return "quotes"
+}
+
+// QuoteRepository is a Postgres-backed quote.Repository.
+type QuoteRepository struct {
+	db *gorm.DB
+}
+
+func NewQuoteRepository(db *gorm.DB) *QuoteRepository {
+	return &QuoteRepository{db: db}
+}
+
+func (r *QuoteRepository) Create(ctx context.Context, q *quote.Quote) error {
+	defer observe("QuoteCreate", time.Now())
+
+	model, err := toQuoteModel(q)
+	if err != nil {
+		return err
+	}
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fmt.Errorf("failed to create quote: %w", err)
+	}
+	return nil
+}
+
+// Update stores the status of an existing quote.
+func (r *QuoteRepository) Update(ctx context.Context, q *quote.Quote) error {
+	defer observe("QuoteUpdate", time.Now())
+
+	model, err := toQuoteModel(q)
+	if err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(&QuoteDBModel{ID: model.ID}).
+		Select("Status", "ConfirmedAt").
+		Updates(model)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update quote: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return quote.ErrNotFound
+	}
+	return nil
+}
+
+func (r *QuoteRepository) FindByID(ctx context.Context, id quote.QuoteID) (*quote.Quote, error) {
+	defer observe("QuoteFindByID", time.Now())
+
+	var model QuoteDBModel
+	err := r.db.WithContext(ctx).Where("id = ?", id.String()).First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, quote.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find quote: %w", err)
+	}
+	return fromQuoteModel(&model)
+}
+
+func toQuoteModel(q *quote.Quote) (*QuoteDBModel, error) {
+	s := q.Snapshot()
+	model := &QuoteDBModel{
+		ID:            s.ID.String(),
+		CalculationID: s.CalculationID,
+		Request:       s.Request,
+		Status:        string(s.Status),
+		Signature:     s.Signature,
+		CreatedAt:     s.CreatedAt,
+		ExpiresAt:     s.ExpiresAt,
+		ConfirmedAt:   s.ConfirmedAt,
+	}
+	var err error
+	if model.Result, err = json.Marshal(s.Result); err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	if model.RuleVersions, err = json.Marshal(s.RuleVersions); err != nil {
+		return nil, fmt.Errorf("failed to encode rule versions: %w", err)
+	}
+	return model, nil
+}
+
+func fromQuoteModel(model *QuoteDBModel) (*quote.Quote, error) {
+	id, err := uuid.Parse(model.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quote ID %q: %w", model.ID, err)
+	}
+	s := quote.Snapshot{
+		ID:            quote.QuoteID(id),
+		CalculationID: model.CalculationID,
+		Request:       model.Request,
+		Status:        quote.Status(model.Status),
+		Signature:     model.Signature,
+		CreatedAt:     model.CreatedAt.UTC(),
+		ExpiresAt:     model.ExpiresAt.UTC(),
+		ConfirmedAt:   model.ConfirmedAt,
+	}
+	if err := decodeJSON(model.Result, &s.Result); err != nil {
+		return nil, err
+	}
+	if err := decodeJSON(model.RuleVersions, &s.RuleVersions); err != nil {
+		return nil, err
+	}
+	return quote.Restore(s), nil
+}
@@ -0,0 +1,81 @@
+package rates
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+)
+
+//go:embed default_rates.json
+var defaultRates []byte
+
+// StaticProvider serves exchange rates from a file, given against a base currency:
+//
+//	{"base": "EUR", "rates": {"USD": "1.08", "GBP": "0.85"}}
+//
+// Rates between two non-base currencies are derived through the base currency.
+type StaticProvider struct {
+	base  string
+	rates map[string]decimal.Decimal
+}
+
+type ratesFile struct {
+	Base  string                     `json:"base"`
+	Rates map[string]decimal.Decimal `json:"rates"`
+}
+
+// NewStaticProvider loads the rates file at path, or the bundled rates when path is empty.
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	data := defaultRates
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read rates file: %w", err)
+		}
+	}
+	return ParseStaticProvider(data)
+}
+
+// ParseStaticProvider creates a StaticProvider from the contents of a rates file.
+func ParseStaticProvider(data []byte) (*StaticProvider, error) {
+	var file ratesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid rates file: %w", err)
+	}
+	base, err := money.ParseCurrency(file.Base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rates file: base: %w", err)
+	}
+	provider := &StaticProvider{base: base, rates: map[string]decimal.Decimal{base: decimal.NewFromInt(1)}}
+	for currency, rate := range file.Rates {
+		code, err := money.ParseCurrency(currency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rates file: %w", err)
+		}
+		if !rate.IsPositive() {
+			return nil, fmt.Errorf("invalid rates file: rate of %s must be positive", code)
+		}
+		provider.rates[code] = rate
+	}
+	return provider, nil
+}
+
+// Rate returns the amount of currency to that one unit of currency from is worth.
+func (p *StaticProvider) Rate(_ context.Context, from, to string) (decimal.Decimal, error) {
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return decimal.Decimal{}, &money.UnknownRateError{From: from, To: to}
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return decimal.Decimal{}, &money.UnknownRateError{From: from, To: to}
+	}
+	// Sixteen decimals keep cross rates exact enough for any amount that is later rounded to cents.
+	return toRate.DivRound(fromRate, 16), nil
+}
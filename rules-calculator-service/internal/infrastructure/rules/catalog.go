@@ -0,0 +1,90 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+)
+
+// Definition is a rule as the evaluation service evaluates it.
+type Definition struct {
+	Category   string `json:"rule_category"`
+	DSLContent string `json:"dsl_content"`
+	Version    int    `json:"rule_version,omitempty"`
+	// ValueKey is the field of the evaluation result that holds the rule's value, e.g. "discount_amount"
+	// or "total_tax"; "value" when empty.
+	ValueKey string `json:"value_key,omitempty"`
+}
+
+// StaticCatalog serves rule definitions from a file, keyed by rule ID, together with named rule sets
+// of those rules:
+//
+//	{
+//	  "rules": {"summer-sale": {"rule_category": "COUPONS", "dsl_content": "IF ...", "value_key": "discount_amount"}},
+//	  "rule_sets": {"current": {"rule_ids": ["summer-sale"]}}
+//	}
+type StaticCatalog struct {
+	rules    map[string]Definition
+	ruleSets map[string]calculation.RuleSet
+}
+
+type catalogFile struct {
+	Rules    map[string]Definition          `json:"rules"`
+	RuleSets map[string]calculation.RuleSet `json:"rule_sets"`
+}
+
+// NewStaticCatalog loads the catalog file at path, or an empty catalog when path is empty.
+func NewStaticCatalog(path string) (*StaticCatalog, error) {
+	if path == "" {
+		return &StaticCatalog{rules: map[string]Definition{}, ruleSets: map[string]calculation.RuleSet{}}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule catalog: %w", err)
+	}
+	return ParseStaticCatalog(data)
+}
+
+// ParseStaticCatalog creates a StaticCatalog from the contents of a catalog file.
+func ParseStaticCatalog(data []byte) (*StaticCatalog, error) {
+	var file catalogFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid rule catalog: %w", err)
+	}
+	for id, definition := range file.Rules {
+		if definition.Category == "" || definition.DSLContent == "" {
+			return nil, fmt.Errorf("invalid rule catalog: rule %s needs a rule_category and a dsl_content", id)
+		}
+	}
+	for id, ruleSet := range file.RuleSets {
+		if (ruleSet.RuleIDs == nil) == (ruleSet.Pipeline == nil) {
+			return nil, fmt.Errorf("invalid rule catalog: rule set %s needs either rule_ids or a pipeline", id)
+		}
+	}
+	if file.Rules == nil {
+		file.Rules = map[string]Definition{}
+	}
+	if file.RuleSets == nil {
+		file.RuleSets = map[string]calculation.RuleSet{}
+	}
+	return &StaticCatalog{rules: file.Rules, ruleSets: file.RuleSets}, nil
+}
+
+// Rule returns the definition of the rule with the given ID.
+func (c *StaticCatalog) Rule(ruleID string) (Definition, bool) {
+	definition, ok := c.rules[ruleID]
+	return definition, ok
+}
+
+// RuleSet returns the rule set with the given ID.
+func (c *StaticCatalog) RuleSet(id string) (calculation.RuleSet, bool) {
+	ruleSet, ok := c.ruleSets[id]
+	return ruleSet, ok
+}
+
+// Len returns the number of rules in the catalog.
+func (c *StaticCatalog) Len() int {
+	return len(c.rules)
+}
@@ -16,9 +16,85 @@ var (
 		Name: "rules_calculator_calculation_duration_seconds",
 		Help: "The duration of calculations",
 	})
+	// CurrencyMismatchesTotal is a counter for calculations rejected for mixing currencies without a target currency.
+	CurrencyMismatchesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rules_calculator_currency_mismatches_total",
+		Help: "The total number of calculations rejected for mixing currencies without a target currency",
+	})
+	// IdempotentReplaysTotal is a counter for retried calculation requests answered with the stored result.
+	IdempotentReplaysTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rules_calculator_idempotent_replays_total",
+		Help: "The total number of retried calculation requests answered with the stored result",
+	})
+	// CacheRequestsTotal is a counter for result cache lookups by result: hit, miss or error. The hit
+	// rate is hits over all lookups.
+	CacheRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_calculator_cache_requests_total",
+		Help: "The total number of calculation result cache lookups by result",
+	}, []string{"result"})
+	// CacheInvalidationsTotal is a counter for cached results removed because one of their rules changed.
+	CacheInvalidationsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rules_calculator_cache_invalidations_total",
+		Help: "The total number of cached calculation results removed because one of their rules changed",
+	})
+	// CacheEntries is a gauge for the number of results in the in-memory cache.
+	CacheEntries = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "rules_calculator_cache_entries",
+		Help: "The number of calculation results in the in-memory cache",
+	})
+	// QuotesTotal is a counter for quotes by outcome: created, confirmed or expired.
+	QuotesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_calculator_quotes_total",
+		Help: "The total number of quotes by outcome",
+	}, []string{"outcome"})
+	// ComparisonsTotal is a counter for what-if comparisons of rule selections.
+	ComparisonsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rules_calculator_comparisons_total",
+		Help: "The total number of comparisons of rule selections",
+	})
+	// JobsTotal is a counter for calculation jobs by status: submitted, completed or failed.
+	JobsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_calculator_jobs_total",
+		Help: "The total number of calculation jobs by status",
+	}, []string{"status"})
+	// JobItemsTotal is a counter for the calculations of jobs by status.
+	JobItemsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_calculator_job_items_total",
+		Help: "The total number of job calculations by status",
+	}, []string{"status"})
+	// JobQueueDepth is a gauge for the number of jobs waiting for a worker.
+	JobQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "rules_calculator_job_queue_depth",
+		Help: "The number of calculation jobs waiting to be processed",
+	})
+	// EvaluationRetriesTotal is a counter for retried calls to the evaluation service.
+	EvaluationRetriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rules_calculator_evaluation_retries_total",
+		Help: "The total number of retried calls to the evaluation service",
+	})
+	// CircuitBreakerState is a gauge for the state of the circuit breakers: 0 closed, 1 open, 2 half-open.
+	CircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rules_calculator_circuit_breaker_state",
+		Help: "The state of the circuit breaker: 0 closed, 1 open, 2 half-open",
+	}, []string{"breaker"})
+	// CircuitBreakerRejectionsTotal is a counter for calls rejected by an open circuit breaker.
+	CircuitBreakerRejectionsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rules_calculator_circuit_breaker_rejections_total",
+		Help: "The total number of evaluation calls rejected by an open circuit breaker",
+	})
+	// RuleFailuresTotal is a counter for failed rule evaluations by error code.
+	RuleFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_calculator_rule_failures_total",
+		Help: "The total number of failed rule evaluations by error code",
+	}, []string{"code"})
 	// RuleEvaluationDuration is a histogram of the duration of individual rule evaluations.
 	RuleEvaluationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Name: "rules_calculator_rule_evaluation_duration_seconds",
 		Help: "The duration of individual rule evaluations.",
 	}, []string{"rule_id"})
+	// DBQueryDuration is a histogram of the duration of database queries.
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "rules_calculator_db_query_duration_seconds",
+		Help: "The duration of database queries.",
+	}, []string{"operation"})
 )
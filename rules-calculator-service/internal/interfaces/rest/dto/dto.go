@@ -1,19 +1,224 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/job"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/shopspring/decimal"
+)
+
 // CalculationRequest is the DTO for a calculation request.
 type CalculationRequest struct {
-	RuleIDs []string               `json:"rule_ids" binding:"required"`
-	Context map[string]interface{} `json:"context" binding:"required"`
+	// RuleIDs are summed into the total; they must be empty when a pipeline is given.
+	RuleIDs  []string               `json:"rule_ids"`
+	Context  map[string]interface{} `json:"context" binding:"required"`
+	Currency string                 `json:"currency,omitempty"`
+	// RuleVersions are recorded in the calculation history.
+	RuleVersions map[string]int `json:"rule_versions,omitempty"`
+	// FailurePolicy is FAIL_FAST or BEST_EFFORT (the default).
+	FailurePolicy string `json:"failure_policy,omitempty"`
+	// OptionalRuleIDs are left out of the total when they fail; when set, other rules fail the calculation.
+	OptionalRuleIDs []string `json:"optional_rule_ids,omitempty"`
+	// RequiredRuleIDs fail the calculation when they fail. Rules the request does not classify are optional.
+	RequiredRuleIDs []string `json:"required_rule_ids,omitempty"`
+	// Pipeline calculates the total in ordered stages: discounts, fees, taxes and loyalty.
+	Pipeline *PipelineRequest `json:"pipeline,omitempty"`
+	// Basket is the line items of a pipeline calculation; discounts and taxes are allocated to its lines.
+	Basket *BasketRequest `json:"basket,omitempty"`
+	// ItemRuleIDs are evaluated once per basket line.
+	ItemRuleIDs []string `json:"item_rule_ids,omitempty"`
+	// Allocations decide how basket-level discounts and taxes are allocated to lines, by rule ID.
+	Allocations map[string]AllocationRequest `json:"allocations,omitempty"`
+}
+
+// PipelineRequest is the DTO for the stages of a pipeline calculation.
+type PipelineRequest struct {
+	Stages []StageRequest `json:"stages" binding:"required,dive"`
+	// LoyaltyBase is PRE_TAX or POST_TAX; the configured base is used when empty.
+	LoyaltyBase string `json:"loyalty_base,omitempty"`
+}
+
+// StageRequest is the DTO for a pipeline stage.
+type StageRequest struct {
+	Category string   `json:"category" binding:"required"`
+	RuleIDs  []string `json:"rule_ids" binding:"required"`
+}
+
+// BasketRequest is the DTO for the basket of a calculation.
+type BasketRequest struct {
+	Currency string              `json:"currency" binding:"required"`
+	Items    []BasketItemRequest `json:"items" binding:"required,dive"`
+}
+
+// BasketItemRequest is the DTO for a basket line.
+type BasketItemRequest struct {
+	LineID    string          `json:"line_id,omitempty"`
+	SKU       string          `json:"sku" binding:"required"`
+	Category  string          `json:"category,omitempty"`
+	Quantity  int             `json:"quantity" binding:"required"`
+	UnitPrice decimal.Decimal `json:"unit_price"`
+}
+
+// AllocationRequest is the DTO for the allocation of a rule's value to basket lines.
+type AllocationRequest struct {
+	// Method is PRO_RATA (the default) or TARGETED.
+	Method     string   `json:"method,omitempty"`
+	SKUs       []string `json:"skus,omitempty"`
+	Categories []string `json:"categories,omitempty"`
 }
 
 // CalculationResponse is the DTO for a calculation response.
 type CalculationResponse struct {
-	CalculationID string             `json:"calculation_id"`
-	Value         float64            `json:"value"`
-	Breakdown     map[string]float64 `json:"breakdown"`
+	CalculationID string                             `json:"calculation_id"`
+	Status        string                             `json:"status"`
+	Value         money.Money                        `json:"value"`
+	Breakdown     map[string]calculation.RuleOutcome `json:"breakdown"`
+	Errors        []calculation.RuleFailure          `json:"errors,omitempty"`
+	Stages        []calculation.StageResult          `json:"stages,omitempty"`
+	Lines         []calculation.LineResult           `json:"lines,omitempty"`
+}
+
+// CalculationDetailResponse is the DTO for a stored calculation.
+type CalculationDetailResponse struct {
+	CalculationID string                             `json:"calculation_id"`
+	Status        string                             `json:"status"`
+	CustomerID    string                             `json:"customer_id,omitempty"`
+	RuleIDs       []string                           `json:"rule_ids"`
+	RuleVersions  map[string]int                     `json:"rule_versions,omitempty"`
+	Context       map[string]interface{}             `json:"context"`
+	Currency      string                             `json:"currency,omitempty"`
+	Value         *money.Money                       `json:"value,omitempty"`
+	Breakdown     map[string]calculation.RuleOutcome `json:"breakdown,omitempty"`
+	Errors        []calculation.RuleFailure          `json:"errors,omitempty"`
+	Stages        []calculation.StageResult          `json:"stages,omitempty"`
+	Lines         []calculation.LineResult           `json:"lines,omitempty"`
+	Error         string                             `json:"error,omitempty"`
+	CreatedAt     time.Time                          `json:"created_at"`
+	CompletedAt   *time.Time                         `json:"completed_at,omitempty"`
+	DurationMs    int64                              `json:"duration_ms"`
+}
+
+// CalculationListResponse is the DTO for a page of the calculation history.
+type CalculationListResponse struct {
+	Calculations []CalculationDetailResponse `json:"calculations"`
+	Limit        int                         `json:"limit"`
+	Offset       int                         `json:"offset"`
+}
+
+// QuoteResponse is the DTO for a quote.
+type QuoteResponse struct {
+	QuoteID       string `json:"quote_id"`
+	CalculationID string `json:"calculation_id"`
+	// Status is ACTIVE, CONFIRMED or EXPIRED.
+	Status string `json:"status"`
+	// CalculationStatus is COMPLETED, or PARTIAL when optional rules failed.
+	CalculationStatus string                             `json:"calculation_status"`
+	Value             money.Money                        `json:"value"`
+	Breakdown         map[string]calculation.RuleOutcome `json:"breakdown"`
+	Errors            []calculation.RuleFailure          `json:"errors,omitempty"`
+	Stages            []calculation.StageResult          `json:"stages,omitempty"`
+	Lines             []calculation.LineResult           `json:"lines,omitempty"`
+	RuleVersions      map[string]int                     `json:"rule_versions,omitempty"`
+	CreatedAt         time.Time                          `json:"created_at"`
+	ExpiresAt         time.Time                          `json:"expires_at"`
+	ConfirmedAt       *time.Time                         `json:"confirmed_at,omitempty"`
+	Signature         string                             `json:"signature"`
+}
+
+// QuoteExpiredResponse is the DTO for the confirmation of an expired quote.
+type QuoteExpiredResponse struct {
+	Error     string    `json:"error"`
+	QuoteID   string    `json:"quote_id"`
+	ExpiredAt time.Time `json:"expired_at"`
+	// Offer is a new quote for the same request at the current rules, which can be confirmed instead.
+	Offer QuoteResponse `json:"offer"`
+}
+
+// JobRequest is the DTO for submitting a calculation job, over REST or NATS.
+type JobRequest struct {
+	Reference string           `json:"reference,omitempty"`
+	Items     []JobItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// JobItemRequest is the DTO for a calculation of a job.
+type JobItemRequest struct {
+	// Reference identifies the item in its result, e.g. a basket ID.
+	Reference string             `json:"reference,omitempty"`
+	Request   CalculationRequest `json:"request" binding:"required"`
+}
+
+// JobResponse is the DTO for the status and progress of a calculation job.
+type JobResponse struct {
+	JobID     string `json:"job_id"`
+	Reference string `json:"reference,omitempty"`
+	// Status is PENDING until every item has a result, then COMPLETED, or FAILED when every item failed.
+	Status    string `json:"status"`
+	Total     int    `json:"total"`
+	Processed int    `json:"processed"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+	// Progress is the percentage of the items processed.
+	Progress    float64    `json:"progress"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// JobResultListResponse is the DTO for a page of the results of a calculation job.
+type JobResultListResponse struct {
+	Results []job.Result `json:"results"`
+	Limit   int          `json:"limit"`
+	Offset  int          `json:"offset"`
+}
+
+// CompareRequest is the DTO for calculating one request under several selections of rules.
+type CompareRequest struct {
+	Context       map[string]interface{}       `json:"context" binding:"required"`
+	Currency      string                       `json:"currency,omitempty"`
+	RuleVersions  map[string]int               `json:"rule_versions,omitempty"`
+	FailurePolicy string                       `json:"failure_policy,omitempty"`
+	Basket        *BasketRequest               `json:"basket,omitempty"`
+	Allocations   map[string]AllocationRequest `json:"allocations,omitempty"`
+	// Scenarios are compared with the first one, the baseline.
+	Scenarios []ScenarioRequest `json:"scenarios" binding:"required,min=2,dive"`
+}
+
+// ScenarioRequest is the DTO for a scenario of a comparison: a rule set, or rules as in a calculation
+// request.
+type ScenarioRequest struct {
+	Name            string           `json:"name,omitempty"`
+	RuleSetID       string           `json:"rule_set_id,omitempty"`
+	RuleIDs         []string         `json:"rule_ids,omitempty"`
+	Pipeline        *PipelineRequest `json:"pipeline,omitempty"`
+	ItemRuleIDs     []string         `json:"item_rule_ids,omitempty"`
+	OptionalRuleIDs []string         `json:"optional_rule_ids,omitempty"`
+	RequiredRuleIDs []string         `json:"required_rule_ids,omitempty"`
+}
+
+// CompareResponse is the DTO for a comparison.
+type CompareResponse struct {
+	Baseline  string             `json:"baseline"`
+	Scenarios []ScenarioResponse `json:"scenarios"`
+}
+
+// ScenarioResponse is the DTO for the calculation of a scenario and its difference from the baseline.
+type ScenarioResponse struct {
+	Name      string `json:"name"`
+	RuleSetID string `json:"rule_set_id,omitempty"`
+	// CalculationResponse is empty when the calculation failed; Error then says why.
+	*CalculationResponse
+	Error string `json:"error,omitempty"`
+	// Failures lists the failed rules when the calculation failed because required rules failed.
+	Failures []calculation.RuleFailure `json:"failures,omitempty"`
+	// Difference is the difference from the baseline, for the other scenarios that were calculated.
+	Difference *calculation.Difference `json:"difference,omitempty"`
 }
 
 // ErrorResponse is the DTO for an error response.
 type ErrorResponse struct {
 	Error string `json:"error"`
+	// Errors lists the failed rules when required rules failed.
+	Errors []calculation.RuleFailure `json:"errors,omitempty"`
 }
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/interfaces/rest/dto"
+)
+
+// CalculationHistoryHandler handles HTTP requests for stored calculations.
+type CalculationHistoryHandler struct {
+	getHandler  *application.GetCalculationHandler
+	listHandler *application.ListCalculationsHandler
+}
+
+// NewCalculationHistoryHandler creates a new CalculationHistoryHandler.
+func NewCalculationHistoryHandler(getHandler *application.GetCalculationHandler, listHandler *application.ListCalculationsHandler) *CalculationHistoryHandler {
+	return &CalculationHistoryHandler{
+		getHandler:  getHandler,
+		listHandler: listHandler,
+	}
+}
+
+// GetCalculation handles a request for one calculation.
+func (h *CalculationHistoryHandler) GetCalculation(c *gin.Context) {
+	calc, err := h.getHandler.Handle(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toCalculationDetailResponse(calc))
+}
+
+// ListCalculations handles a request for the calculation history, filtered by the customer_id, from and
+// to (RFC 3339) query parameters and paged with limit and offset.
+func (h *CalculationHistoryHandler) ListCalculations(c *gin.Context) {
+	query, err := parseListQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	page, err := h.listHandler.Handle(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(errorStatus(err), dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	resp := dto.CalculationListResponse{
+		Calculations: make([]dto.CalculationDetailResponse, len(page.Calculations)),
+		Limit:        page.Limit,
+		Offset:       page.Offset,
+	}
+	for i, calc := range page.Calculations {
+		resp.Calculations[i] = toCalculationDetailResponse(calc)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func parseListQuery(c *gin.Context) (application.ListCalculationsQuery, error) {
+	query := application.ListCalculationsQuery{CustomerID: c.Query("customer_id")}
+	var err error
+	if query.From, err = parseTimeParam(c, "from"); err != nil {
+		return query, err
+	}
+	if query.To, err = parseTimeParam(c, "to"); err != nil {
+		return query, err
+	}
+	if query.Limit, err = parseIntParam(c, "limit"); err != nil {
+		return query, err
+	}
+	if query.Offset, err = parseIntParam(c, "offset"); err != nil {
+		return query, err
+	}
+	return query, nil
+}
+
+func parseTimeParam(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, &shared.ValidationError{Field: name, Message: "must be an RFC 3339 timestamp"}
+	}
+	return &t, nil
+}
+
+func parseIntParam(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &shared.ValidationError{Field: name, Message: "must be an integer"}
+	}
+	return n, nil
+}
+
+func toCalculationDetailResponse(calc *calculation.Calculation) dto.CalculationDetailResponse {
+	resp := dto.CalculationDetailResponse{
+		CalculationID: calc.ID().String(),
+		Status:        string(calc.Status()),
+		CustomerID:    calc.CustomerID(),
+		RuleIDs:       calc.RuleIDs(),
+		RuleVersions:  calc.RuleVersions(),
+		Context:       calc.Context(),
+		Currency:      calc.Currency(),
+		Error:         calc.Failure(),
+		CreatedAt:     calc.CreatedAt(),
+		CompletedAt:   calc.CompletedAt(),
+		DurationMs:    calc.Duration().Milliseconds(),
+	}
+	if result := calc.Result(); result != nil {
+		resp.Value = &result.Value
+		resp.Breakdown = result.Breakdown
+		resp.Errors = result.Errors
+		resp.Stages = result.Stages
+		resp.Lines = result.Lines
+	}
+	return resp
+}
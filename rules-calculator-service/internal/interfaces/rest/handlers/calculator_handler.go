@@ -1,13 +1,30 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/basket"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/job"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/quote"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
 	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/interfaces/rest/dto"
 )
 
+const (
+	// IdempotencyKeyHeader carries the client's key for retrying a calculation safely.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses that replay the result of an earlier request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// CacheStatusHeader is HIT when the result was served from the result cache.
+	CacheStatusHeader = "X-Calculation-Cache"
+)
+
 // CalculatorHandler handles HTTP requests for the calculator service.
 type CalculatorHandler struct {
 	handler *application.CalculateRulesHandler
@@ -28,21 +45,142 @@ func (h *CalculatorHandler) Calculate(c *gin.Context) {
 		return
 	}
 
-	cmd := application.CalculateRulesCommand{
-		RuleIDs: req.RuleIDs,
-		Context: req.Context,
+	cmd, err := toCommand(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
 	}
+	cmd.IdempotencyKey = c.GetHeader(IdempotencyKeyHeader)
 
 	result, err := h.handler.Handle(c.Request.Context(), cmd)
 	if err != nil {
-		// This is a simplification. In a real app, we'd have more nuanced error handling.
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		c.JSON(errorStatus(err), errorResponse(err))
 		return
 	}
 
+	if result.Replayed {
+		c.Header(IdempotentReplayedHeader, "true")
+	}
+	if result.Cached {
+		c.Header(CacheStatusHeader, "HIT")
+	}
 	c.JSON(http.StatusOK, dto.CalculationResponse{
 		CalculationID: result.CalculationID,
+		Status:        string(result.Status),
 		Value:         result.Value,
 		Breakdown:     result.Breakdown,
+		Errors:        result.Errors,
+		Stages:        result.Stages,
+		Lines:         result.Lines,
 	})
 }
+
+// toCommand builds the calculation command of a request.
+func toCommand(req dto.CalculationRequest) (application.CalculateRulesCommand, error) {
+	cmd := application.CalculateRulesCommand{
+		RuleIDs:         req.RuleIDs,
+		Context:         req.Context,
+		Currency:        req.Currency,
+		RuleVersions:    req.RuleVersions,
+		FailurePolicy:   calculation.FailurePolicy(req.FailurePolicy),
+		OptionalRuleIDs: req.OptionalRuleIDs,
+		RequiredRuleIDs: req.RequiredRuleIDs,
+		Pipeline:        toPipeline(req.Pipeline),
+		Basket:          toBasket(req.Basket),
+		ItemRuleIDs:     req.ItemRuleIDs,
+		Allocations:     toAllocations(req.Allocations),
+	}
+	if cmd.RuleIDs == nil && cmd.Pipeline == nil {
+		return cmd, errors.New("rule_ids or pipeline is required")
+	}
+	return cmd, nil
+}
+
+func toPipeline(req *dto.PipelineRequest) *calculation.Pipeline {
+	if req == nil {
+		return nil
+	}
+	pipeline := &calculation.Pipeline{LoyaltyBase: calculation.LoyaltyBase(req.LoyaltyBase)}
+	for _, stage := range req.Stages {
+		pipeline.Stages = append(pipeline.Stages, calculation.Stage{
+			Category: calculation.StageCategory(strings.ToUpper(stage.Category)),
+			RuleIDs:  stage.RuleIDs,
+		})
+	}
+	return pipeline
+}
+
+func toBasket(req *dto.BasketRequest) *basket.Basket {
+	if req == nil {
+		return nil
+	}
+	b := &basket.Basket{Currency: req.Currency}
+	for _, item := range req.Items {
+		b.Items = append(b.Items, basket.Item{
+			LineID:    item.LineID,
+			SKU:       item.SKU,
+			Category:  item.Category,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		})
+	}
+	return b
+}
+
+func toAllocations(req map[string]dto.AllocationRequest) map[string]basket.Allocation {
+	if req == nil {
+		return nil
+	}
+	allocations := make(map[string]basket.Allocation, len(req))
+	for ruleID, allocation := range req {
+		allocations[ruleID] = basket.Allocation{
+			Method:     basket.AllocationMethod(strings.ToUpper(allocation.Method)),
+			SKUs:       allocation.SKUs,
+			Categories: allocation.Categories,
+		}
+	}
+	return allocations
+}
+
+// errorResponse builds the error body, listing the failed rules when required rules failed.
+func errorResponse(err error) dto.ErrorResponse {
+	resp := dto.ErrorResponse{Error: err.Error()}
+	var required *calculation.RequiredRuleError
+	if errors.As(err, &required) {
+		resp.Errors = required.Failures
+	}
+	return resp
+}
+
+// errorStatus maps calculation errors to HTTP status codes: invalid input is a 400 and values that
+// cannot be combined, e.g. mixed currencies without a target currency, are a 422. Retries with an
+// idempotency key get a 409 while the first request is running and a 422 if the key was used for a
+// different request or the first calculation failed. Failed required rules are a 422 as well, unless
+// they all failed because the evaluation service was unavailable (503) or timed out (504). A quote
+// that does not match its signature is a 500, like any other error: the client cannot fix it.
+func errorStatus(err error) int {
+	var validationErr *shared.ValidationError
+	var mismatch *money.CurrencyMismatchError
+	var unknownRate *money.UnknownRateError
+	var failed *calculation.FailedError
+	var required *calculation.RequiredRuleError
+	switch {
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest
+	case errors.Is(err, calculation.ErrNotFound), errors.Is(err, quote.ErrNotFound), errors.Is(err, job.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, application.ErrQueueFull):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, calculation.ErrInProgress):
+		return http.StatusConflict
+	case errors.As(err, &required) && required.TimedOut():
+		return http.StatusGatewayTimeout
+	case errors.As(err, &required) && required.Unavailable():
+		return http.StatusServiceUnavailable
+	case errors.As(err, &mismatch), errors.As(err, &unknownRate),
+		errors.Is(err, calculation.ErrIdempotencyKeyMismatch), errors.As(err, &failed),
+		errors.As(err, &required):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/interfaces/rest/dto"
+)
+
+// CompareHandler handles HTTP requests for what-if comparisons of rule selections.
+type CompareHandler struct {
+	handler *application.CompareHandler
+}
+
+// NewCompareHandler creates a new CompareHandler.
+func NewCompareHandler(handler *application.CompareHandler) *CompareHandler {
+	return &CompareHandler{handler: handler}
+}
+
+// Compare handles a request to calculate a basket under several scenarios side by side. Scenarios
+// that fail are reported in the response, which is 200 as long as the request was valid.
+func (h *CompareHandler) Compare(c *gin.Context) {
+	var req dto.CompareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	cmd := application.CompareCommand{
+		Request: application.CalculateRulesCommand{
+			Context:       req.Context,
+			Currency:      req.Currency,
+			RuleVersions:  req.RuleVersions,
+			FailurePolicy: calculation.FailurePolicy(req.FailurePolicy),
+			Basket:        toBasket(req.Basket),
+			Allocations:   toAllocations(req.Allocations),
+		},
+	}
+	for _, scenario := range req.Scenarios {
+		cmd.Scenarios = append(cmd.Scenarios, application.Scenario{
+			Name:      scenario.Name,
+			RuleSetID: scenario.RuleSetID,
+			RuleSet: calculation.RuleSet{
+				RuleIDs:         scenario.RuleIDs,
+				Pipeline:        toPipeline(scenario.Pipeline),
+				ItemRuleIDs:     scenario.ItemRuleIDs,
+				OptionalRuleIDs: scenario.OptionalRuleIDs,
+				RequiredRuleIDs: scenario.RequiredRuleIDs,
+			},
+		})
+	}
+
+	result, err := h.handler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		c.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	resp := dto.CompareResponse{Baseline: result.Baseline}
+	for _, scenario := range result.Scenarios {
+		scenarioResp := dto.ScenarioResponse{
+			Name:       scenario.Name,
+			RuleSetID:  scenario.RuleSetID,
+			Error:      scenario.Error,
+			Difference: scenario.Difference,
+			Failures:   scenario.Errors,
+		}
+		if r := scenario.Result; r != nil {
+			scenarioResp.CalculationResponse = &dto.CalculationResponse{
+				CalculationID: r.CalculationID,
+				Status:        string(r.Status),
+				Value:         r.Value,
+				Breakdown:     r.Breakdown,
+				Errors:        r.Errors,
+				Stages:        r.Stages,
+				Lines:         r.Lines,
+			}
+		}
+		resp.Scenarios = append(resp.Scenarios, scenarioResp)
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/job"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/interfaces/rest/dto"
+)
+
+// JobHandler handles requests for calculation jobs, over HTTP and NATS.
+type JobHandler struct {
+	runner *application.JobRunner
+}
+
+// NewJobHandler creates a new JobHandler.
+func NewJobHandler(runner *application.JobRunner) *JobHandler {
+	return &JobHandler{runner: runner}
+}
+
+// SubmitJob handles a request to calculate a job in the background. It is answered with 202 Accepted
+// as soon as the job is queued.
+func (h *JobHandler) SubmitJob(c *gin.Context) {
+	var req dto.JobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	j, err := h.submit(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	c.Header("Location", "/v1/jobs/"+j.ID().String())
+	c.JSON(http.StatusAccepted, toJobResponse(j))
+}
+
+// SubmitMessage submits a job from a NATS message, which has the body of a REST submission, and
+// returns the job's ID.
+func (h *JobHandler) SubmitMessage(ctx context.Context, data []byte) (string, error) {
+	var req dto.JobRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return "", &shared.ValidationError{Field: "body", Message: err.Error()}
+	}
+	j, err := h.submit(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return j.ID().String(), nil
+}
+
+// GetJob handles a request for the status and progress of a job.
+func (h *JobHandler) GetJob(c *gin.Context) {
+	j, err := h.runner.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, toJobResponse(j))
+}
+
+// ListJobResults handles a request for a page of the results of a job, in item order.
+func (h *JobHandler) ListJobResults(c *gin.Context) {
+	limit, err := parseIntParam(c, "limit")
+	if err != nil {
+		c.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	offset, err := parseIntParam(c, "offset")
+	if err != nil {
+		c.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	page, err := h.runner.Results(c.Request.Context(), c.Param("id"), limit, offset)
+	if err != nil {
+		c.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, dto.JobResultListResponse{
+		Results: page.Results,
+		Limit:   page.Limit,
+		Offset:  page.Offset,
+	})
+}
+
+func (h *JobHandler) submit(ctx context.Context, req dto.JobRequest) (*job.Job, error) {
+	cmd := application.SubmitJobCommand{Reference: req.Reference}
+	for i, item := range req.Items {
+		itemCmd, err := toCommand(item.Request)
+		if err != nil {
+			return nil, &shared.ValidationError{Field: fmt.Sprintf("items[%d].request", i), Message: err.Error()}
+		}
+		cmd.Items = append(cmd.Items, application.JobItem{Reference: item.Reference, Command: itemCmd})
+	}
+	return h.runner.Submit(ctx, cmd)
+}
+
+func toJobResponse(j *job.Job) dto.JobResponse {
+	return dto.JobResponse{
+		JobID:       j.ID().String(),
+		Reference:   j.Reference(),
+		Status:      string(j.Status()),
+		Total:       j.Total(),
+		Processed:   j.Processed(),
+		Succeeded:   j.Succeeded(),
+		Failed:      j.Failed(),
+		Progress:    math.Round(j.Progress()*10000) / 100,
+		CreatedAt:   j.CreatedAt(),
+		StartedAt:   j.StartedAt(),
+		CompletedAt: j.CompletedAt(),
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/quote"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/interfaces/rest/dto"
+)
+
+// QuoteHandler handles HTTP requests for quotes.
+type QuoteHandler struct {
+	handler *application.QuoteHandler
+}
+
+// NewQuoteHandler creates a new QuoteHandler.
+func NewQuoteHandler(handler *application.QuoteHandler) *QuoteHandler {
+	return &QuoteHandler{handler: handler}
+}
+
+// CreateQuote handles a request to calculate a quote. It takes the same body as a calculation.
+func (h *QuoteHandler) CreateQuote(c *gin.Context) {
+	var req dto.CalculationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+	cmd, err := toCommand(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	q, err := h.handler.Create(c.Request.Context(), cmd)
+	if err != nil {
+		c.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, toQuoteResponse(q, quote.StatusActive))
+}
+
+// ConfirmQuote handles a request to confirm a quote. An expired quote is answered with 410 Gone and a
+// new quote for the same request.
+func (h *QuoteHandler) ConfirmQuote(c *gin.Context) {
+	result, err := h.handler.Confirm(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), errorResponse(err))
+		return
+	}
+
+	if result.Expired() {
+		c.JSON(http.StatusGone, dto.QuoteExpiredResponse{
+			Error:     quote.ErrExpired.Error(),
+			QuoteID:   result.Quote.ID().String(),
+			ExpiredAt: result.Quote.ExpiresAt(),
+			Offer:     toQuoteResponse(result.Offer, quote.StatusActive),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, toQuoteResponse(result.Quote, quote.StatusConfirmed))
+}
+
+func toQuoteResponse(q *quote.Quote, status quote.Status) dto.QuoteResponse {
+	result := q.Result()
+	calculationStatus := calculation.StatusCompleted
+	if len(result.Errors) > 0 {
+		calculationStatus = calculation.StatusPartial
+	}
+	return dto.QuoteResponse{
+		QuoteID:           q.ID().String(),
+		CalculationID:     q.CalculationID(),
+		Status:            string(status),
+		CalculationStatus: string(calculationStatus),
+		Value:             result.Value,
+		Breakdown:         result.Breakdown,
+		Errors:            result.Errors,
+		Stages:            result.Stages,
+		Lines:             result.Lines,
+		RuleVersions:      q.RuleVersions(),
+		CreatedAt:         q.CreatedAt(),
+		ExpiresAt:         q.ExpiresAt(),
+		ConfirmedAt:       q.ConfirmedAt(),
+		Signature:         q.Signature(),
+	}
+}
@@ -0,0 +1,211 @@
+package contract_test
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/adapters"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pactFile is shared with the provider verification of the evaluation service.
+const pactFile = "../../../contracts/rules-calculator-service-rules-evaluation-service.json"
+
+var update = flag.Bool("update", false, "rewrite the pact file from the interactions of this test")
+
+type pact struct {
+	Consumer     participant   `json:"consumer"`
+	Provider     participant   `json:"provider"`
+	Interactions []interaction `json:"interactions"`
+	Metadata     metadata      `json:"metadata"`
+}
+
+type participant struct {
+	Name string `json:"name"`
+}
+
+type metadata struct {
+	PactSpecification struct {
+		Version string `json:"version"`
+	} `json:"pactSpecification"`
+}
+
+type interaction struct {
+	Description   string       `json:"description"`
+	ProviderState string       `json:"providerState,omitempty"`
+	Request       pactRequest  `json:"request"`
+	Response      pactResponse `json:"response"`
+}
+
+type pactRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+type pactResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// consumerCase is an interaction together with what the calculator does to produce its request and
+// what it makes of the response.
+type consumerCase struct {
+	interaction interaction
+	rule        string
+	definition  rules.Definition
+	context     map[string]interface{}
+	wantValue   string
+	wantCode    calculation.ErrorCode
+}
+
+var jsonHeaders = map[string]string{"Content-Type": "application/json"}
+
+func consumerCases() []consumerCase {
+	return []consumerCase{
+		{
+			interaction: interaction{
+				Description: "a request to evaluate a taxes rule on a Spanish order",
+				Request: pactRequest{
+					Method: http.MethodPost, Path: "/v1/evaluate", Headers: jsonHeaders,
+					Body: json.RawMessage(`{
+						"rule_category": "TAXES",
+						"dsl_content": "IF jurisdiction.country = 'ES' THEN tax.percentage = 21",
+						"context": {"currency": "EUR", "jurisdiction": {"country": "ES"}, "order": {"amount": 100}},
+						"rule_id": "vat-es",
+						"rule_version": 3
+					}`),
+				},
+				Response: pactResponse{
+					Status: http.StatusOK, Headers: jsonHeaders,
+					Body: json.RawMessage(`{
+						"result": {
+							"eligible": true,
+							"currency": "EUR",
+							"total_tax": 21,
+							"amounts": {"total_tax": {"amount": "21", "currency": "EUR"}}
+						}
+					}`),
+				},
+			},
+			rule: "vat-es",
+			definition: rules.Definition{
+				Category:   "TAXES",
+				DSLContent: "IF jurisdiction.country = 'ES' THEN tax.percentage = 21",
+				Version:    3,
+				ValueKey:   "total_tax",
+			},
+			context: map[string]interface{}{
+				"currency":     "EUR",
+				"jurisdiction": map[string]interface{}{"country": "ES"},
+				"order":        map[string]interface{}{"amount": 100},
+			},
+			wantValue: "21 EUR",
+		},
+		{
+			interaction: interaction{
+				Description: "a request to evaluate a rule of a category the service has no strategy for",
+				Request: pactRequest{
+					Method: http.MethodPost, Path: "/v1/evaluate", Headers: jsonHeaders,
+					Body: json.RawMessage(`{
+						"rule_category": "SHIPPING",
+						"dsl_content": "IF order.amount > 50 THEN shipping.free = true",
+						"context": {"order": {"amount": 80}},
+						"rule_id": "free-shipping"
+					}`),
+				},
+				Response: pactResponse{
+					Status: http.StatusBadRequest, Headers: jsonHeaders,
+					Body: json.RawMessage(`{"error": "no evaluation strategy found for category: SHIPPING"}`),
+				},
+			},
+			rule: "free-shipping",
+			definition: rules.Definition{
+				Category:   "SHIPPING",
+				DSLContent: "IF order.amount > 50 THEN shipping.free = true",
+			},
+			context:  map[string]interface{}{"order": map[string]interface{}{"amount": 80}},
+			wantCode: calculation.ErrorInvalidRule,
+		},
+	}
+}
+
+// TestEvaluationContract_Consumer checks that the calculator sends the requests of the pact and
+// understands its responses, and that the pact file is up to date. Run with -update after changing
+// an interaction.
+func TestEvaluationContract_Consumer(t *testing.T) {
+	cases := consumerCases()
+	definitions := map[string]rules.Definition{}
+	for _, c := range cases {
+		definitions[c.rule] = c.definition
+	}
+	catalogData, err := json.Marshal(map[string]interface{}{"rules": definitions})
+	require.NoError(t, err)
+	catalog, err := rules.ParseStaticCatalog(catalogData)
+	require.NoError(t, err)
+
+	for _, c := range cases {
+		t.Run(c.interaction.Description, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, c.interaction.Request.Method, r.Method)
+				assert.Equal(t, c.interaction.Request.Path, r.URL.Path)
+				for name, value := range c.interaction.Request.Headers {
+					assert.Equal(t, value, r.Header.Get(name))
+				}
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, string(c.interaction.Request.Body), string(body))
+
+				for name, value := range c.interaction.Response.Headers {
+					w.Header().Set(name, value)
+				}
+				w.WriteHeader(c.interaction.Response.Status)
+				w.Write(c.interaction.Response.Body)
+			}))
+			defer server.Close()
+			adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog, adapters.ResilienceOptions{FailureThreshold: 5})
+
+			value, err := adapter.Evaluate(context.Background(), c.rule, c.context)
+
+			if c.wantCode != "" {
+				var ruleErr *calculation.RuleError
+				require.ErrorAs(t, err, &ruleErr)
+				assert.Equal(t, c.wantCode, ruleErr.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.wantValue, value.String())
+		})
+	}
+
+	p := pact{
+		Consumer: participant{Name: "rules-calculator-service"},
+		Provider: participant{Name: "rules-evaluation-service"},
+	}
+	p.Metadata.PactSpecification.Version = "2.0.0"
+	for _, c := range cases {
+		p.Interactions = append(p.Interactions, c.interaction)
+	}
+	generated, err := json.MarshalIndent(p, "", "  ")
+	require.NoError(t, err)
+	generated = append(generated, '\n')
+
+	if *update {
+		require.NoError(t, os.WriteFile(pactFile, generated, 0o644))
+		return
+	}
+	stored, err := os.ReadFile(pactFile)
+	require.NoError(t, err, "the pact file is missing; run the test with -update")
+	assert.JSONEq(t, string(generated), string(stored), "the pact file is out of date; run the test with -update")
+}
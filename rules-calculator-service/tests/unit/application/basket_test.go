@@ -0,0 +1,174 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/basket"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/memory"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// basketCommand is a checkout of two shirts at 20 EUR and a mug at 60 EUR, with 10% off the order and
+// 21% VAT.
+func basketCommand() application.CalculateRulesCommand {
+	return application.CalculateRulesCommand{
+		Context: map[string]interface{}{"customer": map[string]interface{}{"tier": "GOLD"}},
+		Pipeline: &calculation.Pipeline{Stages: []calculation.Stage{
+			{Category: calculation.StageDiscounts, RuleIDs: []string{"ten_percent_off"}},
+			{Category: calculation.StageTaxes, RuleIDs: []string{"vat"}},
+		}},
+		Basket: &basket.Basket{Currency: "EUR", Items: []basket.Item{
+			{LineID: "shirt", SKU: "SHIRT-1", Category: "apparel", Quantity: 2, UnitPrice: decimal.RequireFromString("20")},
+			{LineID: "mug", SKU: "MUG-1", Category: "home", Quantity: 1, UnitPrice: decimal.RequireFromString("60")},
+		}},
+	}
+}
+
+func linesByID(lines []calculation.LineResult) map[string]calculation.LineResult {
+	byID := make(map[string]calculation.LineResult, len(lines))
+	for _, line := range lines {
+		byID[line.LineID] = line
+	}
+	return byID
+}
+
+func TestCalculateRulesHandler_Basket(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("basket-level discounts and taxes are allocated pro rata", func(t *testing.T) {
+		evaluator := newCheckoutEvaluator()
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+
+		result, err := handler.Handle(ctx, basketCommand())
+
+		require.NoError(t, err)
+		// 100 - 10 = 90, + 21% VAT = 108.90.
+		assert.Equal(t, "108.9 EUR", result.Value.String())
+		lines := linesByID(result.Lines)
+		require.Len(t, lines, 2)
+		assert.Equal(t, "4 EUR", lines["shirt"].Discount.String())
+		assert.Equal(t, "18 EUR", lines["shirt"].AdjustedUnitPrice.String())
+		assert.Equal(t, "7.56 EUR", lines["shirt"].Tax.String())
+		assert.Equal(t, "43.56 EUR", lines["shirt"].Gross.String())
+		assert.Equal(t, "6 EUR", lines["mug"].Discount.String())
+		assert.Equal(t, "11.34 EUR", lines["mug"].Tax.String())
+		require.Len(t, lines["mug"].Adjustments, 2)
+		assert.Equal(t, "ten_percent_off", lines["mug"].Adjustments[0].RuleID)
+		assert.Equal(t, calculation.StageDiscounts, lines["mug"].Adjustments[0].Stage)
+
+		// Rules see the basket as order.amount and items, with the discounts of earlier stages.
+		order := evaluator.contexts["ten_percent_off"]["order"].(map[string]interface{})
+		assert.Equal(t, 100.0, order["amount"])
+		assert.Equal(t, "EUR", order["currency"])
+		items := evaluator.contexts["vat"]["items"].([]interface{})
+		assert.Equal(t, 18.0, items[0].(map[string]interface{})["price"])
+	})
+
+	t.Run("targeted allocations only reach the matching lines", func(t *testing.T) {
+		evaluator := newCheckoutEvaluator()
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		cmd := basketCommand()
+		cmd.Pipeline.Stages[0].RuleIDs = []string{"home_coupon"} // a flat 5 off
+		cmd.Allocations = map[string]basket.Allocation{
+			"home_coupon": {Method: basket.AllocationTargeted, Categories: []string{"home"}},
+		}
+
+		result, err := handler.Handle(ctx, cmd)
+
+		require.NoError(t, err)
+		lines := linesByID(result.Lines)
+		assert.True(t, lines["shirt"].Discount.Amount.IsZero())
+		assert.Equal(t, "5 EUR", lines["mug"].Discount.String())
+		assert.Equal(t, "55 EUR", lines["mug"].Net.String())
+	})
+
+	t.Run("discounts exceeding the targeted lines are reported at the amount applied", func(t *testing.T) {
+		evaluator := newCheckoutEvaluator()
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		cmd := basketCommand()
+		cmd.Basket.Items[1].UnitPrice = decimal.RequireFromString("3")
+		cmd.Pipeline.Stages[0].RuleIDs = []string{"home_coupon"} // a flat 5 off
+		cmd.Allocations = map[string]basket.Allocation{
+			"home_coupon": {Method: basket.AllocationTargeted, Categories: []string{"home"}},
+		}
+
+		result, err := handler.Handle(ctx, cmd)
+
+		require.NoError(t, err)
+		assert.Equal(t, "3 EUR", linesByID(result.Lines)["mug"].Discount.String())
+		assert.Equal(t, "3 EUR", result.Breakdown["home_coupon"].Money.String())
+		discounts := result.Stages[0]
+		assert.Equal(t, "3 EUR", discounts.Total.String())
+		assert.Equal(t, "3 EUR", discounts.Rules[0].Money.String())
+		assert.Equal(t, "40 EUR", discounts.Amount.String())
+	})
+
+	t.Run("item rules are evaluated for each line", func(t *testing.T) {
+		evaluator := newCheckoutEvaluator()
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		cmd := basketCommand()
+		cmd.ItemRuleIDs = []string{"ten_percent_off", "vat"}
+
+		result, err := handler.Handle(ctx, cmd)
+
+		require.NoError(t, err)
+		assert.Equal(t, "108.9 EUR", result.Value.String())
+		assert.Equal(t, "10 EUR", result.Breakdown["ten_percent_off"].Money.String())
+		lines := linesByID(result.Lines)
+		assert.Equal(t, "4 EUR", lines["shirt"].Discount.String())
+		assert.Equal(t, "11.34 EUR", lines["mug"].Tax.String())
+	})
+
+	t.Run("item rule failures name the line", func(t *testing.T) {
+		evaluator := newCheckoutEvaluator()
+		evaluator.failing["ten_percent_off"] = true
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		cmd := basketCommand()
+		cmd.ItemRuleIDs = []string{"ten_percent_off"}
+		cmd.OptionalRuleIDs = []string{"ten_percent_off"}
+
+		result, err := handler.Handle(ctx, cmd)
+
+		require.NoError(t, err)
+		assert.Equal(t, calculation.StatusPartial, result.Status)
+		require.Len(t, result.Errors, 2)
+		assert.ElementsMatch(t, []string{"shirt", "mug"}, []string{result.Errors[0].LineID, result.Errors[1].LineID})
+		assert.Equal(t, "121 EUR", result.Value.String())
+	})
+
+	t.Run("invalid baskets are rejected", func(t *testing.T) {
+		handler := application.NewCalculateRulesHandler(newCheckoutEvaluator(), nil, memory.NewCalculationRepository())
+		tests := map[string]func(cmd *application.CalculateRulesCommand){
+			"pipeline": func(cmd *application.CalculateRulesCommand) {
+				cmd.Pipeline = nil
+				cmd.RuleIDs = []string{"vat"}
+			},
+			"basket": func(cmd *application.CalculateRulesCommand) { cmd.Basket.Items[1].Quantity = 0 },
+			"item_rule_ids": func(cmd *application.CalculateRulesCommand) {
+				cmd.Pipeline.Stages = append(cmd.Pipeline.Stages, calculation.Stage{Category: calculation.StageFees, RuleIDs: []string{"shipping"}})
+				cmd.ItemRuleIDs = []string{"shipping"}
+			},
+			"allocations.ten_percent_off": func(cmd *application.CalculateRulesCommand) {
+				cmd.Allocations = map[string]basket.Allocation{
+					"ten_percent_off": {Method: basket.AllocationTargeted, SKUs: []string{"UNKNOWN"}},
+				}
+			},
+		}
+		for field, change := range tests {
+			cmd := basketCommand()
+			change(&cmd)
+
+			_, err := handler.Handle(ctx, cmd)
+
+			var validationErr *shared.ValidationError
+			require.ErrorAs(t, err, &validationErr, field)
+			assert.Equal(t, field, validationErr.Field)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/cache"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateRulesHandler_Cache(t *testing.T) {
+	ctx := context.Background()
+	command := func() application.CalculateRulesCommand {
+		return application.CalculateRulesCommand{
+			RuleIDs:      []string{"rule1", "rule2"},
+			Context:      map[string]interface{}{"order": map[string]interface{}{"amount": 100.0}},
+			RuleVersions: map[string]int{"rule1": 3, "rule2": 1},
+		}
+	}
+	newHandler := func() (*application.CalculateRulesHandler, *MockRuleEvaluator, *cache.MemoryCache) {
+		evaluator := new(MockRuleEvaluator)
+		evaluator.On("Evaluate", mock.Anything, "rule1", mock.Anything).Return(amount("10", "EUR"), nil)
+		evaluator.On("Evaluate", mock.Anything, "rule2", mock.Anything).Return(amount("5", "EUR"), nil)
+		resultCache := cache.NewMemoryCache(100, 0)
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		handler.SetCache(resultCache)
+		return handler, evaluator, resultCache
+	}
+
+	t.Run("identical requests are served from the cache", func(t *testing.T) {
+		handler, evaluator, _ := newHandler()
+
+		first, err := handler.Handle(ctx, command())
+		require.NoError(t, err)
+		second, err := handler.Handle(ctx, command())
+		require.NoError(t, err)
+
+		assert.False(t, first.Cached)
+		assert.True(t, second.Cached)
+		assert.Equal(t, "15 EUR", second.Value.String())
+		assert.NotEqual(t, first.CalculationID, second.CalculationID, "cached results are still recorded")
+		evaluator.AssertNumberOfCalls(t, "Evaluate", 2)
+	})
+
+	t.Run("a different context or rule version misses", func(t *testing.T) {
+		handler, evaluator, _ := newHandler()
+		_, err := handler.Handle(ctx, command())
+		require.NoError(t, err)
+
+		other := command()
+		other.Context = map[string]interface{}{"order": map[string]interface{}{"amount": 120.0}}
+		result, err := handler.Handle(ctx, other)
+		require.NoError(t, err)
+		assert.False(t, result.Cached)
+
+		upgraded := command()
+		upgraded.RuleVersions = map[string]int{"rule1": 4, "rule2": 1}
+		result, err = handler.Handle(ctx, upgraded)
+		require.NoError(t, err)
+		assert.False(t, result.Cached)
+		evaluator.AssertNumberOfCalls(t, "Evaluate", 6)
+	})
+
+	t.Run("a rule change invalidates the results that depend on it", func(t *testing.T) {
+		handler, evaluator, resultCache := newHandler()
+		_, err := handler.Handle(ctx, command())
+		require.NoError(t, err)
+
+		require.NoError(t, resultCache.InvalidateRules(ctx, "rule2"))
+		result, err := handler.Handle(ctx, command())
+
+		require.NoError(t, err)
+		assert.False(t, result.Cached)
+		evaluator.AssertNumberOfCalls(t, "Evaluate", 4)
+	})
+
+	t.Run("results with failed rules are not cached", func(t *testing.T) {
+		evaluator := new(MockRuleEvaluator)
+		evaluator.On("Evaluate", mock.Anything, "rule1", mock.Anything).Return(amount("10", "EUR"), nil)
+		evaluator.On("Evaluate", mock.Anything, "rule2", mock.Anything).Return(amount("0", ""), errors.New("boom"))
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		resultCache := cache.NewMemoryCache(100, 0)
+		handler.SetCache(resultCache)
+		cmd := command()
+		cmd.OptionalRuleIDs = []string{"rule2"}
+
+		_, err := handler.Handle(ctx, cmd)
+
+		require.NoError(t, err)
+		assert.Zero(t, resultCache.Len())
+	})
+}
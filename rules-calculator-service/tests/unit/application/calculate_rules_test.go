@@ -6,8 +6,15 @@ import (
 	"testing"
 
 	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/memory"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/rates"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockRuleEvaluator is a mock implementation of the RuleEvaluator interface.
@@ -15,15 +22,25 @@ type MockRuleEvaluator struct {
 	mock.Mock
 }
 
-func (m *MockRuleEvaluator) Evaluate(ctx context.Context, ruleID string, context map[string]interface{}) (float64, error) {
+func (m *MockRuleEvaluator) Evaluate(ctx context.Context, ruleID string, context map[string]interface{}) (money.Money, error) {
 	args := m.Called(ctx, ruleID, context)
-	return args.Get(0).(float64), args.Error(1)
+	return args.Get(0).(money.Money), args.Error(1)
+}
+
+func amount(value, currency string) money.Money {
+	return money.Money{Amount: decimal.RequireFromString(value), Currency: currency}
+}
+
+func newConverter(t *testing.T) *money.Converter {
+	provider, err := rates.ParseStaticProvider([]byte(`{"base": "EUR", "rates": {"USD": "1.25"}}`))
+	require.NoError(t, err)
+	return money.NewConverter(provider)
 }
 
 func TestCalculateRulesHandler_Handle_Success(t *testing.T) {
 	// Arrange
 	mockEvaluator := new(MockRuleEvaluator)
-	handler := application.NewCalculateRulesHandler(mockEvaluator)
+	handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
 
 	cmd := application.CalculateRulesCommand{
 		RuleIDs: []string{"rule1", "rule2"},
@@ -31,8 +48,8 @@ func TestCalculateRulesHandler_Handle_Success(t *testing.T) {
 	}
 
 	// Setup mock expectations
-	mockEvaluator.On("Evaluate", mock.Anything, "rule1", cmd.Context).Return(100.0, nil)
-	mockEvaluator.On("Evaluate", mock.Anything, "rule2", cmd.Context).Return(50.5, nil)
+	mockEvaluator.On("Evaluate", mock.Anything, "rule1", cmd.Context).Return(amount("100", "EUR"), nil)
+	mockEvaluator.On("Evaluate", mock.Anything, "rule2", cmd.Context).Return(amount("50.5", "EUR"), nil)
 
 	// Act
 	result, err := handler.Handle(context.Background(), cmd)
@@ -41,9 +58,11 @@ func TestCalculateRulesHandler_Handle_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.NotEmpty(t, result.CalculationID)
-	assert.Equal(t, 150.5, result.Value)
-	assert.Equal(t, 100.0, result.Breakdown["rule1"])
-	assert.Equal(t, 50.5, result.Breakdown["rule2"])
+	assert.Equal(t, "150.5 EUR", result.Value.String())
+	assert.Equal(t, calculation.StatusCompleted, result.Status)
+	assert.Equal(t, amount("100", "EUR"), result.Breakdown["rule1"].Money)
+	assert.Equal(t, amount("50.5", "EUR"), result.Breakdown["rule2"].Money)
+	assert.Empty(t, result.Errors)
 
 	mockEvaluator.AssertExpectations(t)
 }
@@ -51,7 +70,7 @@ func TestCalculateRulesHandler_Handle_Success(t *testing.T) {
 func TestCalculateRulesHandler_Handle_WithFailingRule(t *testing.T) {
 	// Arrange
 	mockEvaluator := new(MockRuleEvaluator)
-	handler := application.NewCalculateRulesHandler(mockEvaluator)
+	handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
 
 	cmd := application.CalculateRulesCommand{
 		RuleIDs: []string{"rule1", "failing_rule", "rule2"},
@@ -59,9 +78,9 @@ func TestCalculateRulesHandler_Handle_WithFailingRule(t *testing.T) {
 	}
 
 	// Setup mock expectations
-	mockEvaluator.On("Evaluate", mock.Anything, "rule1", cmd.Context).Return(100.0, nil)
-	mockEvaluator.On("Evaluate", mock.Anything, "failing_rule", cmd.Context).Return(0.0, errors.New("evaluation failed"))
-	mockEvaluator.On("Evaluate", mock.Anything, "rule2", cmd.Context).Return(50.5, nil)
+	mockEvaluator.On("Evaluate", mock.Anything, "rule1", cmd.Context).Return(amount("100", "EUR"), nil)
+	mockEvaluator.On("Evaluate", mock.Anything, "failing_rule", cmd.Context).Return(money.Money{}, errors.New("evaluation failed"))
+	mockEvaluator.On("Evaluate", mock.Anything, "rule2", cmd.Context).Return(amount("50.5", "EUR"), nil)
 
 	// Act
 	result, err := handler.Handle(context.Background(), cmd)
@@ -69,12 +88,16 @@ func TestCalculateRulesHandler_Handle_WithFailingRule(t *testing.T) {
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, 150.5, result.Value) // failing_rule is skipped
-	assert.Len(t, result.Breakdown, 2)
-	assert.Equal(t, 100.0, result.Breakdown["rule1"])
-	assert.Equal(t, 50.5, result.Breakdown["rule2"])
-	_, exists := result.Breakdown["failing_rule"]
-	assert.False(t, exists)
+	assert.Equal(t, "150.5 EUR", result.Value.String()) // failing_rule is left out of the total
+	assert.Equal(t, calculation.StatusPartial, result.Status)
+	require.Len(t, result.Breakdown, 3)
+	failed := result.Breakdown["failing_rule"]
+	assert.Equal(t, calculation.RuleStatusFailed, failed.Status)
+	require.NotNil(t, failed.Error)
+	assert.Equal(t, calculation.ErrorEvaluationFailed, failed.Error.Code)
+	assert.Equal(t, []calculation.RuleFailure{{
+		RuleID: "failing_rule", Optional: true, Code: calculation.ErrorEvaluationFailed, Message: "evaluation failed",
+	}}, result.Errors)
 
 	mockEvaluator.AssertExpectations(t)
 }
@@ -82,7 +105,7 @@ func TestCalculateRulesHandler_Handle_WithFailingRule(t *testing.T) {
 func TestCalculateRulesHandler_Handle_NoRules(t *testing.T) {
 	// Arrange
 	mockEvaluator := new(MockRuleEvaluator)
-	handler := application.NewCalculateRulesHandler(mockEvaluator)
+	handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
 
 	cmd := application.CalculateRulesCommand{
 		RuleIDs: []string{},
@@ -95,8 +118,77 @@ func TestCalculateRulesHandler_Handle_NoRules(t *testing.T) {
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, 0.0, result.Value)
+	assert.True(t, result.Value.Amount.IsZero())
 	assert.Empty(t, result.Breakdown)
 
 	mockEvaluator.AssertNotCalled(t, "Evaluate")
 }
+
+func TestCalculateRulesHandler_Handle_Currencies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("values without a currency are in the currency of the context", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
+		cmd := application.CalculateRulesCommand{
+			RuleIDs: []string{"rule1", "rule2"},
+			Context: map[string]interface{}{"order": map[string]interface{}{"currency": "usd"}},
+		}
+		mockEvaluator.On("Evaluate", mock.Anything, "rule1", cmd.Context).Return(amount("0.1", ""), nil)
+		mockEvaluator.On("Evaluate", mock.Anything, "rule2", cmd.Context).Return(amount("0.2", "USD"), nil)
+
+		result, err := handler.Handle(ctx, cmd)
+
+		require.NoError(t, err)
+		assert.Equal(t, "0.3 USD", result.Value.String(), "decimal amounts do not accumulate float errors")
+	})
+
+	t.Run("mixed currencies are rejected without a target currency", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		handler := application.NewCalculateRulesHandler(mockEvaluator, newConverter(t), memory.NewCalculationRepository())
+		cmd := application.CalculateRulesCommand{RuleIDs: []string{"eur", "usd"}, Context: map[string]interface{}{}}
+		mockEvaluator.On("Evaluate", mock.Anything, "eur", cmd.Context).Return(amount("10", "EUR"), nil)
+		mockEvaluator.On("Evaluate", mock.Anything, "usd", cmd.Context).Return(amount("5", "USD"), nil)
+
+		_, err := handler.Handle(ctx, cmd)
+
+		var mismatch *money.CurrencyMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, []string{"EUR", "USD"}, mismatch.Currencies)
+	})
+
+	t.Run("mixed currencies are converted to the target currency", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		handler := application.NewCalculateRulesHandler(mockEvaluator, newConverter(t), memory.NewCalculationRepository())
+		cmd := application.CalculateRulesCommand{RuleIDs: []string{"eur", "usd"}, Context: map[string]interface{}{}, Currency: "usd"}
+		mockEvaluator.On("Evaluate", mock.Anything, "eur", cmd.Context).Return(amount("10", "EUR"), nil)
+		mockEvaluator.On("Evaluate", mock.Anything, "usd", cmd.Context).Return(amount("5", "USD"), nil)
+
+		result, err := handler.Handle(ctx, cmd)
+
+		require.NoError(t, err)
+		assert.Equal(t, "17.5 USD", result.Value.String())
+		assert.Equal(t, amount("10", "EUR"), result.Breakdown["eur"].Money, "the breakdown keeps the currency of each rule")
+	})
+
+	t.Run("conversion fails without a known rate", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		handler := application.NewCalculateRulesHandler(mockEvaluator, newConverter(t), memory.NewCalculationRepository())
+		cmd := application.CalculateRulesCommand{RuleIDs: []string{"gbp"}, Context: map[string]interface{}{}, Currency: "EUR"}
+		mockEvaluator.On("Evaluate", mock.Anything, "gbp", cmd.Context).Return(amount("10", "GBP"), nil)
+
+		_, err := handler.Handle(ctx, cmd)
+
+		var unknownRate *money.UnknownRateError
+		assert.ErrorAs(t, err, &unknownRate)
+	})
+
+	t.Run("an invalid target currency is a validation error", func(t *testing.T) {
+		handler := application.NewCalculateRulesHandler(new(MockRuleEvaluator), nil, memory.NewCalculationRepository())
+
+		_, err := handler.Handle(ctx, application.CalculateRulesCommand{Currency: "EURO"})
+
+		var validationErr *shared.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+}
@@ -0,0 +1,215 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculationHistory(t *testing.T) {
+	ctx := context.Background()
+	repository := memory.NewCalculationRepository()
+	mockEvaluator := new(MockRuleEvaluator)
+	mockEvaluator.On("Evaluate", mock.Anything, "rule1", mock.Anything).Return(amount("10", "EUR"), nil)
+	handler := application.NewCalculateRulesHandler(mockEvaluator, nil, repository)
+	getHandler := application.NewGetCalculationHandler(repository)
+	listHandler := application.NewListCalculationsHandler(repository)
+
+	calculate := func(customerID string) *application.CalculateRulesResult {
+		result, err := handler.Handle(ctx, application.CalculateRulesCommand{
+			RuleIDs:      []string{"rule1"},
+			RuleVersions: map[string]int{"rule1": 3},
+			Context:      map[string]interface{}{"customer": map[string]interface{}{"id": customerID}},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	first := calculate("c-1")
+	start := time.Now().UTC()
+	calculate("c-2")
+	calculate("c-1")
+
+	t.Run("stores the inputs and the outcome of each calculation", func(t *testing.T) {
+		calc, err := getHandler.Handle(ctx, first.CalculationID)
+		require.NoError(t, err)
+
+		assert.Equal(t, calculation.StatusCompleted, calc.Status())
+		assert.Equal(t, "c-1", calc.CustomerID())
+		assert.Equal(t, []string{"rule1"}, calc.RuleIDs())
+		assert.Equal(t, map[string]int{"rule1": 3}, calc.RuleVersions())
+		assert.Equal(t, "10 EUR", calc.Result().Value.String())
+		assert.NotNil(t, calc.CompletedAt())
+	})
+
+	t.Run("reports unknown and malformed IDs", func(t *testing.T) {
+		_, err := getHandler.Handle(ctx, "5b0c8d1e-0000-4000-8000-000000000000")
+		assert.ErrorIs(t, err, calculation.ErrNotFound)
+
+		_, err = getHandler.Handle(ctx, "not-a-uuid")
+		var validationErr *shared.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("lists by customer, most recent first", func(t *testing.T) {
+		page, err := listHandler.Handle(ctx, application.ListCalculationsQuery{CustomerID: "c-1"})
+		require.NoError(t, err)
+
+		require.Len(t, page.Calculations, 2)
+		assert.Equal(t, first.CalculationID, page.Calculations[1].ID().String())
+		assert.Equal(t, 50, page.Limit)
+	})
+
+	t.Run("lists by time range", func(t *testing.T) {
+		page, err := listHandler.Handle(ctx, application.ListCalculationsQuery{From: &start})
+		require.NoError(t, err)
+		assert.Len(t, page.Calculations, 2)
+
+		_, err = listHandler.Handle(ctx, application.ListCalculationsQuery{From: &start, To: &start})
+		var validationErr *shared.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+}
+
+func TestCalculateRulesHandler_Handle_Idempotency(t *testing.T) {
+	ctx := context.Background()
+	cmd := application.CalculateRulesCommand{
+		RuleIDs:         []string{"rule1"},
+		Context:         map[string]interface{}{"order_id": "o-1"},
+		RequiredRuleIDs: []string{"rule1"},
+		IdempotencyKey:  "checkout-o-1",
+	}
+
+	t.Run("a retry returns the first calculation without evaluating again", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		mockEvaluator.On("Evaluate", mock.Anything, "rule1", cmd.Context).Return(amount("10", "EUR"), nil).Once()
+		handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
+
+		first, err := handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+		retry, err := handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+
+		assert.False(t, first.Replayed)
+		assert.True(t, retry.Replayed)
+		assert.Equal(t, first.CalculationID, retry.CalculationID)
+		assert.Equal(t, first.Value.String(), retry.Value.String())
+		mockEvaluator.AssertNumberOfCalls(t, "Evaluate", 1)
+	})
+
+	t.Run("a key reused for a different request is rejected", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		mockEvaluator.On("Evaluate", mock.Anything, "rule1", mock.Anything).Return(amount("10", "EUR"), nil)
+		handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
+
+		_, err := handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+		other := cmd
+		other.Context = map[string]interface{}{"order_id": "o-2"}
+		_, err = handler.Handle(ctx, other)
+
+		assert.ErrorIs(t, err, calculation.ErrIdempotencyKeyMismatch)
+	})
+
+	t.Run("a retry while the first request is running is a conflict", func(t *testing.T) {
+		evaluator := &blockingEvaluator{started: make(chan struct{}), release: make(chan struct{})}
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+
+		done := make(chan error)
+		go func() {
+			_, err := handler.Handle(ctx, cmd)
+			done <- err
+		}()
+		<-evaluator.started
+
+		_, err := handler.Handle(ctx, cmd)
+		assert.ErrorIs(t, err, calculation.ErrInProgress)
+
+		close(evaluator.release)
+		require.NoError(t, <-done)
+	})
+	t.Run("a retry calculates again when the evaluation service was unavailable", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		mockEvaluator.On("Evaluate", mock.Anything, "rule1", cmd.Context).
+			Return(money.Money{}, calculation.NewRuleError(calculation.ErrorUnavailable, "connection refused")).Once()
+		mockEvaluator.On("Evaluate", mock.Anything, "rule1", cmd.Context).Return(amount("10", "EUR"), nil).Once()
+		handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
+
+		_, err := handler.Handle(ctx, cmd)
+		var required *calculation.RequiredRuleError
+		require.ErrorAs(t, err, &required)
+		retry, err := handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+
+		assert.False(t, retry.Replayed)
+		assert.Equal(t, "10 EUR", retry.Value.String())
+		again, err := handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+		assert.True(t, again.Replayed)
+		assert.Equal(t, retry.CalculationID, again.CalculationID)
+	})
+
+	t.Run("a retry replays the failure of an invalid rule", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		mockEvaluator.On("Evaluate", mock.Anything, "rule1", cmd.Context).
+			Return(money.Money{}, calculation.NewRuleError(calculation.ErrorInvalidRule, "unknown rule")).Once()
+		handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
+
+		_, err := handler.Handle(ctx, cmd)
+		require.Error(t, err)
+		_, err = handler.Handle(ctx, cmd)
+
+		var failed *calculation.FailedError
+		assert.ErrorAs(t, err, &failed)
+		mockEvaluator.AssertNumberOfCalls(t, "Evaluate", 1)
+	})
+
+	t.Run("a retry calculates again when the first calculation was abandoned", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		mockEvaluator.On("Evaluate", mock.Anything, "rule1", cmd.Context).Return(amount("10", "EUR"), nil)
+		handler := application.NewCalculateRulesHandler(mockEvaluator, nil, lostUpdates{memory.NewCalculationRepository()})
+		handler.SetExecution(application.ExecutionOptions{Concurrency: 1, Timeout: 20 * time.Millisecond})
+
+		first, err := handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+		_, err = handler.Handle(ctx, cmd)
+		require.ErrorIs(t, err, calculation.ErrInProgress, "the calculation may still be running")
+
+		time.Sleep(30 * time.Millisecond)
+		retry, err := handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+		assert.False(t, retry.Replayed)
+		assert.NotEqual(t, first.CalculationID, retry.CalculationID)
+	})
+}
+
+// blockingEvaluator blocks in Evaluate until released.
+// lostUpdates is a repository that loses the outcome of calculations, as when an instance stops mid-calculation.
+type lostUpdates struct {
+	*memory.CalculationRepository
+}
+
+func (lostUpdates) Update(context.Context, *calculation.Calculation) error {
+	return errors.New("instance stopped")
+}
+
+type blockingEvaluator struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (e *blockingEvaluator) Evaluate(ctx context.Context, ruleID string, context map[string]interface{}) (money.Money, error) {
+	close(e.started)
+	<-e.release
+	return amount("10", "EUR"), nil
+}
@@ -0,0 +1,155 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/memory"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareHandler(t *testing.T) {
+	ctx := context.Background()
+	catalog, err := rules.ParseStaticCatalog([]byte(`{"rule_sets": {
+		"current": {"pipeline": {"stages": [
+			{"category": "DISCOUNTS", "rule_ids": ["ten_percent_off"]},
+			{"category": "TAXES", "rule_ids": ["vat"]}
+		]}}
+	}}`))
+	require.NoError(t, err)
+	var repository *memory.CalculationRepository
+	newHandler := func() (*application.CompareHandler, *checkoutEvaluator) {
+		evaluator := newCheckoutEvaluator()
+		repository = memory.NewCalculationRepository()
+		calculator := application.NewCalculateRulesHandler(evaluator, nil, repository)
+		return application.NewCompareHandler(calculator, catalog), evaluator
+	}
+
+	t.Run("compares the totals, rules and lines of each scenario with the baseline", func(t *testing.T) {
+		handler, _ := newHandler()
+		request := basketCommand()
+		request.Pipeline = nil
+
+		result, err := handler.Handle(ctx, application.CompareCommand{
+			Request: request,
+			Scenarios: []application.Scenario{
+				{RuleSetID: "current"},
+				{Name: "proposed", RuleSet: calculation.RuleSet{Pipeline: &calculation.Pipeline{Stages: []calculation.Stage{
+					{Category: calculation.StageDiscounts, RuleIDs: []string{"home_coupon"}}, // a flat 5 off
+					{Category: calculation.StageTaxes, RuleIDs: []string{"vat"}},
+				}}}},
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "current", result.Baseline)
+		require.Len(t, result.Scenarios, 2)
+		current, proposed := result.Scenarios[0], result.Scenarios[1]
+		assert.Equal(t, "current", current.Name)
+		assert.Equal(t, "current", current.RuleSetID)
+		assert.Nil(t, current.Difference)
+		// 100 - 10 = 90, + 21% = 108.90 against 100 - 5 = 95, + 21% = 114.95.
+		assert.Equal(t, "108.9 EUR", current.Result.Value.String())
+		assert.Equal(t, "114.95 EUR", proposed.Result.Value.String())
+
+		difference := proposed.Difference
+		require.NotNil(t, difference)
+		assert.Equal(t, "6.05 EUR", difference.TotalDelta.String())
+		require.Len(t, difference.Rules, 3)
+		assert.Equal(t, "home_coupon", difference.Rules[0].RuleID)
+		assert.Equal(t, calculation.ChangeAdded, difference.Rules[0].Change)
+		assert.Equal(t, "5 EUR", difference.Rules[0].Delta.String())
+		assert.Equal(t, "ten_percent_off", difference.Rules[1].RuleID)
+		assert.Equal(t, calculation.ChangeRemoved, difference.Rules[1].Change)
+		assert.Equal(t, "-10 EUR", difference.Rules[1].Delta.String())
+		assert.Equal(t, "vat", difference.Rules[2].RuleID)
+		assert.Equal(t, calculation.ChangeChanged, difference.Rules[2].Change)
+		assert.Equal(t, "1.05 EUR", difference.Rules[2].Delta.String())
+
+		require.Len(t, difference.Lines, 2)
+		shirt := difference.Lines[0]
+		assert.Equal(t, "shirt", shirt.LineID)
+		assert.Equal(t, calculation.ChangeChanged, shirt.Change)
+		assert.Equal(t, "43.56 EUR", shirt.BaselineGross.String())
+		assert.Equal(t, "45.98 EUR", shirt.Gross.String())
+		assert.Equal(t, "-2 EUR", shirt.DiscountDelta.String())
+		assert.Equal(t, "2.42 EUR", shirt.GrossDelta.String())
+		assert.Equal(t, "3.63 EUR", difference.Lines[1].GrossDelta.String())
+	})
+
+	t.Run("a failing scenario is reported without a difference", func(t *testing.T) {
+		handler, evaluator := newHandler()
+		evaluator.failing["broken"] = true
+
+		result, err := handler.Handle(ctx, application.CompareCommand{
+			Request: checkoutCommand(nil),
+			Scenarios: []application.Scenario{
+				{RuleSet: calculation.RuleSet{RuleIDs: []string{"shipping"}}},
+				{RuleSet: calculation.RuleSet{RuleIDs: []string{"shipping", "broken"}, RequiredRuleIDs: []string{"broken"}}},
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "scenario-1", result.Baseline)
+		failed := result.Scenarios[1]
+		assert.Equal(t, "scenario-2", failed.Name)
+		assert.Nil(t, failed.Result)
+		assert.Nil(t, failed.Difference)
+		assert.NotEmpty(t, failed.Error)
+		require.Len(t, failed.Errors, 1)
+		assert.Equal(t, "broken", failed.Errors[0].RuleID)
+	})
+
+	t.Run("calculates the scenarios as dry runs without storing them", func(t *testing.T) {
+		handler, evaluator := newHandler()
+		evaluator.failing["broken"] = true
+
+		result, err := handler.Handle(ctx, application.CompareCommand{
+			Request: checkoutCommand(nil),
+			Scenarios: []application.Scenario{
+				{RuleSet: calculation.RuleSet{RuleIDs: []string{"shipping"}}},
+				{RuleSet: calculation.RuleSet{RuleIDs: []string{"broken"}}},
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Empty(t, result.Scenarios[0].Result.CalculationID)
+		assert.Equal(t, map[string]bool{"shipping": true, "broken": true}, evaluator.dryRuns)
+		stored, err := repository.List(ctx, calculation.Filter{})
+		require.NoError(t, err)
+		assert.Empty(t, stored, "neither the completed nor the failed scenario is stored")
+	})
+
+	t.Run("validates the scenarios", func(t *testing.T) {
+		handler, _ := newHandler()
+		sum := calculation.RuleSet{RuleIDs: []string{"shipping"}}
+		tests := []struct {
+			name      string
+			scenarios []application.Scenario
+			field     string
+		}{
+			{"a single scenario", []application.Scenario{{RuleSet: sum}}, "scenarios"},
+			{"an unknown rule set", []application.Scenario{{RuleSet: sum}, {RuleSetID: "missing"}}, "scenarios[1].rule_set_id"},
+			{"a rule set and rules", []application.Scenario{{RuleSet: sum}, {RuleSetID: "current", RuleSet: sum}}, "scenarios[1]"},
+			{"no rules", []application.Scenario{{RuleSet: sum}, {Name: "empty"}}, "scenarios[1]"},
+			{"duplicate names", []application.Scenario{{Name: "a", RuleSet: sum}, {Name: "a", RuleSet: sum}}, "scenarios[1].name"},
+			{"a pipeline without a currency", []application.Scenario{{RuleSet: sum}, {RuleSetID: "current"}}, "scenarios[1].currency"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				request := checkoutCommand(nil)
+				request.Context = map[string]interface{}{"order": map[string]interface{}{"amount": 100.0}}
+				_, err := handler.Handle(ctx, application.CompareCommand{Request: request, Scenarios: tt.scenarios})
+
+				var validationErr *shared.ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, tt.field, validationErr.Field)
+			})
+		}
+	})
+}
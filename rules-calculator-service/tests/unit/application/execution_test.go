@@ -0,0 +1,127 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowEvaluator answers after a delay per rule, recording how many evaluations overlapped and the
+// time budget each rule got.
+type slowEvaluator struct {
+	delays map[string]time.Duration
+	values map[string]money.Money
+	errs   map[string]error
+
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+	mu          sync.Mutex
+	budgets     map[string]time.Duration
+}
+
+func (e *slowEvaluator) Evaluate(ctx context.Context, ruleID string, _ map[string]interface{}) (money.Money, error) {
+	n := e.inFlight.Add(1)
+	defer e.inFlight.Add(-1)
+	for {
+		current := e.maxInFlight.Load()
+		if n <= current || e.maxInFlight.CompareAndSwap(current, n) {
+			break
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		e.mu.Lock()
+		e.budgets[ruleID] = time.Until(deadline)
+		e.mu.Unlock()
+	}
+
+	select {
+	case <-time.After(e.delays[ruleID]):
+		return e.values[ruleID], e.errs[ruleID]
+	case <-ctx.Done():
+		return money.Money{}, ctx.Err()
+	}
+}
+
+func TestCalculateRulesHandler_Execution(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rules are evaluated concurrently up to the limit", func(t *testing.T) {
+		evaluator := &slowEvaluator{
+			delays:  map[string]time.Duration{"r1": 40 * time.Millisecond, "r2": 10 * time.Millisecond, "r3": 20 * time.Millisecond, "r4": 5 * time.Millisecond},
+			values:  map[string]money.Money{"r1": amount("0.1", "EUR"), "r2": amount("0.2", "EUR"), "r3": amount("0.3", "EUR"), "r4": amount("0.4", "EUR")},
+			budgets: map[string]time.Duration{},
+		}
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		handler.SetExecution(application.ExecutionOptions{Concurrency: 2})
+
+		result, err := handler.Handle(ctx, application.CalculateRulesCommand{
+			RuleIDs: []string{"r1", "r2", "r3", "r4"},
+			Context: map[string]interface{}{},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "1 EUR", result.Value.String())
+		assert.Len(t, result.Breakdown, 4)
+		assert.Equal(t, int32(2), evaluator.maxInFlight.Load())
+	})
+
+	t.Run("the timeout budget is split across the rules", func(t *testing.T) {
+		evaluator := &slowEvaluator{
+			delays:  map[string]time.Duration{"slow": time.Second},
+			values:  map[string]money.Money{"fast": amount("1", "EUR")},
+			budgets: map[string]time.Duration{},
+		}
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		handler.SetExecution(application.ExecutionOptions{Concurrency: 1, Timeout: 200 * time.Millisecond})
+
+		start := time.Now()
+		result, err := handler.Handle(ctx, application.CalculateRulesCommand{
+			RuleIDs:         []string{"slow", "fast"},
+			Context:         map[string]interface{}{},
+			OptionalRuleIDs: []string{"slow"},
+		})
+
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 200*time.Millisecond)
+		assert.LessOrEqual(t, evaluator.budgets["slow"], 100*time.Millisecond, "the first of two sequential rules gets half the budget")
+		assert.Equal(t, calculation.ErrorTimeout, result.Breakdown["slow"].Error.Code)
+		assert.Equal(t, calculation.RuleStatusOK, result.Breakdown["fast"].Status, "a slow rule does not use up the budget of the next")
+		assert.Equal(t, "1 EUR", result.Value.String())
+	})
+
+	t.Run("fail fast cancels the evaluations in flight", func(t *testing.T) {
+		evaluator := &slowEvaluator{
+			delays:  map[string]time.Duration{"slow": time.Second, "failing": 10 * time.Millisecond},
+			errs:    map[string]error{"failing": errors.New("boom")},
+			budgets: map[string]time.Duration{},
+		}
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		handler.SetExecution(application.ExecutionOptions{Concurrency: 2})
+
+		start := time.Now()
+		_, err := handler.Handle(ctx, application.CalculateRulesCommand{
+			RuleIDs:         []string{"slow", "failing"},
+			Context:         map[string]interface{}{},
+			FailurePolicy:   calculation.PolicyFailFast,
+			RequiredRuleIDs: []string{"slow", "failing"},
+		})
+
+		var required *calculation.RequiredRuleError
+		require.ErrorAs(t, err, &required)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, []calculation.RuleFailure{
+			{RuleID: "slow", Code: calculation.ErrorNotEvaluated, Message: "a required rule failed first"},
+			{RuleID: "failing", Code: calculation.ErrorEvaluationFailed, Message: "boom"},
+		}, required.Failures)
+	})
+}
@@ -0,0 +1,164 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateRulesHandler_FailurePolicy(t *testing.T) {
+	ctx := context.Background()
+	evalContext := map[string]interface{}{"customer_tier": "gold"}
+
+	t.Run("fail fast stops at the first failing required rule", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		repository := memory.NewCalculationRepository()
+		handler := application.NewCalculateRulesHandler(mockEvaluator, nil, repository)
+		// One rule at a time, so that the rules after the failing rule are never started.
+		handler.SetExecution(application.ExecutionOptions{Concurrency: 1})
+		cmd := application.CalculateRulesCommand{
+			RuleIDs:         []string{"rule1", "failing_rule", "rule2"},
+			Context:         evalContext,
+			FailurePolicy:   calculation.PolicyFailFast,
+			RequiredRuleIDs: []string{"rule1", "failing_rule", "rule2"},
+		}
+		mockEvaluator.On("Evaluate", mock.Anything, "rule1", evalContext).Return(amount("100", "EUR"), nil)
+		mockEvaluator.On("Evaluate", mock.Anything, "failing_rule", evalContext).
+			Return(money.Money{}, calculation.NewRuleError(calculation.ErrorUnavailable, "evaluation service is down"))
+
+		result, err := handler.Handle(ctx, cmd)
+
+		assert.Nil(t, result)
+		var required *calculation.RequiredRuleError
+		require.ErrorAs(t, err, &required)
+		assert.Equal(t, []calculation.RuleFailure{
+			{RuleID: "failing_rule", Code: calculation.ErrorUnavailable, Message: "evaluation service is down"},
+			{RuleID: "rule2", Code: calculation.ErrorNotEvaluated, Message: "a required rule failed first"},
+		}, required.Failures)
+		mockEvaluator.AssertNotCalled(t, "Evaluate", mock.Anything, "rule2", mock.Anything)
+
+		page, err := repository.List(ctx, calculation.Filter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		stored := page[0]
+		assert.Equal(t, calculation.StatusFailed, stored.Status())
+		require.NotNil(t, stored.Result(), "the failed calculation keeps the outcome of each rule")
+		assert.Equal(t, calculation.RuleStatusOK, stored.Result().Breakdown["rule1"].Status)
+		assert.Equal(t, calculation.RuleStatusNotEvaluated, stored.Result().Breakdown["rule2"].Status)
+	})
+
+	t.Run("fail fast continues past failing optional rules", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
+		cmd := application.CalculateRulesCommand{
+			RuleIDs:         []string{"optional_rule", "rule1"},
+			Context:         evalContext,
+			FailurePolicy:   calculation.PolicyFailFast,
+			OptionalRuleIDs: []string{"optional_rule"},
+		}
+		mockEvaluator.On("Evaluate", mock.Anything, "optional_rule", evalContext).Return(money.Money{}, errors.New("boom"))
+		mockEvaluator.On("Evaluate", mock.Anything, "rule1", evalContext).Return(amount("100", "EUR"), nil)
+
+		result, err := handler.Handle(ctx, cmd)
+
+		require.NoError(t, err)
+		assert.Equal(t, calculation.StatusPartial, result.Status)
+		assert.Equal(t, "100 EUR", result.Value.String())
+		assert.True(t, result.Breakdown["optional_rule"].Optional)
+	})
+
+	t.Run("best effort evaluates every rule before failing on a required rule", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
+		cmd := application.CalculateRulesCommand{
+			RuleIDs:         []string{"failing_rule", "rule1"},
+			Context:         evalContext,
+			RequiredRuleIDs: []string{"failing_rule"},
+		}
+		mockEvaluator.On("Evaluate", mock.Anything, "failing_rule", evalContext).
+			Return(money.Money{}, calculation.NewRuleError(calculation.ErrorInvalidRule, "unknown rule"))
+		mockEvaluator.On("Evaluate", mock.Anything, "rule1", evalContext).Return(amount("100", "EUR"), nil)
+
+		_, err := handler.Handle(ctx, cmd)
+
+		var required *calculation.RequiredRuleError
+		require.ErrorAs(t, err, &required)
+		assert.Len(t, required.Failures, 1)
+		assert.Contains(t, err.Error(), "failing_rule (INVALID_RULE: unknown rule)")
+		mockEvaluator.AssertExpectations(t)
+	})
+
+	t.Run("evaluations cut off by the deadline are timeouts", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
+		cmd := application.CalculateRulesCommand{
+			RuleIDs:         []string{"slow_rule"},
+			Context:         evalContext,
+			OptionalRuleIDs: []string{"slow_rule"},
+		}
+		mockEvaluator.On("Evaluate", mock.Anything, "slow_rule", evalContext).Return(money.Money{}, context.DeadlineExceeded)
+
+		result, err := handler.Handle(ctx, cmd)
+
+		require.NoError(t, err)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, calculation.ErrorTimeout, result.Errors[0].Code)
+	})
+
+	t.Run("rules are optional unless marked required", func(t *testing.T) {
+		mockEvaluator := new(MockRuleEvaluator)
+		handler := application.NewCalculateRulesHandler(mockEvaluator, nil, memory.NewCalculationRepository())
+		cmd := application.CalculateRulesCommand{
+			RuleIDs:       []string{"failing_rule", "rule1"},
+			Context:       evalContext,
+			FailurePolicy: calculation.PolicyFailFast,
+		}
+		mockEvaluator.On("Evaluate", mock.Anything, "failing_rule", evalContext).Return(money.Money{}, errors.New("boom"))
+		mockEvaluator.On("Evaluate", mock.Anything, "rule1", evalContext).Return(amount("100", "EUR"), nil)
+
+		result, err := handler.Handle(ctx, cmd)
+
+		require.NoError(t, err)
+		assert.Equal(t, calculation.StatusPartial, result.Status)
+		assert.Equal(t, "100 EUR", result.Value.String())
+		assert.True(t, result.Breakdown["failing_rule"].Optional)
+	})
+
+	t.Run("rules both required and optional are rejected", func(t *testing.T) {
+		handler := application.NewCalculateRulesHandler(new(MockRuleEvaluator), nil, memory.NewCalculationRepository())
+
+		_, err := handler.Handle(ctx, application.CalculateRulesCommand{
+			RuleIDs:         []string{"rule1"},
+			Context:         evalContext,
+			OptionalRuleIDs: []string{"rule1"},
+			RequiredRuleIDs: []string{"rule1"},
+		})
+
+		var validationErr *shared.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "required_rule_ids", validationErr.Field)
+	})
+
+	t.Run("unknown policies are rejected", func(t *testing.T) {
+		handler := application.NewCalculateRulesHandler(new(MockRuleEvaluator), nil, memory.NewCalculationRepository())
+
+		_, err := handler.Handle(ctx, application.CalculateRulesCommand{
+			RuleIDs:       []string{"rule1"},
+			Context:       evalContext,
+			FailurePolicy: "SOMETIMES",
+		})
+
+		var validationErr *shared.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "failure_policy", validationErr.Field)
+	})
+}
@@ -0,0 +1,203 @@
+package application_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/job"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingJobPublisher struct {
+	mu        sync.Mutex
+	results   []job.Result
+	completed []job.JobID
+}
+
+func (p *recordingJobPublisher) PublishResult(_ context.Context, result job.Result) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.results = append(p.results, result)
+	return nil
+}
+
+func (p *recordingJobPublisher) PublishCompleted(_ context.Context, j *job.Job) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.completed = append(p.completed, j.ID())
+	return nil
+}
+
+func (p *recordingJobPublisher) counts() (int, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.results), len(p.completed)
+}
+
+func jobItem(reference, ruleID string) application.JobItem {
+	return application.JobItem{
+		Reference: reference,
+		Command: application.CalculateRulesCommand{
+			RuleIDs:         []string{ruleID},
+			Context:         map[string]interface{}{"basket": reference},
+			RequiredRuleIDs: []string{ruleID},
+		},
+	}
+}
+
+func TestJobRunner(t *testing.T) {
+	newRunner := func(options application.JobOptions) (*application.JobRunner, *MockRuleEvaluator, *memory.JobRepository) {
+		evaluator := new(MockRuleEvaluator)
+		calculator := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		repository := memory.NewJobRepository()
+		return application.NewJobRunner(calculator, repository, options), evaluator, repository
+	}
+	start := func(t *testing.T, runner *application.JobRunner) {
+		ctx, cancel := context.WithCancel(context.Background())
+		runner.Start(ctx)
+		t.Cleanup(func() {
+			cancel()
+			runner.Wait()
+		})
+	}
+	awaitJob := func(t *testing.T, runner *application.JobRunner, id job.JobID) *job.Job {
+		var j *job.Job
+		require.Eventually(t, func() bool {
+			var err error
+			j, err = runner.Get(context.Background(), id.String())
+			require.NoError(t, err)
+			return j.Status() != calculation.StatusPending
+		}, 5*time.Second, 5*time.Millisecond)
+		return j
+	}
+
+	t.Run("calculates every item and records the failed ones", func(t *testing.T) {
+		runner, evaluator, _ := newRunner(application.JobOptions{Workers: 2, QueueSize: 10})
+		publisher := &recordingJobPublisher{}
+		runner.SetPublisher(publisher)
+		evaluator.On("Evaluate", mock.Anything, "price", mock.Anything).Return(amount("100", "EUR"), nil)
+		evaluator.On("Evaluate", mock.Anything, "broken", mock.Anything).Return(amount("0", ""), errors.New("boom"))
+		start(t, runner)
+
+		submitted, err := runner.Submit(context.Background(), application.SubmitJobCommand{
+			Reference: "nightly",
+			Items:     []application.JobItem{jobItem("b-1", "price"), jobItem("b-2", "broken"), jobItem("b-3", "price")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, calculation.StatusPending, submitted.Status())
+		assert.Equal(t, 3, submitted.Total())
+
+		j := awaitJob(t, runner, submitted.ID())
+		assert.Equal(t, calculation.StatusCompleted, j.Status())
+		assert.Equal(t, 3, j.Processed())
+		assert.Equal(t, 2, j.Succeeded())
+		assert.Equal(t, 1, j.Failed())
+		assert.Equal(t, 1.0, j.Progress())
+		assert.NotNil(t, j.StartedAt())
+		assert.NotNil(t, j.CompletedAt())
+
+		page, err := runner.Results(context.Background(), j.ID().String(), 0, 0)
+		require.NoError(t, err)
+		require.Len(t, page.Results, 3)
+		assert.Equal(t, 50, page.Limit)
+		for i, reference := range []string{"b-1", "b-2", "b-3"} {
+			assert.Equal(t, i, page.Results[i].Index)
+			assert.Equal(t, reference, page.Results[i].Reference)
+		}
+		assert.NotEqual(t, calculation.StatusFailed, page.Results[0].Status)
+		assert.Equal(t, "100 EUR", page.Results[0].Value.String())
+		assert.NotEmpty(t, page.Results[0].CalculationID)
+		assert.Equal(t, calculation.StatusFailed, page.Results[1].Status)
+
+		results, completed := publisher.counts()
+		assert.Equal(t, 3, results)
+		assert.Equal(t, 1, completed)
+	})
+
+	t.Run("a job whose items all fail is failed", func(t *testing.T) {
+		runner, evaluator, _ := newRunner(application.JobOptions{Workers: 1, QueueSize: 10})
+		evaluator.On("Evaluate", mock.Anything, "broken", mock.Anything).Return(amount("0", ""), errors.New("boom"))
+		start(t, runner)
+
+		submitted, err := runner.Submit(context.Background(), application.SubmitJobCommand{
+			Items: []application.JobItem{jobItem("b-1", "broken"), jobItem("b-2", "broken")},
+		})
+		require.NoError(t, err)
+
+		j := awaitJob(t, runner, submitted.ID())
+		assert.Equal(t, calculation.StatusFailed, j.Status())
+		assert.Equal(t, 2, j.Failed())
+	})
+
+	t.Run("rejects jobs when the queue is full", func(t *testing.T) {
+		runner, _, _ := newRunner(application.JobOptions{Workers: 1, QueueSize: 1})
+		cmd := application.SubmitJobCommand{Items: []application.JobItem{jobItem("b-1", "price")}}
+
+		_, err := runner.Submit(context.Background(), cmd)
+		require.NoError(t, err)
+		_, err = runner.Submit(context.Background(), cmd)
+		assert.ErrorIs(t, err, application.ErrQueueFull)
+	})
+
+	t.Run("validates the size of a job", func(t *testing.T) {
+		runner, _, _ := newRunner(application.JobOptions{Workers: 1, QueueSize: 1, MaxItems: 1})
+		var validationErr *shared.ValidationError
+
+		_, err := runner.Submit(context.Background(), application.SubmitJobCommand{})
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "items", validationErr.Field)
+
+		_, err = runner.Submit(context.Background(), application.SubmitJobCommand{
+			Items: []application.JobItem{jobItem("b-1", "price"), jobItem("b-2", "price")},
+		})
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "items", validationErr.Field)
+	})
+
+	t.Run("resumes an interrupted job without recalculating its results", func(t *testing.T) {
+		runner, evaluator, repository := newRunner(application.JobOptions{Workers: 1, QueueSize: 10})
+		evaluator.On("Evaluate", mock.Anything, "price", mock.Anything).Return(amount("100", "EUR"), nil)
+
+		var items []job.Item
+		for _, item := range []application.JobItem{jobItem("b-1", "price"), jobItem("b-2", "price")} {
+			request, err := json.Marshal(item.Command)
+			require.NoError(t, err)
+			items = append(items, job.Item{Reference: item.Reference, Request: request})
+		}
+		interrupted := job.NewJob("nightly", items)
+		require.NoError(t, repository.Create(context.Background(), interrupted))
+		require.NoError(t, repository.SaveResult(context.Background(), job.Result{
+			JobID: interrupted.ID(), Index: 0, Reference: "b-1", Status: calculation.StatusCompleted,
+		}))
+
+		recovered, err := runner.Recover(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, recovered)
+		start(t, runner)
+
+		j := awaitJob(t, runner, interrupted.ID())
+		assert.Equal(t, calculation.StatusCompleted, j.Status())
+		assert.Equal(t, 2, j.Processed())
+		evaluator.AssertNumberOfCalls(t, "Evaluate", 1)
+	})
+
+	t.Run("unknown jobs are not found", func(t *testing.T) {
+		runner, _, _ := newRunner(application.JobOptions{})
+
+		_, err := runner.Get(context.Background(), "8a7d9f0e-0000-4000-8000-000000000000")
+		assert.ErrorIs(t, err, job.ErrNotFound)
+		_, err = runner.Results(context.Background(), "not-a-uuid", 0, 0)
+		var validationErr *shared.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+}
@@ -0,0 +1,175 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/shared"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/memory"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkoutEvaluator computes rule values from the order.amount it is given, like the rules of a
+// checkout would, and records the context of each rule.
+type checkoutEvaluator struct {
+	mu       sync.Mutex
+	contexts map[string]map[string]interface{}
+	dryRuns  map[string]bool
+	failing  map[string]bool
+}
+
+func (e *checkoutEvaluator) Evaluate(ctx context.Context, ruleID string, evalContext map[string]interface{}) (money.Money, error) {
+	e.mu.Lock()
+	e.contexts[ruleID] = evalContext
+	e.dryRuns[ruleID] = calculation.IsDryRun(ctx)
+	e.mu.Unlock()
+	if e.failing[ruleID] {
+		return money.Money{}, errors.New("boom")
+	}
+
+	base := decimal.NewFromFloat(evalContext["order"].(map[string]interface{})["amount"].(float64))
+	rates := map[string]string{"ten_percent_off": "0.1", "vat": "0.21", "points": "1"}
+	if rate, ok := rates[ruleID]; ok {
+		return money.Money{Amount: base.Mul(decimal.RequireFromString(rate))}, nil
+	}
+	return money.Money{Amount: decimal.RequireFromString("5")}, nil // a flat fee
+}
+
+func newCheckoutEvaluator() *checkoutEvaluator {
+	return &checkoutEvaluator{contexts: map[string]map[string]interface{}{}, dryRuns: map[string]bool{}, failing: map[string]bool{}}
+}
+
+func checkoutCommand(pipeline *calculation.Pipeline) application.CalculateRulesCommand {
+	return application.CalculateRulesCommand{
+		Context:  map[string]interface{}{"order": map[string]interface{}{"amount": 100.0, "currency": "EUR"}},
+		Pipeline: pipeline,
+	}
+}
+
+func TestCalculateRulesHandler_Pipeline(t *testing.T) {
+	ctx := context.Background()
+	stages := []calculation.Stage{
+		// Out of order on purpose: stages always run discounts, fees, taxes, loyalty.
+		{Category: calculation.StageTaxes, RuleIDs: []string{"vat"}},
+		{Category: calculation.StageLoyalty, RuleIDs: []string{"points"}},
+		{Category: calculation.StageDiscounts, RuleIDs: []string{"ten_percent_off"}},
+		{Category: calculation.StageFees, RuleIDs: []string{"shipping"}},
+	}
+
+	t.Run("each stage is evaluated on the amount left by the previous stages", func(t *testing.T) {
+		evaluator := newCheckoutEvaluator()
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+
+		result, err := handler.Handle(ctx, checkoutCommand(&calculation.Pipeline{Stages: stages}))
+
+		require.NoError(t, err)
+		// 100 - 10% = 90, + 5 shipping = 95, + 21% VAT = 114.95.
+		assert.Equal(t, "114.95 EUR", result.Value.String())
+		require.Len(t, result.Stages, 4)
+		var categories []calculation.StageCategory
+		for _, stage := range result.Stages {
+			categories = append(categories, stage.Category)
+		}
+		assert.Equal(t, []calculation.StageCategory{
+			calculation.StageDiscounts, calculation.StageFees, calculation.StageTaxes, calculation.StageLoyalty,
+		}, categories)
+
+		taxes := result.Stages[2]
+		assert.Equal(t, "95 EUR", taxes.Base.String())
+		assert.Equal(t, "19.95 EUR", taxes.Total.String())
+		assert.Equal(t, "114.95 EUR", taxes.Amount.String())
+		assert.Equal(t, "vat", taxes.Rules[0].RuleID)
+		assert.Equal(t, map[string]interface{}{"subtotal": 100.0, "discounts": 10.0, "fees": 5.0}, evaluator.contexts["vat"]["pipeline"])
+
+		loyalty := result.Stages[3]
+		assert.Equal(t, "114.95", loyalty.Points.String(), "points are computed after taxes by default")
+		assert.Nil(t, loyalty.Total)
+		assert.Equal(t, "114.95 EUR", loyalty.Amount.String(), "points do not change the amount")
+
+		assert.Equal(t, 100.0, checkoutCommand(nil).Context["order"].(map[string]interface{})["amount"], "the caller's context is not changed")
+		assert.Len(t, result.Breakdown, 4)
+	})
+
+	t.Run("loyalty points can be computed before taxes", func(t *testing.T) {
+		handler := application.NewCalculateRulesHandler(newCheckoutEvaluator(), nil, memory.NewCalculationRepository())
+		handler.SetPipeline(application.PipelineOptions{LoyaltyBase: calculation.LoyaltyPreTax})
+
+		result, err := handler.Handle(ctx, checkoutCommand(&calculation.Pipeline{Stages: stages}))
+
+		require.NoError(t, err)
+		assert.Equal(t, "95", result.Stages[3].Points.String())
+
+		result, err = handler.Handle(ctx, checkoutCommand(&calculation.Pipeline{Stages: stages, LoyaltyBase: calculation.LoyaltyPostTax}))
+
+		require.NoError(t, err)
+		assert.Equal(t, "114.95", result.Stages[3].Points.String(), "the request overrides the configured base")
+	})
+
+	t.Run("fail fast skips the stages after a failed required rule", func(t *testing.T) {
+		evaluator := newCheckoutEvaluator()
+		evaluator.failing["shipping"] = true
+		handler := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		cmd := checkoutCommand(&calculation.Pipeline{Stages: stages})
+		cmd.FailurePolicy = calculation.PolicyFailFast
+		cmd.RequiredRuleIDs = cmd.Pipeline.RuleIDs()
+
+		_, err := handler.Handle(ctx, cmd)
+
+		var required *calculation.RequiredRuleError
+		require.ErrorAs(t, err, &required)
+		assert.Len(t, required.Failures, 3)
+		assert.NotContains(t, evaluator.contexts, "vat")
+		assert.NotContains(t, evaluator.contexts, "points")
+	})
+
+	t.Run("invalid pipelines are rejected", func(t *testing.T) {
+		handler := application.NewCalculateRulesHandler(newCheckoutEvaluator(), nil, memory.NewCalculationRepository())
+		tests := map[string]struct {
+			cmd   application.CalculateRulesCommand
+			field string
+		}{
+			"duplicate stage": {
+				cmd: checkoutCommand(&calculation.Pipeline{Stages: []calculation.Stage{
+					{Category: calculation.StageFees, RuleIDs: []string{"a"}},
+					{Category: calculation.StageFees, RuleIDs: []string{"b"}},
+				}}),
+				field: "pipeline",
+			},
+			"unknown stage": {
+				cmd:   checkoutCommand(&calculation.Pipeline{Stages: []calculation.Stage{{Category: "SHIPPING"}}}),
+				field: "pipeline",
+			},
+			"rule ids and pipeline": {
+				cmd: func() application.CalculateRulesCommand {
+					cmd := checkoutCommand(&calculation.Pipeline{Stages: stages})
+					cmd.RuleIDs = []string{"vat"}
+					return cmd
+				}(),
+				field: "rule_ids",
+			},
+			"no order amount": {
+				cmd: application.CalculateRulesCommand{
+					Context:  map[string]interface{}{"currency": "EUR"},
+					Pipeline: &calculation.Pipeline{Stages: stages},
+				},
+				field: "context.order.amount",
+			},
+		}
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := handler.Handle(ctx, tt.cmd)
+
+				var validationErr *shared.ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, tt.field, validationErr.Field)
+			})
+		}
+	})
+}
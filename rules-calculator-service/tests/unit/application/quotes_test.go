@@ -0,0 +1,135 @@
+package application_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/quote"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/persistence/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteHandler(t *testing.T) {
+	ctx := context.Background()
+	command := application.CalculateRulesCommand{
+		RuleIDs:      []string{"price"},
+		Context:      map[string]interface{}{"customer": map[string]interface{}{"id": "c-1"}},
+		RuleVersions: map[string]int{"price": 7},
+	}
+	newHandler := func() (*application.QuoteHandler, *MockRuleEvaluator, *memory.QuoteRepository, *time.Time) {
+		evaluator := new(MockRuleEvaluator)
+		calculator := application.NewCalculateRulesHandler(evaluator, nil, memory.NewCalculationRepository())
+		repository := memory.NewQuoteRepository()
+		handler := application.NewQuoteHandler(calculator, repository, quote.NewSigner([]byte("secret")), 5*time.Minute)
+		now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		handler.SetClock(func() time.Time { return now })
+		return handler, evaluator, repository, &now
+	}
+
+	t.Run("a confirmed quote keeps its price after the rule changes", func(t *testing.T) {
+		handler, evaluator, _, now := newHandler()
+		evaluator.On("Evaluate", mock.Anything, "price", mock.Anything).Return(amount("100", "EUR"), nil).Once()
+		q, err := handler.Create(ctx, command)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(5*time.Minute), q.ExpiresAt())
+		assert.Equal(t, map[string]int{"price": 7}, q.RuleVersions())
+		assert.NotEmpty(t, q.Signature())
+
+		*now = now.Add(4 * time.Minute)
+		result, err := handler.Confirm(ctx, q.ID().String())
+
+		require.NoError(t, err)
+		assert.False(t, result.Expired())
+		assert.Equal(t, quote.StatusConfirmed, result.Quote.Status(*now))
+		assert.Equal(t, "100 EUR", result.Quote.Result().Value.String())
+		evaluator.AssertNumberOfCalls(t, "Evaluate", 1)
+
+		// Confirming again, even after the expiry, returns the same guarantee.
+		*now = now.Add(time.Hour)
+		again, err := handler.Confirm(ctx, q.ID().String())
+		require.NoError(t, err)
+		assert.False(t, again.Expired())
+		assert.Equal(t, result.Quote.ConfirmedAt(), again.Quote.ConfirmedAt())
+	})
+
+	t.Run("an expired quote offers a recalculation", func(t *testing.T) {
+		handler, evaluator, _, now := newHandler()
+		evaluator.On("Evaluate", mock.Anything, "price", mock.Anything).Return(amount("100", "EUR"), nil).Once()
+		evaluator.On("Evaluate", mock.Anything, "price", mock.Anything).Return(amount("120", "EUR"), nil).Once()
+		q, err := handler.Create(ctx, command)
+		require.NoError(t, err)
+
+		*now = now.Add(5 * time.Minute)
+		result, err := handler.Confirm(ctx, q.ID().String())
+
+		require.NoError(t, err)
+		assert.True(t, result.Expired())
+		assert.Equal(t, quote.StatusExpired, result.Quote.Status(*now))
+		require.NotNil(t, result.Offer)
+		assert.NotEqual(t, q.ID(), result.Offer.ID())
+		assert.Equal(t, "120 EUR", result.Offer.Result().Value.String())
+		assert.Equal(t, quote.StatusActive, result.Offer.Status(*now))
+	})
+
+	t.Run("a tampered quote is not honoured", func(t *testing.T) {
+		handler, evaluator, repository, _ := newHandler()
+		evaluator.On("Evaluate", mock.Anything, "price", mock.Anything).Return(amount("100", "EUR"), nil).Once()
+		q, err := handler.Create(ctx, command)
+		require.NoError(t, err)
+
+		snapshot := q.Snapshot()
+		snapshot.Result.Value = amount("1", "EUR")
+		require.NoError(t, repository.Update(ctx, quote.Restore(snapshot)))
+		_, err = handler.Confirm(ctx, q.ID().String())
+
+		assert.ErrorIs(t, err, quote.ErrInvalidSignature)
+	})
+
+	t.Run("a quote whose request was tampered with is not honoured", func(t *testing.T) {
+		handler, evaluator, repository, _ := newHandler()
+		evaluator.On("Evaluate", mock.Anything, "price", mock.Anything).Return(amount("100", "EUR"), nil).Once()
+		q, err := handler.Create(ctx, command)
+		require.NoError(t, err)
+
+		snapshot := q.Snapshot()
+		var request map[string]interface{}
+		require.NoError(t, json.Unmarshal(snapshot.Request, &request))
+		request["context"] = map[string]interface{}{"customer": map[string]interface{}{"id": "c-2"}}
+		snapshot.Request, err = json.Marshal(request)
+		require.NoError(t, err)
+		require.NoError(t, repository.Update(ctx, quote.Restore(snapshot)))
+		_, err = handler.Confirm(ctx, q.ID().String())
+
+		assert.ErrorIs(t, err, quote.ErrInvalidSignature)
+	})
+
+	t.Run("the signature does not depend on how the request is encoded", func(t *testing.T) {
+		handler, evaluator, repository, _ := newHandler()
+		evaluator.On("Evaluate", mock.Anything, "price", mock.Anything).Return(amount("100", "EUR"), nil).Once()
+		q, err := handler.Create(ctx, command)
+		require.NoError(t, err)
+
+		snapshot := q.Snapshot()
+		var indented bytes.Buffer
+		require.NoError(t, json.Indent(&indented, snapshot.Request, "", "  "))
+		snapshot.Request = indented.Bytes()
+		require.NoError(t, repository.Update(ctx, quote.Restore(snapshot)))
+		_, err = handler.Confirm(ctx, q.ID().String())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown quotes are not found", func(t *testing.T) {
+		handler, _, _, _ := newHandler()
+
+		_, err := handler.Confirm(ctx, "7b0e7a43-5a4c-4c39-9b76-0b7d1f3b1f42")
+
+		assert.ErrorIs(t, err, quote.ErrNotFound)
+	})
+}
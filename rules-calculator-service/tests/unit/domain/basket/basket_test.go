@@ -0,0 +1,90 @@
+package basket_test
+
+import (
+	"testing"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/basket"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decimals(values ...string) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(values))
+	for i, value := range values {
+		result[i] = decimal.RequireFromString(value)
+	}
+	return result
+}
+
+func fixed(values []decimal.Decimal) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = value.StringFixed(2)
+	}
+	return result
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  string
+		weights []string
+		want    []string
+	}{
+		{"in proportion to the weights", "10", []string{"40", "60"}, []string{"4.00", "6.00"}},
+		{"leftover cents go to the largest remainders", "10", []string{"1", "1", "1"}, []string{"3.34", "3.33", "3.33"}},
+		{"shares add up to the amount", "18.90", []string{"36", "54"}, []string{"7.56", "11.34"}},
+		{"zero weights split evenly", "1", []string{"0", "0"}, []string{"0.50", "0.50"}},
+		{"negative amounts", "-10", []string{"1", "1", "1"}, []string{"-3.34", "-3.33", "-3.33"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := basket.Allocate(decimal.RequireFromString(tt.amount), decimals(tt.weights...), 2)
+
+			assert.Equal(t, tt.want, fixed(shares))
+			total := decimal.Zero
+			for _, share := range shares {
+				total = total.Add(share)
+			}
+			assert.True(t, total.Equal(decimal.RequireFromString(tt.amount)))
+		})
+	}
+}
+
+func TestBasket_Normalize(t *testing.T) {
+	t.Run("assigns line IDs and normalizes the currency", func(t *testing.T) {
+		b, err := basket.Basket{Currency: "eur", Items: []basket.Item{
+			{SKU: "shirt", Quantity: 2, UnitPrice: decimal.RequireFromString("20")},
+			{LineID: "gift", SKU: "mug", Quantity: 1, UnitPrice: decimal.RequireFromString("60")},
+		}}.Normalize()
+
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", b.Currency)
+		assert.Equal(t, "1", b.Items[0].LineID)
+		assert.Equal(t, "gift", b.Items[1].LineID)
+		assert.Equal(t, "100 EUR", b.Subtotal().String())
+	})
+
+	t.Run("rejects invalid lines", func(t *testing.T) {
+		for name, item := range map[string]basket.Item{
+			"no sku":         {Quantity: 1},
+			"zero quantity":  {SKU: "shirt"},
+			"negative price": {SKU: "shirt", Quantity: 1, UnitPrice: decimal.RequireFromString("-1")},
+		} {
+			_, err := basket.Basket{Currency: "EUR", Items: []basket.Item{item}}.Normalize()
+			assert.Error(t, err, name)
+		}
+		_, err := basket.Basket{Currency: "EUR"}.Normalize()
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects duplicate line IDs", func(t *testing.T) {
+		_, err := basket.Basket{Currency: "EUR", Items: []basket.Item{
+			{LineID: "a", SKU: "shirt", Quantity: 1},
+			{LineID: "a", SKU: "mug", Quantity: 1},
+		}}.Normalize()
+
+		assert.Error(t, err)
+	})
+}
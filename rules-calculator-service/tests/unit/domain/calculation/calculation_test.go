@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,9 +28,10 @@ func TestCalculation_Complete(t *testing.T) {
 	ruleIDs := []string{"rule1"}
 	context := map[string]interface{}{"key": "value"}
 	calc, _ := calculation.NewCalculation(ruleIDs, context)
+	value := money.Money{Amount: decimal.RequireFromString("123.45"), Currency: "EUR"}
 	result := calculation.Result{
-		Value:     123.45,
-		Breakdown: map[string]float64{"rule1": 123.45},
+		Value:     value,
+		Breakdown: map[string]calculation.RuleOutcome{"rule1": {Money: value, Status: calculation.RuleStatusOK}},
 	}
 
 	// Act
@@ -37,7 +40,7 @@ func TestCalculation_Complete(t *testing.T) {
 	// Assert
 	assert.Equal(t, calculation.StatusCompleted, calc.Status())
 	assert.NotNil(t, calc.Result())
-	assert.Equal(t, 123.45, calc.Result().Value)
+	assert.Equal(t, value, calc.Result().Value)
 	assert.NotNil(t, calc.CompletedAt())
 }
 
@@ -48,10 +51,11 @@ func TestCalculation_Fail(t *testing.T) {
 	calc, _ := calculation.NewCalculation(ruleIDs, context)
 
 	// Act
-	calc.Fail()
+	calc.Fail("currencies differ")
 
 	// Assert
 	assert.Equal(t, calculation.StatusFailed, calc.Status())
+	assert.Equal(t, "currencies differ", calc.Failure())
 	assert.Nil(t, calc.Result()) // No result on failure
 	assert.NotNil(t, calc.CompletedAt())
 }
@@ -0,0 +1,79 @@
+package calculation_test
+
+import (
+	"testing"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eur(value string) money.Money {
+	return money.Money{Amount: decimal.RequireFromString(value), Currency: "EUR"}
+}
+
+func ok(value string) calculation.RuleOutcome {
+	return calculation.RuleOutcome{Money: eur(value), Status: calculation.RuleStatusOK}
+}
+
+func TestCompare(t *testing.T) {
+	t.Run("reports the change of every rule of either result", func(t *testing.T) {
+		baseline := calculation.Result{Value: eur("15"), Breakdown: map[string]calculation.RuleOutcome{
+			"kept": ok("5"), "changed": ok("10"), "removed": ok("0"),
+		}}
+		result := calculation.Result{Value: eur("20.50"), Breakdown: map[string]calculation.RuleOutcome{
+			"kept": ok("5.00"), "changed": ok("12.50"), "added": ok("3"),
+			"failed": {Status: calculation.RuleStatusFailed},
+		}}
+
+		difference := calculation.Compare(baseline, result)
+
+		assert.Equal(t, "5.5 EUR", difference.TotalDelta.String())
+		changes := map[string]calculation.Change{}
+		deltas := map[string]string{}
+		for _, rule := range difference.Rules {
+			changes[rule.RuleID] = rule.Change
+			if rule.Delta != nil {
+				deltas[rule.RuleID] = rule.Delta.String()
+			}
+		}
+		assert.Equal(t, map[string]calculation.Change{
+			"added":   calculation.ChangeAdded,
+			"changed": calculation.ChangeChanged,
+			"failed":  calculation.ChangeAdded,
+			"kept":    calculation.ChangeUnchanged,
+			"removed": calculation.ChangeRemoved,
+		}, changes)
+		assert.Equal(t, map[string]string{"added": "3 EUR", "changed": "2.5 EUR", "kept": "0 EUR", "removed": "0 EUR"}, deltas)
+		assert.Equal(t, "added", difference.Rules[0].RuleID, "rules are sorted by ID")
+	})
+
+	t.Run("matches lines by ID", func(t *testing.T) {
+		line := func(id, discount, gross string) calculation.LineResult {
+			return calculation.LineResult{LineID: id, SKU: id, Discount: eur(discount), Tax: eur("0"), Gross: eur(gross)}
+		}
+		baseline := calculation.Result{Value: eur("30"), Lines: []calculation.LineResult{line("a", "0", "10"), line("b", "0", "20")}}
+		result := calculation.Result{Value: eur("28"), Lines: []calculation.LineResult{line("c", "0", "5"), line("a", "2", "8")}}
+
+		difference := calculation.Compare(baseline, result)
+
+		require.Len(t, difference.Lines, 3)
+		assert.Equal(t, "a", difference.Lines[0].LineID)
+		assert.Equal(t, calculation.ChangeChanged, difference.Lines[0].Change)
+		assert.Equal(t, "2 EUR", difference.Lines[0].DiscountDelta.String())
+		assert.Equal(t, "-2 EUR", difference.Lines[0].GrossDelta.String())
+		assert.Equal(t, calculation.ChangeRemoved, difference.Lines[1].Change)
+		assert.Equal(t, "-20 EUR", difference.Lines[1].GrossDelta.String())
+		assert.Equal(t, calculation.ChangeAdded, difference.Lines[2].Change)
+		assert.Nil(t, difference.Lines[2].BaselineGross)
+	})
+
+	t.Run("has no delta between currencies", func(t *testing.T) {
+		baseline := calculation.Result{Value: eur("10")}
+		result := calculation.Result{Value: money.Money{Amount: decimal.RequireFromString("10"), Currency: "USD"}}
+
+		assert.Nil(t, calculation.Compare(baseline, result).TotalDelta)
+	})
+}
@@ -0,0 +1,50 @@
+package calculation_test
+
+import (
+	"testing"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredRuleError(t *testing.T) {
+	failure := func(ruleID string, code calculation.ErrorCode, optional bool) calculation.RuleFailure {
+		return calculation.RuleFailure{RuleID: ruleID, Code: code, Optional: optional, Message: "failed"}
+	}
+
+	tests := []struct {
+		name        string
+		failures    []calculation.RuleFailure
+		unavailable bool
+		timedOut    bool
+	}{
+		{
+			name:        "timeouts",
+			failures:    []calculation.RuleFailure{failure("vat", calculation.ErrorTimeout, false), failure("promo", calculation.ErrorNotEvaluated, false)},
+			unavailable: true,
+			timedOut:    true,
+		},
+		{
+			name:        "timeouts and an unavailable service",
+			failures:    []calculation.RuleFailure{failure("vat", calculation.ErrorTimeout, false), failure("promo", calculation.ErrorUnavailable, false)},
+			unavailable: true,
+		},
+		{
+			name:     "an invalid rule among unavailable ones",
+			failures: []calculation.RuleFailure{failure("vat", calculation.ErrorUnavailable, false), failure("promo", calculation.ErrorInvalidRule, false)},
+		},
+		{
+			name:        "an invalid optional rule",
+			failures:    []calculation.RuleFailure{failure("vat", calculation.ErrorUnavailable, false), failure("promo", calculation.ErrorInvalidResponse, true)},
+			unavailable: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &calculation.RequiredRuleError{Failures: tt.failures}
+
+			assert.Equal(t, tt.unavailable, err.Unavailable())
+			assert.Equal(t, tt.timedOut, err.TimedOut())
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package adapters_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/adapters"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusServer answers with the given statuses in turn, repeating the last one.
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		status := statuses[min(n, len(statuses))-1]
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			w.Write([]byte(`{"result": {"value": 12.5, "currency": "eur"}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func catalog(t *testing.T) *rules.StaticCatalog {
+	catalog, err := rules.ParseStaticCatalog([]byte(`{"rules": {
+		"rule1": {"rule_category": "PROMOTIONS", "dsl_content": "IF customer.tier = 'gold' THEN discount.percentage = 10"},
+		"vat": {"rule_category": "TAXES", "dsl_content": "IF jurisdiction.country = 'ES' THEN tax.percentage = 21", "value_key": "total_tax"}
+	}}`))
+	require.NoError(t, err)
+	return catalog
+}
+
+func options() adapters.ResilienceOptions {
+	return adapters.ResilienceOptions{
+		MaxRetries:       2,
+		BaseBackoff:      time.Millisecond,
+		MaxBackoff:       5 * time.Millisecond,
+		FailureThreshold: 3,
+		OpenTimeout:      time.Minute,
+	}
+}
+
+func ruleErrorCode(t *testing.T, err error) calculation.ErrorCode {
+	var ruleErr *calculation.RuleError
+	require.ErrorAs(t, err, &ruleErr)
+	return ruleErr.Code
+}
+
+func TestHTTPEvaluationAdapter_Evaluate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unavailable responses are retried", func(t *testing.T) {
+		server, calls := statusServer(t, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+		adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog(t), options())
+
+		value, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "12.5 EUR", value.String())
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("retries stop after the maximum", func(t *testing.T) {
+		server, calls := statusServer(t, http.StatusServiceUnavailable)
+		adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog(t), options())
+
+		_, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{})
+
+		assert.Equal(t, calculation.ErrorUnavailable, ruleErrorCode(t, err))
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("requests the service may have processed are not retried", func(t *testing.T) {
+		for _, status := range []int{http.StatusBadRequest, http.StatusInternalServerError} {
+			server, calls := statusServer(t, status)
+			adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog(t), options())
+
+			_, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{})
+
+			assert.Error(t, err)
+			assert.Equal(t, int32(1), calls.Load(), "status %d", status)
+		}
+	})
+
+	t.Run("responses cut short after the status are not retried", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Content-Length", "100")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"result": {`))
+			w.(http.Flusher).Flush()
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+		}))
+		t.Cleanup(server.Close)
+		adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog(t), options())
+
+		_, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{})
+
+		assert.Equal(t, calculation.ErrorUnavailable, ruleErrorCode(t, err))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("failures to connect are retried", func(t *testing.T) {
+		server, _ := statusServer(t, http.StatusOK)
+		server.Close()
+		adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog(t), options())
+
+		_, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{})
+
+		assert.Equal(t, calculation.ErrorUnavailable, ruleErrorCode(t, err))
+		assert.Contains(t, err.Error(), "failed to connect")
+		assert.Equal(t, adapters.BreakerOpen, adapter.Breaker().State(), "each of the three attempts failed")
+	})
+
+	t.Run("rate-limited calls do not open the breaker", func(t *testing.T) {
+		server, calls := statusServer(t, http.StatusTooManyRequests)
+		opts := options()
+		opts.MaxRetries = 0
+		adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog(t), opts)
+
+		for i := 0; i < 5; i++ {
+			_, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{})
+			assert.Equal(t, calculation.ErrorUnavailable, ruleErrorCode(t, err))
+		}
+
+		assert.Equal(t, adapters.BreakerClosed, adapter.Breaker().State())
+		assert.Equal(t, int32(5), calls.Load())
+	})
+
+	t.Run("rate-limited calls are retried after the wait the service asks for", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte(`{"result": {"value": 12.5, "currency": "eur"}}`))
+		}))
+		t.Cleanup(server.Close)
+		opts := options()
+		opts.MaxBackoff = 2 * time.Second
+		adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog(t), opts)
+
+		start := time.Now()
+		value, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "12.5 EUR", value.String())
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+
+	t.Run("waits longer than the maximum backoff are not made", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		t.Cleanup(server.Close)
+		adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog(t), options())
+
+		_, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{})
+
+		assert.Equal(t, calculation.ErrorUnavailable, ruleErrorCode(t, err))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("rejected rules are invalid rules", func(t *testing.T) {
+		server, _ := statusServer(t, http.StatusNotFound)
+		adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog(t), options())
+
+		_, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{})
+
+		assert.Equal(t, calculation.ErrorInvalidRule, ruleErrorCode(t, err))
+		assert.Equal(t, adapters.BreakerClosed, adapter.Breaker().State(), "rule errors do not open the breaker")
+	})
+
+	t.Run("the breaker opens after consecutive failures", func(t *testing.T) {
+		server, calls := statusServer(t, http.StatusServiceUnavailable)
+		opts := options()
+		opts.MaxRetries = 0
+		adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog(t), opts)
+
+		for i := 0; i < 3; i++ {
+			_, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{})
+			require.Error(t, err)
+		}
+		_, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{})
+
+		assert.Equal(t, calculation.ErrorUnavailable, ruleErrorCode(t, err))
+		assert.Contains(t, err.Error(), "circuit breaker is open")
+		assert.Equal(t, int32(3), calls.Load(), "an open breaker does not call the service")
+	})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := adapters.NewCircuitBreaker("test", 2, 10*time.Second)
+	breaker.SetClock(func() time.Time { return now })
+
+	for i := 0; i < 2; i++ {
+		require.True(t, breaker.Allow())
+		breaker.Record(false)
+	}
+	assert.Equal(t, adapters.BreakerOpen, breaker.State())
+	assert.False(t, breaker.Allow())
+
+	now = now.Add(10 * time.Second)
+	assert.True(t, breaker.Allow(), "a trial call is let through after the open timeout")
+	assert.Equal(t, adapters.BreakerHalfOpen, breaker.State())
+	assert.False(t, breaker.Allow(), "only one trial call at a time")
+
+	breaker.Record(false)
+	assert.Equal(t, adapters.BreakerOpen, breaker.State(), "a failed trial reopens the breaker")
+
+	now = now.Add(10 * time.Second)
+	require.True(t, breaker.Allow())
+	breaker.Record(true)
+	assert.Equal(t, adapters.BreakerClosed, breaker.State())
+	assert.True(t, breaker.Allow())
+}
+
+func TestEvaluationAdapters_DryRun(t *testing.T) {
+	var dryRuns []bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			DryRun bool `json:"dry_run"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		dryRuns = append(dryRuns, request.DryRun)
+		w.Write([]byte(`{"result": {"value": 12.5, "currency": "eur"}}`))
+	}))
+	t.Cleanup(server.Close)
+	adapter := adapters.NewHTTPEvaluationAdapter(server.URL, catalog(t), options())
+
+	_, err := adapter.Evaluate(context.Background(), "rule1", map[string]interface{}{})
+	require.NoError(t, err)
+	_, err = adapter.Evaluate(calculation.WithDryRun(context.Background()), "rule1", map[string]interface{}{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []bool{false, true}, dryRuns, "the evaluation of a dry run asks the service to skip side effects")
+}
@@ -0,0 +1,136 @@
+package adapters_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	evaluationv1 "github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/api/proto/gen/evaluationv1"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/adapters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// fakeEvaluationServer records the last request and answers with a fixed result.
+type fakeEvaluationServer struct {
+	evaluationv1.UnimplementedEvaluationServiceServer
+	lastRequest  *evaluationv1.EvaluateRuleRequest
+	lastDeadline bool
+	result       map[string]interface{}
+	err          error
+}
+
+func (s *fakeEvaluationServer) EvaluateRule(ctx context.Context, req *evaluationv1.EvaluateRuleRequest) (*evaluationv1.EvaluateRuleResponse, error) {
+	s.lastRequest = req
+	_, s.lastDeadline = ctx.Deadline()
+	if s.err != nil {
+		return nil, s.err
+	}
+	result, err := structpb.NewStruct(s.result)
+	if err != nil {
+		return nil, err
+	}
+	return &evaluationv1.EvaluateRuleResponse{Result: result}, nil
+}
+
+func newAdapter(t *testing.T, fake *fakeEvaluationServer) *adapters.GRPCEvaluationAdapter {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	evaluationv1.RegisterEvaluationServiceServer(server, fake)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	adapter := adapters.NewGRPCEvaluationAdapterWithClient(conn, evaluationv1.NewEvaluationServiceClient(conn), catalog(t))
+	t.Cleanup(func() { adapter.Close() })
+	return adapter
+}
+
+func TestGRPCEvaluationAdapter_Evaluate(t *testing.T) {
+	fake := &fakeEvaluationServer{result: map[string]interface{}{"value": 12.5, "currency": "eur"}}
+	adapter := newAdapter(t, fake)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	value, err := adapter.Evaluate(ctx, "rule1", map[string]interface{}{"customer_tier": "gold"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "12.5", value.Amount.String())
+	assert.Equal(t, "EUR", value.Currency)
+	assert.Equal(t, "rule1", fake.lastRequest.RuleId)
+	assert.Equal(t, "PROMOTIONS", fake.lastRequest.RuleCategory)
+	assert.NotEmpty(t, fake.lastRequest.DslContent)
+	assert.Equal(t, map[string]interface{}{"customer_tier": "gold"}, fake.lastRequest.Context.AsMap())
+	assert.True(t, fake.lastDeadline, "the caller's deadline should be propagated")
+}
+
+func TestGRPCEvaluationAdapter_Evaluate_MissingValue(t *testing.T) {
+	adapter := newAdapter(t, &fakeEvaluationServer{result: map[string]interface{}{"eligible": false}})
+
+	_, err := adapter.Evaluate(context.Background(), "rule1", map[string]interface{}{})
+
+	var ruleErr *calculation.RuleError
+	require.ErrorAs(t, err, &ruleErr)
+	assert.Equal(t, calculation.ErrorInvalidResponse, ruleErr.Code)
+	assert.Equal(t, "evaluation result has no value", ruleErr.Message)
+}
+
+func TestGRPCEvaluationAdapter_Evaluate_ValueKey(t *testing.T) {
+	adapter := newAdapter(t, &fakeEvaluationServer{result: map[string]interface{}{
+		"total_tax": 21.0,
+		"amounts":   map[string]interface{}{"total_tax": map[string]interface{}{"amount": "21.00", "currency": "EUR"}},
+	}})
+
+	value, err := adapter.Evaluate(context.Background(), "vat", map[string]interface{}{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "21 EUR", value.String())
+}
+
+func TestGRPCEvaluationAdapter_Evaluate_UnknownRule(t *testing.T) {
+	fake := &fakeEvaluationServer{}
+	adapter := newAdapter(t, fake)
+
+	_, err := adapter.Evaluate(context.Background(), "missing", map[string]interface{}{})
+
+	var ruleErr *calculation.RuleError
+	require.ErrorAs(t, err, &ruleErr)
+	assert.Equal(t, calculation.ErrorInvalidRule, ruleErr.Code)
+	assert.Nil(t, fake.lastRequest, "rules missing from the catalog should not be sent")
+}
+
+func TestGRPCEvaluationAdapter_Evaluate_ClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want calculation.ErrorCode
+	}{
+		{"invalid argument", status.Error(codes.InvalidArgument, "unknown rule"), calculation.ErrorInvalidRule},
+		{"unavailable", status.Error(codes.Unavailable, "overloaded"), calculation.ErrorUnavailable},
+		{"deadline exceeded", status.Error(codes.DeadlineExceeded, "too slow"), calculation.ErrorTimeout},
+		{"internal", status.Error(codes.Internal, "boom"), calculation.ErrorEvaluationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := newAdapter(t, &fakeEvaluationServer{err: tt.err})
+
+			_, err := adapter.Evaluate(context.Background(), "rule1", map[string]interface{}{})
+
+			var ruleErr *calculation.RuleError
+			require.ErrorAs(t, err, &ruleErr)
+			assert.Equal(t, tt.want, ruleErr.Code)
+		})
+	}
+}
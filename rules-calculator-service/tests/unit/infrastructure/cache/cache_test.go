@@ -0,0 +1,116 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/application"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/calculation"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/cache"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func result(value string) calculation.Result {
+	total := money.Money{Amount: decimal.RequireFromString(value), Currency: "EUR"}
+	return calculation.Result{
+		Value:     total,
+		Breakdown: map[string]calculation.RuleOutcome{"rule1": {Money: total, Status: calculation.RuleStatusOK}},
+	}
+}
+
+// testResultCache runs the behaviour every backend shares.
+func testResultCache(t *testing.T, newCache func(t *testing.T) application.ResultCache) {
+	ctx := context.Background()
+
+	t.Run("returns cached results and misses unknown keys", func(t *testing.T) {
+		c := newCache(t)
+		require.NoError(t, c.Put(ctx, "a", []string{"rule1"}, result("15"), 0))
+
+		cached, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		require.NotNil(t, cached)
+		assert.Equal(t, "15 EUR", cached.Value.String())
+		assert.Equal(t, calculation.RuleStatusOK, cached.Breakdown["rule1"].Status)
+
+		missing, err := c.Get(ctx, "b")
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("invalidates the results of a rule only", func(t *testing.T) {
+		c := newCache(t)
+		require.NoError(t, c.Put(ctx, "a", []string{"rule1", "rule2"}, result("15"), 0))
+		require.NoError(t, c.Put(ctx, "b", []string{"rule3"}, result("5"), 0))
+
+		require.NoError(t, c.InvalidateRules(ctx, "rule2"))
+
+		invalidated, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.Nil(t, invalidated)
+		kept, err := c.Get(ctx, "b")
+		require.NoError(t, err)
+		assert.NotNil(t, kept)
+	})
+
+	t.Run("drops results calculated before an invalidation", func(t *testing.T) {
+		c := newCache(t)
+		generation, err := c.Generation(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, c.InvalidateRules(ctx, "rule9"))
+		require.NoError(t, c.Put(ctx, "a", []string{"rule1"}, result("15"), generation))
+
+		stale, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.Nil(t, stale)
+	})
+}
+
+func TestMemoryCache(t *testing.T) {
+	testResultCache(t, func(t *testing.T) application.ResultCache {
+		return cache.NewMemoryCache(10, time.Minute)
+	})
+
+	ctx := context.Background()
+	t.Run("evicts the least recently used result", func(t *testing.T) {
+		c := cache.NewMemoryCache(2, 0)
+		require.NoError(t, c.Put(ctx, "a", nil, result("1"), 0))
+		require.NoError(t, c.Put(ctx, "b", nil, result("2"), 0))
+		_, _ = c.Get(ctx, "a")
+		require.NoError(t, c.Put(ctx, "c", nil, result("3"), 0))
+
+		evicted, _ := c.Get(ctx, "b")
+		kept, _ := c.Get(ctx, "a")
+		assert.Nil(t, evicted)
+		assert.NotNil(t, kept)
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("expires results after the TTL", func(t *testing.T) {
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		c := cache.NewMemoryCache(10, time.Minute)
+		c.SetClock(func() time.Time { return now })
+		require.NoError(t, c.Put(ctx, "a", nil, result("1"), 0))
+
+		now = now.Add(time.Minute)
+		expired, err := c.Get(ctx, "a")
+
+		require.NoError(t, err)
+		assert.Nil(t, expired)
+	})
+}
+
+func TestRedisCache(t *testing.T) {
+	testResultCache(t, func(t *testing.T) application.ResultCache {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		return cache.NewRedisCache(client, "test", time.Minute)
+	})
+}
@@ -0,0 +1,55 @@
+package rates_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/domain/money"
+	"github.com/juanpablolazaro/ENGINE-RULES-SP/rules-calculator-service/internal/infrastructure/rates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("derives cross rates through the base currency", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rates.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"base": "EUR", "rates": {"USD": "1.25", "GBP": "0.5"}}`), 0o600))
+		provider, err := rates.NewStaticProvider(path)
+		require.NoError(t, err)
+
+		rate, err := provider.Rate(ctx, "EUR", "USD")
+		require.NoError(t, err)
+		assert.Equal(t, "1.25", rate.String())
+
+		rate, err = provider.Rate(ctx, "GBP", "USD")
+		require.NoError(t, err)
+		assert.Equal(t, "2.5", rate.String())
+
+		_, err = provider.Rate(ctx, "EUR", "JPY")
+		var unknownRate *money.UnknownRateError
+		assert.ErrorAs(t, err, &unknownRate)
+	})
+
+	t.Run("uses the bundled rates by default", func(t *testing.T) {
+		provider, err := rates.NewStaticProvider("")
+		require.NoError(t, err)
+
+		_, err = provider.Rate(ctx, "USD", "GBP")
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects invalid files", func(t *testing.T) {
+		for _, data := range []string{
+			`{"base": "euro", "rates": {}}`,
+			`{"base": "EUR", "rates": {"USD": "0"}}`,
+			`{"base": "EUR", "rates": {"US": "1.1"}}`,
+		} {
+			_, err := rates.ParseStaticProvider([]byte(data))
+			assert.Error(t, err, data)
+		}
+	})
+}
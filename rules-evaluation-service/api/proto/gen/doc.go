@@ -0,0 +1,4 @@
+// Package evaluationv1 holds the Go types and gRPC stubs generated from evaluation.v1.proto.
+package evaluationv1
+
+//go:generate protoc -I .. --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative evaluation.v1.proto
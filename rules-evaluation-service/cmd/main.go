@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,52 +12,162 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 
-	// "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
+	evaluationv1 "rules-evaluation-service/api/proto/gen"
 	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/coupon"
+	"rules-evaluation-service/internal/domain/decision"
 	"rules-evaluation-service/internal/domain/evaluation"
 	"rules-evaluation-service/internal/infrastructure/config"
+	"rules-evaluation-service/internal/infrastructure/decisionlog"
+	"rules-evaluation-service/internal/infrastructure/dsl"
+	"rules-evaluation-service/internal/infrastructure/messaging/nats"
+	"rules-evaluation-service/internal/infrastructure/persistence/memory"
+	persistence "rules-evaluation-service/internal/infrastructure/persistence/postgres"
+	"rules-evaluation-service/internal/infrastructure/persistence/postgres/migrations"
+	"rules-evaluation-service/internal/infrastructure/ratelimit"
 	"rules-evaluation-service/internal/infrastructure/strategies"
 
-	// "rules-evaluation-service/internal/infrastructure/telemetry"
+	"rules-evaluation-service/internal/infrastructure/telemetry"
+	"rules-evaluation-service/internal/interfaces/grpc/server"
 	"rules-evaluation-service/internal/interfaces/rest/handlers"
+	"rules-evaluation-service/internal/interfaces/rest/middleware"
 )
 
 func main() {
-	cfg := config.DefaultConfig()
-
-	// Setup OpenTelemetry - temporarily disabled
-	// tp, err := telemetry.InitTracer(cfg.Telemetry.ServiceName, cfg.Telemetry.Exporter)
-	// if err != nil {
-	// 	log.Fatalf("failed to initialize tracer: %v", err)
-	// }
-	// defer func() {
-	// 	if err := tp.Shutdown(context.Background()); err != nil {
-	// 		log.Printf("Error shutting down tracer provider: %v", err)
-	// 	}
-	// }()
+	cfg, err := config.Load(strategies.DefaultResolutions())
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+
+	// Setup OpenTelemetry
+	tp, err := telemetry.InitTracer(cfg.Telemetry.ServiceName, cfg.Telemetry.Exporter)
+	if err != nil {
+		log.Fatalf("failed to initialize tracer: %v", err)
+	}
+	defer func() {
+		if err := tp.Shutdown(context.Background()); err != nil {
+			log.Printf("Error shutting down tracer provider: %v", err)
+		}
+	}()
 
 	// Infrastructure
-	promotionsStrategy := &strategies.PromotionsStrategy{}
-	taxesStrategy := &strategies.TaxesStrategy{}
+	var couponStore coupon.UsageStore
+	switch cfg.Coupons.Store {
+	case "postgres":
+		db, err := gorm.Open(postgres.Open(cfg.Database.DSN), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("failed to connect to database: %v", err)
+		}
+		if err := migrations.ApplyMigrations(db); err != nil {
+			log.Fatalf("failed to apply migrations: %v", err)
+		}
+		couponStore = persistence.NewCouponUsageStore(db)
+	default:
+		log.Println("Using in-memory coupon usage store")
+		couponStore = memory.NewCouponUsageStore()
+	}
+
+	rounding := make(map[string]strategies.Rounding, len(cfg.Taxes.Rounding))
+	for currency, rc := range cfg.Taxes.Rounding {
+		mode, err := strategies.ParseRoundingMode(rc.Mode)
+		if err != nil {
+			log.Fatalf("invalid rounding for %s: %v", currency, err)
+		}
+		rounding[currency] = strategies.Rounding{Mode: mode, Decimals: rc.Decimals}
+	}
+
+	dslLimits := dsl.Limits{
+		MaxDepth:       cfg.DSL.MaxDepth,
+		MaxNodes:       cfg.DSL.MaxNodes,
+		MaxSteps:       cfg.DSL.MaxSteps,
+		Timeout:        cfg.DSL.Timeout,
+		MaxResultBytes: cfg.DSL.MaxResultBytes,
+	}
+
+	// Strategies register themselves; build the ones that are not disabled.
+	registered, descriptors, err := strategies.Build(strategies.Dependencies{
+		CouponStore:          couponStore,
+		CouponReservationTTL: cfg.Coupons.ReservationTTL,
+		Rounding:             rounding,
+		Limits:               &dslLimits,
+	}, cfg.Strategies.Disabled)
+	if err != nil {
+		log.Fatalf("failed to build evaluation strategies: %v", err)
+	}
 
 	// Domain
-	evaluationService := evaluation.NewService(map[string]evaluation.EvaluationStrategy{
-		"PROMOTIONS": promotionsStrategy,
-		"TAXES":      taxesStrategy,
-	})
+	evaluationService := evaluation.NewService(registered)
+	var fallbackDescriptor *evaluation.StrategyDescriptor
+	if cfg.Strategies.Fallback {
+		generic := strategies.NewGenericStrategy()
+		generic.SetLimits(dslLimits)
+		evaluationService = evaluation.NewServiceWithFallback(registered, generic)
+		fallbackDescriptor = &strategies.GenericDescriptor
+	}
+
+	// Decision log, published in the background so that evaluations are not slowed down
+	var decisionLogger decision.Logger = decision.NoOpLogger{}
+	if cfg.DecisionLog.Enabled {
+		publisher, err := nats.NewDecisionPublisher(cfg.DecisionLog.NATSURL, cfg.DecisionLog.Subject, cfg.Telemetry.ServiceName)
+		if err != nil {
+			log.Printf("Warning: failed to create decision publisher, decision log disabled: %v", err)
+		} else {
+			defer publisher.Close()
+			batchingLogger := decisionlog.NewBatchingLogger(publisher, decisionlog.Options{
+				SampleRate:    cfg.DecisionLog.SampleRate,
+				BatchSize:     cfg.DecisionLog.BatchSize,
+				FlushInterval: cfg.DecisionLog.FlushInterval,
+				BufferSize:    cfg.DecisionLog.BufferSize,
+			})
+			defer batchingLogger.Close()
+			decisionLogger = batchingLogger
+		}
+	}
+	decisionRecorder := application.NewDecisionRecorder(decisionLogger, cfg.DecisionLog.CustomerHashSalt)
 
 	// Application
-	evaluateRuleHandler := application.NewEvaluateRuleHandler(evaluationService)
+	evaluateRuleHandler := application.NewEvaluateRuleHandler(evaluationService, decisionRecorder)
+	explainRuleHandler := application.NewExplainRuleHandler(evaluateRuleHandler)
+	evaluateCategoryHandler := application.NewEvaluateCategoryHandler(evaluationService, cfg.Resolution, decisionRecorder)
+	settleCouponReservationHandler := application.NewSettleCouponReservationHandler(couponStore)
+	listStrategiesHandler := application.NewListStrategiesHandler(descriptors, fallbackDescriptor)
+
+	// Rate limiting and load shedding of the evaluation endpoints
+	var clientLimiter *ratelimit.ClientLimiter
+	if cfg.RateLimit.Enabled {
+		clientLimiter = ratelimit.NewClientLimiter(cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst, cfg.RateLimit.IdleTTL, cfg.RateLimit.MaxClients)
+	}
+	var concurrencyLimiter *ratelimit.ConcurrencyLimiter
+	if cfg.LoadShedding.Enabled {
+		concurrencyLimiter = ratelimit.NewConcurrencyLimiter(ratelimit.ConcurrencyOptions{
+			TargetP99:    cfg.LoadShedding.TargetP99,
+			InitialLimit: cfg.LoadShedding.InitialLimit,
+			MinLimit:     cfg.LoadShedding.MinLimit,
+			MaxLimit:     cfg.LoadShedding.MaxLimit,
+			Window:       cfg.LoadShedding.Window,
+		})
+	}
+	guard := ratelimit.NewGuard(clientLimiter, concurrencyLimiter)
 
 	// Interfaces
-	evaluationHandler := handlers.NewEvaluationHandler(evaluateRuleHandler)
+	evaluationHandler := handlers.NewEvaluationHandler(evaluateRuleHandler, evaluateCategoryHandler)
+	couponHandler := handlers.NewCouponHandler(settleCouponReservationHandler)
+	strategyHandler := handlers.NewStrategyHandler(listStrategiesHandler)
 
 	router := gin.New()
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
-	// router.Use(otelgin.Middleware(cfg.Telemetry.ServiceName))
+	router.Use(otelgin.Middleware(cfg.Telemetry.ServiceName))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -66,15 +177,24 @@ func main() {
 	// Prometheus metrics endpoint
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	limits := middleware.Limits(guard)
 	v1 := router.Group("/v1")
 	{
-		v1.POST("/evaluate", evaluationHandler.EvaluateRule)
+		v1.POST("/evaluate", limits, evaluationHandler.EvaluateRule)
+		v1.POST("/evaluate/category", limits, evaluationHandler.EvaluateCategory)
+		v1.POST("/coupons/reservations/:id/commit", couponHandler.CommitReservation)
+		v1.POST("/coupons/reservations/:id/release", couponHandler.ReleaseReservation)
+		v1.GET("/strategies", strategyHandler.ListStrategies)
 	}
 
 	// API Gateway routes
 	apiV1 := router.Group("/api/v1")
 	{
-		apiV1.POST("/evaluate", evaluationHandler.EvaluateRule)
+		apiV1.POST("/evaluate", limits, evaluationHandler.EvaluateRule)
+		apiV1.POST("/evaluate/category", limits, evaluationHandler.EvaluateCategory)
+		apiV1.POST("/coupons/reservations/:id/commit", couponHandler.CommitReservation)
+		apiV1.POST("/coupons/reservations/:id/release", couponHandler.ReleaseReservation)
+		apiV1.GET("/strategies", strategyHandler.ListStrategies)
 	}
 
 	srv := &http.Server{
@@ -82,6 +202,29 @@ func main() {
 		Handler: router,
 	}
 
+	// gRPC API, sharing the application handlers with the REST API
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(server.RecoveryUnaryInterceptor(), server.LimitsUnaryInterceptor(guard)),
+		grpc.ChainStreamInterceptor(server.RecoveryStreamInterceptor(), server.LimitsStreamInterceptor(guard)),
+	)
+	evaluationv1.RegisterEvaluationServiceServer(grpcServer, server.NewEvaluationServer(evaluateRuleHandler, explainRuleHandler))
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(evaluationv1.EvaluationService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	reflection.Register(grpcServer)
+
+	grpcListener, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+	if err != nil {
+		log.Fatalf("failed to listen on gRPC port: %v", err)
+	}
+	go func() {
+		log.Printf("Starting gRPC server on port %s", cfg.Server.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+
 	// Graceful shutdown
 	go func() {
 		log.Printf("Starting Rules Evaluation Service on port %s", cfg.Server.Port)
@@ -97,6 +240,8 @@ func main() {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	healthServer.Shutdown()
+	grpcServer.GracefulStop()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
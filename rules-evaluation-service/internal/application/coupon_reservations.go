@@ -0,0 +1,52 @@
+package application
+
+import (
+	"context"
+	"errors"
+
+	"rules-evaluation-service/internal/domain/coupon"
+	"rules-evaluation-service/internal/domain/shared"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SettleCouponReservationCommand represents the command to commit or release a coupon reservation.
+type SettleCouponReservationCommand struct {
+	ReservationID string
+	// Commit makes the usage permanent; otherwise the reservation is released.
+	Commit bool
+}
+
+// SettleCouponReservationHandler settles the coupon reservations made during evaluation.
+type SettleCouponReservationHandler struct {
+	store coupon.UsageStore
+}
+
+// NewSettleCouponReservationHandler creates a new handler.
+func NewSettleCouponReservationHandler(store coupon.UsageStore) *SettleCouponReservationHandler {
+	return &SettleCouponReservationHandler{store: store}
+}
+
+// Handle executes the command.
+func (h *SettleCouponReservationHandler) Handle(ctx context.Context, cmd SettleCouponReservationCommand) error {
+	tr := otel.Tracer("application")
+	ctx, span := tr.Start(ctx, "SettleCouponReservationHandler.Handle")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("coupon.reservation_id", cmd.ReservationID),
+		attribute.Bool("coupon.commit", cmd.Commit),
+	)
+
+	var err error
+	if cmd.Commit {
+		err = h.store.Commit(ctx, cmd.ReservationID)
+	} else {
+		err = h.store.Release(ctx, cmd.ReservationID)
+	}
+	if errors.Is(err, coupon.ErrReservationNotFound) {
+		return shared.NewNotFoundError("coupon reservation not found or expired", err)
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package application
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"rules-evaluation-service/internal/domain/decision"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/dsl"
+)
+
+// DecisionRecorder turns evaluations into decision records and hands them to the decision logger.
+// A nil recorder records nothing.
+type DecisionRecorder struct {
+	logger           decision.Logger
+	customerHashSalt string
+}
+
+// NewDecisionRecorder creates a recorder. The salt is prepended to customer IDs before hashing.
+func NewDecisionRecorder(logger decision.Logger, customerHashSalt string) *DecisionRecorder {
+	return &DecisionRecorder{logger: logger, customerHashSalt: customerHashSalt}
+}
+
+// recordRule records a single rule decision. cmd holds the rule version that was evaluated.
+func (r *DecisionRecorder) recordRule(cmd EvaluateRuleCommand, variant decision.Variant, result evaluation.Result, err error, latency time.Duration, divergence *evaluation.Divergence) {
+	if r == nil {
+		return
+	}
+	record := r.newRecord(cmd.RuleCategory, cmd.Context, cmd.AsOf, variant, latency, divergence)
+	if cmd.RuleID != "" {
+		record.Rules = []decision.RuleRef{{ID: cmd.RuleID, Version: cmd.RuleVersion}}
+	}
+	switch {
+	case err != nil:
+		record.Outcome, record.Reason = decision.OutcomeError, err.Error()
+	case result.IsEligible():
+		record.Outcome, record.Actions = decision.OutcomeEligible, actionsOf(result)
+	default:
+		record.Outcome = decision.OutcomeNotEligible
+		record.Reason, _ = result["reason"].(string)
+	}
+	r.logger.Log(record)
+}
+
+// recordCategory records a category decision. cmd holds the rule versions that were evaluated.
+func (r *DecisionRecorder) recordCategory(cmd EvaluateCategoryCommand, variant decision.Variant, resolution *evaluation.Resolution, err error, latency time.Duration, divergence *evaluation.Divergence) {
+	if r == nil {
+		return
+	}
+	record := r.newRecord(cmd.RuleCategory, cmd.Context, cmd.AsOf, variant, latency, divergence)
+	for _, rule := range cmd.Rules {
+		record.Rules = append(record.Rules, decision.RuleRef{ID: rule.RuleID, Version: rule.RuleVersion})
+	}
+	switch {
+	case err != nil:
+		record.Outcome, record.Reason = decision.OutcomeError, err.Error()
+	case len(resolution.Applied) > 0:
+		record.Outcome, record.Actions = decision.OutcomeEligible, actionsOf(resolution.Result())
+		for _, applied := range resolution.Applied {
+			record.AppliedRuleIDs = append(record.AppliedRuleIDs, applied.RuleID)
+		}
+	default:
+		record.Outcome = decision.OutcomeNotEligible
+	}
+	r.logger.Log(record)
+}
+
+func (r *DecisionRecorder) newRecord(category string, evalContext evaluation.Context, asOf *time.Time, variant decision.Variant, latency time.Duration, divergence *evaluation.Divergence) decision.Record {
+	record := decision.Record{
+		DecisionID:   uuid.NewString(),
+		Timestamp:    time.Now().UTC(),
+		Category:     category,
+		CustomerHash: decision.HashCustomerID(customerID(evalContext), r.customerHashSalt),
+		LatencyMs:    float64(latency.Microseconds()) / 1000,
+		Variant:      variant,
+	}
+	if asOf != nil {
+		record.AsOf = asOf.UTC()
+	}
+	if divergence != nil {
+		if divergence.Eligibility {
+			record.DivergentFields = append(record.DivergentFields, "eligible")
+		}
+		record.DivergentFields = append(record.DivergentFields, divergence.Fields...)
+	}
+	return record
+}
+
+// customerID returns the customer ID of an evaluation context, used for hashing and canary slices.
+func customerID(evalContext evaluation.Context) string {
+	id, _ := dsl.LookupString(evalContext, "customer.id")
+	return id
+}
+
+// actionsOf returns the result without its eligibility flags, i.e. what the decision applied.
+func actionsOf(result evaluation.Result) map[string]interface{} {
+	actions := make(map[string]interface{}, len(result))
+	for key, value := range result {
+		if key != "eligible" && key != "reason" {
+			actions[key] = value
+		}
+	}
+	return actions
+}
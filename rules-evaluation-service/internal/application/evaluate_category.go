@@ -0,0 +1,162 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"rules-evaluation-service/internal/domain/decision"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/domain/shared"
+	"rules-evaluation-service/internal/infrastructure/telemetry"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EvaluateCategoryCommand represents the command to evaluate several rules of one category.
+type EvaluateCategoryCommand struct {
+	RuleCategory string
+	Rules        []evaluation.RuleSpec
+	Context      evaluation.Context
+	AsOf         *time.Time // optional time the rules consider to be now, to replay a past decision
+}
+
+// EvaluateCategoryResult represents the combined result of a category evaluation.
+type EvaluateCategoryResult struct {
+	Resolution *evaluation.Resolution
+	AsOf       time.Time // the time the rules considered to be now
+}
+
+// FallbackResolutionKey is the resolution configuration key used for categories evaluated by the fallback strategy.
+const FallbackResolutionKey = "*"
+
+// EvaluateCategoryHandler handles the evaluation of all the rules of a category.
+type EvaluateCategoryHandler struct {
+	evaluationService *evaluation.Service
+	resolution        map[string]evaluation.ResolutionConfig
+	recorder          *DecisionRecorder
+}
+
+// NewEvaluateCategoryHandler creates a new handler with the resolution configuration of each category.
+// The recorder may be nil when decisions are not logged.
+func NewEvaluateCategoryHandler(evaluationService *evaluation.Service, resolution map[string]evaluation.ResolutionConfig, recorder *DecisionRecorder) *EvaluateCategoryHandler {
+	return &EvaluateCategoryHandler{evaluationService: evaluationService, resolution: resolution, recorder: recorder}
+}
+
+// Handle executes the command.
+func (h *EvaluateCategoryHandler) Handle(ctx context.Context, cmd EvaluateCategoryCommand) (*EvaluateCategoryResult, error) {
+	tr := otel.Tracer("application")
+	ctx, span := tr.Start(ctx, "EvaluateCategoryHandler.Handle")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("rule.category", cmd.RuleCategory),
+		attribute.Int("rules.count", len(cmd.Rules)),
+	)
+
+	if len(cmd.Rules) == 0 {
+		return nil, shared.NewValidationError("at least one rule is required", nil)
+	}
+	cfg, ok := h.resolution[cmd.RuleCategory]
+	if !ok && h.evaluationService.HasFallback() {
+		cfg, ok = h.resolution[FallbackResolutionKey]
+	}
+	if !ok {
+		return nil, shared.NewValidationError(fmt.Sprintf("no resolution policy configured for category: %s", cmd.RuleCategory), nil)
+	}
+
+	canary := false
+	for _, rule := range cmd.Rules {
+		if rule.Candidate != nil {
+			if err := rule.Candidate.Validate(); err != nil {
+				return nil, err
+			}
+			canary = canary || rule.Candidate.Mode == evaluation.RolloutCanary
+		}
+	}
+
+	ctx, asOf := h.evaluationService.Pin(ctx, cmd.AsOf)
+	cmd.AsOf = &asOf
+
+	// Customers in the canary slice of a rule are served its candidate version.
+	served, variant := cmd, decision.Variant("")
+	if canary {
+		if rules, substituted := evaluation.CanaryRules(cmd.Rules, customerID(cmd.Context)); substituted {
+			served.Rules, variant = rules, decision.VariantCanary
+			telemetry.CanaryEvaluationsTotal.WithLabelValues(cmd.RuleCategory, "candidate").Inc()
+		} else {
+			telemetry.CanaryEvaluationsTotal.WithLabelValues(cmd.RuleCategory, "active").Inc()
+		}
+		span.SetAttributes(attribute.Bool("rules.canary", variant == decision.VariantCanary))
+	}
+
+	startTime := time.Now()
+	resolution, err := h.evaluationService.EvaluateCategory(ctx, cmd.RuleCategory, served.Rules, cmd.Context, cfg)
+	telemetry.EvaluationsTotal.WithLabelValues(cmd.RuleCategory, strconv.FormatBool(err == nil)).Inc()
+	telemetry.EvaluationDuration.WithLabelValues(cmd.RuleCategory).Observe(time.Since(startTime).Seconds())
+	h.recorder.recordCategory(served, variant, resolution, err, time.Since(startTime), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, suppressed := range resolution.Suppressed {
+		telemetry.SuppressedRulesTotal.WithLabelValues(cmd.RuleCategory, string(suppressed.Reason)).Inc()
+	}
+	h.compensate(ctx, cmd.RuleCategory, resolution)
+	span.SetAttributes(attribute.Int("rules.applied", len(resolution.Applied)))
+
+	if rules, ok := evaluation.ShadowRules(served.Rules); ok {
+		shadow := served
+		shadow.Rules = rules
+		h.evaluateShadow(ctx, shadow, cfg, resolution)
+	}
+
+	return &EvaluateCategoryResult{Resolution: resolution, AsOf: asOf}, nil
+}
+
+// compensate undoes the side effects of the rules that matched but lost the resolution, such as the
+// coupon usage they reserved, so that only the applied rules keep theirs. Failures are recorded on the
+// span: the side effects of evaluations, like coupon reservations, expire on their own.
+func (h *EvaluateCategoryHandler) compensate(ctx context.Context, category string, resolution *evaluation.Resolution) {
+	strategy, err := h.evaluationService.GetStrategyForCategory(category)
+	if err != nil {
+		return
+	}
+	compensator, ok := strategy.(evaluation.Compensator)
+	if !ok {
+		return
+	}
+	for _, suppressed := range resolution.Suppressed {
+		if suppressed.Result == nil {
+			continue
+		}
+		if err := compensator.Compensate(ctx, suppressed.Result); err != nil {
+			trace.SpanFromContext(ctx).RecordError(err, trace.WithAttributes(attribute.String("rule.id", suppressed.RuleID)))
+		}
+	}
+}
+
+// evaluateShadow resolves the category again with the shadow candidates in place of their active versions
+// and records how the combined result diverges from the served one. The candidates run dry and the shadow
+// resolution is never returned.
+func (h *EvaluateCategoryHandler) evaluateShadow(ctx context.Context, shadow EvaluateCategoryCommand, cfg evaluation.ResolutionConfig, served *evaluation.Resolution) {
+	ctx, span := otel.Tracer("application").Start(ctx, "EvaluateCategoryHandler.evaluateShadow")
+	defer span.End()
+	ctx = evaluation.WithDryRun(ctx)
+
+	startTime := time.Now()
+	resolution, err := h.evaluationService.EvaluateCategory(ctx, shadow.RuleCategory, shadow.Rules, shadow.Context, cfg)
+	if err != nil {
+		telemetry.ShadowEvaluationsTotal.WithLabelValues(shadow.RuleCategory, "error").Inc()
+		h.recorder.recordCategory(shadow, decision.VariantShadow, nil, err, time.Since(startTime), nil)
+		return
+	}
+
+	divergence := evaluation.Compare(served.Result(), resolution.Result())
+	telemetry.ShadowEvaluationsTotal.WithLabelValues(shadow.RuleCategory, shadowOutcome(divergence)).Inc()
+	span.SetAttributes(attribute.Bool("rules.diverged", divergence.Diverged()))
+	h.recorder.recordCategory(shadow, decision.VariantShadow, resolution, nil, time.Since(startTime), &divergence)
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"rules-evaluation-service/internal/domain/decision"
 	"rules-evaluation-service/internal/domain/evaluation"
 	"rules-evaluation-service/internal/infrastructure/telemetry"
 
@@ -14,52 +15,131 @@ import (
 
 // EvaluateRuleCommand represents the command to evaluate a rule.
 type EvaluateRuleCommand struct {
+	RuleID       string // optional, for tracing and the decision log
+	RuleVersion  int    // optional, for the decision log
 	RuleCategory string
 	DSLContent   string
 	Context      evaluation.Context
+	Candidate    *evaluation.Candidate // optional version rolled out in shadow or canary mode
+	AsOf         *time.Time            // optional time the rule considers to be now, to replay a past decision
+	DryRun       bool                  // evaluates without side effects, such as coupon reservations
 }
 
 // EvaluateRuleResult represents the result of a rule evaluation.
 type EvaluateRuleResult struct {
 	Result evaluation.Result
+	AsOf   time.Time // the time the rule considered to be now
 }
 
 // EvaluateRuleHandler handles the evaluation of a rule.
 type EvaluateRuleHandler struct {
 	evaluationService *evaluation.Service
+	recorder          *DecisionRecorder
 }
 
-// NewEvaluateRuleHandler creates a new handler.
-func NewEvaluateRuleHandler(evaluationService *evaluation.Service) *EvaluateRuleHandler {
-	return &EvaluateRuleHandler{evaluationService: evaluationService}
+// NewEvaluateRuleHandler creates a new handler. The recorder may be nil when decisions are not logged.
+func NewEvaluateRuleHandler(evaluationService *evaluation.Service, recorder *DecisionRecorder) *EvaluateRuleHandler {
+	return &EvaluateRuleHandler{evaluationService: evaluationService, recorder: recorder}
 }
 
 // Handle executes the command.
 func (h *EvaluateRuleHandler) Handle(ctx context.Context, cmd EvaluateRuleCommand) (*EvaluateRuleResult, error) {
 	tr := otel.Tracer("application")
-	_, span := tr.Start(ctx, "EvaluateRuleHandler.Handle")
+	ctx, span := tr.Start(ctx, "EvaluateRuleHandler.Handle")
 	defer span.End()
 
 	span.SetAttributes(attribute.String("rule.category", cmd.RuleCategory))
+	if cmd.RuleID != "" {
+		span.SetAttributes(attribute.String("rule.id", cmd.RuleID))
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if cmd.Candidate != nil {
+		if err := cmd.Candidate.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, asOf := h.evaluationService.Pin(ctx, cmd.AsOf)
+	cmd.AsOf = &asOf
+	if cmd.DryRun {
+		ctx = evaluation.WithDryRun(ctx)
+	}
 
 	startTime := time.Now()
 	strategy, err := h.evaluationService.GetStrategyForCategory(cmd.RuleCategory)
 	if err != nil {
 		telemetry.EvaluationsTotal.WithLabelValues(cmd.RuleCategory, "false").Inc()
 		telemetry.EvaluationDuration.WithLabelValues(cmd.RuleCategory).Observe(time.Since(startTime).Seconds())
+		h.recorder.recordRule(cmd, "", nil, err, time.Since(startTime), nil)
 		return nil, err
 	}
 
-	result, err := strategy.Evaluate(cmd.DSLContent, cmd.Context)
+	served, variant := cmd, decision.Variant("")
+	if cmd.Candidate != nil && cmd.Candidate.Mode == evaluation.RolloutCanary {
+		if cmd.Candidate.ServesCandidate(cmd.RuleID, customerID(cmd.Context)) {
+			served, variant = cmd.withCandidate(), decision.VariantCanary
+			telemetry.CanaryEvaluationsTotal.WithLabelValues(cmd.RuleCategory, "candidate").Inc()
+		} else {
+			telemetry.CanaryEvaluationsTotal.WithLabelValues(cmd.RuleCategory, "active").Inc()
+		}
+		span.SetAttributes(attribute.Bool("rule.canary", variant == decision.VariantCanary))
+	}
+
+	result, err := strategy.Evaluate(ctx, served.DSLContent, served.Context)
 	success := err == nil
 	telemetry.EvaluationsTotal.WithLabelValues(cmd.RuleCategory, strconv.FormatBool(success)).Inc()
 	telemetry.EvaluationDuration.WithLabelValues(cmd.RuleCategory).Observe(time.Since(startTime).Seconds())
+	h.recorder.recordRule(served, variant, result, err, time.Since(startTime), nil)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if cmd.Candidate != nil && cmd.Candidate.Mode == evaluation.RolloutShadow {
+		h.evaluateShadow(ctx, strategy, cmd.withCandidate(), result)
+	}
+
 	return &EvaluateRuleResult{
 		Result: result,
+		AsOf:   asOf,
 	}, nil
 }
+
+// evaluateShadow evaluates the candidate version of a rule and records how its result diverges from the
+// served result. The candidate runs dry, its result is never returned and its failures do not fail the evaluation.
+func (h *EvaluateRuleHandler) evaluateShadow(ctx context.Context, strategy evaluation.EvaluationStrategy, candidate EvaluateRuleCommand, served evaluation.Result) {
+	ctx, span := otel.Tracer("application").Start(ctx, "EvaluateRuleHandler.evaluateShadow")
+	defer span.End()
+	ctx = evaluation.WithDryRun(ctx)
+
+	startTime := time.Now()
+	result, err := strategy.Evaluate(ctx, candidate.DSLContent, candidate.Context)
+	if err != nil {
+		telemetry.ShadowEvaluationsTotal.WithLabelValues(candidate.RuleCategory, "error").Inc()
+		h.recorder.recordRule(candidate, decision.VariantShadow, nil, err, time.Since(startTime), nil)
+		return
+	}
+
+	divergence := evaluation.Compare(served, result)
+	telemetry.ShadowEvaluationsTotal.WithLabelValues(candidate.RuleCategory, shadowOutcome(divergence)).Inc()
+	span.SetAttributes(attribute.Bool("rule.diverged", divergence.Diverged()))
+	h.recorder.recordRule(candidate, decision.VariantShadow, result, nil, time.Since(startTime), &divergence)
+}
+
+// withCandidate returns the command with the candidate version in place of the active version.
+func (cmd EvaluateRuleCommand) withCandidate() EvaluateRuleCommand {
+	cmd.RuleVersion = cmd.Candidate.RuleVersion
+	cmd.DSLContent = cmd.Candidate.DSLContent
+	cmd.Candidate = nil
+	return cmd
+}
+
+func shadowOutcome(divergence evaluation.Divergence) string {
+	if divergence.Diverged() {
+		return "diverged"
+	}
+	return "match"
+}
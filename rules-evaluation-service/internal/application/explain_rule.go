@@ -0,0 +1,55 @@
+package application
+
+import (
+	"context"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/dsl"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ExplainRuleResult is the result of a rule evaluation together with how the rule read the context.
+type ExplainRuleResult struct {
+	Result      evaluation.Result
+	Explanation *dsl.Explanation
+}
+
+// ExplainRuleHandler handles the explanation of a rule evaluation.
+type ExplainRuleHandler struct {
+	evaluateRuleHandler *EvaluateRuleHandler
+}
+
+// NewExplainRuleHandler creates a new handler.
+func NewExplainRuleHandler(evaluateRuleHandler *EvaluateRuleHandler) *ExplainRuleHandler {
+	return &ExplainRuleHandler{evaluateRuleHandler: evaluateRuleHandler}
+}
+
+// Handle evaluates the active version of the rule once with its category strategy, in a dry run at the
+// pinned time, and reports the context values that run referenced. Explanations are not logged as decisions.
+func (h *ExplainRuleHandler) Handle(ctx context.Context, cmd EvaluateRuleCommand) (*ExplainRuleResult, error) {
+	ctx, span := otel.Tracer("application").Start(ctx, "ExplainRuleHandler.Handle")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("rule.category", cmd.RuleCategory))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	evaluationService := h.evaluateRuleHandler.evaluationService
+	strategy, err := evaluationService.GetStrategyForCategory(cmd.RuleCategory)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, _ = evaluationService.Pin(ctx, cmd.AsOf)
+	explanation := &dsl.Explanation{}
+	result, err := strategy.Evaluate(dsl.WithExplanation(evaluation.WithDryRun(ctx), explanation), cmd.DSLContent, cmd.Context)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Bool("rule.matched", explanation.Matched))
+
+	return &ExplainRuleResult{Result: result, Explanation: explanation}, nil
+}
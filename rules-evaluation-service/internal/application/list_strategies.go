@@ -0,0 +1,34 @@
+package application
+
+import (
+	"context"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+
+	"go.opentelemetry.io/otel"
+)
+
+// ListStrategiesResult describes the registered strategies and, when enabled, the fallback strategy.
+type ListStrategiesResult struct {
+	Strategies []evaluation.StrategyDescriptor
+	Fallback   *evaluation.StrategyDescriptor
+}
+
+// ListStrategiesHandler handles the query for the registered strategies.
+type ListStrategiesHandler struct {
+	strategies []evaluation.StrategyDescriptor
+	fallback   *evaluation.StrategyDescriptor
+}
+
+// NewListStrategiesHandler creates a new handler. fallback is nil when no fallback strategy is enabled.
+func NewListStrategiesHandler(strategies []evaluation.StrategyDescriptor, fallback *evaluation.StrategyDescriptor) *ListStrategiesHandler {
+	return &ListStrategiesHandler{strategies: strategies, fallback: fallback}
+}
+
+// Handle executes the query.
+func (h *ListStrategiesHandler) Handle(ctx context.Context) (*ListStrategiesResult, error) {
+	_, span := otel.Tracer("application").Start(ctx, "ListStrategiesHandler.Handle")
+	defer span.End()
+
+	return &ListStrategiesResult{Strategies: h.strategies, Fallback: h.fallback}, nil
+}
@@ -0,0 +1,70 @@
+package coupon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrReservationNotFound is returned when a reservation does not exist, has expired or was already settled.
+var ErrReservationNotFound = errors.New("coupon reservation not found")
+
+// Limits are the usage limits of a coupon code. Zero values mean no limit.
+type Limits struct {
+	PerCustomer int
+	Global      int
+	// SingleUse allows a code to be redeemed once in total, regardless of the other limits.
+	SingleUse bool
+}
+
+// GlobalLimit returns the effective global limit, taking single-use codes into account.
+func (l Limits) GlobalLimit() int {
+	if l.SingleUse {
+		return 1
+	}
+	return l.Global
+}
+
+// ReservationStatus is the lifecycle state of a reservation.
+type ReservationStatus string
+
+const (
+	ReservationReserved  ReservationStatus = "RESERVED"
+	ReservationCommitted ReservationStatus = "COMMITTED"
+	ReservationReleased  ReservationStatus = "RELEASED"
+)
+
+// Reservation holds one usage of a coupon code until the checkout commits or releases it.
+// Reservations that are neither committed nor released stop counting against the limits once they expire.
+type Reservation struct {
+	ID         string
+	Code       string
+	CustomerID string
+	// Holder is the checkout, or else the customer, the reservation is held for.
+	Holder    string
+	Status    ReservationStatus
+	ExpiresAt time.Time
+}
+
+// LimitExceededError is returned when reserving a code would exceed one of its usage limits.
+type LimitExceededError struct {
+	Code  string
+	Scope string // "customer" or "global"
+	Limit int
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("coupon %s has reached its %s usage limit of %d", e.Code, e.Scope, e.Limit)
+}
+
+// UsageStore keeps coupon usage counters. Reserve must check the limits and record the reservation atomically,
+// so that concurrent checkouts cannot redeem a code more often than its limits allow. Reservations are
+// idempotent per holder: while the holder has an active reservation of the code, Reserve extends it by ttl
+// and returns it instead of reserving another usage, so evaluating the same checkout again does not count
+// twice. Reservations without a holder are always new usages.
+type UsageStore interface {
+	Reserve(ctx context.Context, code, customerID, holder string, limits Limits, ttl time.Duration) (*Reservation, error)
+	Commit(ctx context.Context, reservationID string) error
+	Release(ctx context.Context, reservationID string) error
+}
@@ -0,0 +1,75 @@
+package decision
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Outcome is the business outcome of an evaluation.
+type Outcome string
+
+const (
+	OutcomeEligible    Outcome = "ELIGIBLE"
+	OutcomeNotEligible Outcome = "NOT_ELIGIBLE"
+	OutcomeError       Outcome = "ERROR"
+)
+
+// Variant tells which rule versions a decision was made with when a candidate version is rolled out.
+type Variant string
+
+const (
+	// VariantCanary marks a decision served with candidate versions.
+	VariantCanary Variant = "CANARY"
+	// VariantShadow marks a decision made with candidate versions in shadow; it was not served.
+	VariantShadow Variant = "SHADOW"
+)
+
+// RuleRef identifies an evaluated rule version.
+type RuleRef struct {
+	ID      string `json:"id"`
+	Version int    `json:"version,omitempty"`
+}
+
+// Record is the compact audit record of one evaluation decision.
+type Record struct {
+	DecisionID string    `json:"decision_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	// AsOf is the time the rules considered to be now. Evaluating the same rule versions and context
+	// with this as_of reproduces the decision.
+	AsOf     time.Time `json:"as_of"`
+	Category string    `json:"category"`
+	Rules    []RuleRef `json:"rules"`
+	// AppliedRuleIDs lists the rules that contributed to the result of a category evaluation.
+	AppliedRuleIDs []string `json:"applied_rule_ids,omitempty"`
+	// CustomerHash is a salted SHA-256 of the customer ID; the raw ID is never logged.
+	CustomerHash string                 `json:"customer_hash,omitempty"`
+	Outcome      Outcome                `json:"outcome"`
+	Actions      map[string]interface{} `json:"actions,omitempty"`
+	Reason       string                 `json:"reason,omitempty"`
+	LatencyMs    float64                `json:"latency_ms"`
+	// Variant is empty for decisions made with the active rule versions only.
+	Variant Variant `json:"variant,omitempty"`
+	// DivergentFields lists, for shadow decisions, the result fields that differ from the served decision;
+	// "eligible" when the eligibility differs.
+	DivergentFields []string `json:"divergent_fields,omitempty"`
+}
+
+// Logger records evaluation decisions. Implementations must not block the caller.
+type Logger interface {
+	Log(record Record)
+}
+
+// NoOpLogger discards decision records.
+type NoOpLogger struct{}
+
+func (NoOpLogger) Log(Record) {}
+
+// HashCustomerID returns the salted SHA-256 of a customer ID, or an empty string when there is no ID.
+func HashCustomerID(customerID, salt string) string {
+	if customerID == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(salt + customerID))
+	return hex.EncodeToString(sum[:])
+}
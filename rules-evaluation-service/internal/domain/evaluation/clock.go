@@ -0,0 +1,44 @@
+package evaluation
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells what time an evaluation considers to be now. Strategies and the DSL read it from the
+// request context instead of the wall clock, so that time-dependent rules can be tested and replayed.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the wall clock.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always returns the same time. It pins now for a whole evaluation, and to the as_of
+// time of a request when a past decision is replayed.
+type FixedClock struct {
+	Time time.Time
+}
+
+func (c FixedClock) Now() time.Time {
+	return c.Time
+}
+
+type clockKey struct{}
+
+// WithClock returns a context carrying the clock of an evaluation.
+func WithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// ClockFromContext returns the clock of an evaluation, or the system clock when the context has none.
+func ClockFromContext(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return clock
+	}
+	return SystemClock{}
+}
@@ -0,0 +1,17 @@
+package evaluation
+
+// StrategyDescriptor describes a registered evaluation strategy and the context it expects.
+type StrategyDescriptor struct {
+	Category      string
+	Description   string
+	ContextSchema []ContextField
+}
+
+// ContextField describes one field of an evaluation context, addressed by its dotted path.
+// Nested paths may also be sent as flat keys, e.g. "order.amount" as "order_amount".
+type ContextField struct {
+	Path        string
+	Type        string // "number", "string", "boolean", "array" or "object"
+	Required    bool
+	Description string
+}
@@ -0,0 +1,18 @@
+package evaluation
+
+import "context"
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context for an evaluation whose result is never served: shadow candidates,
+// explanations and replays. Strategies with side effects, such as reserving coupon usage, skip them
+// in a dry run.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether the evaluation of the context is a dry run.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
@@ -1,23 +1,71 @@
 package evaluation
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"rules-evaluation-service/internal/domain/shared"
+)
 
 // Service is the main application service for rule evaluation.
 type Service struct {
 	strategies map[string]EvaluationStrategy
+	fallback   EvaluationStrategy
+	clock      Clock
 }
 
 // NewService creates a new EvaluationService.
 func NewService(strategies map[string]EvaluationStrategy) *Service {
-	return &Service{strategies: strategies}
+	return &Service{strategies: strategies, clock: SystemClock{}}
+}
+
+// NewServiceWithFallback creates an EvaluationService that evaluates categories without a
+// dedicated strategy with the fallback strategy.
+func NewServiceWithFallback(strategies map[string]EvaluationStrategy, fallback EvaluationStrategy) *Service {
+	return &Service{strategies: strategies, fallback: fallback, clock: SystemClock{}}
+}
+
+// SetClock replaces the clock evaluations read now from when a request does not pin it.
+func (s *Service) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// Pin returns a context in which now is fixed for the whole evaluation: to asOf when it is set,
+// otherwise to the current time of the service clock. The pinned time is returned as well.
+// An evaluation at asOf replays a past decision and runs dry, so that it has no side effects.
+func (s *Service) Pin(ctx context.Context, asOf *time.Time) (context.Context, time.Time) {
+	if asOf != nil {
+		return WithClock(WithDryRun(ctx), FixedClock{Time: *asOf}), *asOf
+	}
+	now := s.clock.Now()
+	return WithClock(ctx, FixedClock{Time: now}), now
 }
 
 // GetStrategyForCategory returns the appropriate evaluation strategy for a given rule category.
 func (s *Service) GetStrategyForCategory(category string) (EvaluationStrategy, error) {
 	strategy, ok := s.strategies[category]
 	if !ok {
-		// A default strategy could be returned here if needed.
-		return nil, fmt.Errorf("no evaluation strategy found for category: %s", category)
+		if s.fallback != nil {
+			return s.fallback, nil
+		}
+		return nil, shared.NewValidationError(fmt.Sprintf("no evaluation strategy found for category: %s", category), nil)
 	}
 	return strategy, nil
 }
+
+// Categories returns the categories with a dedicated strategy, sorted by name.
+func (s *Service) Categories() []string {
+	categories := make([]string, 0, len(s.strategies))
+	for category := range s.strategies {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// HasFallback reports whether categories without a dedicated strategy are evaluated by a fallback strategy.
+func (s *Service) HasFallback() bool {
+	return s.fallback != nil
+}
@@ -1,7 +1,16 @@
 package evaluation
 
+import "context"
+
 // EvaluationStrategy defines the interface for different rule evaluation algorithms.
 type EvaluationStrategy interface {
-	// Evaluate applies the rule logic to the given context.
-	Evaluate(dslContent string, context Context) (Result, error)
+	// Evaluate applies the rule logic to the given context. ctx carries the request's trace,
+	// deadline and cancellation.
+	Evaluate(ctx context.Context, dslContent string, evalContext Context) (Result, error)
+}
+
+// Compensator is implemented by strategies whose evaluations have side effects, such as reserving
+// coupon usage. Compensate undoes the side effects of a result that is not served.
+type Compensator interface {
+	Compensate(ctx context.Context, result Result) error
 }
@@ -0,0 +1,297 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ResolutionPolicy decides how the results of several matching rules of the same category combine.
+type ResolutionPolicy string
+
+const (
+	// PolicyHighestPriority applies only the matching rule with the highest priority.
+	PolicyHighestPriority ResolutionPolicy = "HIGHEST_PRIORITY"
+	// PolicyBestForCustomer applies only the matching rule with the most favourable value: the largest
+	// value, or the smallest when the category's direction is MINIMIZE, e.g. for surcharges.
+	PolicyBestForCustomer ResolutionPolicy = "BEST_FOR_CUSTOMER"
+	// PolicyFirstMatch evaluates rules in priority order and stops at the first match.
+	PolicyFirstMatch ResolutionPolicy = "FIRST_MATCH"
+	// PolicyAdditive stacks the values of all matching rules, up to the configured caps.
+	PolicyAdditive ResolutionPolicy = "ADDITIVE"
+	// PolicyExclusiveGroups applies one rule per exclusive group and stacks across groups.
+	PolicyExclusiveGroups ResolutionPolicy = "EXCLUSIVE_GROUPS"
+)
+
+// IsValid reports whether the policy is one of the supported policies.
+func (p ResolutionPolicy) IsValid() bool {
+	switch p {
+	case PolicyHighestPriority, PolicyBestForCustomer, PolicyFirstMatch, PolicyAdditive, PolicyExclusiveGroups:
+		return true
+	}
+	return false
+}
+
+// Direction tells which values favour the customer.
+type Direction string
+
+const (
+	// DirectionMaximize favours larger values, e.g. discounts or points. It is the default.
+	DirectionMaximize Direction = "MAXIMIZE"
+	// DirectionMinimize favours smaller values, e.g. fees, surcharges or totals.
+	DirectionMinimize Direction = "MINIMIZE"
+)
+
+// better reports whether value a favours the customer over value b.
+func (d Direction) better(a, b float64) bool {
+	if d == DirectionMinimize {
+		return a < b
+	}
+	return a > b
+}
+
+// SuppressionReason explains why a rule did not contribute to the combined result.
+type SuppressionReason string
+
+const (
+	ReasonNotEligible     SuppressionReason = "NOT_ELIGIBLE"
+	ReasonEvaluationError SuppressionReason = "EVALUATION_ERROR"
+	ReasonNotEvaluated    SuppressionReason = "NOT_EVALUATED"
+	ReasonLowerPriority   SuppressionReason = "LOWER_PRIORITY"
+	ReasonLessFavourable  SuppressionReason = "LESS_FAVOURABLE"
+	ReasonCapReached      SuppressionReason = "CAP_REACHED"
+	ReasonGroupConflict   SuppressionReason = "GROUP_CONFLICT"
+)
+
+// ResolutionConfig configures conflict resolution for a rule category.
+type ResolutionConfig struct {
+	Policy ResolutionPolicy
+	// ValueKey is the result field compared and stacked across rules, e.g. "discount_percentage".
+	ValueKey string
+	// Direction tells which values favour the customer; empty means DirectionMaximize.
+	Direction Direction
+	// Cap limits the stacked total for additive policies. Zero means no cap.
+	Cap float64
+	// MaxStacked limits how many rules may be stacked for additive policies. Zero means no limit.
+	MaxStacked int
+}
+
+// Validate reports a configuration that cannot resolve the conflicts of a category: besides a supported
+// policy, a configured category needs the key its results are compared and reported under.
+func (c ResolutionConfig) Validate() error {
+	if !c.Policy.IsValid() {
+		return fmt.Errorf("unsupported resolution policy: %s", c.Policy)
+	}
+	if c.ValueKey == "" {
+		return fmt.Errorf("resolution value key is required")
+	}
+	if c.Direction != "" && c.Direction != DirectionMaximize && c.Direction != DirectionMinimize {
+		return fmt.Errorf("unsupported resolution direction: %s", c.Direction)
+	}
+	if c.Cap < 0 || c.MaxStacked < 0 {
+		return fmt.Errorf("resolution cap and stacking limit must not be negative")
+	}
+	return nil
+}
+
+// RuleSpec is a single rule submitted for evaluation within a category.
+type RuleSpec struct {
+	RuleID      string
+	RuleVersion int // optional, recorded in the decision log
+	Priority    int
+	Group       string
+	DSLContent  string
+	Candidate   *Candidate // optional version rolled out in shadow or canary mode
+}
+
+// AppliedRule is a rule whose result contributed to the combined result.
+type AppliedRule struct {
+	RuleID string
+	Value  float64
+	Capped bool
+	Result Result
+}
+
+// SuppressedRule is a rule that was evaluated, or skipped, without contributing to the combined result.
+type SuppressedRule struct {
+	RuleID string
+	Reason SuppressionReason
+	Detail string
+	// Result is the result of a rule that matched but lost the resolution to other rules.
+	Result Result
+}
+
+// Resolution is the outcome of evaluating several rules of one category under a resolution policy.
+type Resolution struct {
+	Category   string
+	Policy     ResolutionPolicy
+	ValueKey   string
+	Total      float64
+	Applied    []AppliedRule
+	Suppressed []SuppressedRule
+}
+
+// Result returns the combined result of the resolution.
+func (r *Resolution) Result() Result {
+	return Result{"eligible": len(r.Applied) > 0, r.ValueKey: r.Total}
+}
+
+// IsEligible reports whether a strategy result represents a matching rule.
+// Results without an "eligible" flag are considered matching.
+func (r Result) IsEligible() bool {
+	eligible, ok := r["eligible"].(bool)
+	return !ok || eligible
+}
+
+// outcome is the evaluated state of a rule while resolving conflicts.
+type outcome struct {
+	spec   RuleSpec
+	index  int
+	result Result
+	value  float64
+}
+
+// EvaluateCategory evaluates several rules of the same category and combines their results
+// according to the given resolution configuration. It stops with ctx's error when ctx is done.
+func (s *Service) EvaluateCategory(ctx context.Context, category string, rules []RuleSpec, evalContext Context, cfg ResolutionConfig) (*Resolution, error) {
+	if !cfg.Policy.IsValid() {
+		return nil, fmt.Errorf("unsupported resolution policy: %s", cfg.Policy)
+	}
+	strategy, err := s.GetStrategyForCategory(category)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rules are considered in descending priority; ties keep the submitted order.
+	ordered := make([]outcome, len(rules))
+	for i, spec := range rules {
+		ordered[i] = outcome{spec: spec, index: i}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].spec.Priority > ordered[j].spec.Priority
+	})
+
+	resolution := &Resolution{Category: category, Policy: cfg.Policy, ValueKey: cfg.ValueKey}
+	var matched []outcome
+	for i, o := range ordered {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result, err := strategy.Evaluate(ctx, o.spec.DSLContent, evalContext)
+		if err != nil {
+			resolution.suppress(o, ReasonEvaluationError, err.Error())
+			continue
+		}
+		if !result.IsEligible() {
+			resolution.suppress(o, ReasonNotEligible, "rule conditions not met")
+			continue
+		}
+		o.result = result
+		o.value = numericValue(result, cfg.ValueKey)
+		matched = append(matched, o)
+
+		if cfg.Policy == PolicyFirstMatch {
+			for _, skipped := range ordered[i+1:] {
+				resolution.suppress(skipped, ReasonNotEvaluated, fmt.Sprintf("rule %s matched first", o.spec.RuleID))
+			}
+			break
+		}
+	}
+
+	switch cfg.Policy {
+	case PolicyHighestPriority, PolicyFirstMatch:
+		resolution.applySingle(matched, ReasonLowerPriority)
+	case PolicyBestForCustomer:
+		sort.SliceStable(matched, func(i, j int) bool { return cfg.Direction.better(matched[i].value, matched[j].value) })
+		resolution.applySingle(matched, ReasonLessFavourable)
+	case PolicyAdditive:
+		resolution.stack(matched, cfg)
+	case PolicyExclusiveGroups:
+		resolution.stack(resolution.pickGroupWinners(matched, cfg.Direction), cfg)
+	}
+
+	return resolution, nil
+}
+
+func (r *Resolution) suppress(o outcome, reason SuppressionReason, detail string) {
+	r.Suppressed = append(r.Suppressed, SuppressedRule{RuleID: o.spec.RuleID, Reason: reason, Detail: detail, Result: o.result})
+}
+
+func (r *Resolution) apply(o outcome, value float64, capped bool) {
+	r.Applied = append(r.Applied, AppliedRule{RuleID: o.spec.RuleID, Value: value, Capped: capped, Result: o.result})
+	r.Total += value
+}
+
+// applySingle applies the first outcome and suppresses the rest with the given reason.
+func (r *Resolution) applySingle(matched []outcome, reason SuppressionReason) {
+	if len(matched) == 0 {
+		return
+	}
+	winner := matched[0]
+	r.apply(winner, winner.value, false)
+	for _, o := range matched[1:] {
+		r.suppress(o, reason, fmt.Sprintf("rule %s was applied instead", winner.spec.RuleID))
+	}
+}
+
+// stack applies outcomes in order until the configured cap or stacking limit is reached.
+func (r *Resolution) stack(matched []outcome, cfg ResolutionConfig) {
+	for _, o := range matched {
+		if cfg.MaxStacked > 0 && len(r.Applied) >= cfg.MaxStacked {
+			r.suppress(o, ReasonCapReached, fmt.Sprintf("at most %d rules may be stacked", cfg.MaxStacked))
+			continue
+		}
+		if cfg.Cap > 0 && r.Total >= cfg.Cap {
+			r.suppress(o, ReasonCapReached, fmt.Sprintf("stacking cap of %g reached", cfg.Cap))
+			continue
+		}
+		value, capped := o.value, false
+		if cfg.Cap > 0 && r.Total+value > cfg.Cap {
+			value, capped = cfg.Cap-r.Total, true
+		}
+		r.apply(o, value, capped)
+	}
+}
+
+// pickGroupWinners keeps the highest-priority rule of each exclusive group, breaking ties by the value
+// that favours the customer. Rules without a group are not exclusive and are always kept.
+func (r *Resolution) pickGroupWinners(matched []outcome, direction Direction) []outcome {
+	winners := make(map[string]outcome)
+	for _, o := range matched {
+		if o.spec.Group == "" {
+			continue
+		}
+		current, ok := winners[o.spec.Group]
+		if !ok || o.spec.Priority > current.spec.Priority ||
+			(o.spec.Priority == current.spec.Priority && direction.better(o.value, current.value)) {
+			winners[o.spec.Group] = o
+		}
+	}
+
+	var kept []outcome
+	for _, o := range matched {
+		if o.spec.Group == "" {
+			kept = append(kept, o)
+			continue
+		}
+		winner := winners[o.spec.Group]
+		if winner.index == o.index {
+			kept = append(kept, o)
+			continue
+		}
+		r.suppress(o, ReasonGroupConflict, fmt.Sprintf("rule %s was applied for group %s", winner.spec.RuleID, o.spec.Group))
+	}
+	return kept
+}
+
+func numericValue(result Result, key string) float64 {
+	switch v := result[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return 0
+}
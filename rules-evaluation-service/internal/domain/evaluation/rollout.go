@@ -0,0 +1,143 @@
+package evaluation
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+
+	"rules-evaluation-service/internal/domain/shared"
+)
+
+// RolloutMode is how a candidate rule version is exercised on live traffic.
+type RolloutMode string
+
+const (
+	// RolloutShadow evaluates the candidate next to the active version and only reports divergences.
+	RolloutShadow RolloutMode = "SHADOW"
+	// RolloutCanary serves the candidate result to a deterministic slice of customers.
+	RolloutCanary RolloutMode = "CANARY"
+)
+
+// Candidate is a not yet activated version of a rule, submitted together with the active version.
+type Candidate struct {
+	RuleVersion int
+	DSLContent  string
+	Mode        RolloutMode
+	// Percentage of customers, between 0 and 100, served by the candidate in canary mode.
+	Percentage float64
+}
+
+// Validate checks that the candidate can be rolled out.
+func (c *Candidate) Validate() error {
+	switch {
+	case c.DSLContent == "":
+		return shared.NewValidationError("candidate dsl_content is required", nil)
+	case c.Mode != RolloutShadow && c.Mode != RolloutCanary:
+		return shared.NewValidationError(fmt.Sprintf("unsupported candidate mode: %s", c.Mode), nil)
+	case c.Mode == RolloutCanary && (c.Percentage < 0 || c.Percentage > 100):
+		return shared.NewValidationError("candidate percentage must be between 0 and 100", nil)
+	}
+	return nil
+}
+
+// ServesCandidate reports whether a customer falls in the canary slice of a rule.
+// The slice is derived from a hash of the rule and customer IDs, so a customer keeps seeing the same
+// version of a rule while the percentage is unchanged, and different rules select different customers.
+// Requests without a customer ID are never served the candidate.
+func (c *Candidate) ServesCandidate(ruleID, customerID string) bool {
+	if c.Mode != RolloutCanary || customerID == "" {
+		return false
+	}
+	return CanaryBucket(ruleID, customerID) < c.Percentage
+}
+
+// CanaryBucket maps a rule and customer to a stable bucket in [0, 100).
+func CanaryBucket(ruleID, customerID string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(ruleID + ":" + customerID))
+	return float64(h.Sum32()%10000) / 100
+}
+
+// Divergence describes how a candidate result differs from the active result.
+type Divergence struct {
+	Eligibility bool     // the versions disagree on eligibility
+	Fields      []string // numeric result fields whose values differ, sorted by name
+}
+
+// Diverged reports whether the results differ.
+func (d Divergence) Diverged() bool {
+	return d.Eligibility || len(d.Fields) > 0
+}
+
+// divergenceTolerance absorbs floating point noise when comparing amounts.
+const divergenceTolerance = 1e-9
+
+// Compare reports the eligibility and amount differences between an active and a candidate result.
+// Non-numeric fields are not compared.
+func Compare(active, candidate Result) Divergence {
+	divergence := Divergence{Eligibility: active.IsEligible() != candidate.IsEligible()}
+	keys := make(map[string]bool)
+	for key, value := range active {
+		if isNumeric(value) {
+			keys[key] = true
+		}
+	}
+	for key, value := range candidate {
+		if isNumeric(value) {
+			keys[key] = true
+		}
+	}
+	for key := range keys {
+		if math.Abs(numericValue(active, key)-numericValue(candidate, key)) > divergenceTolerance {
+			divergence.Fields = append(divergence.Fields, key)
+		}
+	}
+	sort.Strings(divergence.Fields)
+	return divergence
+}
+
+func isNumeric(value interface{}) bool {
+	switch value.(type) {
+	case float64, int, int64:
+		return true
+	}
+	return false
+}
+
+// CanaryRules substitutes, for the given customer, the candidate of every canary rule whose slice
+// includes the customer. It reports whether any candidate was substituted.
+func CanaryRules(rules []RuleSpec, customerID string) ([]RuleSpec, bool) {
+	served := make([]RuleSpec, len(rules))
+	substituted := false
+	for i, rule := range rules {
+		served[i] = rule
+		if rule.Candidate != nil && rule.Candidate.ServesCandidate(rule.RuleID, customerID) {
+			served[i] = rule.WithCandidate()
+			substituted = true
+		}
+	}
+	return served, substituted
+}
+
+// ShadowRules substitutes the candidate of every shadow rule. It reports whether there was any.
+func ShadowRules(rules []RuleSpec) ([]RuleSpec, bool) {
+	shadow := make([]RuleSpec, len(rules))
+	substituted := false
+	for i, rule := range rules {
+		shadow[i] = rule
+		if rule.Candidate != nil && rule.Candidate.Mode == RolloutShadow {
+			shadow[i] = rule.WithCandidate()
+			substituted = true
+		}
+	}
+	return shadow, substituted
+}
+
+// WithCandidate returns the rule with its candidate version in place of the active version.
+func (s RuleSpec) WithCandidate() RuleSpec {
+	s.RuleVersion = s.Candidate.RuleVersion
+	s.DSLContent = s.Candidate.DSLContent
+	s.Candidate = nil
+	return s
+}
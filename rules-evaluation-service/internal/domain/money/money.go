@@ -0,0 +1,55 @@
+package money
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Money is a decimal amount in an ISO 4217 currency. It is encoded in JSON as
+// {"amount": "12.34", "currency": "EUR"}, with the amount as a string so that no precision is lost.
+type Money struct {
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// New creates a Money value, validating the currency code.
+func New(amount decimal.Decimal, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !currencyCode.MatchString(currency) {
+		return Money{}, fmt.Errorf("invalid currency code %q", currency)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// FromFloat creates a Money value from a float amount that has already been rounded to the
+// currency's precision.
+func FromFloat(amount float64, currency string) (Money, error) {
+	return New(decimal.NewFromFloat(amount), currency)
+}
+
+// Add adds two amounts of the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, &CurrencyMismatchError{Currencies: []string{m.Currency, other.Currency}}
+	}
+	return Money{Amount: m.Amount.Add(other.Amount), Currency: m.Currency}, nil
+}
+
+// String formats the amount followed by the currency, e.g. "12.34 EUR".
+func (m Money) String() string {
+	return m.Amount.String() + " " + m.Currency
+}
+
+// CurrencyMismatchError is returned when amounts in different currencies are combined.
+type CurrencyMismatchError struct {
+	Currencies []string
+}
+
+func (e *CurrencyMismatchError) Error() string {
+	return fmt.Sprintf("cannot combine amounts in different currencies: %s", strings.Join(e.Currencies, ", "))
+}
@@ -18,3 +18,56 @@ func (e *ValidationError) Error() string {
 	}
 	return e.message
 }
+
+// InfrastructureError represents an error from an infrastructure component.
+type InfrastructureError struct {
+	message string
+	cause   error
+}
+
+func NewInfrastructureError(message string, cause error) *InfrastructureError {
+	return &InfrastructureError{message: message, cause: cause}
+}
+
+func (e *InfrastructureError) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %v", e.message, e.cause)
+	}
+	return e.message
+}
+
+func (e *InfrastructureError) Unwrap() error {
+	return e.cause
+}
+
+// NotFoundError represents a resource not found error.
+type NotFoundError struct {
+	message string
+	cause   error
+}
+
+func NewNotFoundError(message string, cause error) *NotFoundError {
+	return &NotFoundError{message: message, cause: cause}
+}
+
+func (e *NotFoundError) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %v", e.message, e.cause)
+	}
+	return e.message
+}
+
+// LimitExceededError is returned when a rule exceeds an evaluation limit, e.g. its expression depth
+// or its evaluation time.
+type LimitExceededError struct {
+	Limit   string // the exceeded limit, e.g. "max_depth"
+	message string
+}
+
+func NewLimitExceededError(limit, message string) *LimitExceededError {
+	return &LimitExceededError{Limit: limit, message: message}
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("evaluation limit %s exceeded: %s", e.Limit, e.message)
+}
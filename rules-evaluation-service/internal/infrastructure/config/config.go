@@ -1,18 +1,34 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"rules-evaluation-service/internal/domain/evaluation"
 )
 
 // Config holds the application configuration.
 type Config struct {
-	Server    ServerConfig
-	Telemetry TelemetryConfig
+	Server       ServerConfig
+	Telemetry    TelemetryConfig
+	Strategies   StrategiesConfig
+	DSL          DSLConfig
+	Resolution   map[string]evaluation.ResolutionConfig
+	Coupons      CouponsConfig
+	Taxes        TaxesConfig
+	Database     DatabaseConfig
+	DecisionLog  DecisionLogConfig
+	RateLimit    RateLimitConfig
+	LoadShedding LoadSheddingConfig
 }
 
 // ServerConfig holds the server configuration.
 type ServerConfig struct {
-	Port string
+	Port     string
+	GRPCPort string
 }
 
 // TelemetryConfig holds the telemetry configuration.
@@ -21,22 +37,195 @@ type TelemetryConfig struct {
 	Exporter    string // e.g., "stdout", "jaeger", "otlp"
 }
 
+// StrategiesConfig holds the configuration of the evaluation strategies.
+type StrategiesConfig struct {
+	Disabled []string // registered categories that are not served
+	Fallback bool     // evaluate categories without a dedicated strategy with the generic DSL strategy
+}
+
+// DSLConfig holds the limits of the DSL interpreter. Zero disables a limit.
+type DSLConfig struct {
+	MaxDepth       int
+	MaxNodes       int
+	MaxSteps       int
+	Timeout        time.Duration
+	MaxResultBytes int
+}
+
+// CouponsConfig holds the configuration of coupon usage tracking.
+type CouponsConfig struct {
+	Store          string // "memory" or "postgres"
+	ReservationTTL time.Duration
+}
+
+// TaxesConfig holds the configuration of tax calculations.
+type TaxesConfig struct {
+	Rounding map[string]RoundingConfig // keyed by ISO currency code
+}
+
+// RoundingConfig holds the rounding rule of a currency.
+type RoundingConfig struct {
+	Mode     string // "HALF_UP", "HALF_EVEN", "UP" or "DOWN"
+	Decimals int
+}
+
+// DatabaseConfig holds the database configuration.
+type DatabaseConfig struct {
+	DSN string
+}
+
+// DecisionLogConfig holds the configuration of the evaluation decision log.
+type DecisionLogConfig struct {
+	Enabled          bool
+	NATSURL          string
+	Subject          string
+	SampleRate       float64 // fraction of decisions recorded, between 0 and 1
+	BatchSize        int
+	FlushInterval    time.Duration
+	BufferSize       int    // decisions queued for publishing; further decisions are dropped
+	CustomerHashSalt string // prepended to customer IDs before hashing
+}
+
+// RateLimitConfig holds the per-client rate limit of the evaluation endpoints.
+type RateLimitConfig struct {
+	Enabled           bool
+	RequestsPerSecond float64
+	Burst             int
+	IdleTTL           time.Duration // buckets of clients idle for longer are discarded
+	MaxClients        int           // further clients share one bucket; 0 means no bound
+}
+
+// LoadSheddingConfig holds the adaptive concurrency limit of the evaluation endpoints.
+type LoadSheddingConfig struct {
+	Enabled      bool
+	TargetP99    time.Duration // the limit is reduced while the p99 latency exceeds this target
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	Window       int // completed requests per latency measurement
+}
+
 // DefaultConfig returns the default configuration.
 func DefaultConfig() *Config {
 	// Get environment variables with defaults
 	serverPort := getEnv("SERVER_PORT", "8081")
 	telemetryServiceName := getEnv("TELEMETRY_SERVICE_NAME", "rules-evaluation-service")
 	telemetryExporter := getEnv("TELEMETRY_EXPORTER", "stdout")
+	dbHost := getEnv("DB_HOST", "localhost")
+	dbPort := getEnv("DB_PORT", "5432")
+	dbName := getEnv("DB_NAME", "rules_dev")
+	dbUser := getEnv("DB_USER", "user")
+	dbPassword := getEnv("DB_PASSWORD", "password")
+	dbSSLMode := getEnv("DB_SSL_MODE", "disable")
+
+	// Build DSN
+	dsn := "host=" + dbHost + " user=" + dbUser + " password=" + dbPassword + " dbname=" + dbName + " port=" + dbPort + " sslmode=" + dbSSLMode + " TimeZone=UTC"
 
 	return &Config{
 		Server: ServerConfig{
-			Port: serverPort,
+			Port:     serverPort,
+			GRPCPort: getEnv("GRPC_PORT", "9081"),
 		},
 		Telemetry: TelemetryConfig{
 			ServiceName: telemetryServiceName,
 			Exporter:    telemetryExporter,
 		},
+		Strategies: StrategiesConfig{
+			Disabled: getEnvList("STRATEGIES_DISABLED"),
+			Fallback: getEnvBool("STRATEGIES_FALLBACK_ENABLED", false),
+		},
+		DSL: DSLConfig{
+			MaxDepth:       getEnvInt("DSL_MAX_DEPTH", 32),
+			MaxNodes:       getEnvInt("DSL_MAX_NODES", 2000),
+			MaxSteps:       getEnvInt("DSL_MAX_STEPS", 100000),
+			Timeout:        getEnvDuration("DSL_TIMEOUT", 100*time.Millisecond),
+			MaxResultBytes: getEnvInt("DSL_MAX_RESULT_BYTES", 64*1024),
+		},
+		// The "*" entry applies to categories evaluated by the fallback strategy; Load adds the others.
+		Resolution: map[string]evaluation.ResolutionConfig{
+			"*": resolutionConfig("FALLBACK", evaluation.ResolutionConfig{Policy: evaluation.PolicyFirstMatch, ValueKey: "value"}),
+		},
+		Coupons: CouponsConfig{
+			Store:          getEnv("COUPONS_STORE", "memory"),
+			ReservationTTL: getEnvDuration("COUPONS_RESERVATION_TTL", 15*time.Minute),
+		},
+		Taxes: TaxesConfig{
+			Rounding: parseRounding(getEnv("TAXES_ROUNDING", "EUR:HALF_UP:2,USD:HALF_UP:2,GBP:HALF_UP:2,JPY:HALF_UP:0")),
+		},
+		Database: DatabaseConfig{
+			DSN: dsn,
+		},
+		DecisionLog: DecisionLogConfig{
+			Enabled:          getEnvBool("DECISION_LOG_ENABLED", false),
+			NATSURL:          getEnv("NATS_URL", "nats://localhost:4222"),
+			Subject:          getEnv("DECISION_LOG_SUBJECT", "analytics.events.EvaluationDecision"),
+			SampleRate:       getEnvFloat("DECISION_LOG_SAMPLE_RATE", 1),
+			BatchSize:        getEnvInt("DECISION_LOG_BATCH_SIZE", 100),
+			FlushInterval:    getEnvDuration("DECISION_LOG_FLUSH_INTERVAL", time.Second),
+			BufferSize:       getEnvInt("DECISION_LOG_BUFFER_SIZE", 10000),
+			CustomerHashSalt: getEnv("DECISION_LOG_CUSTOMER_HASH_SALT", ""),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           getEnvBool("RATE_LIMIT_ENABLED", true),
+			RequestsPerSecond: getEnvFloat("RATE_LIMIT_REQUESTS_PER_SECOND", 200),
+			Burst:             getEnvInt("RATE_LIMIT_BURST", 400),
+			IdleTTL:           getEnvDuration("RATE_LIMIT_IDLE_TTL", 10*time.Minute),
+			MaxClients:        getEnvInt("RATE_LIMIT_MAX_CLIENTS", 10000),
+		},
+		LoadShedding: LoadSheddingConfig{
+			Enabled:      getEnvBool("LOAD_SHEDDING_ENABLED", true),
+			TargetP99:    getEnvDuration("LOAD_SHEDDING_TARGET_P99", 250*time.Millisecond),
+			InitialLimit: getEnvInt("LOAD_SHEDDING_INITIAL_LIMIT", 200),
+			MinLimit:     getEnvInt("LOAD_SHEDDING_MIN_LIMIT", 20),
+			MaxLimit:     getEnvInt("LOAD_SHEDDING_MAX_LIMIT", 1000),
+			Window:       getEnvInt("LOAD_SHEDDING_WINDOW", 200),
+		},
+	}
+}
+
+// Load returns the configuration with the conflict resolution of each category, read from
+// <CATEGORY>_RESOLUTION_* variables on top of the given defaults. An invalid resolution fails the load,
+// so that it is reported at startup rather than by every request of the category.
+func Load(resolutionDefaults map[string]evaluation.ResolutionConfig) (*Config, error) {
+	cfg := DefaultConfig()
+	for category, defaults := range resolutionDefaults {
+		cfg.Resolution[category] = resolutionConfig(category, defaults)
+	}
+	for category, rc := range cfg.Resolution {
+		if err := rc.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid resolution of %s: %w", category, err)
+		}
+	}
+	return cfg, nil
+}
+
+// resolutionConfig reads the resolution configuration of a category from <CATEGORY>_RESOLUTION_* variables.
+func resolutionConfig(category string, defaults evaluation.ResolutionConfig) evaluation.ResolutionConfig {
+	return evaluation.ResolutionConfig{
+		Policy:     evaluation.ResolutionPolicy(getEnv(category+"_RESOLUTION_POLICY", string(defaults.Policy))),
+		ValueKey:   getEnv(category+"_RESOLUTION_VALUE_KEY", defaults.ValueKey),
+		Direction:  evaluation.Direction(getEnv(category+"_RESOLUTION_DIRECTION", string(defaults.Direction))),
+		Cap:        getEnvFloat(category+"_RESOLUTION_CAP", defaults.Cap),
+		MaxStacked: getEnvInt(category+"_RESOLUTION_MAX_STACKED", defaults.MaxStacked),
+	}
+}
+
+// parseRounding parses per-currency rounding rules written as "EUR:HALF_EVEN:2,JPY:HALF_UP:0".
+// Malformed entries are ignored.
+func parseRounding(value string) map[string]RoundingConfig {
+	rounding := make(map[string]RoundingConfig)
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 3 {
+			continue
+		}
+		decimals, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+		rounding[strings.ToUpper(parts[0])] = RoundingConfig{Mode: strings.ToUpper(parts[1]), Decimals: decimals}
 	}
+	return rounding
 }
 
 // getEnv gets an environment variable with a default value
@@ -46,3 +235,46 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvFloat gets a float environment variable with a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvInt gets an integer environment variable with a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvDuration gets a duration environment variable with a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvBool gets a boolean environment variable with a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvList gets a comma-separated list environment variable, ignoring empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, strings.ToUpper(value))
+		}
+	}
+	return values
+}
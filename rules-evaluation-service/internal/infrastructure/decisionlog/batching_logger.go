@@ -0,0 +1,139 @@
+package decisionlog
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"rules-evaluation-service/internal/domain/decision"
+	"rules-evaluation-service/internal/infrastructure/telemetry"
+)
+
+// publishTimeout bounds the time spent publishing one batch.
+const publishTimeout = 5 * time.Second
+
+// Sink publishes batches of decision records.
+type Sink interface {
+	PublishBatch(ctx context.Context, records []decision.Record) error
+}
+
+// Options configures a BatchingLogger.
+type Options struct {
+	SampleRate    float64 // fraction of decisions recorded, between 0 and 1
+	BatchSize     int
+	FlushInterval time.Duration
+	BufferSize    int
+}
+
+// BatchingLogger is a decision.Logger that samples decisions and publishes them to a sink in batches
+// from a background goroutine. Log never blocks: decisions that do not fit in the buffer are dropped.
+type BatchingLogger struct {
+	sink       Sink
+	sampleRate float64
+	batchSize  int
+	interval   time.Duration
+	records    chan decision.Record
+	stop       chan struct{}
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+// NewBatchingLogger creates a logger and starts its publishing loop. Call Close to flush and stop it.
+func NewBatchingLogger(sink Sink, opts Options) *BatchingLogger {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = opts.BatchSize
+	}
+	l := &BatchingLogger{
+		sink:       sink,
+		sampleRate: opts.SampleRate,
+		batchSize:  opts.BatchSize,
+		interval:   opts.FlushInterval,
+		records:    make(chan decision.Record, opts.BufferSize),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Log queues a decision for publishing if it is sampled in.
+func (l *BatchingLogger) Log(record decision.Record) {
+	if l.sampleRate < 1 && rand.Float64() >= l.sampleRate {
+		telemetry.DecisionLogRecordsTotal.WithLabelValues("sampled_out").Inc()
+		return
+	}
+	select {
+	case <-l.stop:
+		telemetry.DecisionLogRecordsTotal.WithLabelValues("dropped").Inc()
+		return
+	default:
+	}
+	select {
+	case l.records <- record:
+	default:
+		telemetry.DecisionLogRecordsTotal.WithLabelValues("dropped").Inc()
+	}
+}
+
+// Close publishes the queued decisions and stops the publishing loop.
+func (l *BatchingLogger) Close() {
+	l.closeOnce.Do(func() { close(l.stop) })
+	<-l.done
+}
+
+func (l *BatchingLogger) run() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	batch := make([]decision.Record, 0, l.batchSize)
+	for {
+		select {
+		case record := <-l.records:
+			batch = append(batch, record)
+			if len(batch) >= l.batchSize {
+				batch = l.flush(batch)
+			}
+		case <-ticker.C:
+			batch = l.flush(batch)
+		case <-l.stop:
+			for {
+				select {
+				case record := <-l.records:
+					batch = append(batch, record)
+					if len(batch) >= l.batchSize {
+						batch = l.flush(batch)
+					}
+				default:
+					l.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush publishes the batch and returns it emptied for reuse.
+func (l *BatchingLogger) flush(batch []decision.Record) []decision.Record {
+	if len(batch) == 0 {
+		return batch
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	if err := l.sink.PublishBatch(ctx, batch); err != nil {
+		log.Printf("Failed to publish %d decision records: %v", len(batch), err)
+		telemetry.DecisionLogRecordsTotal.WithLabelValues("failed").Add(float64(len(batch)))
+	} else {
+		telemetry.DecisionLogRecordsTotal.WithLabelValues("published").Add(float64(len(batch)))
+	}
+	return batch[:0]
+}
@@ -0,0 +1,81 @@
+package dsl
+
+import (
+	"context"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+)
+
+// FieldValue is a context path referenced by a program and the value it resolved to.
+type FieldValue struct {
+	Path  string
+	Value interface{}
+	Found bool
+}
+
+// Explanation describes how a program ran against a context.
+type Explanation struct {
+	Matched bool
+	// Fields lists the referenced context paths in the order they appear in the rule.
+	Fields  []FieldValue
+	Actions map[string]interface{}
+	// Err is set when the program could not run, e.g. because a referenced field is missing.
+	Err error
+}
+
+type explanationKey struct{}
+
+// WithExplanation returns a context in which program runs are recorded in explanation. A strategy
+// evaluated with this context is explained by the very run that produced its result.
+func WithExplanation(ctx context.Context, explanation *Explanation) context.Context {
+	return context.WithValue(ctx, explanationKey{}, explanation)
+}
+
+// Explain runs the program and reports the context values it referenced.
+func (p *Program) Explain(ctx context.Context, evalContext evaluation.Context) *Explanation {
+	explanation := &Explanation{}
+	_, _ = p.Run(WithExplanation(ctx, explanation), evalContext)
+	return explanation
+}
+
+func (e *Explanation) record(p *Program, evalContext evaluation.Context, outcome *Outcome, err error) {
+	*e = Explanation{}
+	for _, path := range p.Paths() {
+		value, found := Lookup(evalContext, path)
+		e.Fields = append(e.Fields, FieldValue{Path: path, Value: normalize(value), Found: found})
+	}
+	if err != nil {
+		e.Err = err
+		return
+	}
+	e.Matched = outcome.Matched
+	e.Actions = outcome.Actions
+}
+
+// Paths returns the distinct context paths referenced by the condition and the actions.
+// Paths inside WHERE conditions are relative to collection elements and are not included.
+func (p *Program) Paths() []string {
+	seen := make(map[string]bool)
+	var paths []string
+	var walk func(e Expr)
+	walk = func(e Expr) {
+		switch n := e.(type) {
+		case Path:
+			if !seen[n.Name] {
+				seen[n.Name] = true
+				paths = append(paths, n.Name)
+			}
+		case Where:
+			walk(n.Collection)
+		default:
+			for _, child := range children(e) {
+				walk(child)
+			}
+		}
+	}
+	walk(p.Condition)
+	for _, action := range p.Actions {
+		walk(action.Value)
+	}
+	return paths
+}
@@ -0,0 +1,596 @@
+package dsl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	// Embedded so that time zone names resolve on hosts without a zoneinfo database.
+	_ "time/tzdata"
+)
+
+// valueType is the static type of an expression, used to check function signatures at compile time.
+type valueType int
+
+const (
+	typeAny valueType = iota
+	typeNumber
+	typeString
+	typeBool
+	typeDate
+	typeList
+)
+
+func (t valueType) String() string {
+	switch t {
+	case typeNumber:
+		return "number"
+	case typeString:
+		return "string"
+	case typeBool:
+		return "boolean"
+	case typeDate:
+		return "date"
+	case typeList:
+		return "list"
+	}
+	return "any"
+}
+
+// accepts reports whether a value of type arg may be passed for a parameter of type t.
+// Dates are also accepted as strings, which are parsed when the function runs.
+func (t valueType) accepts(arg valueType) bool {
+	return t == typeAny || arg == typeAny || t == arg || (t == typeDate && arg == typeString)
+}
+
+// function is a built-in function of the DSL.
+type function struct {
+	params []valueType
+	// optional is the number of trailing parameters that may be omitted.
+	optional int
+	// variadic allows the last parameter to repeat.
+	variadic bool
+	result   valueType
+	call     func(r *runner, args []interface{}) (interface{}, error)
+}
+
+// functions is the built-in function library, keyed by lower-case name.
+// Date functions take an optional IANA time zone; without it, a date keeps the offset it was written with.
+var functions = map[string]function{
+	// Date and time
+	"now":          {result: typeDate, call: fnNow},
+	"date":         {params: []valueType{typeDate, typeString}, optional: 1, result: typeDate, call: fnDate},
+	"weekday":      datePart("weekday", typeString),
+	"day":          datePart("day", typeNumber),
+	"month":        datePart("month", typeNumber),
+	"year":         datePart("year", typeNumber),
+	"hour":         datePart("hour", typeNumber),
+	"between":      {params: []valueType{typeAny, typeAny, typeAny}, result: typeBool, call: fnBetween},
+	"days_between": {params: []valueType{typeDate, typeDate}, result: typeNumber, call: fnDaysBetween},
+	"add_days":     {params: []valueType{typeDate, typeNumber}, result: typeDate, call: fnAddDays},
+	// Strings
+	"lower":     stringFunction(strings.ToLower),
+	"upper":     stringFunction(strings.ToUpper),
+	"trim":      stringFunction(strings.TrimSpace),
+	"length":    {params: []valueType{typeAny}, result: typeNumber, call: fnLength},
+	"concat":    {params: []valueType{typeAny}, variadic: true, result: typeString, call: fnConcat},
+	"substring": {params: []valueType{typeString, typeNumber, typeNumber}, optional: 1, result: typeString, call: fnSubstring},
+	// Collections
+	"count": {params: []valueType{typeList}, result: typeNumber, call: fnCount},
+	"any":   {params: []valueType{typeList}, result: typeBool, call: fnAny},
+	"all":   {params: []valueType{typeList}, result: typeBool, call: fnAll},
+	"sum":   aggregate(total),
+	"min":   aggregate(func(values []float64) float64 { return reduce(values, math.Inf(1), math.Min) }),
+	"max":   aggregate(func(values []float64) float64 { return reduce(values, math.Inf(-1), math.Max) }),
+	"avg":   aggregate(func(values []float64) float64 { return total(values) / float64(len(values)) }),
+}
+
+// checkTypes checks the calls of the program against the function signatures.
+func (p *Program) checkTypes() error {
+	if _, err := typeOf(p.Condition); err != nil {
+		return err
+	}
+	for _, action := range p.Actions {
+		if _, err := typeOf(action.Value); err != nil {
+			return fmt.Errorf("action %s: %w", action.Target, err)
+		}
+	}
+	return nil
+}
+
+// typeOf infers the static type of an expression, checking the function calls it contains. Each node
+// is typed once: the types of a call's arguments are passed on to checkCall.
+func typeOf(e Expr) (valueType, error) {
+	nodes := children(e)
+	types := make([]valueType, len(nodes))
+	for i, child := range nodes {
+		childType, err := typeOf(child)
+		if err != nil {
+			return typeAny, err
+		}
+		types[i] = childType
+	}
+	switch n := e.(type) {
+	case Literal:
+		switch n.Value.(type) {
+		case float64:
+			return typeNumber, nil
+		case string:
+			return typeString, nil
+		case bool:
+			return typeBool, nil
+		}
+	case List, Where:
+		return typeList, nil
+	case Unary:
+		if n.Op == "NOT" {
+			return typeBool, nil
+		}
+		return typeNumber, nil
+	case Binary:
+		switch n.Op {
+		case "+", "-", "*", "/":
+			return typeNumber, nil
+		}
+		return typeBool, nil
+	case Call:
+		return checkCall(n, types)
+	}
+	return typeAny, nil
+}
+
+// checkCall checks a call against its function's signature, given the types of its arguments.
+func checkCall(call Call, argTypes []valueType) (valueType, error) {
+	fn, ok := functions[call.Name]
+	if !ok {
+		return typeAny, fmt.Errorf("unknown function %s", call.Name)
+	}
+	minArgs, maxArgs := len(fn.params)-fn.optional, len(fn.params)
+	if len(call.Args) < minArgs || (!fn.variadic && len(call.Args) > maxArgs) {
+		return typeAny, fmt.Errorf("%s expects %s, got %d", call.Name, arity(minArgs, maxArgs, fn.variadic), len(call.Args))
+	}
+	for i, arg := range call.Args {
+		param := fn.params[min(i, len(fn.params)-1)]
+		if _, isWhere := arg.(Where); isWhere && param != typeList {
+			return typeAny, fmt.Errorf("%s does not accept WHERE in argument %d", call.Name, i+1)
+		}
+		argType := argTypes[i]
+		if !param.accepts(argType) {
+			return typeAny, fmt.Errorf("%s expects a %s as argument %d, got %s", call.Name, param, i+1, argType)
+		}
+	}
+	// Time zone names are checked now rather than on every evaluation.
+	if isDateFunction(call.Name) && len(call.Args) == 2 {
+		if literal, ok := call.Args[1].(Literal); ok {
+			if _, err := loadLocation(literal.Value.(string)); err != nil {
+				return typeAny, fmt.Errorf("%s: unknown time zone %q", call.Name, literal.Value)
+			}
+		}
+	}
+	return fn.result, nil
+}
+
+func arity(minArgs, maxArgs int, variadic bool) string {
+	switch {
+	case variadic:
+		return fmt.Sprintf("at least %d arguments", minArgs)
+	case minArgs == maxArgs:
+		return fmt.Sprintf("%d arguments", minArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", minArgs, maxArgs)
+}
+
+func isDateFunction(name string) bool {
+	switch name {
+	case "date", "weekday", "day", "month", "year", "hour":
+		return true
+	}
+	return false
+}
+
+// evalCall evaluates a function call. Quantifiers over WHERE filters are evaluated element by element.
+func (r *runner) evalCall(call Call) (interface{}, error) {
+	fn := functions[call.Name]
+	if call.Name == "all" {
+		if where, ok := call.Args[0].(Where); ok {
+			return r.all(where)
+		}
+	}
+	args := make([]interface{}, len(call.Args))
+	for i, arg := range call.Args {
+		value, err := r.eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	value, err := fn.call(r, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", call.Name, err)
+	}
+	return value, nil
+}
+
+// evalWhere returns the elements of a collection for which the condition holds.
+func (r *runner) evalWhere(where Where) (interface{}, error) {
+	items, err := r.collection(where.Collection)
+	if err != nil {
+		return nil, err
+	}
+	matched := []interface{}{}
+	for _, item := range items {
+		ok, err := r.inScope(item, where.Condition)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, item)
+		}
+	}
+	return matched, nil
+}
+
+// all reports whether the condition holds for every element of the collection.
+func (r *runner) all(where Where) (bool, error) {
+	items, err := r.collection(where.Collection)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range items {
+		ok, err := r.inScope(item, where.Condition)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (r *runner) collection(e Expr) ([]interface{}, error) {
+	value, err := r.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("WHERE expects a list, got %v", value)
+	}
+	return items, nil
+}
+
+// inScope evaluates a condition with an element in scope.
+func (r *runner) inScope(item interface{}, condition Expr) (bool, error) {
+	r.scopes = append(r.scopes, item)
+	defer func() { r.scopes = r.scopes[:len(r.scopes)-1] }()
+	return r.evalBool(condition)
+}
+
+func fnNow(r *runner, _ []interface{}) (interface{}, error) {
+	return r.now(), nil
+}
+
+func fnDate(_ *runner, args []interface{}) (interface{}, error) {
+	location, err := locationArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+	return toTime(args[0], location)
+}
+
+// datePart is a function returning a part of a date, e.g. weekday(order.date, 'Europe/Madrid').
+func datePart(part string, result valueType) function {
+	return function{
+		params:   []valueType{typeDate, typeString},
+		optional: 1,
+		result:   result,
+		call: func(_ *runner, args []interface{}) (interface{}, error) {
+			location, err := locationArg(args, 1)
+			if err != nil {
+				return nil, err
+			}
+			t, err := toTime(args[0], location)
+			if err != nil {
+				return nil, err
+			}
+			value, _ := dateProperty(t, part)
+			return value, nil
+		},
+	}
+}
+
+// fnBetween reports whether a value lies in an inclusive range. When the value is a date, the bounds
+// are parsed as dates and a date-only upper bound, such as '2026-11-30', includes the whole day.
+func fnBetween(_ *runner, args []interface{}) (interface{}, error) {
+	value, from, to := args[0], args[1], args[2]
+	if t, ok := value.(time.Time); ok {
+		start, err := toTime(from, t.Location())
+		if err != nil {
+			return nil, err
+		}
+		end, err := toTime(to, t.Location())
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := to.(string); ok && len(s) == len(dateLayout) {
+			end = end.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return !t.Before(start) && !t.After(end), nil
+	}
+	afterStart, err := compare(">=", value, from)
+	if err != nil {
+		return nil, err
+	}
+	beforeEnd, err := compare("<=", value, to)
+	if err != nil {
+		return nil, err
+	}
+	return afterStart && beforeEnd, nil
+}
+
+func fnDaysBetween(_ *runner, args []interface{}) (interface{}, error) {
+	from, err := toTime(args[0], nil)
+	if err != nil {
+		return nil, err
+	}
+	to, err := toTime(args[1], nil)
+	if err != nil {
+		return nil, err
+	}
+	return math.Floor(to.Sub(from).Hours() / 24), nil
+}
+
+func fnAddDays(_ *runner, args []interface{}) (interface{}, error) {
+	t, err := toTime(args[0], nil)
+	if err != nil {
+		return nil, err
+	}
+	days, ok := args[1].(float64)
+	if !ok {
+		return nil, fmt.Errorf("expected a number of days, got %v", args[1])
+	}
+	return t.AddDate(0, 0, int(days)), nil
+}
+
+func stringFunction(fn func(string) string) function {
+	return function{
+		params: []valueType{typeString},
+		result: typeString,
+		call: func(_ *runner, args []interface{}) (interface{}, error) {
+			s, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string, got %v", args[0])
+			}
+			return fn(s), nil
+		},
+	}
+}
+
+func fnLength(_ *runner, args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case string:
+		return float64(len([]rune(v))), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	}
+	return nil, fmt.Errorf("expected a string or a list, got %v", args[0])
+}
+
+func fnConcat(_ *runner, args []interface{}) (interface{}, error) {
+	var b strings.Builder
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case float64:
+			b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		case time.Time:
+			b.WriteString(v.Format(time.RFC3339))
+		default:
+			fmt.Fprint(&b, v)
+		}
+	}
+	return b.String(), nil
+}
+
+func fnSubstring(_ *runner, args []interface{}) (interface{}, error) {
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a string, got %v", args[0])
+	}
+	runes := []rune(s)
+	start, ok := args[1].(float64)
+	if !ok {
+		return nil, fmt.Errorf("expected a start index, got %v", args[1])
+	}
+	// Bounds are clamped as floats: converting a large float to int overflows.
+	from := len(runes)
+	if start < float64(len(runes)) {
+		from = int(max(start, 0))
+	}
+	to := len(runes)
+	if len(args) > 2 {
+		length, ok := args[2].(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected a length, got %v", args[2])
+		}
+		if length < float64(len(runes)-from) {
+			to = from + int(max(length, 0))
+		}
+	}
+	return string(runes[from:to]), nil
+}
+
+func fnCount(_ *runner, args []interface{}) (interface{}, error) {
+	items, err := listArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return float64(len(items)), nil
+}
+
+// fnAny reports whether the list has an element; with WHERE, whether an element matches.
+func fnAny(_ *runner, args []interface{}) (interface{}, error) {
+	items, err := listArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return len(items) > 0, nil
+}
+
+// fnAll without WHERE reports whether every element of a list is true.
+func fnAll(_ *runner, args []interface{}) (interface{}, error) {
+	items, err := listArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if b, ok := item.(bool); !ok || !b {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// aggregate is a function reducing a list of numbers. Aggregates of an empty list are 0.
+func aggregate(fn func(values []float64) float64) function {
+	return function{
+		params: []valueType{typeList},
+		result: typeNumber,
+		call: func(r *runner, args []interface{}) (interface{}, error) {
+			items, err := listArg(args[0])
+			if err != nil {
+				return nil, err
+			}
+			if len(items) == 0 {
+				return 0.0, nil
+			}
+			values := make([]float64, len(items))
+			for i, item := range items {
+				if err := r.step(); err != nil {
+					return nil, err
+				}
+				f, ok := item.(float64)
+				if !ok {
+					return nil, fmt.Errorf("expected numbers, got %v", item)
+				}
+				values[i] = f
+			}
+			return fn(values), nil
+		},
+	}
+}
+
+func total(values []float64) float64 {
+	return reduce(values, 0, func(a, b float64) float64 { return a + b })
+}
+
+func reduce(values []float64, initial float64, fn func(a, b float64) float64) float64 {
+	result := initial
+	for _, v := range values {
+		result = fn(result, v)
+	}
+	return result
+}
+
+func listArg(value interface{}) ([]interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a list, got %v", value)
+	}
+	return items, nil
+}
+
+// locationArg returns the time zone passed at index i, or nil when it was omitted.
+func locationArg(args []interface{}, i int) (*time.Location, error) {
+	if len(args) <= i {
+		return nil, nil
+	}
+	name, ok := args[i].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a time zone name, got %v", args[i])
+	}
+	return loadLocation(name)
+}
+
+// locations caches the time zones loaded by name, which time.LoadLocation reads from the zoneinfo
+// database on every call.
+var locations sync.Map
+
+// loadLocation returns the time zone with the given name. Only known zones are cached, so that the
+// cache is bounded by the zoneinfo database.
+func loadLocation(name string) (*time.Location, error) {
+	if location, ok := locations.Load(name); ok {
+		return location.(*time.Location), nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, location)
+	return location, nil
+}
+
+// dateLayout is the layout of date-only values.
+const dateLayout = "2006-01-02"
+
+// dateLayouts are the accepted layouts of date strings, most specific first.
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", dateLayout}
+
+// toTime converts a date or a date string to a time. Strings without an offset are read in the given
+// location, or in UTC when it is nil. When a location is given, the result is expressed in it.
+func toTime(value interface{}, location *time.Location) (time.Time, error) {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case string:
+		parseIn := location
+		if parseIn == nil {
+			parseIn = time.UTC
+		}
+		parsed, err := parseTime(v, parseIn)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t = parsed
+	default:
+		return time.Time{}, fmt.Errorf("expected a date, got %v", value)
+	}
+	if location != nil {
+		t = t.In(location)
+	}
+	return t, nil
+}
+
+func parseTime(s string, location *time.Location) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// weekdays are the names returned by weekday(), indexed by time.Weekday.
+var weekdays = [...]string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+// dateProperty returns a part of a date. Parts are also readable as path suffixes, e.g. "order.date.weekday".
+func dateProperty(t time.Time, property string) (interface{}, bool) {
+	switch property {
+	case "weekday":
+		return weekdays[t.Weekday()], true
+	case "day":
+		return float64(t.Day()), true
+	case "month":
+		return float64(t.Month()), true
+	case "year":
+		return float64(t.Year()), true
+	case "hour":
+		return float64(t.Hour()), true
+	case "minute":
+		return float64(t.Minute()), true
+	}
+	return nil, false
+}
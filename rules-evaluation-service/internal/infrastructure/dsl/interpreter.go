@@ -0,0 +1,446 @@
+package dsl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+)
+
+// MissingFieldError is returned when an expression references a path that is absent from the context.
+type MissingFieldError struct {
+	Path string
+}
+
+func (e *MissingFieldError) Error() string {
+	return fmt.Sprintf("missing %s in context", e.Path)
+}
+
+// Outcome is the result of running a Program against a context.
+type Outcome struct {
+	Matched bool
+	// Actions holds the evaluated action values keyed by their dotted target path.
+	Actions map[string]interface{}
+}
+
+// Run evaluates the program condition and, when it matches, its actions. The run stops when it
+// exceeds the step budget or the timeout of the program limits, or when ctx is done.
+// When ctx carries an explanation (see WithExplanation), the run is recorded in it.
+func (p *Program) Run(ctx context.Context, evalContext evaluation.Context) (*Outcome, error) {
+	outcome, err := p.run(ctx, evalContext)
+	if explanation, ok := ctx.Value(explanationKey{}).(*Explanation); ok {
+		explanation.record(p, evalContext, outcome, err)
+	}
+	return outcome, err
+}
+
+func (p *Program) run(ctx context.Context, evalContext evaluation.Context) (*Outcome, error) {
+	r := newRunner(ctx, evalContext, p.limits)
+	matched, err := r.evalBool(p.Condition)
+	if err != nil {
+		return nil, err
+	}
+	if !matched {
+		return &Outcome{Matched: false}, nil
+	}
+
+	actions := make(map[string]interface{}, len(p.Actions))
+	for _, action := range p.Actions {
+		value, err := r.eval(action.Value)
+		if err != nil {
+			return nil, fmt.Errorf("action %s: %w", action.Target, err)
+		}
+		actions[action.Target] = value
+	}
+	if err := checkResultSize(actions, p.limits); err != nil {
+		return nil, err
+	}
+	return &Outcome{Matched: true, Actions: actions}, nil
+}
+
+// deadlineCheckInterval is the number of steps between two checks of the clock and the context,
+// which keeps the timeout cheap on the hot path.
+const deadlineCheckInterval = 64
+
+// runner holds the state of one program run.
+type runner struct {
+	ctx         context.Context
+	evalContext evaluation.Context
+	limits      Limits
+	steps       int
+	deadline    time.Time
+	// scopes holds the collection elements whose WHERE conditions are being evaluated, innermost last.
+	scopes []interface{}
+	// now is the evaluation clock, which may be pinned to replay a past decision.
+	now func() time.Time
+}
+
+func newRunner(ctx context.Context, evalContext evaluation.Context, limits Limits) *runner {
+	r := &runner{ctx: ctx, evalContext: evalContext, limits: limits, now: evaluation.ClockFromContext(ctx).Now}
+	if limits.Timeout > 0 {
+		r.deadline = time.Now().Add(limits.Timeout)
+	}
+	return r
+}
+
+// step accounts for one unit of evaluation work.
+func (r *runner) step() error {
+	r.steps++
+	if r.limits.MaxSteps > 0 && r.steps > r.limits.MaxSteps {
+		return limitExceeded(LimitMaxSteps, "rule took more than %d evaluation steps", r.limits.MaxSteps)
+	}
+	if r.steps%deadlineCheckInterval != 0 {
+		return nil
+	}
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+	if !r.deadline.IsZero() && time.Now().After(r.deadline) {
+		return limitExceeded(LimitTimeout, "rule ran for more than %s", r.limits.Timeout)
+	}
+	return nil
+}
+
+// Lookup resolves a dotted path in the context. Nested maps are walked first; flat keys
+// using the path itself or its underscore form (order.amount -> order_amount) are accepted as well.
+func Lookup(context evaluation.Context, path string) (interface{}, bool) {
+	if value, ok := lookupNested(map[string]interface{}(context), strings.Split(path, ".")); ok {
+		return value, true
+	}
+	if value, ok := context[path]; ok {
+		return value, true
+	}
+	value, ok := context[strings.ReplaceAll(path, ".", "_")]
+	return value, ok
+}
+
+// LookupFloat resolves a numeric value in the context.
+func LookupFloat(context evaluation.Context, path string) (float64, bool) {
+	value, ok := Lookup(context, path)
+	if !ok {
+		return 0, false
+	}
+	f, ok := normalize(value).(float64)
+	return f, ok
+}
+
+// LookupString resolves a string value in the context.
+func LookupString(context evaluation.Context, path string) (string, bool) {
+	value, ok := Lookup(context, path)
+	if !ok {
+		return "", false
+	}
+	s, ok := value.(string)
+	return s, ok
+}
+
+func lookupNested(current map[string]interface{}, segments []string) (interface{}, bool) {
+	value, ok := current[segments[0]]
+	if !ok {
+		return nil, false
+	}
+	if len(segments) == 1 {
+		return value, true
+	}
+	// A path through a list projects the rest of the path over its elements, e.g. "items.price".
+	if items, isList := value.([]interface{}); isList {
+		projected := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			if fields, isMap := item.(map[string]interface{}); isMap {
+				if v, found := lookupNested(fields, segments[1:]); found {
+					projected = append(projected, v)
+				}
+			}
+		}
+		return projected, true
+	}
+	nested, ok := value.(map[string]interface{})
+	if !ok {
+		if ctx, isCtx := value.(evaluation.Context); isCtx {
+			nested = ctx
+		} else {
+			return nil, false
+		}
+	}
+	return lookupNested(nested, segments[1:])
+}
+
+func (r *runner) eval(e Expr) (interface{}, error) {
+	if err := r.step(); err != nil {
+		return nil, err
+	}
+	switch n := e.(type) {
+	case Literal:
+		return n.Value, nil
+	case Path:
+		return r.lookup(n.Name)
+	case List:
+		items := make([]interface{}, len(n.Items))
+		for i, item := range n.Items {
+			value, err := r.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = value
+		}
+		return items, nil
+	case Unary:
+		return r.evalUnary(n)
+	case Binary:
+		return r.evalBinary(n)
+	case Call:
+		return r.evalCall(n)
+	case Where:
+		return r.evalWhere(n)
+	}
+	return nil, fmt.Errorf("unsupported expression %T", e)
+}
+
+// lookup resolves a path against the WHERE element in scope, if any, then the context. The path "it"
+// is the element itself. A path ending in a date part, such as "order.date.weekday", reads that part
+// of the date found at the rest of the path.
+func (r *runner) lookup(path string) (interface{}, error) {
+	if len(r.scopes) > 0 {
+		element := r.scopes[len(r.scopes)-1]
+		if path == "it" {
+			return normalize(element), nil
+		}
+		if fields, ok := element.(map[string]interface{}); ok {
+			if value, ok := lookupNested(fields, strings.Split(path, ".")); ok {
+				return normalize(value), nil
+			}
+		}
+	}
+	if value, ok := Lookup(r.evalContext, path); ok {
+		return normalize(value), nil
+	}
+	if dot := strings.LastIndex(path, "."); dot > 0 {
+		if value, ok := Lookup(r.evalContext, path[:dot]); ok {
+			if t, err := toTime(value, nil); err == nil {
+				if part, ok := dateProperty(t, path[dot+1:]); ok {
+					return part, nil
+				}
+			}
+		}
+	}
+	return nil, &MissingFieldError{Path: path}
+}
+
+func (r *runner) evalBool(e Expr) (bool, error) {
+	value, err := r.eval(e)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a boolean condition, got %v", value)
+	}
+	return b, nil
+}
+
+func (r *runner) evalUnary(n Unary) (interface{}, error) {
+	switch n.Op {
+	case "NOT":
+		b, err := r.evalBool(n.Operand)
+		return !b, err
+	case "-":
+		value, err := r.eval(n.Operand)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %v", value)
+		}
+		return -f, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %s", n.Op)
+}
+
+func (r *runner) evalBinary(n Binary) (interface{}, error) {
+	switch n.Op {
+	case "AND":
+		left, err := r.evalBool(n.Left)
+		if err != nil || !left {
+			return false, err
+		}
+		return r.evalBool(n.Right)
+	case "OR":
+		left, err := r.evalBool(n.Left)
+		if err != nil || left {
+			return left, err
+		}
+		return r.evalBool(n.Right)
+	}
+
+	left, err := r.eval(n.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := r.eval(n.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.Op {
+	case "=":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.Op, left, right)
+	case "+", "-", "*", "/":
+		return arithmetic(n.Op, left, right)
+	case "IN":
+		items, _ := right.([]interface{})
+		for _, item := range items {
+			if err := r.step(); err != nil {
+				return nil, err
+			}
+			if equal(left, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "CONTAINS":
+		if items, ok := left.([]interface{}); ok {
+			for _, item := range items {
+				if err := r.step(); err != nil {
+					return nil, err
+				}
+				if equal(item, right) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+		return stringOp(n.Op, left, right, strings.Contains)
+	case "STARTS_WITH":
+		return stringOp(n.Op, left, right, strings.HasPrefix)
+	case "ENDS_WITH":
+		return stringOp(n.Op, left, right, strings.HasSuffix)
+	}
+	return nil, fmt.Errorf("unsupported operator %s", n.Op)
+}
+
+func equal(left, right interface{}) bool {
+	switch l := left.(type) {
+	case float64, string, bool:
+		if r, ok := right.(time.Time); ok {
+			return equal(r, left)
+		}
+		return left == right
+	case time.Time:
+		r, err := toTime(right, l.Location())
+		return err == nil && l.Equal(r)
+	}
+	return false
+}
+
+func compare(op string, left, right interface{}) (bool, error) {
+	// Dates compare with dates and date strings.
+	if l, ok := left.(time.Time); ok {
+		r, err := toTime(right, l.Location())
+		if err != nil {
+			return false, fmt.Errorf("cannot compare %v %s %v", left, op, right)
+		}
+		return compareOrdered(op, l.UnixNano(), r.UnixNano()), nil
+	}
+	if r, ok := right.(time.Time); ok {
+		l, err := toTime(left, r.Location())
+		if err != nil {
+			return false, fmt.Errorf("cannot compare %v %s %v", left, op, right)
+		}
+		return compareOrdered(op, l.UnixNano(), r.UnixNano()), nil
+	}
+	if l, ok := left.(float64); ok {
+		r, ok := right.(float64)
+		if !ok {
+			return false, fmt.Errorf("cannot compare %v %s %v", left, op, right)
+		}
+		return compareOrdered(op, l, r), nil
+	}
+	if l, ok := left.(string); ok {
+		r, ok := right.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot compare %v %s %v", left, op, right)
+		}
+		return compareOrdered(op, l, r), nil
+	}
+	return false, fmt.Errorf("cannot compare %v %s %v", left, op, right)
+}
+
+func compareOrdered[T float64 | string | int64](op string, l, r T) bool {
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	}
+	return l >= r
+}
+
+func arithmetic(op string, left, right interface{}) (float64, error) {
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return 0, fmt.Errorf("cannot apply %s to %v and %v", op, left, right)
+	}
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	}
+	if r == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+	return l / r, nil
+}
+
+func stringOp(op string, left, right interface{}, fn func(string, string) bool) (bool, error) {
+	l, lok := left.(string)
+	r, rok := right.(string)
+	if !lok || !rok {
+		return false, fmt.Errorf("%s expects strings, got %v and %v", op, left, right)
+	}
+	return fn(l, r), nil
+}
+
+// normalize converts context values to the interpreter's value types: float64, string, bool, []interface{}
+// and, for values produced by date functions, time.Time.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = normalize(item)
+		}
+		return items
+	}
+	return value
+}
@@ -0,0 +1,150 @@
+package dsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifies the lexical class of a token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenKeyword
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+// token is a lexical unit of the DSL.
+type token struct {
+	kind  tokenKind
+	text  string
+	value float64
+	pos   int
+}
+
+var keywords = map[string]bool{
+	"IF": true, "THEN": true, "AND": true, "OR": true, "NOT": true, "IN": true,
+	"TRUE": true, "FALSE": true, "CONTAINS": true, "STARTS_WITH": true, "ENDS_WITH": true, "WHERE": true,
+}
+
+// lex splits DSL source into tokens.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[i+1 : end]), pos: i})
+			i = end + 1
+		case unicode.IsDigit(r):
+			end := i
+			for end < len(runes) && isNumberRune(runes, end) {
+				end++
+			}
+			text := string(runes[i:end])
+			if n := decimalPrefix(text); n < len(text) {
+				return nil, fmt.Errorf("invalid number %q: unexpected %q at position %d", text, []rune(text)[n], i+n)
+			}
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", text, i)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value, pos: i})
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_' || runes[end] == '.') {
+				end++
+			}
+			text := string(runes[i:end])
+			if keywords[strings.ToUpper(text)] {
+				tokens = append(tokens, token{kind: tokenKeyword, text: strings.ToUpper(text), pos: i})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: text, pos: i})
+			}
+			i = end
+		case strings.ContainsRune("=!<>+-*/", r):
+			end := i + 1
+			if end < len(runes) && strings.ContainsRune("=>", runes[end]) {
+				end++
+			}
+			op := string(runes[i:end])
+			switch op {
+			case "=", "==", "!=", "<>", "<", "<=", ">", ">=", "+", "-", "*", "/":
+			default:
+				return nil, fmt.Errorf("unknown operator %q at position %d", op, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// isNumberRune reports whether the rune at i continues a number literal. The whole literal is scanned,
+// letters and dots included, so that a malformed number such as 1.2.3 is rejected instead of split.
+func isNumberRune(runes []rune, i int) bool {
+	r := runes[i]
+	if r == '+' || r == '-' {
+		return runes[i-1] == 'e' || runes[i-1] == 'E'
+	}
+	return unicode.IsDigit(r) || unicode.IsLetter(r) || r == '_' || r == '.'
+}
+
+// decimalPrefix returns the length of the longest prefix of text that is a decimal number: digits,
+// optionally followed by a fraction and an exponent.
+func decimalPrefix(text string) int {
+	digits := func(i int) int {
+		for i < len(text) && text[i] >= '0' && text[i] <= '9' {
+			i++
+		}
+		return i
+	}
+	n := digits(0)
+	if n < len(text) && text[n] == '.' {
+		if end := digits(n + 1); end > n+1 {
+			n = end
+		} else {
+			return n
+		}
+	}
+	if n < len(text) && (text[n] == 'e' || text[n] == 'E') {
+		start := n + 1
+		if start < len(text) && (text[start] == '+' || text[start] == '-') {
+			start++
+		}
+		if end := digits(start); end > start {
+			n = end
+		}
+	}
+	return n
+}
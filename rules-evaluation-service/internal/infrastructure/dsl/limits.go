@@ -0,0 +1,81 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"rules-evaluation-service/internal/domain/shared"
+	"rules-evaluation-service/internal/infrastructure/telemetry"
+)
+
+// Names of the limits, reported in LimitExceededError and the violations metric.
+const (
+	LimitMaxDepth       = "max_depth"
+	LimitMaxNodes       = "max_nodes"
+	LimitMaxSteps       = "max_steps"
+	LimitTimeout        = "timeout"
+	LimitMaxResultBytes = "max_result_bytes"
+)
+
+// Limits guards the interpreter against rules that would stall the evaluator, such as deeply
+// nested expressions or huge IN-lists. A zero value disables the corresponding limit.
+type Limits struct {
+	MaxDepth       int           // maximum expression nesting depth, checked at compile time
+	MaxNodes       int           // maximum number of expression nodes, checked at compile time
+	MaxSteps       int           // maximum number of evaluation steps per run
+	Timeout        time.Duration // maximum wall-clock time per run
+	MaxResultBytes int           // maximum JSON size of the evaluated actions
+}
+
+// DefaultLimits returns the limits applied by Compile.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxDepth:       32,
+		MaxNodes:       2000,
+		MaxSteps:       100000,
+		Timeout:        100 * time.Millisecond,
+		MaxResultBytes: 64 * 1024,
+	}
+}
+
+// limitExceeded records a limit violation and returns the corresponding error.
+func limitExceeded(limit, format string, args ...interface{}) error {
+	telemetry.RuleLimitViolationsTotal.WithLabelValues(limit).Inc()
+	return shared.NewLimitExceededError(limit, fmt.Sprintf(format, args...))
+}
+
+// checkSize enforces the compile-time limits on a parsed program.
+func (p *Program) checkSize(limits Limits) error {
+	nodes := 0
+	var walk func(e Expr)
+	walk = func(e Expr) {
+		nodes++
+		for _, child := range children(e) {
+			walk(child)
+		}
+	}
+	walk(p.Condition)
+	for _, action := range p.Actions {
+		walk(action.Value)
+	}
+	if limits.MaxNodes > 0 && nodes > limits.MaxNodes {
+		return limitExceeded(LimitMaxNodes, "rule has %d expression nodes, the maximum is %d", nodes, limits.MaxNodes)
+	}
+	return nil
+}
+
+// checkResultSize enforces the result size limit on evaluated actions.
+func checkResultSize(actions map[string]interface{}, limits Limits) error {
+	if limits.MaxResultBytes <= 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(actions)
+	if err != nil {
+		return fmt.Errorf("failed to encode actions: %w", err)
+	}
+	if len(encoded) > limits.MaxResultBytes {
+		return limitExceeded(LimitMaxResultBytes, "actions take %d bytes, the maximum is %d", len(encoded), limits.MaxResultBytes)
+	}
+	return nil
+}
@@ -0,0 +1,503 @@
+package dsl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Program is a compiled DSL rule of the form "IF <condition> THEN <action>, <action>...".
+type Program struct {
+	Condition Expr
+	Actions   []Action
+	// limits are the limits the program was compiled with; they also apply when it runs.
+	limits Limits
+}
+
+// Action assigns the value of an expression to a dotted target path, e.g. "discount.percentage = 10".
+type Action struct {
+	Target string
+	Value  Expr
+}
+
+// Expr is a node of a compiled expression.
+type Expr interface {
+	expr()
+}
+
+// Literal is a constant number, string or boolean.
+type Literal struct {
+	Value interface{}
+}
+
+// Path references a value of the evaluation context, e.g. "order.amount".
+type Path struct {
+	Name string
+}
+
+// List is a parenthesised list of expressions, used with IN or as a list-valued action.
+type List struct {
+	Items []Expr
+}
+
+// Unary is a NOT or negation applied to an expression.
+type Unary struct {
+	Op      string
+	Operand Expr
+}
+
+// Binary is a logical, comparison or arithmetic operation.
+type Binary struct {
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+// Call is a call of a built-in function, e.g. "lower(customer.email)".
+type Call struct {
+	Name string
+	Args []Expr
+}
+
+// Where filters the elements of a collection, e.g. "items WHERE category = 'electronics'".
+// The condition is evaluated once per element; its paths resolve against the element first.
+type Where struct {
+	Collection Expr
+	Condition  Expr
+}
+
+func (Literal) expr() {}
+func (Path) expr()    {}
+func (List) expr()    {}
+func (Unary) expr()   {}
+func (Binary) expr()  {}
+func (Call) expr()    {}
+func (Where) expr()   {}
+
+// children returns the direct sub-expressions of an expression.
+func children(e Expr) []Expr {
+	switch n := e.(type) {
+	case List:
+		return n.Items
+	case Unary:
+		return []Expr{n.Operand}
+	case Binary:
+		return []Expr{n.Left, n.Right}
+	case Call:
+		return n.Args
+	case Where:
+		return []Expr{n.Collection, n.Condition}
+	}
+	return nil
+}
+
+// Compile parses DSL source into a Program, within DefaultLimits.
+func Compile(src string) (*Program, error) {
+	return CompileWithLimits(src, DefaultLimits())
+}
+
+// CompileWithLimits parses DSL source into a Program that is compiled and run within the given limits.
+func CompileWithLimits(src string, limits Limits) (*Program, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSL: %w", err)
+	}
+	p := &parser{tokens: tokens, maxDepth: limits.MaxDepth}
+	program, err := p.parseProgram()
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSL: %w", err)
+	}
+	if err := program.checkSize(limits); err != nil {
+		return nil, err
+	}
+	if err := program.checkTypes(); err != nil {
+		return nil, fmt.Errorf("invalid DSL: %w", err)
+	}
+	program.limits = limits
+	return program, nil
+}
+
+type parser struct {
+	tokens   []token
+	pos      int
+	depth    int
+	maxDepth int
+}
+
+// enter descends one nesting level (parentheses, NOT or negation) and fails beyond the maximum depth.
+// Every successful enter is paired with a deferred leave.
+func (p *parser) enter() error {
+	p.depth++
+	if p.maxDepth > 0 && p.depth > p.maxDepth {
+		return limitExceeded(LimitMaxDepth, "expression nesting exceeds %d levels at position %d", p.maxDepth, p.peek().pos)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tokenKeyword && t.text == kw
+}
+
+func (p *parser) isOperator(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if !p.isKeyword(kw) {
+		return p.errorf("expected %s", kw)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	found := t.text
+	if t.kind == tokenEOF {
+		found = "end of input"
+	}
+	return fmt.Errorf("%s at position %d, found %q", fmt.Sprintf(format, args...), t.pos, found)
+}
+
+// parseProgram parses "[IF] condition THEN action {(AND|,) action}". The IF keyword is optional.
+func (p *parser) parseProgram() (*Program, error) {
+	if p.isKeyword("IF") {
+		p.next()
+	}
+	condition, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("THEN"); err != nil {
+		return nil, err
+	}
+
+	var actions []Action
+	for {
+		action, err := p.parseAction()
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+		if p.isKeyword("AND") || p.peek().kind == tokenComma {
+			p.next()
+			continue
+		}
+		break
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.errorf("unexpected token")
+	}
+	return &Program{Condition: condition, Actions: actions}, nil
+}
+
+func (p *parser) parseAction() (Action, error) {
+	t := p.next()
+	if t.kind != tokenIdent {
+		return Action{}, fmt.Errorf("expected action target at position %d, found %q", t.pos, t.text)
+	}
+	if !p.isOperator("=", "==") {
+		return Action{}, p.errorf("expected '=' after %s", t.text)
+	}
+	p.next()
+	value, err := p.parseAdditive()
+	if err != nil {
+		return Action{}, err
+	}
+	return Action{Target: t.text, Value: value}, nil
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: "OR", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: "AND", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return Unary{Op: "NOT", Operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.isOperator("=", "==", "!=", "<>", "<", "<=", ">", ">="):
+		op := normalizeOperator(p.next().text)
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return Binary{Op: op, Left: left, Right: right}, nil
+	case p.isKeyword("IN"):
+		p.next()
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return Binary{Op: "IN", Left: left, Right: list}, nil
+	case p.isKeyword("NOT"):
+		p.next()
+		if err := p.expectKeyword("IN"); err != nil {
+			return nil, err
+		}
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return Unary{Op: "NOT", Operand: Binary{Op: "IN", Left: left, Right: list}}, nil
+	case p.isKeyword("CONTAINS"), p.isKeyword("STARTS_WITH"), p.isKeyword("ENDS_WITH"):
+		op := p.next().text
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return Binary{Op: op, Left: left, Right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseList() (Expr, error) {
+	if p.peek().kind != tokenLParen {
+		return nil, p.errorf("expected '(' to start a list")
+	}
+	p.next()
+	var items []Expr
+	for {
+		item, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.peek().kind == tokenComma {
+			p.next()
+			continue
+		}
+		break
+	}
+	if p.peek().kind != tokenRParen {
+		return nil, p.errorf("expected ')' to close a list")
+	}
+	p.next()
+	return List{Items: items}, nil
+}
+
+func (p *parser) parseAdditive() (Expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+", "-") {
+		op := p.next().text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: op, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*", "/") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: op, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.isOperator("-") {
+		p.next()
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Unary{Op: "-", Operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenNumber:
+		p.next()
+		return Literal{Value: t.value}, nil
+	case t.kind == tokenString:
+		p.next()
+		return Literal{Value: t.text}, nil
+	case t.kind == tokenKeyword && (t.text == "TRUE" || t.text == "FALSE"):
+		p.next()
+		return Literal{Value: t.text == "TRUE"}, nil
+	case t.kind == tokenIdent:
+		p.next()
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(t)
+		}
+		if strings.HasSuffix(t.text, ".") {
+			return nil, fmt.Errorf("invalid path %q at position %d", t.text, t.pos)
+		}
+		return Path{Name: t.text}, nil
+	case t.kind == tokenLParen:
+		p.next()
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		// A parenthesised, comma-separated sequence is a list literal, e.g. ('electronics', 'books').
+		if p.peek().kind == tokenComma {
+			items := []Expr{inner}
+			for p.peek().kind == tokenComma {
+				p.next()
+				item, err := p.parseAdditive()
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			if p.peek().kind != tokenRParen {
+				return nil, p.errorf("expected ')' to close a list")
+			}
+			p.next()
+			return List{Items: items}, nil
+		}
+		if p.peek().kind != tokenRParen {
+			return nil, p.errorf("expected ')'")
+		}
+		p.next()
+		return inner, nil
+	}
+	return nil, p.errorf("expected a value")
+}
+
+// parseCall parses the arguments of a function call, "name(arg, arg WHERE condition, ...)".
+func (p *parser) parseCall(name token) (Expr, error) {
+	p.next()
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	call := Call{Name: strings.ToLower(name.text)}
+	if p.peek().kind == tokenRParen {
+		p.next()
+		return call, nil
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.isKeyword("WHERE") {
+			p.next()
+			condition, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			arg = Where{Collection: arg, Condition: condition}
+		}
+		call.Args = append(call.Args, arg)
+		if p.peek().kind == tokenComma {
+			p.next()
+			continue
+		}
+		break
+	}
+	if p.peek().kind != tokenRParen {
+		return nil, p.errorf("expected ')' to close the arguments of %s", name.text)
+	}
+	p.next()
+	return call, nil
+}
+
+func normalizeOperator(op string) string {
+	switch op {
+	case "==":
+		return "="
+	case "<>":
+		return "!="
+	}
+	return op
+}
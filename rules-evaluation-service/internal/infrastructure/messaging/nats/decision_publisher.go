@@ -0,0 +1,102 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"rules-evaluation-service/internal/domain/decision"
+)
+
+// decisionEventType is the analytics event type of a decision record.
+const decisionEventType = "EvaluationDecision"
+
+// decisionEvent is the analytics domain event envelope carrying a decision record.
+type decisionEvent struct {
+	ID          string                 `json:"id"`
+	Type        string                 `json:"type"`
+	AggregateID string                 `json:"aggregateId"`
+	Version     int                    `json:"version"`
+	Data        decision.Record        `json:"data"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Timestamp   time.Time              `json:"timestamp"`
+}
+
+// DecisionPublisher publishes decision records to a JetStream subject, one message per record.
+type DecisionPublisher struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	subject string
+	source  string
+}
+
+// NewDecisionPublisher connects to NATS. The stream covering the subject is expected to be configured externally.
+func NewDecisionPublisher(url, subject, source string) (*DecisionPublisher, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to get JetStream context: %w", err)
+	}
+
+	return &DecisionPublisher{conn: conn, js: js, subject: subject, source: source}, nil
+}
+
+// PublishBatch publishes the records asynchronously and waits until JetStream acknowledged all of them.
+func (p *DecisionPublisher) PublishBatch(ctx context.Context, records []decision.Record) error {
+	futures := make([]nats.PubAckFuture, 0, len(records))
+	for _, record := range records {
+		payload, err := json.Marshal(decisionEvent{
+			ID:          record.DecisionID,
+			Type:        decisionEventType,
+			AggregateID: record.DecisionID,
+			Version:     1,
+			Data:        record,
+			Metadata:    map[string]interface{}{"source": p.source, "category": record.Category},
+			Timestamp:   record.Timestamp,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal decision record: %w", err)
+		}
+		msg := nats.NewMsg(p.subject)
+		msg.Data = payload
+		// The decision ID lets JetStream discard duplicates of retried publications.
+		msg.Header.Set(nats.MsgIdHdr, record.DecisionID)
+		future, err := p.js.PublishMsgAsync(msg)
+		if err != nil {
+			return fmt.Errorf("failed to publish decision record: %w", err)
+		}
+		futures = append(futures, future)
+	}
+
+	select {
+	case <-p.js.PublishAsyncComplete():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	var errs []error
+	for _, future := range futures {
+		select {
+		case err := <-future.Err():
+			errs = append(errs, err)
+		default:
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close drains pending publications and closes the NATS connection.
+func (p *DecisionPublisher) Close() {
+	if p.conn != nil {
+		_ = p.conn.Drain()
+	}
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rules-evaluation-service/internal/domain/coupon"
+)
+
+// CouponUsageStore is an in-memory coupon.UsageStore for single-instance deployments and tests.
+type CouponUsageStore struct {
+	mu           sync.Mutex
+	reservations map[string]*coupon.Reservation
+	now          func() time.Time
+}
+
+// NewCouponUsageStore creates a new in-memory CouponUsageStore.
+func NewCouponUsageStore() *CouponUsageStore {
+	return &CouponUsageStore{
+		reservations: make(map[string]*coupon.Reservation),
+		now:          time.Now,
+	}
+}
+
+// Reserve records a usage of the code if its limits allow it, or extends the holder's active reservation.
+func (s *CouponUsageStore) Reserve(ctx context.Context, code, customerID, holder string, limits coupon.Limits, ttl time.Duration) (*coupon.Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var global, perCustomer int
+	var held *coupon.Reservation
+	for id, r := range s.reservations {
+		if r.Status == coupon.ReservationReserved && now.After(r.ExpiresAt) {
+			delete(s.reservations, id)
+			continue
+		}
+		if r.Code != code {
+			continue
+		}
+		if holder != "" && r.Holder == holder && r.Status == coupon.ReservationReserved {
+			held = r
+		}
+		global++
+		if r.CustomerID == customerID {
+			perCustomer++
+		}
+	}
+
+	if held != nil {
+		held.ExpiresAt = now.Add(ttl)
+		reserved := *held
+		return &reserved, nil
+	}
+	if limit := limits.GlobalLimit(); limit > 0 && global >= limit {
+		return nil, &coupon.LimitExceededError{Code: code, Scope: "global", Limit: limit}
+	}
+	if limits.PerCustomer > 0 && perCustomer >= limits.PerCustomer {
+		return nil, &coupon.LimitExceededError{Code: code, Scope: "customer", Limit: limits.PerCustomer}
+	}
+
+	reservation := &coupon.Reservation{
+		ID:         uuid.NewString(),
+		Code:       code,
+		CustomerID: customerID,
+		Holder:     holder,
+		Status:     coupon.ReservationReserved,
+		ExpiresAt:  now.Add(ttl),
+	}
+	s.reservations[reservation.ID] = reservation
+	reserved := *reservation
+	return &reserved, nil
+}
+
+// Commit turns an active reservation into a permanent usage.
+func (s *CouponUsageStore) Commit(ctx context.Context, reservationID string) error {
+	return s.settle(reservationID, coupon.ReservationCommitted)
+}
+
+// Release gives an active reservation back so it no longer counts against the limits.
+func (s *CouponUsageStore) Release(ctx context.Context, reservationID string) error {
+	return s.settle(reservationID, coupon.ReservationReleased)
+}
+
+func (s *CouponUsageStore) settle(reservationID string, status coupon.ReservationStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.reservations[reservationID]
+	if !ok || r.Status != coupon.ReservationReserved || s.now().After(r.ExpiresAt) {
+		return coupon.ErrReservationNotFound
+	}
+	r.Status = status
+	if status == coupon.ReservationReleased {
+		delete(s.reservations, reservationID)
+	}
+	return nil
+}
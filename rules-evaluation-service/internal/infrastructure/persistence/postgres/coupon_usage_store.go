@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"rules-evaluation-service/internal/domain/coupon"
+	"rules-evaluation-service/internal/domain/shared"
+	"rules-evaluation-service/internal/infrastructure/telemetry"
+)
+
+// CouponReservationDBModel is the GORM model for a coupon usage reservation.
+type CouponReservationDBModel struct {
+	ID         string `gorm:"primaryKey"`
+	Code       string `gorm:"not null;index:idx_coupon_reservations_code_status"`
+	CustomerID string `gorm:"index"`
+	Holder     string `gorm:"index"`
+	Status     string `gorm:"not null;index:idx_coupon_reservations_code_status"`
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (CouponReservationDBModel) TableName() string {
+	return "coupon_reservations"
+}
+
+func (m CouponReservationDBModel) toDomain() *coupon.Reservation {
+	return &coupon.Reservation{
+		ID:         m.ID,
+		Code:       m.Code,
+		CustomerID: m.CustomerID,
+		Holder:     m.Holder,
+		Status:     coupon.ReservationStatus(m.Status),
+		ExpiresAt:  m.ExpiresAt,
+	}
+}
+
+// CouponUsageStore is a Postgres-backed coupon.UsageStore shared by all evaluator instances.
+type CouponUsageStore struct {
+	db *gorm.DB
+}
+
+func NewCouponUsageStore(db *gorm.DB) *CouponUsageStore {
+	return &CouponUsageStore{db: db}
+}
+
+// Reserve checks the limits and inserts the reservation in one transaction, unless the holder already has an
+// active reservation of the code, which is extended instead. A transaction-scoped advisory lock on the code
+// serialises concurrent reservations of the same code across instances.
+func (s *CouponUsageStore) Reserve(ctx context.Context, code, customerID, holder string, limits coupon.Limits, ttl time.Duration) (*coupon.Reservation, error) {
+	start := time.Now()
+	defer func() {
+		telemetry.DBQueryDuration.WithLabelValues("CouponReserve").Observe(time.Since(start).Seconds())
+	}()
+
+	var reservation *coupon.Reservation
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", code).Error; err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		if holder != "" {
+			var held CouponReservationDBModel
+			err := tx.Where("code = ? AND holder = ? AND status = ? AND expires_at > ?", code, holder, coupon.ReservationReserved, now).
+				Take(&held).Error
+			if err == nil {
+				held.ExpiresAt = now.Add(ttl)
+				if err := tx.Model(&held).Update("expires_at", held.ExpiresAt).Error; err != nil {
+					return err
+				}
+				reservation = held.toDomain()
+				return nil
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		active := tx.Model(&CouponReservationDBModel{}).
+			Where("code = ?", code).
+			Where("status = ? OR (status = ? AND expires_at > ?)", coupon.ReservationCommitted, coupon.ReservationReserved, now)
+
+		if limit := limits.GlobalLimit(); limit > 0 {
+			var global int64
+			if err := active.Session(&gorm.Session{}).Count(&global).Error; err != nil {
+				return err
+			}
+			if int(global) >= limit {
+				return &coupon.LimitExceededError{Code: code, Scope: "global", Limit: limit}
+			}
+		}
+		if limits.PerCustomer > 0 {
+			var perCustomer int64
+			if err := active.Session(&gorm.Session{}).Where("customer_id = ?", customerID).Count(&perCustomer).Error; err != nil {
+				return err
+			}
+			if int(perCustomer) >= limits.PerCustomer {
+				return &coupon.LimitExceededError{Code: code, Scope: "customer", Limit: limits.PerCustomer}
+			}
+		}
+
+		model := CouponReservationDBModel{
+			ID:         uuid.NewString(),
+			Code:       code,
+			CustomerID: customerID,
+			Holder:     holder,
+			Status:     string(coupon.ReservationReserved),
+			ExpiresAt:  now.Add(ttl),
+		}
+		if err := tx.Create(&model).Error; err != nil {
+			return err
+		}
+		reservation = model.toDomain()
+		return nil
+	})
+
+	var limitErr *coupon.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return nil, limitErr
+	}
+	if err != nil {
+		return nil, shared.NewInfrastructureError("failed to reserve coupon usage", err)
+	}
+	return reservation, nil
+}
+
+// Commit turns an active reservation into a permanent usage.
+func (s *CouponUsageStore) Commit(ctx context.Context, reservationID string) error {
+	return s.settle(ctx, "CouponCommit", reservationID, coupon.ReservationCommitted)
+}
+
+// Release gives an active reservation back so it no longer counts against the limits.
+func (s *CouponUsageStore) Release(ctx context.Context, reservationID string) error {
+	return s.settle(ctx, "CouponRelease", reservationID, coupon.ReservationReleased)
+}
+
+func (s *CouponUsageStore) settle(ctx context.Context, operation, reservationID string, status coupon.ReservationStatus) error {
+	start := time.Now()
+	defer func() {
+		telemetry.DBQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	}()
+
+	result := s.db.WithContext(ctx).Model(&CouponReservationDBModel{}).
+		Where("id = ? AND status = ? AND expires_at > ?", reservationID, coupon.ReservationReserved, time.Now().UTC()).
+		Update("status", string(status))
+	if result.Error != nil {
+		return shared.NewInfrastructureError("failed to settle coupon reservation", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return coupon.ErrReservationNotFound
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+
+	"rules-evaluation-service/internal/infrastructure/persistence/postgres"
+)
+
+// ApplyMigrations applies database migrations
+func ApplyMigrations(db *gorm.DB) error {
+	// Auto-migrate the schema
+	return db.AutoMigrate(&postgres.CouponReservationDBModel{})
+}
@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// credentialSecret keys the hashes of client credentials. Buckets live in one instance only, so the
+// secret is drawn anew by each process.
+var credentialSecret = func() []byte {
+	secret := make([]byte, sha256.Size)
+	if _, err := rand.Read(secret); err != nil {
+		panic("ratelimit: cannot draw the credential secret: " + err.Error())
+	}
+	return secret
+}()
+
+// CredentialKey returns the client key of a request identified by a credential of the given kind,
+// e.g. an API key. Credentials are not verified before rate limiting, so the key is an HMAC of the
+// credential: the limiter never keeps the credential itself, and the number of buckets that made-up
+// credentials can create is capped by the ClientLimiter.
+func CredentialKey(kind, credential string) string {
+	mac := hmac.New(sha256.New, credentialSecret)
+	mac.Write([]byte(credential))
+	return kind + ":" + hex.EncodeToString(mac.Sum(nil)[:16])
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ClientLimiter rate limits requests per client with a token bucket for each client.
+// Buckets of clients that have been idle for longer than the idle TTL are discarded. Once there are
+// maxClients buckets, further clients share a single overflow bucket until idle buckets are discarded.
+type ClientLimiter struct {
+	mu         sync.Mutex
+	limit      rate.Limit
+	burst      int
+	idleTTL    time.Duration
+	maxClients int
+	buckets    map[string]*bucket
+	overflow   *bucket
+	lastSweep  time.Time
+	now        func() time.Time
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewClientLimiter creates a limiter that allows each client requestsPerSecond requests on average,
+// with bursts of up to burst requests. maxClients bounds the number of buckets; 0 means no bound.
+func NewClientLimiter(requestsPerSecond float64, burst int, idleTTL time.Duration, maxClients int) *ClientLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &ClientLimiter{
+		limit:      rate.Limit(requestsPerSecond),
+		burst:      burst,
+		idleTTL:    idleTTL,
+		maxClients: maxClients,
+		buckets:    make(map[string]*bucket),
+		overflow:   &bucket{limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), burst)},
+		now:        time.Now,
+	}
+}
+
+// SetClock replaces the time source of the limiter; used in tests.
+func (l *ClientLimiter) SetClock(now func() time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = now
+}
+
+// Allow takes a token from the client's bucket. When the bucket is empty it returns false and
+// the time after which a token will be available.
+func (l *ClientLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[client]
+	switch {
+	case ok:
+	case l.maxClients > 0 && len(l.buckets) >= l.maxClients:
+		b = l.overflow
+	default:
+		b = &bucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.buckets[client] = b
+	}
+	b.lastSeen = now
+
+	reservation := b.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep discards idle buckets, at most once per idle TTL.
+func (l *ClientLimiter) sweep(now time.Time) {
+	if l.idleTTL <= 0 || now.Sub(l.lastSweep) < l.idleTTL {
+		return
+	}
+	l.lastSweep = now
+	for client, b := range l.buckets {
+		if now.Sub(b.lastSeen) > l.idleTTL {
+			delete(l.buckets, client)
+		}
+	}
+}
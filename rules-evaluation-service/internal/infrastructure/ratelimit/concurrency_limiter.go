@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"rules-evaluation-service/internal/infrastructure/telemetry"
+)
+
+// ConcurrencyOptions configures a ConcurrencyLimiter.
+type ConcurrencyOptions struct {
+	// TargetP99 is the p99 latency above which the concurrency limit is reduced.
+	TargetP99 time.Duration
+	// InitialLimit, MinLimit and MaxLimit bound the number of requests served concurrently.
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// Window is the number of completed requests the p99 latency is computed over.
+	Window int
+}
+
+// ConcurrencyLimiter bounds the number of requests served concurrently and adapts the bound
+// to the observed latency: after every window of requests the limit is cut by a tenth when the
+// p99 latency exceeded the target, and raised by one when it did not and the limit was reached.
+type ConcurrencyLimiter struct {
+	mu        sync.Mutex
+	opts      ConcurrencyOptions
+	limit     int
+	inFlight  int
+	saturated bool
+	latencies []time.Duration
+}
+
+// NewConcurrencyLimiter creates an adaptive concurrency limiter.
+func NewConcurrencyLimiter(opts ConcurrencyOptions) *ConcurrencyLimiter {
+	if opts.MinLimit < 1 {
+		opts.MinLimit = 1
+	}
+	if opts.MaxLimit < opts.MinLimit {
+		opts.MaxLimit = opts.MinLimit
+	}
+	if opts.Window < 1 {
+		opts.Window = 100
+	}
+	limit := min(max(opts.InitialLimit, opts.MinLimit), opts.MaxLimit)
+	telemetry.ConcurrencyLimit.Set(float64(limit))
+	return &ConcurrencyLimiter{
+		opts:      opts,
+		limit:     limit,
+		latencies: make([]time.Duration, 0, opts.Window),
+	}
+}
+
+// Acquire admits a request if fewer requests than the current limit are in flight. The returned
+// function must be called with the request's latency once the request completes.
+func (l *ConcurrencyLimiter) Acquire() (func(latency time.Duration), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight >= l.limit {
+		l.saturated = true
+		return nil, false
+	}
+	l.inFlight++
+	if l.inFlight == l.limit {
+		l.saturated = true
+	}
+	var once sync.Once
+	return func(latency time.Duration) {
+		once.Do(func() { l.release(latency) })
+	}, true
+}
+
+// Limit returns the current concurrency limit.
+func (l *ConcurrencyLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+func (l *ConcurrencyLimiter) release(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	l.latencies = append(l.latencies, latency)
+	if len(l.latencies) < l.opts.Window {
+		return
+	}
+
+	sort.Slice(l.latencies, func(i, j int) bool { return l.latencies[i] < l.latencies[j] })
+	p99 := l.latencies[(len(l.latencies)*99-1)/100]
+	switch {
+	case l.opts.TargetP99 > 0 && p99 > l.opts.TargetP99:
+		l.limit = max(l.opts.MinLimit, l.limit*9/10)
+	case l.saturated:
+		l.limit = min(l.opts.MaxLimit, l.limit+1)
+	}
+	telemetry.ConcurrencyLimit.Set(float64(l.limit))
+	l.latencies = l.latencies[:0]
+	l.saturated = false
+}
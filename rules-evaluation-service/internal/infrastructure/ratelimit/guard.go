@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// Rejection reasons.
+const (
+	ReasonRateLimited = "rate_limited"
+	ReasonOverloaded  = "overloaded"
+)
+
+// overloadRetryAfter is suggested to clients whose request was shed; the concurrency limit is
+// re-evaluated continuously, so a short back-off is enough.
+const overloadRetryAfter = time.Second
+
+// Rejection describes why a request was not admitted and when the client may retry.
+type Rejection struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// RetryAfterSeconds formats the retry delay as a Retry-After value, rounded up to whole seconds.
+func (r Rejection) RetryAfterSeconds() string {
+	return strconv.Itoa(max(1, int(math.Ceil(r.RetryAfter.Seconds()))))
+}
+
+// Guard admits requests through an optional per-client rate limiter and an optional adaptive
+// concurrency limiter. A nil Guard admits every request.
+type Guard struct {
+	clients     *ClientLimiter
+	concurrency *ConcurrencyLimiter
+}
+
+// NewGuard creates a guard; either limiter may be nil to disable it.
+func NewGuard(clients *ClientLimiter, concurrency *ConcurrencyLimiter) *Guard {
+	return &Guard{clients: clients, concurrency: concurrency}
+}
+
+// Admit checks the client's rate limit and then the concurrency limit. When the request is admitted
+// the returned function must be called with its latency once it completes.
+func (g *Guard) Admit(client string) (func(latency time.Duration), *Rejection) {
+	if g == nil {
+		return func(time.Duration) {}, nil
+	}
+	if g.clients != nil {
+		if ok, retryAfter := g.clients.Allow(client); !ok {
+			return nil, &Rejection{Reason: ReasonRateLimited, RetryAfter: retryAfter}
+		}
+	}
+	if g.concurrency != nil {
+		release, ok := g.concurrency.Acquire()
+		if !ok {
+			return nil, &Rejection{Reason: ReasonOverloaded, RetryAfter: overloadRetryAfter}
+		}
+		return release, nil
+	}
+	return func(time.Duration) {}, nil
+}
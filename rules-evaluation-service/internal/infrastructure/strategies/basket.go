@@ -0,0 +1,48 @@
+package strategies
+
+import (
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/dsl"
+)
+
+// basketLine is a line of the "items" array of an evaluation context.
+type basketLine struct {
+	Context  evaluation.Context
+	Category string
+	Price    float64
+	Quantity float64
+}
+
+// Amount returns the line total before any adjustment.
+func (l basketLine) Amount() float64 {
+	return l.Price * l.Quantity
+}
+
+// basketLines returns the lines of the basket, and false when the context has no basket.
+// Lines without a quantity count once.
+func basketLines(evalContext evaluation.Context) ([]basketLine, bool) {
+	items, ok := dsl.Lookup(evalContext, "items")
+	if !ok {
+		return nil, false
+	}
+	raw, ok := items.([]interface{})
+	if !ok || len(raw) == 0 {
+		return nil, false
+	}
+
+	lines := make([]basketLine, 0, len(raw))
+	for _, entry := range raw {
+		item, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		line := basketLine{Context: evaluation.Context(item), Quantity: 1}
+		line.Category, _ = dsl.LookupString(line.Context, "category")
+		line.Price, _ = dsl.LookupFloat(line.Context, "price")
+		if quantity, ok := dsl.LookupFloat(line.Context, "quantity"); ok {
+			line.Quantity = quantity
+		}
+		lines = append(lines, line)
+	}
+	return lines, true
+}
@@ -0,0 +1,254 @@
+package strategies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"rules-evaluation-service/internal/domain/coupon"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/dsl"
+	"rules-evaluation-service/internal/infrastructure/telemetry"
+
+	"go.opentelemetry.io/otel"
+)
+
+// CouponsStrategy is a strategy for validating coupon codes and reserving their usage.
+//
+// The rule condition selects the code and the actions configure its validation, for example:
+//
+//	IF coupon.code = 'WELCOME10' THEN coupon.discount_percentage = 10, coupon.valid_from = '2026-01-01',
+//	    coupon.valid_until = '2026-12-31', coupon.min_basket = 50, coupon.eligible_categories = ('books', 'toys'),
+//	    coupon.per_customer_limit = 1, coupon.global_limit = 1000
+//
+// A valid coupon reserves one usage; the checkout then commits or releases the returned reservation. The
+// reservation is held for checkout.id, or customer.id without one, so evaluating the same checkout again
+// returns the reservation it already holds. Dry runs (see evaluation.WithDryRun) validate the coupon without reserving it.
+type CouponsStrategy struct {
+	dslLimits
+	store          coupon.UsageStore
+	reservationTTL time.Duration
+}
+
+func NewCouponsStrategy(store coupon.UsageStore, reservationTTL time.Duration) *CouponsStrategy {
+	return &CouponsStrategy{store: store, reservationTTL: reservationTTL}
+}
+
+func init() {
+	Register(Registration{
+		Descriptor: evaluation.StrategyDescriptor{
+			Category:    "COUPONS",
+			Description: "Coupon validation with basket and category conditions, reserving one usage per valid coupon.",
+			ContextSchema: []evaluation.ContextField{
+				{Path: "coupon.code", Type: "string", Required: true, Description: "Coupon code entered by the customer"},
+				{Path: "customer.id", Type: "string", Description: "Customer identifier, used for per-customer limits"},
+				{Path: "checkout.id", Type: "string", Description: "Checkout identifier; evaluations of one checkout share a reservation"},
+				{Path: "order.amount", Type: "number", Description: "Order amount, checked against the minimum basket"},
+				{Path: "order.category", Type: "string", Description: "Order category, used when there are no items"},
+				{Path: "items", Type: "array", Description: "Basket lines with category, price and quantity"},
+			},
+		},
+		Resolution: evaluation.ResolutionConfig{Policy: evaluation.PolicyFirstMatch, ValueKey: "discount_amount"},
+		Factory: func(deps Dependencies) (evaluation.EvaluationStrategy, error) {
+			if deps.CouponStore == nil {
+				return nil, fmt.Errorf("coupon usage store is required")
+			}
+			return NewCouponsStrategy(deps.CouponStore, deps.CouponReservationTTL), nil
+		},
+	})
+}
+
+// Evaluate evaluates a coupons rule.
+func (s *CouponsStrategy) Evaluate(ctx context.Context, dslContent string, evalContext evaluation.Context) (evaluation.Result, error) {
+	ctx, span := otel.Tracer("strategy").Start(ctx, "CouponsStrategy.Evaluate")
+	defer span.End()
+
+	program, err := s.compile(dslContent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coupons DSL: %w", err)
+	}
+
+	code, ok := dsl.LookupString(evalContext, "coupon.code")
+	if !ok || code == "" {
+		return rejected("Missing coupon.code in context"), nil
+	}
+
+	outcome, err := program.Run(ctx, evalContext)
+	var missing *dsl.MissingFieldError
+	if errors.As(err, &missing) {
+		return rejected(fmt.Sprintf("Missing %s in context", missing.Path)), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate coupons rule: %w", err)
+	}
+	if !outcome.Matched {
+		return rejected("coupon is not applicable"), nil
+	}
+	actions := outcome.Actions
+
+	if reason, err := checkValidityWindow(actions, evaluation.ClockFromContext(ctx).Now()); err != nil {
+		return nil, err
+	} else if reason != "" {
+		return rejected(reason), nil
+	}
+
+	orderAmount, _ := dsl.LookupFloat(evalContext, "order.amount")
+	if minBasket := actionFloat(actions, "coupon.min_basket", 0); orderAmount < minBasket {
+		return rejected(fmt.Sprintf("basket total %.2f is below the minimum of %.2f", orderAmount, minBasket)), nil
+	}
+
+	eligibleAmount := orderAmount
+	if categories, ok := actions["coupon.eligible_categories"]; ok {
+		eligibleAmount = amountInCategories(evalContext, toStringSet(categories))
+		if eligibleAmount <= 0 {
+			return rejected("basket has no items in the eligible categories"), nil
+		}
+	}
+
+	limits := coupon.Limits{
+		PerCustomer: int(actionFloat(actions, "coupon.per_customer_limit", 0)),
+		Global:      int(actionFloat(actions, "coupon.global_limit", 0)),
+		SingleUse:   actions["coupon.single_use"] == true,
+	}
+	customerID, _ := dsl.LookupString(evalContext, "customer.id")
+	if limits.PerCustomer > 0 && customerID == "" {
+		return rejected("Missing customer.id in context"), nil
+	}
+
+	result := evaluation.Result{
+		"eligible":        true,
+		"coupon_code":     code,
+		"eligible_amount": eligibleAmount,
+	}
+	// A dry run neither reserves a usage nor checks the limits, which depend on the reservations of live traffic.
+	if !evaluation.IsDryRun(ctx) {
+		holder := customerID
+		if checkoutID, ok := dsl.LookupString(evalContext, "checkout.id"); ok && checkoutID != "" {
+			holder = checkoutID
+		}
+		reservation, err := s.store.Reserve(ctx, code, customerID, holder, limits, s.reservationTTL)
+		var limitErr *coupon.LimitExceededError
+		if errors.As(err, &limitErr) {
+			telemetry.CouponReservationsTotal.WithLabelValues("limit_exceeded").Inc()
+			return rejected(limitErr.Error()), nil
+		}
+		if err != nil {
+			telemetry.CouponReservationsTotal.WithLabelValues("error").Inc()
+			return nil, fmt.Errorf("failed to reserve coupon usage: %w", err)
+		}
+		telemetry.CouponReservationsTotal.WithLabelValues("reserved").Inc()
+		result["reservation_id"] = reservation.ID
+		result["reservation_expires_at"] = reservation.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	if percentage := actionFloat(actions, "coupon.discount_percentage", 0); percentage > 0 {
+		result["discount_percentage"] = percentage
+		result["discount_amount"] = math.Round(eligibleAmount*percentage) / 100
+	} else if amount := actionFloat(actions, "coupon.discount_amount", 0); amount > 0 {
+		result["discount_amount"] = math.Min(amount, eligibleAmount)
+	}
+	monetary := map[string]float64{"eligible_amount": eligibleAmount}
+	if discount, ok := result["discount_amount"].(float64); ok {
+		monetary["discount_amount"] = discount
+	}
+	if amounts := amounts(lookupFirst(evalContext, "currency", "order.currency"), monetary); amounts != nil {
+		result["amounts"] = amounts
+	}
+	return result, nil
+}
+
+// Compensate releases the usage reserved for a result that is not served, e.g. a coupon rule that lost
+// the resolution of its category. Reservations that already expired need no release.
+func (s *CouponsStrategy) Compensate(ctx context.Context, result evaluation.Result) error {
+	reservationID, ok := result["reservation_id"].(string)
+	if !ok {
+		return nil
+	}
+	err := s.store.Release(ctx, reservationID)
+	if errors.Is(err, coupon.ErrReservationNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release coupon reservation %s: %w", reservationID, err)
+	}
+	telemetry.CouponReservationsTotal.WithLabelValues("released").Inc()
+	return nil
+}
+
+func rejected(reason string) evaluation.Result {
+	return evaluation.Result{"eligible": false, "reason": reason}
+}
+
+// checkValidityWindow returns a rejection reason when now is outside the coupon's validity window.
+func checkValidityWindow(actions map[string]interface{}, now time.Time) (string, error) {
+	if from, ok := actions["coupon.valid_from"].(string); ok {
+		start, err := parseDate(from)
+		if err != nil {
+			return "", fmt.Errorf("invalid coupon.valid_from: %w", err)
+		}
+		if now.Before(start) {
+			return fmt.Sprintf("coupon is not valid before %s", from), nil
+		}
+	}
+	if until, ok := actions["coupon.valid_until"].(string); ok {
+		end, err := parseDate(until)
+		if err != nil {
+			return "", fmt.Errorf("invalid coupon.valid_until: %w", err)
+		}
+		// A date-only end of validity includes the whole day.
+		if len(until) == len("2006-01-02") {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !now.Before(end) {
+			return fmt.Sprintf("coupon expired on %s", until), nil
+		}
+	}
+	return "", nil
+}
+
+// parseDate accepts RFC 3339 timestamps and ISO dates, which are interpreted in UTC.
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// amountInCategories sums the basket lines that belong to one of the categories.
+// Without a basket, the whole order counts when the order category is eligible.
+func amountInCategories(evalContext evaluation.Context, categories map[string]bool) float64 {
+	lines, ok := basketLines(evalContext)
+	if !ok {
+		category, _ := dsl.LookupString(evalContext, "order.category")
+		if categories[category] {
+			amount, _ := dsl.LookupFloat(evalContext, "order.amount")
+			return amount
+		}
+		return 0
+	}
+
+	var total float64
+	for _, line := range lines {
+		if categories[line.Category] {
+			total += line.Amount()
+		}
+	}
+	return total
+}
+
+func toStringSet(value interface{}) map[string]bool {
+	set := make(map[string]bool)
+	switch v := value.(type) {
+	case string:
+		set[v] = true
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				set[s] = true
+			}
+		}
+	}
+	return set
+}
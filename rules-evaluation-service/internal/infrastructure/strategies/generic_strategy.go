@@ -0,0 +1,52 @@
+package strategies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/dsl"
+
+	"go.opentelemetry.io/otel"
+)
+
+// GenericDescriptor describes the fallback strategy used for categories without special handling.
+var GenericDescriptor = evaluation.StrategyDescriptor{
+	Category:    "*",
+	Description: "Runs the rule with the core DSL interpreter and returns the actions of a matching rule.",
+}
+
+// GenericStrategy evaluates any rule with the core DSL interpreter. It has no category-specific
+// semantics: when the condition matches, the actions are returned as they are.
+type GenericStrategy struct {
+	dslLimits
+}
+
+func NewGenericStrategy() *GenericStrategy {
+	return &GenericStrategy{}
+}
+
+// Evaluate evaluates a rule of any category.
+func (s *GenericStrategy) Evaluate(ctx context.Context, dslContent string, evalContext evaluation.Context) (evaluation.Result, error) {
+	_, span := otel.Tracer("strategy").Start(ctx, "GenericStrategy.Evaluate")
+	defer span.End()
+
+	program, err := s.compile(dslContent)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome, err := program.Run(ctx, evalContext)
+	var missing *dsl.MissingFieldError
+	if errors.As(err, &missing) {
+		return rejected(fmt.Sprintf("Missing %s in context", missing.Path)), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate rule: %w", err)
+	}
+	if !outcome.Matched {
+		return evaluation.Result{"eligible": false}, nil
+	}
+	return evaluation.Result{"eligible": true, "actions": outcome.Actions}, nil
+}
@@ -0,0 +1,21 @@
+package strategies
+
+import "rules-evaluation-service/internal/infrastructure/dsl"
+
+// dslLimits holds the limits a strategy compiles and runs its rules within.
+type dslLimits struct {
+	limits *dsl.Limits
+}
+
+// SetLimits sets the DSL limits of the strategy's rules, which are dsl.DefaultLimits until it is called.
+func (l *dslLimits) SetLimits(limits dsl.Limits) {
+	l.limits = &limits
+}
+
+// compile compiles a rule within the strategy's limits.
+func (l *dslLimits) compile(src string) (*dsl.Program, error) {
+	if l.limits == nil {
+		return dsl.Compile(src)
+	}
+	return dsl.CompileWithLimits(src, *l.limits)
+}
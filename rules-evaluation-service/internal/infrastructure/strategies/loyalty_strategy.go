@@ -0,0 +1,271 @@
+package strategies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/dsl"
+
+	"go.opentelemetry.io/otel"
+)
+
+// LoyaltyStrategy is a strategy for evaluating loyalty rules.
+//
+// Rule actions configure earning, redemption and tiers, for example:
+//
+//	IF customer.tier IN ('GOLD', 'PLATINUM') THEN earn.points_per_unit = 1, earn.tier_multiplier.GOLD = 2,
+//	    earn.category_multiplier.electronics = 3, redeem.rate = 0.01, redeem.min_points = 500, tier.GOLD = 5000
+type LoyaltyStrategy struct {
+	dslLimits
+}
+
+func NewLoyaltyStrategy() *LoyaltyStrategy {
+	return &LoyaltyStrategy{}
+}
+
+func init() {
+	Register(Registration{
+		Descriptor: evaluation.StrategyDescriptor{
+			Category:    "LOYALTY",
+			Description: "Points earning with tier and category multipliers, redemptions and tier progress.",
+			ContextSchema: []evaluation.ContextField{
+				{Path: "order.amount", Type: "number", Required: true, Description: "Order amount"},
+				{Path: "customer.tier", Type: "string", Description: "Current loyalty tier"},
+				{Path: "order.category", Type: "string", Description: "Order category, used when there are no items"},
+				{Path: "items", Type: "array", Description: "Basket lines with category, price and quantity"},
+				{Path: "loyalty.redeem_points", Type: "number", Description: "Points the customer wants to redeem"},
+				{Path: "customer.points_balance", Type: "number", Description: "Current points balance"},
+				{Path: "customer.lifetime_points", Type: "number", Description: "Lifetime points, used for tier progress"},
+			},
+		},
+		Resolution: evaluation.ResolutionConfig{Policy: evaluation.PolicyBestForCustomer, ValueKey: "points_earned"},
+		Factory: func(Dependencies) (evaluation.EvaluationStrategy, error) {
+			return NewLoyaltyStrategy(), nil
+		},
+	})
+}
+
+// LoyaltyResult is the typed outcome of a loyalty rule.
+type LoyaltyResult struct {
+	Eligible          bool
+	Reason            string
+	PointsEarned      int64
+	MultiplierApplied float64
+	Redemption        *Redemption
+	TierProgress      *TierProgress
+}
+
+// Redemption describes the conversion of points into currency.
+type Redemption struct {
+	Allowed        bool
+	PointsRedeemed int64
+	Value          float64
+	Reason         string
+}
+
+// TierProgress describes the customer's position relative to the tier qualification thresholds.
+type TierProgress struct {
+	CurrentTier    string
+	NextTier       string
+	Points         int64
+	PointsToNext   int64
+	ProgressToNext float64
+}
+
+// ToResult converts the typed loyalty result to an evaluation result.
+func (r LoyaltyResult) ToResult() evaluation.Result {
+	result := evaluation.Result{"eligible": r.Eligible}
+	if !r.Eligible {
+		if r.Reason != "" {
+			result["reason"] = r.Reason
+		}
+		return result
+	}
+	result["points_earned"] = r.PointsEarned
+	result["multiplier_applied"] = r.MultiplierApplied
+	if r.Redemption != nil {
+		redemption := map[string]interface{}{
+			"allowed":         r.Redemption.Allowed,
+			"points_redeemed": r.Redemption.PointsRedeemed,
+			"value":           r.Redemption.Value,
+		}
+		if r.Redemption.Reason != "" {
+			redemption["reason"] = r.Redemption.Reason
+		}
+		result["redemption"] = redemption
+	}
+	if r.TierProgress != nil {
+		result["tier_progress"] = map[string]interface{}{
+			"current_tier":     r.TierProgress.CurrentTier,
+			"next_tier":        r.TierProgress.NextTier,
+			"points":           r.TierProgress.Points,
+			"points_to_next":   r.TierProgress.PointsToNext,
+			"progress_to_next": r.TierProgress.ProgressToNext,
+		}
+	}
+	return result
+}
+
+// Evaluate evaluates a loyalty rule.
+func (s *LoyaltyStrategy) Evaluate(ctx context.Context, dslContent string, evalContext evaluation.Context) (evaluation.Result, error) {
+	_, span := otel.Tracer("strategy").Start(ctx, "LoyaltyStrategy.Evaluate")
+	defer span.End()
+
+	result, err := s.evaluate(ctx, dslContent, evalContext)
+	if err != nil {
+		return nil, err
+	}
+	return result.ToResult(), nil
+}
+
+func (s *LoyaltyStrategy) evaluate(ctx context.Context, dslContent string, evalContext evaluation.Context) (*LoyaltyResult, error) {
+	program, err := s.compile(dslContent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loyalty DSL: %w", err)
+	}
+
+	outcome, err := program.Run(ctx, evalContext)
+	var missing *dsl.MissingFieldError
+	if errors.As(err, &missing) {
+		return &LoyaltyResult{Reason: fmt.Sprintf("Missing %s in context", missing.Path)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate loyalty rule: %w", err)
+	}
+	if !outcome.Matched {
+		return &LoyaltyResult{}, nil
+	}
+
+	orderAmount, ok := dsl.LookupFloat(evalContext, "order.amount")
+	if !ok {
+		return &LoyaltyResult{Reason: "Missing order.amount in context"}, nil
+	}
+
+	actions := outcome.Actions
+	pointsPerUnit := actionFloat(actions, "earn.points_per_unit", 1)
+	baseMultiplier := actionFloat(actions, "earn.multiplier", 1)
+	tier, _ := dsl.LookupString(evalContext, "customer.tier")
+	tierMultiplier := actionFloat(actions, "earn.tier_multiplier."+tier, 1)
+
+	// Category multipliers apply per line when the basket is available, otherwise to the whole order.
+	categoryMultipliers := actionsWithPrefix(actions, "earn.category_multiplier.")
+	weightedAmount := orderAmount * categoryMultiplier(categoryMultipliers, evalContext, "order.category")
+	if lines, ok := basketLines(evalContext); ok {
+		weightedAmount = 0
+		for _, line := range lines {
+			weightedAmount += line.Amount() * categoryMultiplier(categoryMultipliers, line.Context, "category")
+		}
+	}
+
+	multiplier := baseMultiplier * tierMultiplier
+	pointsEarned := int64(math.Floor(weightedAmount * pointsPerUnit * multiplier))
+	effectiveMultiplier := multiplier
+	if orderAmount > 0 {
+		effectiveMultiplier = math.Round(weightedAmount/orderAmount*multiplier*100) / 100
+	}
+
+	return &LoyaltyResult{
+		Eligible:          true,
+		PointsEarned:      pointsEarned,
+		MultiplierApplied: effectiveMultiplier,
+		Redemption:        redemption(actions, evalContext, orderAmount),
+		TierProgress:      tierProgress(actions, evalContext, pointsEarned),
+	}, nil
+}
+
+// redemption converts the requested points into currency within the configured limits.
+func redemption(actions map[string]interface{}, evalContext evaluation.Context, orderAmount float64) *Redemption {
+	requested, ok := dsl.LookupFloat(evalContext, "loyalty.redeem_points")
+	if !ok || requested <= 0 {
+		return nil
+	}
+	rate := actionFloat(actions, "redeem.rate", 0)
+	if rate <= 0 {
+		return &Redemption{Reason: "redemption is not enabled by this rule"}
+	}
+	points := int64(requested)
+	if balance, ok := dsl.LookupFloat(evalContext, "customer.points_balance"); ok && points > int64(balance) {
+		return &Redemption{Reason: fmt.Sprintf("insufficient points balance: %d", int64(balance))}
+	}
+	if minPoints := int64(actionFloat(actions, "redeem.min_points", 0)); points < minPoints {
+		return &Redemption{Reason: fmt.Sprintf("at least %d points must be redeemed", minPoints)}
+	}
+	if maxPoints := int64(actionFloat(actions, "redeem.max_points", 0)); maxPoints > 0 && points > maxPoints {
+		points = maxPoints
+	}
+	// Points never redeem for more than the order is worth.
+	if maxByAmount := int64(math.Floor(orderAmount / rate)); points > maxByAmount {
+		points = maxByAmount
+	}
+	return &Redemption{
+		Allowed:        true,
+		PointsRedeemed: points,
+		Value:          math.Round(float64(points)*rate*100) / 100,
+	}
+}
+
+// tierProgress places the customer's qualifying points, including the points just earned, between tier thresholds.
+func tierProgress(actions map[string]interface{}, evalContext evaluation.Context, pointsEarned int64) *TierProgress {
+	thresholds := actionsWithPrefix(actions, "tier.")
+	if len(thresholds) == 0 {
+		return nil
+	}
+	tiers := make([]string, 0, len(thresholds))
+	for name := range thresholds {
+		tiers = append(tiers, name)
+	}
+	sort.Slice(tiers, func(i, j int) bool { return thresholds[tiers[i]] < thresholds[tiers[j]] })
+
+	lifetime, _ := dsl.LookupFloat(evalContext, "customer.lifetime_points")
+	points := int64(lifetime) + pointsEarned
+	progress := &TierProgress{Points: points, ProgressToNext: 1}
+	var floor float64
+	for _, name := range tiers {
+		threshold := thresholds[name]
+		if float64(points) >= threshold {
+			progress.CurrentTier = name
+			floor = threshold
+			continue
+		}
+		progress.NextTier = name
+		progress.PointsToNext = int64(math.Ceil(threshold - float64(points)))
+		progress.ProgressToNext = math.Round((float64(points)-floor)/(threshold-floor)*100) / 100
+		break
+	}
+	return progress
+}
+
+func categoryMultiplier(multipliers map[string]float64, evalContext evaluation.Context, path string) float64 {
+	category, ok := dsl.LookupString(evalContext, path)
+	if !ok {
+		return 1
+	}
+	if multiplier, ok := multipliers[category]; ok {
+		return multiplier
+	}
+	return 1
+}
+
+// actionFloat returns a numeric action value, or the default when the action is absent.
+func actionFloat(actions map[string]interface{}, target string, defaultValue float64) float64 {
+	if value, ok := actions[target].(float64); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// actionsWithPrefix returns the numeric actions under a target prefix keyed by the remainder of the target.
+func actionsWithPrefix(actions map[string]interface{}, prefix string) map[string]float64 {
+	values := make(map[string]float64)
+	for target, value := range actions {
+		if f, ok := value.(float64); ok && strings.HasPrefix(target, prefix) {
+			values[strings.TrimPrefix(target, prefix)] = f
+		}
+	}
+	return values
+}
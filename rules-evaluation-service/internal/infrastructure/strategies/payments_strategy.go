@@ -0,0 +1,251 @@
+package strategies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/dsl"
+
+	"go.opentelemetry.io/otel"
+)
+
+// PaymentsStrategy is a strategy for evaluating payment rules.
+//
+// Actions are scoped by payment method, for example:
+//
+//	IF order.amount > 0 THEN payment.CARD.surcharge_percentage = 1.5, payment.CARD.acquirer = 'adyen',
+//	    payment.COD.max_amount = 300, payment.COD.countries = ('ES', 'PT'),
+//	    payment.BNPL.blocked_risk_flags = ('CHARGEBACK'), payment.BNPL.priority = 10, payment.preferred = 'CARD'
+//
+// The candidate methods are taken from payment.methods in the context, or from the methods the rule configures.
+type PaymentsStrategy struct {
+	dslLimits
+}
+
+func NewPaymentsStrategy() *PaymentsStrategy {
+	return &PaymentsStrategy{}
+}
+
+func init() {
+	Register(Registration{
+		Descriptor: evaluation.StrategyDescriptor{
+			Category:    "PAYMENTS",
+			Description: "Payment method eligibility, surcharges and routing, as a ranked method list.",
+			ContextSchema: []evaluation.ContextField{
+				{Path: "order.amount", Type: "number", Required: true, Description: "Order amount"},
+				{Path: "jurisdiction.country", Type: "string", Description: "Country of the payment; falls back to customer.country"},
+				{Path: "customer.risk_flags", Type: "array", Description: "Risk flags of the customer"},
+				{Path: "payment.methods", Type: "array", Description: "Methods offered at checkout; defaults to the methods the rule configures"},
+			},
+		},
+		Resolution: evaluation.ResolutionConfig{Policy: evaluation.PolicyHighestPriority, ValueKey: "total", Direction: evaluation.DirectionMinimize},
+		Factory: func(Dependencies) (evaluation.EvaluationStrategy, error) {
+			return NewPaymentsStrategy(), nil
+		},
+	})
+}
+
+// PaymentMethodOption is the evaluated eligibility and cost of one payment method.
+type PaymentMethodOption struct {
+	Method    string
+	Allowed   bool
+	Rank      int
+	Priority  float64
+	Surcharge float64
+	Discount  float64
+	Total     float64
+	Acquirer  string
+	Preferred bool
+	Reasons   []string
+}
+
+// Evaluate evaluates a payments rule.
+func (s *PaymentsStrategy) Evaluate(ctx context.Context, dslContent string, evalContext evaluation.Context) (evaluation.Result, error) {
+	_, span := otel.Tracer("strategy").Start(ctx, "PaymentsStrategy.Evaluate")
+	defer span.End()
+
+	program, err := s.compile(dslContent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payments DSL: %w", err)
+	}
+
+	outcome, err := program.Run(ctx, evalContext)
+	var missing *dsl.MissingFieldError
+	if errors.As(err, &missing) {
+		return rejected(fmt.Sprintf("Missing %s in context", missing.Path)), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate payments rule: %w", err)
+	}
+	if !outcome.Matched {
+		return evaluation.Result{"eligible": false}, nil
+	}
+
+	orderAmount, ok := dsl.LookupFloat(evalContext, "order.amount")
+	if !ok {
+		return rejected("Missing order.amount in context"), nil
+	}
+
+	options := rankPaymentMethods(outcome.Actions, evalContext, orderAmount)
+	return paymentsResult(options), nil
+}
+
+// rankPaymentMethods evaluates every candidate method and orders them: allowed methods first, then the
+// preferred method, then by descending priority, then by the lowest total paid by the customer.
+func rankPaymentMethods(actions map[string]interface{}, evalContext evaluation.Context, orderAmount float64) []PaymentMethodOption {
+	preferred := actionString(actions, "payment.preferred")
+	country := lookupFirst(evalContext, "jurisdiction.country", "customer.country")
+	riskFlags := map[string]bool{}
+	if flags, ok := dsl.Lookup(evalContext, "customer.risk_flags"); ok {
+		riskFlags = toStringSet(flags)
+	}
+
+	options := make([]PaymentMethodOption, 0)
+	for _, method := range candidateMethods(actions, evalContext) {
+		prefix := "payment." + method + "."
+		option := PaymentMethodOption{
+			Method:    method,
+			Allowed:   true,
+			Priority:  actionFloat(actions, prefix+"priority", 0),
+			Acquirer:  actionString(actions, prefix+"acquirer"),
+			Preferred: method == preferred,
+		}
+
+		if allowed, ok := actions[prefix+"allowed"].(bool); ok && !allowed {
+			option.disallow("disabled by rule")
+		}
+		if minAmount := actionFloat(actions, prefix+"min_amount", 0); minAmount > 0 && orderAmount < minAmount {
+			option.disallow(fmt.Sprintf("amount %.2f is below the minimum of %.2f", orderAmount, minAmount))
+		}
+		if maxAmount := actionFloat(actions, prefix+"max_amount", 0); maxAmount > 0 && orderAmount > maxAmount {
+			option.disallow(fmt.Sprintf("amount %.2f exceeds the maximum of %.2f", orderAmount, maxAmount))
+		}
+		if countries, ok := actions[prefix+"countries"]; ok && !toStringSet(countries)[country] {
+			option.disallow(fmt.Sprintf("not available in country %q", country))
+		}
+		for flag := range toStringSet(actions[prefix+"blocked_risk_flags"]) {
+			if riskFlags[flag] {
+				option.disallow(fmt.Sprintf("blocked by risk flag %s", flag))
+			}
+		}
+
+		option.Surcharge = DefaultRounding.Round(orderAmount*actionFloat(actions, prefix+"surcharge_percentage", 0)/100 +
+			actionFloat(actions, prefix+"surcharge_fixed", 0))
+		option.Discount = DefaultRounding.Round(orderAmount * actionFloat(actions, prefix+"discount_percentage", 0) / 100)
+		option.Total = DefaultRounding.Round(orderAmount + option.Surcharge - option.Discount)
+		if option.Allowed {
+			if option.Surcharge > 0 {
+				option.Reasons = append(option.Reasons, fmt.Sprintf("surcharge of %.2f", option.Surcharge))
+			}
+			if option.Discount > 0 {
+				option.Reasons = append(option.Reasons, fmt.Sprintf("discount of %.2f", option.Discount))
+			}
+			if option.Acquirer != "" {
+				option.Reasons = append(option.Reasons, fmt.Sprintf("routed to %s", option.Acquirer))
+			}
+			if option.Preferred {
+				option.Reasons = append(option.Reasons, "preferred method")
+			}
+		}
+		options = append(options, option)
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		a, b := options[i], options[j]
+		if a.Allowed != b.Allowed {
+			return a.Allowed
+		}
+		if a.Preferred != b.Preferred {
+			return a.Preferred
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if a.Total != b.Total {
+			return a.Total < b.Total
+		}
+		return a.Method < b.Method
+	})
+	for i := range options {
+		options[i].Rank = i + 1
+	}
+	return options
+}
+
+func (o *PaymentMethodOption) disallow(reason string) {
+	o.Allowed = false
+	o.Reasons = append(o.Reasons, reason)
+}
+
+// candidateMethods returns the methods offered by the context, or else the methods configured by the rule.
+func candidateMethods(actions map[string]interface{}, evalContext evaluation.Context) []string {
+	if offered, ok := dsl.Lookup(evalContext, "payment.methods"); ok {
+		set := toStringSet(offered)
+		methods := make([]string, 0, len(set))
+		for method := range set {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		return methods
+	}
+
+	set := make(map[string]bool)
+	for target := range actions {
+		rest, ok := strings.CutPrefix(target, "payment.")
+		if !ok {
+			continue
+		}
+		if method, _, ok := strings.Cut(rest, "."); ok {
+			set[method] = true
+		}
+	}
+	methods := make([]string, 0, len(set))
+	for method := range set {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// paymentsResult converts the ranked methods to an evaluation result. The total is the amount payable
+// with the preferred method.
+func paymentsResult(options []PaymentMethodOption) evaluation.Result {
+	methods := make([]map[string]interface{}, len(options))
+	routing := make(map[string]interface{})
+	var preferred string
+	var total float64
+	for i, o := range options {
+		reasons := o.Reasons
+		if reasons == nil {
+			reasons = []string{}
+		}
+		methods[i] = map[string]interface{}{
+			"method":    o.Method,
+			"allowed":   o.Allowed,
+			"rank":      o.Rank,
+			"surcharge": o.Surcharge,
+			"discount":  o.Discount,
+			"total":     o.Total,
+			"acquirer":  o.Acquirer,
+			"reasons":   reasons,
+		}
+		if o.Allowed && o.Acquirer != "" {
+			routing[o.Method] = o.Acquirer
+		}
+		if o.Allowed && preferred == "" {
+			preferred, total = o.Method, o.Total
+		}
+	}
+
+	return evaluation.Result{
+		"eligible":         preferred != "",
+		"methods":          methods,
+		"preferred_method": preferred,
+		"routing":          routing,
+		"total":            total,
+	}
+}
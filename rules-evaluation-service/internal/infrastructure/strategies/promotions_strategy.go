@@ -2,59 +2,76 @@ package strategies
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/dsl"
 
 	"go.opentelemetry.io/otel"
 )
 
 // PromotionsStrategy is a strategy for evaluating promotions rules.
-type PromotionsStrategy struct{}
+//
+// The condition can use the whole DSL and the discount.* actions of a matching rule are returned as
+// discount_* fields, for example:
+//
+//	IF between(now(), '2026-11-27', '2026-11-30') AND count(items WHERE category = 'toys') >= 2
+//	    THEN discount.percentage = 10
+type PromotionsStrategy struct {
+	dslLimits
+}
 
 func NewPromotionsStrategy() *PromotionsStrategy {
 	return &PromotionsStrategy{}
 }
 
+func init() {
+	Register(Registration{
+		Descriptor: evaluation.StrategyDescriptor{
+			Category:    "PROMOTIONS",
+			Description: "Percentage or fixed discounts for orders matching the rule condition.",
+			ContextSchema: []evaluation.ContextField{
+				{Path: "order.amount", Type: "number", Required: true, Description: "Order amount"},
+				{Path: "customer", Type: "object", Description: "Customer attributes, e.g. tier or email"},
+				{Path: "items", Type: "array", Description: "Basket lines with category, price and quantity"},
+			},
+		},
+		Resolution: evaluation.ResolutionConfig{Policy: evaluation.PolicyBestForCustomer, ValueKey: "discount_percentage"},
+		Factory: func(Dependencies) (evaluation.EvaluationStrategy, error) {
+			return NewPromotionsStrategy(), nil
+		},
+	})
+}
+
 // Evaluate evaluates a promotions rule.
-func (s *PromotionsStrategy) Evaluate(dslContent string, evalContext evaluation.Context) (evaluation.Result, error) {
-	ctx := context.Background()
-	_, span := otel.Tracer("strategy").Start(ctx, "PromotionsStrategy.Evaluate")
+func (s *PromotionsStrategy) Evaluate(ctx context.Context, dslContent string, evalContext evaluation.Context) (evaluation.Result, error) {
+	ctx, span := otel.Tracer("strategy").Start(ctx, "PromotionsStrategy.Evaluate")
 	defer span.End()
-	// Example DSL: "IF order.amount > 100 THEN discount.percentage = 10"
-	if !strings.Contains(dslContent, "order.amount >") {
-		return nil, fmt.Errorf("invalid promotions DSL: missing 'order.amount >'")
-	}
 
-	orderAmount, ok := evalContext["order_amount"].(float64)
-	if !ok {
-		return evaluation.Result{"eligible": false, "reason": "Missing order_amount in context"}, nil
+	program, err := s.compile(dslContent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid promotions DSL: %w", err)
 	}
 
-	// Simplified parsing logic
-	parts := strings.Split(dslContent, ">")
-	if len(parts) < 2 {
-		return nil, fmt.Errorf("invalid promotions DSL")
+	outcome, err := program.Run(ctx, evalContext)
+	var missing *dsl.MissingFieldError
+	if errors.As(err, &missing) {
+		return rejected(fmt.Sprintf("Missing %s in context", missing.Path)), nil
 	}
-	thresholdStr := strings.Split(strings.TrimSpace(parts[1]), " ")[0]
-	var threshold float64
-	if _, err := fmt.Sscanf(thresholdStr, "%f", &threshold); err != nil {
-		return nil, fmt.Errorf("invalid threshold in promotions DSL: %w", err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate promotions rule: %w", err)
+	}
+	if !outcome.Matched {
+		return evaluation.Result{"eligible": false}, nil
 	}
 
-	if orderAmount > threshold {
-		// Another simplified extraction
-		discountParts := strings.Split(dslContent, "= ")
-		if len(discountParts) < 2 {
-			return nil, fmt.Errorf("invalid promotions DSL: missing discount value")
-		}
-		var discount float64
-		if _, err := fmt.Sscanf(strings.TrimSpace(discountParts[1]), "%f", &discount); err != nil {
-			return nil, fmt.Errorf("invalid discount in promotions DSL: %w", err)
+	result := evaluation.Result{"eligible": true}
+	for target, value := range outcome.Actions {
+		if name, ok := strings.CutPrefix(target, "discount."); ok {
+			result["discount_"+name] = value
 		}
-		return evaluation.Result{"eligible": true, "discount_percentage": discount}, nil
 	}
-
-	return evaluation.Result{"eligible": false}, nil
+	return result, nil
 }
@@ -0,0 +1,104 @@
+package strategies
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"rules-evaluation-service/internal/domain/coupon"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/dsl"
+)
+
+// Dependencies holds what strategy factories may need to build a strategy.
+type Dependencies struct {
+	CouponStore          coupon.UsageStore
+	CouponReservationTTL time.Duration
+	Rounding             map[string]Rounding
+	// Limits are the DSL limits the strategies compile and run rules within; dsl.DefaultLimits when nil.
+	Limits *dsl.Limits
+}
+
+// Factory builds a strategy from its dependencies.
+type Factory func(deps Dependencies) (evaluation.EvaluationStrategy, error)
+
+// Registration binds a rule category to the factory of its strategy.
+type Registration struct {
+	Descriptor evaluation.StrategyDescriptor
+	Factory    Factory
+	// Resolution is the default conflict resolution of the category, which configuration may override.
+	Resolution evaluation.ResolutionConfig
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Registration)
+)
+
+// Register adds a strategy to the registry. Strategies register themselves from init functions,
+// so registering a category twice is a programming error and panics.
+func Register(r Registration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	category := r.Descriptor.Category
+	if category == "" || r.Factory == nil {
+		panic("strategies: Register requires a category and a factory")
+	}
+	if _, exists := registry[category]; exists {
+		panic("strategies: Register called twice for category " + category)
+	}
+	registry[category] = r
+}
+
+// Registered returns all registered strategies, sorted by category.
+func Registered() []Registration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	registrations := make([]Registration, 0, len(registry))
+	for _, r := range registry {
+		registrations = append(registrations, r)
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].Descriptor.Category < registrations[j].Descriptor.Category
+	})
+	return registrations
+}
+
+// DefaultResolutions returns the default conflict resolution of every registered category.
+func DefaultResolutions() map[string]evaluation.ResolutionConfig {
+	resolutions := make(map[string]evaluation.ResolutionConfig)
+	for _, r := range Registered() {
+		resolutions[r.Descriptor.Category] = r.Resolution
+	}
+	return resolutions
+}
+
+// Build creates the strategies of all registered categories except the disabled ones, within the DSL
+// limits of the dependencies, and returns them with their descriptors.
+func Build(deps Dependencies, disabled []string) (map[string]evaluation.EvaluationStrategy, []evaluation.StrategyDescriptor, error) {
+	skip := make(map[string]bool, len(disabled))
+	for _, category := range disabled {
+		skip[category] = true
+	}
+
+	built := make(map[string]evaluation.EvaluationStrategy)
+	descriptors := make([]evaluation.StrategyDescriptor, 0)
+	for _, r := range Registered() {
+		if skip[r.Descriptor.Category] {
+			continue
+		}
+		strategy, err := r.Factory(deps)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to build %s strategy: %w", r.Descriptor.Category, err)
+		}
+		if limited, ok := strategy.(interface{ SetLimits(dsl.Limits) }); ok && deps.Limits != nil {
+			limited.SetLimits(*deps.Limits)
+		}
+		built[r.Descriptor.Category] = strategy
+		descriptors = append(descriptors, r.Descriptor)
+	}
+	return built, descriptors, nil
+}
@@ -0,0 +1,72 @@
+package strategies
+
+import (
+	"fmt"
+	"math"
+
+	"rules-evaluation-service/internal/domain/money"
+)
+
+// RoundingMode is the rule used to round monetary amounts.
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "HALF_UP"
+	RoundHalfEven RoundingMode = "HALF_EVEN"
+	RoundUp       RoundingMode = "UP"
+	RoundDown     RoundingMode = "DOWN"
+)
+
+// Rounding is the rounding mode and number of decimals used for a currency.
+type Rounding struct {
+	Mode     RoundingMode
+	Decimals int
+}
+
+// DefaultRounding rounds half up to two decimals.
+var DefaultRounding = Rounding{Mode: RoundHalfUp, Decimals: 2}
+
+// ParseRoundingMode validates a rounding mode name.
+func ParseRoundingMode(mode string) (RoundingMode, error) {
+	switch m := RoundingMode(mode); m {
+	case RoundHalfUp, RoundHalfEven, RoundUp, RoundDown:
+		return m, nil
+	}
+	return "", fmt.Errorf("unsupported rounding mode: %s", mode)
+}
+
+// Round rounds an amount according to the rounding rule. UP and DOWN round away from and towards zero.
+func (r Rounding) Round(amount float64) float64 {
+	scale := math.Pow10(r.Decimals)
+	// Strip binary representation noise first, so that 2.675 rounds like the decimal it stands for.
+	scaled := math.Round(amount*scale*1e6) / 1e6
+	switch r.Mode {
+	case RoundHalfEven:
+		scaled = math.RoundToEven(scaled)
+	case RoundUp:
+		if scaled < 0 {
+			scaled = math.Floor(scaled)
+		} else {
+			scaled = math.Ceil(scaled)
+		}
+	case RoundDown:
+		scaled = math.Trunc(scaled)
+	default:
+		scaled = math.Round(scaled)
+	}
+	return scaled / scale
+}
+
+// amounts expresses the given monetary result fields as Money in the given currency. It returns nil
+// when the currency is unknown, in which case results only carry the plain float fields.
+func amounts(currency string, values map[string]float64) map[string]money.Money {
+	result := make(map[string]money.Money, len(values))
+	for key, value := range values {
+		m, err := money.FromFloat(value, currency)
+		if err != nil {
+			return nil
+		}
+		result[key] = m
+	}
+	return result
+}
@@ -2,20 +2,338 @@ package strategies
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 
 	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/dsl"
 
 	"go.opentelemetry.io/otel"
 )
 
-// TaxesStrategy is a strategy for evaluating taxes rules.
-type TaxesStrategy struct{}
+// TaxesStrategy is a strategy for evaluating taxes and fees rules.
+//
+// The rule condition selects the jurisdiction and the actions configure the rates, for example:
+//
+//	IF jurisdiction.country = 'ES' THEN tax.name = 'IVA', tax.percentage = 21, tax.class.reduced = 10,
+//	    tax.class.super_reduced = 4, tax.compound.surcharge = 5.2, fee.fixed.eco = 0.5,
+//	    fee.percentage.handling = 1, tax.exempt_customer_types = ('DIPLOMAT', 'NGO')
+//
+// Prices are tax-exclusive unless the rule sets tax.inclusive or the context sets pricing.tax_inclusive.
+type TaxesStrategy struct {
+	dslLimits
+	rounding map[string]Rounding
+}
+
+// NewTaxesStrategy creates a TaxesStrategy with the rounding rule of each currency.
+// Currencies without a rule use DefaultRounding.
+func NewTaxesStrategy(rounding map[string]Rounding) *TaxesStrategy {
+	return &TaxesStrategy{rounding: rounding}
+}
+
+func init() {
+	Register(Registration{
+		Descriptor: evaluation.StrategyDescriptor{
+			Category:    "TAXES",
+			Description: "Jurisdiction-aware taxes and fees with per-line tax classes and per-currency rounding.",
+			ContextSchema: []evaluation.ContextField{
+				{Path: "jurisdiction.country", Type: "string", Description: "Country of the jurisdiction; falls back to customer.country"},
+				{Path: "jurisdiction.region", Type: "string", Description: "Region of the jurisdiction; falls back to customer.region"},
+				{Path: "customer.type", Type: "string", Description: "Customer type, checked against exempt types"},
+				{Path: "currency", Type: "string", Description: "ISO currency code selecting the rounding rule"},
+				{Path: "pricing.tax_inclusive", Type: "boolean", Description: "Whether prices include taxes"},
+				{Path: "items", Type: "array", Description: "Basket lines with sku, price, quantity and tax_class"},
+				{Path: "order.amount", Type: "number", Description: "Order amount, used when there are no items"},
+			},
+		},
+		Resolution: evaluation.ResolutionConfig{Policy: evaluation.PolicyAdditive, ValueKey: "total_tax"},
+		Factory: func(deps Dependencies) (evaluation.EvaluationStrategy, error) {
+			return NewTaxesStrategy(deps.Rounding), nil
+		},
+	})
+}
+
+// TaxLine is the tax breakdown of one basket line.
+type TaxLine struct {
+	SKU         string
+	TaxClass    string
+	Net         float64
+	TaxRate     float64
+	Tax         float64
+	CompoundTax float64
+	Gross       float64
+}
+
+// Fee is a fixed or percentage fee charged on the order.
+type Fee struct {
+	Name   string
+	Type   string // "FIXED" or "PERCENTAGE"
+	Amount float64
+}
+
+// TaxBreakdown is the typed outcome of a taxes rule.
+type TaxBreakdown struct {
+	Taxable      bool
+	Exempt       bool
+	Reason       string
+	TaxName      string
+	TaxRate      float64
+	Country      string
+	Region       string
+	Currency     string
+	TaxInclusive bool
+	Lines        []TaxLine
+	Fees         []Fee
+	TotalNet     float64
+	TotalTax     float64
+	TotalFees    float64
+	TotalGross   float64
+}
+
+// ToResult converts the typed breakdown to an evaluation result.
+func (b TaxBreakdown) ToResult() evaluation.Result {
+	result := evaluation.Result{"eligible": b.Taxable, "taxable": b.Taxable}
+	if b.Reason != "" {
+		result["reason"] = b.Reason
+	}
+	if !b.Taxable && !b.Exempt {
+		return result
+	}
+
+	lines := make([]map[string]interface{}, len(b.Lines))
+	for i, l := range b.Lines {
+		lines[i] = map[string]interface{}{
+			"sku":          l.SKU,
+			"tax_class":    l.TaxClass,
+			"net":          l.Net,
+			"tax_rate":     l.TaxRate,
+			"tax":          l.Tax,
+			"compound_tax": l.CompoundTax,
+			"gross":        l.Gross,
+		}
+	}
+	fees := make([]map[string]interface{}, len(b.Fees))
+	for i, f := range b.Fees {
+		fees[i] = map[string]interface{}{"name": f.Name, "type": f.Type, "amount": f.Amount}
+	}
+
+	result["exempt"] = b.Exempt
+	result["tax_name"] = b.TaxName
+	result["tax_percentage"] = b.TaxRate
+	result["jurisdiction"] = map[string]interface{}{"country": b.Country, "region": b.Region}
+	result["currency"] = b.Currency
+	result["tax_inclusive"] = b.TaxInclusive
+	result["lines"] = lines
+	result["fees"] = fees
+	result["total_tax"] = b.TotalTax
+	result["totals"] = map[string]interface{}{
+		"net":   b.TotalNet,
+		"tax":   b.TotalTax,
+		"fees":  b.TotalFees,
+		"gross": b.TotalGross,
+	}
+	if amounts := amounts(b.Currency, map[string]float64{
+		"total_net":   b.TotalNet,
+		"total_tax":   b.TotalTax,
+		"total_fees":  b.TotalFees,
+		"total_gross": b.TotalGross,
+	}); amounts != nil {
+		result["amounts"] = amounts
+	}
+	return result
+}
 
 // Evaluate evaluates a taxes rule.
-func (s *TaxesStrategy) Evaluate(dslContent string, evalContext evaluation.Context) (evaluation.Result, error) {
-	ctx := context.Background()
+func (s *TaxesStrategy) Evaluate(ctx context.Context, dslContent string, evalContext evaluation.Context) (evaluation.Result, error) {
 	_, span := otel.Tracer("strategy").Start(ctx, "TaxesStrategy.Evaluate")
 	defer span.End()
-	// Placeholder implementation
-	return evaluation.Result{"tax_rate": 0.21}, nil
+
+	breakdown, err := s.evaluate(ctx, dslContent, evalContext)
+	if err != nil {
+		return nil, err
+	}
+	return breakdown.ToResult(), nil
+}
+
+func (s *TaxesStrategy) evaluate(ctx context.Context, dslContent string, evalContext evaluation.Context) (*TaxBreakdown, error) {
+	program, err := s.compile(dslContent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid taxes DSL: %w", err)
+	}
+
+	outcome, err := program.Run(ctx, evalContext)
+	var missing *dsl.MissingFieldError
+	if errors.As(err, &missing) {
+		return &TaxBreakdown{Reason: fmt.Sprintf("Missing %s in context", missing.Path)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate taxes rule: %w", err)
+	}
+	if !outcome.Matched {
+		return &TaxBreakdown{}, nil
+	}
+	actions := outcome.Actions
+
+	currency := lookupFirst(evalContext, "currency", "order.currency")
+	rounding, ok := s.rounding[currency]
+	if !ok {
+		rounding = DefaultRounding
+	}
+
+	breakdown := &TaxBreakdown{
+		Taxable:      true,
+		TaxName:      actionString(actions, "tax.name"),
+		TaxRate:      actionFloat(actions, "tax.percentage", 0),
+		Country:      lookupFirst(evalContext, "jurisdiction.country", "customer.country"),
+		Region:       lookupFirst(evalContext, "jurisdiction.region", "customer.region"),
+		Currency:     currency,
+		TaxInclusive: taxInclusive(actions, evalContext),
+	}
+
+	customerType, _ := dsl.LookupString(evalContext, "customer.type")
+	if customerType != "" && toStringSet(actions["tax.exempt_customer_types"])[customerType] {
+		breakdown.Taxable = false
+		breakdown.Exempt = true
+		breakdown.Reason = fmt.Sprintf("customer type %s is exempt", customerType)
+	}
+
+	classRates := actionsWithPrefix(actions, "tax.class.")
+	compoundRates := sortedRates(actionsWithPrefix(actions, "tax.compound."))
+
+	for _, line := range taxableLines(evalContext) {
+		rate := breakdown.TaxRate
+		if classRate, ok := classRates[line.TaxClass]; ok {
+			rate = classRate
+		}
+		compounds := compoundRates
+		if breakdown.Exempt {
+			rate, compounds = 0, nil
+		}
+		tl := taxLine(line, rate, compounds, breakdown.TaxInclusive, rounding)
+		breakdown.Lines = append(breakdown.Lines, tl)
+		breakdown.TotalNet += tl.Net
+		breakdown.TotalTax += tl.Tax + tl.CompoundTax
+	}
+
+	for _, fee := range sortedRates(actionsWithPrefix(actions, "fee.fixed.")) {
+		breakdown.Fees = append(breakdown.Fees, Fee{Name: fee.name, Type: "FIXED", Amount: rounding.Round(fee.rate)})
+	}
+	for _, fee := range sortedRates(actionsWithPrefix(actions, "fee.percentage.")) {
+		amount := rounding.Round(breakdown.TotalNet * fee.rate / 100)
+		breakdown.Fees = append(breakdown.Fees, Fee{Name: fee.name, Type: "PERCENTAGE", Amount: amount})
+	}
+	for _, fee := range breakdown.Fees {
+		breakdown.TotalFees += fee.Amount
+	}
+
+	// Totals are sums of rounded line amounts; rounding them again only removes float noise.
+	breakdown.TotalNet = rounding.Round(breakdown.TotalNet)
+	breakdown.TotalTax = rounding.Round(breakdown.TotalTax)
+	breakdown.TotalFees = rounding.Round(breakdown.TotalFees)
+	breakdown.TotalGross = rounding.Round(breakdown.TotalNet + breakdown.TotalTax + breakdown.TotalFees)
+	return breakdown, nil
+}
+
+// taxableLine is a line amount with its product tax class.
+type taxableLine struct {
+	SKU      string
+	TaxClass string
+	Amount   float64
+}
+
+// taxableLines returns the basket lines, or the whole order as a single line when there is no basket.
+func taxableLines(evalContext evaluation.Context) []taxableLine {
+	lines, ok := basketLines(evalContext)
+	if !ok {
+		amount, _ := dsl.LookupFloat(evalContext, "order.amount")
+		taxClass, _ := dsl.LookupString(evalContext, "order.tax_class")
+		return []taxableLine{{TaxClass: taxClass, Amount: amount}}
+	}
+	out := make([]taxableLine, len(lines))
+	for i, line := range lines {
+		sku, _ := dsl.LookupString(line.Context, "sku")
+		taxClass, _ := dsl.LookupString(line.Context, "tax_class")
+		out[i] = taxableLine{SKU: sku, TaxClass: taxClass, Amount: line.Amount()}
+	}
+	return out
+}
+
+// taxLine computes the taxes of one line. Compound taxes apply, in order, on the net amount plus the taxes before them.
+// For tax-inclusive prices the line amount is the gross amount and the net amount is derived from it.
+func taxLine(line taxableLine, rate float64, compounds []namedRate, inclusive bool, rounding Rounding) TaxLine {
+	multiplier := 1 + rate/100
+	for _, c := range compounds {
+		multiplier *= 1 + c.rate/100
+	}
+
+	net := line.Amount
+	if inclusive {
+		net = line.Amount / multiplier
+	}
+	tax := net * rate / 100
+	running := net + tax
+	var compound float64
+	for _, c := range compounds {
+		amount := running * c.rate / 100
+		compound += amount
+		running += amount
+	}
+
+	tl := TaxLine{
+		SKU:         line.SKU,
+		TaxClass:    line.TaxClass,
+		TaxRate:     rate,
+		Tax:         rounding.Round(tax),
+		CompoundTax: rounding.Round(compound),
+	}
+	if inclusive {
+		// The gross amount is what the customer pays; rounding differences go to the net amount.
+		tl.Gross = rounding.Round(line.Amount)
+		tl.Net = rounding.Round(tl.Gross - tl.Tax - tl.CompoundTax)
+	} else {
+		tl.Net = rounding.Round(net)
+		tl.Gross = rounding.Round(tl.Net + tl.Tax + tl.CompoundTax)
+	}
+	return tl
+}
+
+func taxInclusive(actions map[string]interface{}, evalContext evaluation.Context) bool {
+	if inclusive, ok := actions["tax.inclusive"].(bool); ok {
+		return inclusive
+	}
+	inclusive, _ := dsl.Lookup(evalContext, "pricing.tax_inclusive")
+	return inclusive == true
+}
+
+// namedRate is a named numeric action such as a compound tax or a fee.
+type namedRate struct {
+	name string
+	rate float64
+}
+
+// sortedRates orders named rates by name so that results are deterministic.
+func sortedRates(rates map[string]float64) []namedRate {
+	out := make([]namedRate, 0, len(rates))
+	for name, rate := range rates {
+		out = append(out, namedRate{name: name, rate: rate})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+// lookupFirst returns the first string value found at one of the paths.
+func lookupFirst(evalContext evaluation.Context, paths ...string) string {
+	for _, path := range paths {
+		if value, ok := dsl.LookupString(evalContext, path); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// actionString returns a string action value, or an empty string when the action is absent.
+func actionString(actions map[string]interface{}, target string) string {
+	value, _ := actions[target].(string)
+	return value
 }
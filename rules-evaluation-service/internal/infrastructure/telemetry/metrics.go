@@ -16,4 +16,49 @@ var (
 		Name: "rules_evaluation_evaluation_duration_seconds",
 		Help: "The duration of evaluations",
 	}, []string{"category"})
+	// SuppressedRulesTotal is a counter for rules that did not contribute to a category result.
+	SuppressedRulesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_evaluation_suppressed_rules_total",
+		Help: "The total number of rules that did not contribute to a category result",
+	}, []string{"category", "reason"})
+	// CouponReservationsTotal is a counter for coupon usage reservations by outcome.
+	CouponReservationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_evaluation_coupon_reservations_total",
+		Help: "The total number of coupon usage reservations by outcome",
+	}, []string{"outcome"})
+	// ShadowEvaluationsTotal is a counter for shadow evaluations of candidate rule versions by outcome.
+	ShadowEvaluationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_evaluation_shadow_evaluations_total",
+		Help: "The total number of shadow evaluations of candidate rule versions by outcome",
+	}, []string{"category", "outcome"})
+	// CanaryEvaluationsTotal is a counter for canary evaluations by served version.
+	CanaryEvaluationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_evaluation_canary_evaluations_total",
+		Help: "The total number of canary evaluations by served version",
+	}, []string{"category", "version"})
+	// DecisionLogRecordsTotal is a counter for evaluation decision records by outcome.
+	DecisionLogRecordsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_evaluation_decision_log_records_total",
+		Help: "The total number of evaluation decision records by outcome",
+	}, []string{"outcome"})
+	// RuleLimitViolationsTotal is a counter for rules rejected for exceeding an evaluation limit.
+	RuleLimitViolationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_evaluation_rule_limit_violations_total",
+		Help: "The total number of rules rejected for exceeding an evaluation limit",
+	}, []string{"limit"})
+	// RejectedRequestsTotal is a counter for requests rejected by the rate limiter or load shedding.
+	RejectedRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rules_evaluation_rejected_requests_total",
+		Help: "The total number of requests rejected by the rate limiter or load shedding",
+	}, []string{"transport", "reason"})
+	// ConcurrencyLimit is a gauge of the current adaptive concurrency limit.
+	ConcurrencyLimit = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "rules_evaluation_concurrency_limit",
+		Help: "The current adaptive limit of concurrently served evaluation requests",
+	})
+	// DBQueryDuration is a histogram of the duration of database queries.
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "rules_evaluation_db_query_duration_seconds",
+		Help: "The duration of database queries.",
+	}, []string{"operation"})
 )
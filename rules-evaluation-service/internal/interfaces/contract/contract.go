@@ -0,0 +1,99 @@
+// Package contract maps the messages of evaluation.v1.proto, the versioned contract of the service,
+// to the application layer. The gRPC API serves the messages as they are and the REST API serves
+// their JSON encoding, so both transports accept and return the same documents.
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	evaluationv1 "rules-evaluation-service/api/proto/gen"
+	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/evaluation"
+)
+
+var (
+	// MarshalOptions encode messages for the REST API, with the field names of the proto file.
+	MarshalOptions = protojson.MarshalOptions{UseProtoNames: true}
+	// UnmarshalOptions decode REST requests, ignoring unknown fields as the REST API always has.
+	UnmarshalOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// ValidateRequest checks the fields of an evaluation request that the proto file cannot mark as
+// required.
+func ValidateRequest(req *evaluationv1.EvaluateRuleRequest) error {
+	switch {
+	case req.GetRuleCategory() == "":
+		return errors.New("rule_category is required")
+	case req.GetDslContent() == "":
+		return errors.New("dsl_content is required")
+	case req.GetContext() == nil:
+		return errors.New("context is required")
+	case req.GetCandidate() != nil && (req.GetCandidate().GetDslContent() == "" || req.GetCandidate().GetMode() == ""):
+		return errors.New("candidate needs a dsl_content and a mode")
+	}
+	return nil
+}
+
+// ToCommand converts an evaluation request to its application command.
+func ToCommand(req *evaluationv1.EvaluateRuleRequest) application.EvaluateRuleCommand {
+	cmd := application.EvaluateRuleCommand{
+		RuleID:       req.GetRuleId(),
+		RuleVersion:  int(req.GetRuleVersion()),
+		RuleCategory: req.GetRuleCategory(),
+		DSLContent:   req.GetDslContent(),
+		Context:      evaluation.Context(req.GetContext().AsMap()),
+		Candidate:    toCandidate(req.GetCandidate()),
+		DryRun:       req.GetDryRun(),
+	}
+	if req.GetAsOf() != nil {
+		asOf := req.GetAsOf().AsTime()
+		cmd.AsOf = &asOf
+	}
+	return cmd
+}
+
+func toCandidate(candidate *evaluationv1.Candidate) *evaluation.Candidate {
+	if candidate == nil {
+		return nil
+	}
+	return &evaluation.Candidate{
+		RuleVersion: int(candidate.GetRuleVersion()),
+		DSLContent:  candidate.GetDslContent(),
+		Mode:        evaluation.RolloutMode(candidate.GetMode()),
+		Percentage:  candidate.GetPercentage(),
+	}
+}
+
+// ToStruct converts a result map to a protobuf Struct. Strategies return typed slices and maps
+// that structpb does not accept, so the value goes through its JSON form first.
+func ToStruct(m map[string]interface{}) (*structpb.Struct, error) {
+	if m == nil {
+		return nil, nil
+	}
+	var generic map[string]interface{}
+	if err := jsonRoundTrip(m, &generic); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(generic)
+}
+
+// ToValue converts any JSON-encodable value to a protobuf Value.
+func ToValue(v interface{}) (*structpb.Value, error) {
+	var generic interface{}
+	if err := jsonRoundTrip(v, &generic); err != nil {
+		return nil, err
+	}
+	return structpb.NewValue(generic)
+}
+
+func jsonRoundTrip(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
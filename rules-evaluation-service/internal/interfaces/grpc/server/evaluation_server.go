@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	evaluationv1 "rules-evaluation-service/api/proto/gen"
+	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/shared"
+	"rules-evaluation-service/internal/interfaces/contract"
+)
+
+// EvaluationServer implements the gRPC EvaluationService on top of the application handlers.
+type EvaluationServer struct {
+	evaluationv1.UnimplementedEvaluationServiceServer
+	evaluateRuleHandler *application.EvaluateRuleHandler
+	explainRuleHandler  *application.ExplainRuleHandler
+}
+
+func NewEvaluationServer(evaluateRuleHandler *application.EvaluateRuleHandler, explainRuleHandler *application.ExplainRuleHandler) *EvaluationServer {
+	return &EvaluationServer{
+		evaluateRuleHandler: evaluateRuleHandler,
+		explainRuleHandler:  explainRuleHandler,
+	}
+}
+
+// EvaluateRule evaluates a single rule.
+func (s *EvaluationServer) EvaluateRule(ctx context.Context, req *evaluationv1.EvaluateRuleRequest) (*evaluationv1.EvaluateRuleResponse, error) {
+	if err := contract.ValidateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	result, err := s.evaluateRuleHandler.Handle(ctx, contract.ToCommand(req))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	resultStruct, err := contract.ToStruct(result.Result)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode result: %v", err)
+	}
+	return &evaluationv1.EvaluateRuleResponse{Result: resultStruct, AsOf: timestamppb.New(result.AsOf)}, nil
+}
+
+// BatchEvaluate evaluates each request of the stream in turn. Invalid requests and evaluation failures
+// are reported per request; the stream ends when the client closes it or the call is cancelled.
+func (s *EvaluationServer) BatchEvaluate(stream evaluationv1.EvaluationService_BatchEvaluateServer) error {
+	ctx := stream.Context()
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.evaluateBatchRequest(ctx, req)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// evaluateBatchRequest evaluates one request of a batch. It only fails when the call is cancelled.
+func (s *EvaluationServer) evaluateBatchRequest(ctx context.Context, req *evaluationv1.BatchEvaluateRequest) (*evaluationv1.BatchEvaluateResponse, error) {
+	resp := &evaluationv1.BatchEvaluateResponse{RequestId: req.GetRequestId()}
+	if err := contract.ValidateRequest(req.GetRule()); err != nil {
+		resp.Error = err.Error()
+		return resp, nil
+	}
+	result, err := s.evaluateRuleHandler.Handle(ctx, contract.ToCommand(req.GetRule()))
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, status.FromContextError(ctxErr).Err()
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	} else if resp.Result, err = contract.ToStruct(result.Result); err != nil {
+		resp.Error = "failed to encode result: " + err.Error()
+	}
+	return resp, nil
+}
+
+// Explain evaluates a rule and reports the context values it read and the actions it produced.
+func (s *EvaluationServer) Explain(ctx context.Context, req *evaluationv1.EvaluateRuleRequest) (*evaluationv1.ExplainResponse, error) {
+	if err := contract.ValidateRequest(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	explained, err := s.explainRuleHandler.Handle(ctx, contract.ToCommand(req))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &evaluationv1.ExplainResponse{Matched: explained.Explanation.Matched}
+	if resp.Result, err = contract.ToStruct(explained.Result); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode result: %v", err)
+	}
+	if resp.Actions, err = contract.ToStruct(explained.Explanation.Actions); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode actions: %v", err)
+	}
+	if explained.Explanation.Err != nil {
+		resp.Error = explained.Explanation.Err.Error()
+	}
+	for _, field := range explained.Explanation.Fields {
+		value, err := contract.ToValue(field.Value)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode field %s: %v", field.Path, err)
+		}
+		resp.Fields = append(resp.Fields, &evaluationv1.ContextFieldValue{Path: field.Path, Value: value, Found: field.Found})
+	}
+	return resp, nil
+}
+
+// toStatusError maps application errors to gRPC status codes.
+func toStatusError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	var validationErr *shared.ValidationError
+	if errors.As(err, &validationErr) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	var limitErr *shared.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
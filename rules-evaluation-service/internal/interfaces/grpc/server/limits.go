@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	evaluationv1 "rules-evaluation-service/api/proto/gen"
+	"rules-evaluation-service/internal/infrastructure/ratelimit"
+	"rules-evaluation-service/internal/infrastructure/telemetry"
+)
+
+// LimitsUnaryInterceptor applies the guard to unary calls of the EvaluationService; health checks
+// and reflection are never limited. Rate-limited calls fail with
+// ResourceExhausted and shed calls with Unavailable; both carry a retry-after trailer in seconds.
+func LimitsUnaryInterceptor(guard *ratelimit.Guard) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !limited(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		release, err := admit(ctx, guard)
+		if err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		defer func() { release(time.Since(start)) }()
+		return handler(ctx, req)
+	}
+}
+
+// LimitsStreamInterceptor applies the guard to every message of streaming calls: each request received
+// takes a token and a concurrency slot, which is released with the request's latency once its response
+// is sent. A rejected request ends the stream with the same errors as unary calls.
+func LimitsStreamInterceptor(guard *ratelimit.Guard) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !limited(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		stream := &limitedStream{ServerStream: ss, guard: guard}
+		defer stream.releaseAll()
+		return handler(srv, stream)
+	}
+}
+
+// admission is a received request holding its admission until its response is sent.
+type admission struct {
+	release func(time.Duration)
+	start   time.Time
+}
+
+// limitedStream admits the requests of a stream one by one. Responses are matched to requests in order.
+type limitedStream struct {
+	grpc.ServerStream
+	guard *ratelimit.Guard
+
+	mu      sync.Mutex
+	pending []admission
+}
+
+func (s *limitedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	release, err := admit(s.Context(), s.guard)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.pending = append(s.pending, admission{release: release, start: time.Now()})
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *limitedStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) > 0 {
+		next := s.pending[0]
+		s.pending = s.pending[1:]
+		next.release(time.Since(next.start))
+	}
+	return err
+}
+
+// releaseAll releases the requests left without a response when the stream ends.
+func (s *limitedStream) releaseAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.pending {
+		a.release(time.Since(a.start))
+	}
+	s.pending = nil
+}
+
+func limited(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+evaluationv1.EvaluationService_ServiceDesc.ServiceName+"/")
+}
+
+func admit(ctx context.Context, guard *ratelimit.Guard) (func(time.Duration), error) {
+	release, rejection := guard.Admit(clientKey(ctx))
+	if rejection == nil {
+		return release, nil
+	}
+	telemetry.RejectedRequestsTotal.WithLabelValues("grpc", rejection.Reason).Inc()
+	_ = grpc.SetTrailer(ctx, metadata.Pairs("retry-after", rejection.RetryAfterSeconds()))
+	if rejection.Reason == ratelimit.ReasonOverloaded {
+		return nil, status.Error(codes.Unavailable, "server overloaded, retry later")
+	}
+	return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+}
+
+// clientKey identifies the caller like the REST API does: API key, bearer token, then peer address.
+func clientKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get("x-api-key"); len(keys) > 0 && keys[0] != "" {
+		return ratelimit.CredentialKey("key", keys[0])
+	}
+	if auth := md.Get("authorization"); len(auth) > 0 {
+		if token, ok := strings.CutPrefix(auth[0], "Bearer "); ok && token != "" {
+			return ratelimit.CredentialKey("token", token)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "addr:" + host
+	}
+	return "addr:unknown"
+}
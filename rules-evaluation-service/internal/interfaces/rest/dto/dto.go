@@ -1,21 +1,91 @@
 package dto
 
-import "rules-evaluation-service/internal/domain/evaluation"
+import (
+	"time"
 
-// EvaluationRequest defines the request body for an evaluation.
-type EvaluationRequest struct {
+	"rules-evaluation-service/internal/domain/evaluation"
+)
+
+// CandidateDTO defines a candidate version of a rule rolled out in shadow or canary mode.
+type CandidateDTO struct {
+	RuleVersion int     `json:"rule_version,omitempty"`
+	DSLContent  string  `json:"dsl_content" binding:"required"`
+	Mode        string  `json:"mode" binding:"required"`
+	Percentage  float64 `json:"percentage,omitempty"`
+}
+
+// CategoryEvaluationRequest defines the request body for evaluating several rules of one category.
+type CategoryEvaluationRequest struct {
 	RuleCategory string             `json:"rule_category" binding:"required"`
-	DSLContent   string             `json:"dsl_content" binding:"required"`
+	Rules        []RuleDTO          `json:"rules" binding:"required,min=1,dive"`
 	Context      evaluation.Context `json:"context" binding:"required"`
+	AsOf         *time.Time         `json:"as_of,omitempty"`
+}
+
+// RuleDTO defines a rule submitted as part of a category evaluation.
+type RuleDTO struct {
+	RuleID      string        `json:"rule_id" binding:"required"`
+	RuleVersion int           `json:"rule_version,omitempty"`
+	Priority    int           `json:"priority"`
+	Group       string        `json:"group,omitempty"`
+	DSLContent  string        `json:"dsl_content" binding:"required"`
+	Candidate   *CandidateDTO `json:"candidate,omitempty"`
 }
 
-// EvaluationResponse defines the API response for an evaluation.
-type EvaluationResponse struct {
+// CategoryEvaluationResponse defines the API response for a category evaluation.
+type CategoryEvaluationResponse struct {
+	RuleCategory string              `json:"rule_category"`
+	Policy       string              `json:"policy"`
+	Result       evaluation.Result   `json:"result"`
+	Applied      []AppliedRuleDTO    `json:"applied"`
+	Suppressed   []SuppressedRuleDTO `json:"suppressed"`
+	AsOf         time.Time           `json:"as_of"`
+}
+
+// AppliedRuleDTO describes a rule that contributed to the combined result.
+type AppliedRuleDTO struct {
+	RuleID string            `json:"rule_id"`
+	Value  float64           `json:"value"`
+	Capped bool              `json:"capped"`
 	Result evaluation.Result `json:"result"`
 }
 
+// SuppressedRuleDTO describes a rule that did not contribute to the combined result, and why.
+type SuppressedRuleDTO struct {
+	RuleID string `json:"rule_id"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// CouponReservationResponse defines the API response after settling a coupon reservation.
+type CouponReservationResponse struct {
+	ReservationID string `json:"reservation_id"`
+	Status        string `json:"status"`
+}
+
 // ErrorResponse defines the structure for a generic error response.
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message,omitempty"`
 }
+
+// StrategiesResponse defines the API response describing the registered strategies.
+type StrategiesResponse struct {
+	Strategies []StrategyDTO `json:"strategies"`
+	Fallback   *StrategyDTO  `json:"fallback"`
+}
+
+// StrategyDTO describes a registered strategy and the context it expects.
+type StrategyDTO struct {
+	Category      string            `json:"category"`
+	Description   string            `json:"description"`
+	ContextSchema []ContextFieldDTO `json:"context_schema"`
+}
+
+// ContextFieldDTO describes one field of an evaluation context.
+type ContextFieldDTO struct {
+	Path        string `json:"path"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/shared"
+	"rules-evaluation-service/internal/interfaces/rest/dto"
+)
+
+// CouponHandler handles HTTP requests for coupon reservations.
+type CouponHandler struct {
+	settleHandler *application.SettleCouponReservationHandler
+}
+
+func NewCouponHandler(settleHandler *application.SettleCouponReservationHandler) *CouponHandler {
+	return &CouponHandler{settleHandler: settleHandler}
+}
+
+// CommitReservation handles POST /v1/coupons/reservations/:id/commit
+func (h *CouponHandler) CommitReservation(c *gin.Context) {
+	h.settle(c, true)
+}
+
+// ReleaseReservation handles POST /v1/coupons/reservations/:id/release
+func (h *CouponHandler) ReleaseReservation(c *gin.Context) {
+	h.settle(c, false)
+}
+
+func (h *CouponHandler) settle(c *gin.Context, commit bool) {
+	cmd := application.SettleCouponReservationCommand{
+		ReservationID: c.Param("id"),
+		Commit:        commit,
+	}
+
+	if err := h.settleHandler.Handle(c.Request.Context(), cmd); err != nil {
+		var notFoundErr *shared.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	status := "RELEASED"
+	if commit {
+		status = "COMMITTED"
+	}
+	c.JSON(http.StatusOK, dto.CouponReservationResponse{ReservationID: cmd.ReservationID, Status: status})
+}
@@ -1,26 +1,75 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	evaluationv1 "rules-evaluation-service/api/proto/gen"
 	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/domain/shared"
+	"rules-evaluation-service/internal/interfaces/contract"
 	"rules-evaluation-service/internal/interfaces/rest/dto"
 )
 
 // EvaluationHandler handles HTTP requests for rule evaluation.
 type EvaluationHandler struct {
-	evaluateRuleHandler *application.EvaluateRuleHandler
+	evaluateRuleHandler     *application.EvaluateRuleHandler
+	evaluateCategoryHandler *application.EvaluateCategoryHandler
 }
 
-func NewEvaluationHandler(evaluateRuleHandler *application.EvaluateRuleHandler) *EvaluationHandler {
-	return &EvaluationHandler{evaluateRuleHandler: evaluateRuleHandler}
+func NewEvaluationHandler(evaluateRuleHandler *application.EvaluateRuleHandler, evaluateCategoryHandler *application.EvaluateCategoryHandler) *EvaluationHandler {
+	return &EvaluationHandler{
+		evaluateRuleHandler:     evaluateRuleHandler,
+		evaluateCategoryHandler: evaluateCategoryHandler,
+	}
 }
 
-// EvaluateRule handles POST /v1/evaluate
+// EvaluateRule handles POST /v1/evaluate. The request and response bodies are the JSON encoding of
+// the EvaluateRuleRequest and EvaluateRuleResponse messages of evaluation.v1.proto.
 func (h *EvaluationHandler) EvaluateRule(c *gin.Context) {
-	var req dto.EvaluationRequest
+	var req evaluationv1.EvaluateRuleRequest
+	body, err := io.ReadAll(c.Request.Body)
+	if err == nil {
+		err = contract.UnmarshalOptions.Unmarshal(body, &req)
+	}
+	if err == nil {
+		err = contract.ValidateRequest(&req)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid request body",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.evaluateRuleHandler.Handle(c.Request.Context(), contract.ToCommand(&req))
+	if err != nil {
+		writeEvaluationError(c, err)
+		return
+	}
+
+	resultStruct, err := contract.ToStruct(result.Result)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "failed to encode result", Message: err.Error()})
+		return
+	}
+	resp, err := contract.MarshalOptions.Marshal(&evaluationv1.EvaluateRuleResponse{Result: resultStruct, AsOf: timestamppb.New(result.AsOf)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: "failed to encode result", Message: err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", resp)
+}
+
+// EvaluateCategory handles POST /v1/evaluate/category
+func (h *EvaluationHandler) EvaluateCategory(c *gin.Context) {
+	var req dto.CategoryEvaluationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "invalid request body",
@@ -29,18 +78,77 @@ func (h *EvaluationHandler) EvaluateRule(c *gin.Context) {
 		return
 	}
 
-	cmd := application.EvaluateRuleCommand{
+	rules := make([]evaluation.RuleSpec, len(req.Rules))
+	for i, r := range req.Rules {
+		rules[i] = evaluation.RuleSpec{
+			RuleID:      r.RuleID,
+			RuleVersion: r.RuleVersion,
+			Priority:    r.Priority,
+			Group:       r.Group,
+			DSLContent:  r.DSLContent,
+			Candidate:   toCandidate(r.Candidate),
+		}
+	}
+
+	cmd := application.EvaluateCategoryCommand{
 		RuleCategory: req.RuleCategory,
-		DSLContent:   req.DSLContent,
+		Rules:        rules,
 		Context:      req.Context,
+		AsOf:         req.AsOf,
 	}
 
-	result, err := h.evaluateRuleHandler.Handle(c.Request.Context(), cmd)
+	result, err := h.evaluateCategoryHandler.Handle(c.Request.Context(), cmd)
 	if err != nil {
-		// In a real app, you would have more sophisticated error handling.
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		writeEvaluationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCategoryEvaluationResponse(result))
+}
+
+// writeEvaluationError maps an evaluation error to its HTTP status: invalid requests are 400,
+// rules exceeding an interpreter limit are 422 and anything else is 500.
+func writeEvaluationError(c *gin.Context, err error) {
+	var validationErr *shared.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
 		return
 	}
+	var limitErr *shared.LimitExceededError
+	if errors.As(err, &limitErr) {
+		c.JSON(http.StatusUnprocessableEntity, dto.ErrorResponse{Error: err.Error(), Message: limitErr.Limit})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+}
+
+func toCandidate(candidate *dto.CandidateDTO) *evaluation.Candidate {
+	if candidate == nil {
+		return nil
+	}
+	return &evaluation.Candidate{
+		RuleVersion: candidate.RuleVersion,
+		DSLContent:  candidate.DSLContent,
+		Mode:        evaluation.RolloutMode(candidate.Mode),
+		Percentage:  candidate.Percentage,
+	}
+}
 
-	c.JSON(http.StatusOK, dto.EvaluationResponse{Result: result.Result})
+func toCategoryEvaluationResponse(result *application.EvaluateCategoryResult) dto.CategoryEvaluationResponse {
+	resolution := result.Resolution
+	resp := dto.CategoryEvaluationResponse{
+		AsOf:         result.AsOf,
+		RuleCategory: resolution.Category,
+		Policy:       string(resolution.Policy),
+		Result:       resolution.Result(),
+		Applied:      make([]dto.AppliedRuleDTO, len(resolution.Applied)),
+		Suppressed:   make([]dto.SuppressedRuleDTO, len(resolution.Suppressed)),
+	}
+	for i, a := range resolution.Applied {
+		resp.Applied[i] = dto.AppliedRuleDTO{RuleID: a.RuleID, Value: a.Value, Capped: a.Capped, Result: a.Result}
+	}
+	for i, s := range resolution.Suppressed {
+		resp.Suppressed[i] = dto.SuppressedRuleDTO{RuleID: s.RuleID, Reason: string(s.Reason), Detail: s.Detail}
+	}
+	return resp
 }
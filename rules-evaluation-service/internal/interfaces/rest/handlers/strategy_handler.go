@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/interfaces/rest/dto"
+)
+
+// StrategyHandler handles HTTP requests describing the evaluation strategies.
+type StrategyHandler struct {
+	listHandler *application.ListStrategiesHandler
+}
+
+func NewStrategyHandler(listHandler *application.ListStrategiesHandler) *StrategyHandler {
+	return &StrategyHandler{listHandler: listHandler}
+}
+
+// ListStrategies handles GET /v1/strategies
+func (h *StrategyHandler) ListStrategies(c *gin.Context) {
+	result, err := h.listHandler.Handle(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	resp := dto.StrategiesResponse{Strategies: make([]dto.StrategyDTO, len(result.Strategies))}
+	for i, d := range result.Strategies {
+		resp.Strategies[i] = toStrategyDTO(d)
+	}
+	if result.Fallback != nil {
+		fallback := toStrategyDTO(*result.Fallback)
+		resp.Fallback = &fallback
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func toStrategyDTO(d evaluation.StrategyDescriptor) dto.StrategyDTO {
+	fields := make([]dto.ContextFieldDTO, len(d.ContextSchema))
+	for i, f := range d.ContextSchema {
+		fields[i] = dto.ContextFieldDTO{Path: f.Path, Type: f.Type, Required: f.Required, Description: f.Description}
+	}
+	return dto.StrategyDTO{Category: d.Category, Description: d.Description, ContextSchema: fields}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rules-evaluation-service/internal/infrastructure/ratelimit"
+	"rules-evaluation-service/internal/infrastructure/telemetry"
+)
+
+// Limits rejects requests that exceed the client's rate limit with 429 and sheds requests beyond
+// the concurrency limit with 503. Both responses carry a Retry-After header in seconds.
+func Limits(guard *ratelimit.Guard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		release, rejection := guard.Admit(ClientKey(c))
+		if rejection != nil {
+			telemetry.RejectedRequestsTotal.WithLabelValues("http", rejection.Reason).Inc()
+			status := http.StatusTooManyRequests
+			if rejection.Reason == ratelimit.ReasonOverloaded {
+				status = http.StatusServiceUnavailable
+			}
+			c.Header("Retry-After", rejection.RetryAfterSeconds())
+			c.AbortWithStatusJSON(status, gin.H{"error": rejection.Reason})
+			return
+		}
+
+		start := time.Now()
+		defer func() { release(time.Since(start)) }()
+		c.Next()
+	}
+}
+
+// ClientKey identifies the client of a request by its API key, then by its bearer token, and otherwise
+// by its IP address. Credentials are not verified here, so they are keyed by ratelimit.CredentialKey.
+func ClientKey(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return ratelimit.CredentialKey("key", key)
+	}
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && token != "" {
+		return ratelimit.CredentialKey("token", token)
+	}
+	return "addr:" + c.ClientIP()
+}
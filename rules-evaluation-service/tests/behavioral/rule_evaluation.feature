@@ -49,16 +49,31 @@ Feature: Rule Evaluation API
     Then the response status code should be 200
     And the response body should contain a "result" object where "taxable" is true and "tax_percentage" is 9.5
 
-  Scenario: Evaluate a rule with an unsupported category
+  Scenario: Successfully evaluate a loyalty rule
     When I send a "POST" request to "/v1/evaluate" with the following body:
     """
     {
       "rule_category": "LOYALTY",
-      "dsl_content": "IF customer.tier == 'GOLD' THEN points.multiplier = 2",
+      "dsl_content": "IF customer.tier == 'GOLD' THEN earn.points_per_unit = 1, earn.multiplier = 2",
+      "context": {
+        "customer_tier": "GOLD",
+        "order_amount": 120.0
+      }
+    }
+    """
+    Then the response status code should be 200
+    And the response body should contain a "result" object where "points_earned" is 240 and "multiplier_applied" is 2
+
+  Scenario: Evaluate a rule with an unsupported category
+    When I send a "POST" request to "/v1/evaluate" with the following body:
+    """
+    {
+      "rule_category": "SHIPPING",
+      "dsl_content": "IF order.amount > 50 THEN shipping.cost = 0",
       "context": {
-        "customer_tier": "GOLD"
+        "order_amount": 75.0
       }
     }
     """
-    Then the response status code should be 500
-    And the response body should contain the error message "no evaluation strategy found for category: LOYALTY"
+    Then the response status code should be 400
+    And the response body should contain the error message "no evaluation strategy found for category: SHIPPING"
@@ -0,0 +1,207 @@
+package contract_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	evaluationv1 "rules-evaluation-service/api/proto/gen"
+	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/decision"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/persistence/memory"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+	"rules-evaluation-service/internal/interfaces/contract"
+	"rules-evaluation-service/internal/interfaces/grpc/server"
+	"rules-evaluation-service/internal/interfaces/rest/handlers"
+)
+
+// pacts are written by the consumers of the service, next to their own contract tests.
+const pacts = "../../../contracts/*-rules-evaluation-service.json"
+
+type pact struct {
+	Consumer struct {
+		Name string `json:"name"`
+	} `json:"consumer"`
+	Interactions []struct {
+		Description string `json:"description"`
+		Request     struct {
+			Method  string            `json:"method"`
+			Path    string            `json:"path"`
+			Headers map[string]string `json:"headers"`
+			Body    json.RawMessage   `json:"body"`
+		} `json:"request"`
+		Response struct {
+			Status  int               `json:"status"`
+			Headers map[string]string `json:"headers"`
+			Body    json.RawMessage   `json:"body"`
+		} `json:"response"`
+	} `json:"interactions"`
+}
+
+func newEvaluateRuleHandler(t *testing.T) (*evaluation.Service, *application.DecisionRecorder, *application.EvaluateRuleHandler) {
+	registered, _, err := strategies.Build(strategies.Dependencies{
+		CouponStore:          memory.NewCouponUsageStore(),
+		CouponReservationTTL: time.Minute,
+	}, nil)
+	require.NoError(t, err)
+	service := evaluation.NewService(registered)
+	recorder := application.NewDecisionRecorder(decision.NoOpLogger{}, "")
+	return service, recorder, application.NewEvaluateRuleHandler(service, recorder)
+}
+
+func newRouter(t *testing.T) *gin.Engine {
+	service, recorder, evaluateRuleHandler := newEvaluateRuleHandler(t)
+	handler := handlers.NewEvaluationHandler(evaluateRuleHandler, application.NewEvaluateCategoryHandler(service, nil, recorder))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/v1/evaluate", handler.EvaluateRule)
+	return router
+}
+
+// TestEvaluationContract_Provider replays the interactions of every consumer pact against the REST
+// API. A response satisfies an interaction when it has the status of the pact and a body holding
+// every field of the pact's body; fields the consumer does not read are free to change.
+func TestEvaluationContract_Provider(t *testing.T) {
+	files, err := filepath.Glob(pacts)
+	require.NoError(t, err)
+	require.NotEmpty(t, files, "no consumer pacts found")
+	router := newRouter(t)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		var p pact
+		require.NoError(t, json.Unmarshal(data, &p))
+
+		for _, interaction := range p.Interactions {
+			t.Run(p.Consumer.Name+"/"+interaction.Description, func(t *testing.T) {
+				if interaction.Request.Path == "/v1/evaluate" {
+					var req evaluationv1.EvaluateRuleRequest
+					require.NoError(t, contract.UnmarshalOptions.Unmarshal(interaction.Request.Body, &req),
+						"the request should be an EvaluateRuleRequest")
+				}
+
+				req := httptest.NewRequest(interaction.Request.Method, interaction.Request.Path, bytes.NewReader(interaction.Request.Body))
+				for name, value := range interaction.Request.Headers {
+					req.Header.Set(name, value)
+				}
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				require.Equal(t, interaction.Response.Status, rec.Code, rec.Body.String())
+				for name, value := range interaction.Response.Headers {
+					assert.Equal(t, mediaType(value), mediaType(rec.Header().Get(name)), "header %s", name)
+				}
+				var want, got interface{}
+				require.NoError(t, json.Unmarshal(interaction.Response.Body, &want))
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+				assertContains(t, "body", want, got)
+			})
+		}
+	}
+}
+
+func newGRPCClient(t *testing.T) evaluationv1.EvaluationServiceClient {
+	_, _, evaluateRuleHandler := newEvaluateRuleHandler(t)
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	evaluationv1.RegisterEvaluationServiceServer(grpcServer, server.NewEvaluationServer(evaluateRuleHandler, application.NewExplainRuleHandler(evaluateRuleHandler)))
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return evaluationv1.NewEvaluationServiceClient(conn)
+}
+
+// TestEvaluationContract_InvalidRequests checks that both transports reject the same invalid requests.
+func TestEvaluationContract_InvalidRequests(t *testing.T) {
+	ctx := context.Background()
+	router := newRouter(t)
+	client := newGRPCClient(t)
+
+	invalid := map[string]string{
+		"without a rule category": `{"dsl_content": "IF order.amount > 10 THEN discount.percentage = 5", "context": {}}`,
+		"without a DSL":           `{"rule_category": "PROMOTIONS", "context": {}}`,
+		"without a context":       `{"rule_category": "PROMOTIONS", "dsl_content": "IF order.amount > 10 THEN discount.percentage = 5"}`,
+		"with an incomplete candidate": `{"rule_category": "PROMOTIONS", "dsl_content": "IF order.amount > 10 THEN discount.percentage = 5",
+			"context": {}, "candidate": {"dsl_content": "IF order.amount > 10 THEN discount.percentage = 6"}}`,
+	}
+	for name, body := range invalid {
+		t.Run(name, func(t *testing.T) {
+			var req evaluationv1.EvaluateRuleRequest
+			require.NoError(t, contract.UnmarshalOptions.Unmarshal([]byte(body), &req))
+			want := contract.ValidateRequest(&req)
+			require.Error(t, want)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/evaluate", bytes.NewReader([]byte(body))))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), want.Error())
+
+			_, err := client.EvaluateRule(ctx, &req)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Equal(t, want.Error(), status.Convert(err).Message())
+
+			_, err = client.Explain(ctx, &req)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+			stream, err := client.BatchEvaluate(ctx)
+			require.NoError(t, err)
+			require.NoError(t, stream.Send(&evaluationv1.BatchEvaluateRequest{RequestId: "a", Rule: &req}))
+			require.NoError(t, stream.CloseSend())
+			resp, err := stream.Recv()
+			require.NoError(t, err)
+			assert.Equal(t, want.Error(), resp.Error)
+		})
+	}
+}
+
+// assertContains checks that got holds want: objects may have more fields, anything else must be equal.
+func assertContains(t *testing.T, path string, want, got interface{}) {
+	t.Helper()
+	wantObject, ok := want.(map[string]interface{})
+	if !ok {
+		assert.Equal(t, want, got, path)
+		return
+	}
+	gotObject, ok := got.(map[string]interface{})
+	if !assert.True(t, ok, "%s should be an object, got %v", path, got) {
+		return
+	}
+	for key, value := range wantObject {
+		if assert.Contains(t, gotObject, key, path) {
+			assertContains(t, path+"."+key, value, gotObject[key])
+		}
+	}
+}
+
+func mediaType(value string) string {
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		return value
+	}
+	return mediaType
+}
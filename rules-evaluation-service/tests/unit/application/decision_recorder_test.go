@@ -0,0 +1,97 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/decision"
+	"rules-evaluation-service/internal/domain/evaluation"
+)
+
+// CapturingLogger keeps the logged decision records.
+type CapturingLogger struct {
+	Records []decision.Record
+}
+
+func (l *CapturingLogger) Log(record decision.Record) {
+	l.Records = append(l.Records, record)
+}
+
+// FixedStrategy returns a canned result for each DSL content.
+type FixedStrategy map[string]evaluation.Result
+
+func (s FixedStrategy) Evaluate(ctx context.Context, dslContent string, evalContext evaluation.Context) (evaluation.Result, error) {
+	return s[dslContent], nil
+}
+
+func TestDecisionRecorder(t *testing.T) {
+	ctx := context.Background()
+	service := evaluation.NewService(map[string]evaluation.EvaluationStrategy{"PROMOTIONS": FixedStrategy{
+		"ten":  {"eligible": true, "discount_percentage": 10.0},
+		"none": {"eligible": false, "reason": "minimum not reached"},
+	}})
+	evalContext := evaluation.Context{"customer": map[string]interface{}{"id": "cust-1"}}
+
+	t.Run("should record a single rule evaluation", func(t *testing.T) {
+		logger := &CapturingLogger{}
+		handler := application.NewEvaluateRuleHandler(service, application.NewDecisionRecorder(logger, "salt"))
+
+		_, err := handler.Handle(ctx, application.EvaluateRuleCommand{RuleID: "r1", RuleVersion: 3, RuleCategory: "PROMOTIONS", DSLContent: "ten", Context: evalContext})
+		require.NoError(t, err)
+		_, err = handler.Handle(ctx, application.EvaluateRuleCommand{RuleID: "r2", RuleCategory: "PROMOTIONS", DSLContent: "none", Context: evalContext})
+		require.NoError(t, err)
+
+		require.Len(t, logger.Records, 2)
+		eligible := logger.Records[0]
+		assert.NotEmpty(t, eligible.DecisionID)
+		assert.Equal(t, "PROMOTIONS", eligible.Category)
+		assert.Equal(t, []decision.RuleRef{{ID: "r1", Version: 3}}, eligible.Rules)
+		assert.Equal(t, decision.OutcomeEligible, eligible.Outcome)
+		assert.Equal(t, map[string]interface{}{"discount_percentage": 10.0}, eligible.Actions)
+		assert.Equal(t, decision.HashCustomerID("cust-1", "salt"), eligible.CustomerHash)
+		assert.NotContains(t, eligible.CustomerHash, "cust-1")
+
+		notEligible := logger.Records[1]
+		assert.Equal(t, decision.OutcomeNotEligible, notEligible.Outcome)
+		assert.Equal(t, "minimum not reached", notEligible.Reason)
+		assert.Empty(t, notEligible.Actions)
+	})
+
+	t.Run("should record the applied rules of a category evaluation", func(t *testing.T) {
+		logger := &CapturingLogger{}
+		resolution := map[string]evaluation.ResolutionConfig{"PROMOTIONS": {Policy: evaluation.PolicyBestForCustomer, ValueKey: "discount_percentage"}}
+		handler := application.NewEvaluateCategoryHandler(service, resolution, application.NewDecisionRecorder(logger, ""))
+
+		_, err := handler.Handle(ctx, application.EvaluateCategoryCommand{
+			RuleCategory: "PROMOTIONS",
+			Rules: []evaluation.RuleSpec{
+				{RuleID: "r1", RuleVersion: 2, DSLContent: "ten"},
+				{RuleID: "r2", DSLContent: "none"},
+			},
+			Context: evalContext,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, logger.Records, 1)
+		record := logger.Records[0]
+		assert.Equal(t, []decision.RuleRef{{ID: "r1", Version: 2}, {ID: "r2"}}, record.Rules)
+		assert.Equal(t, []string{"r1"}, record.AppliedRuleIDs)
+		assert.Equal(t, decision.OutcomeEligible, record.Outcome)
+	})
+
+	t.Run("should record evaluation errors", func(t *testing.T) {
+		logger := &CapturingLogger{}
+		handler := application.NewEvaluateRuleHandler(service, application.NewDecisionRecorder(logger, ""))
+
+		_, err := handler.Handle(ctx, application.EvaluateRuleCommand{RuleCategory: "SHIPPING", DSLContent: "ten", Context: evaluation.Context{}})
+		require.Error(t, err)
+
+		require.Len(t, logger.Records, 1)
+		assert.Equal(t, decision.OutcomeError, logger.Records[0].Outcome)
+		assert.Empty(t, logger.Records[0].CustomerHash)
+	})
+}
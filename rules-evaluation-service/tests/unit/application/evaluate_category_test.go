@@ -0,0 +1,46 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+)
+
+func TestEvaluateCategoryHandler_Coupons(t *testing.T) {
+	ctx := context.Background()
+	evalContext := evaluation.Context{
+		"coupon": map[string]interface{}{"code": "WELCOME"},
+		"order":  map[string]interface{}{"amount": 100.0},
+	}
+	rules := []evaluation.RuleSpec{
+		{RuleID: "welcome-10", Priority: 1, Group: "welcome", DSLContent: "IF coupon.code = 'WELCOME' THEN coupon.discount_percentage = 10"},
+		{RuleID: "welcome-15", Priority: 2, Group: "welcome", DSLContent: "IF coupon.code = 'WELCOME' THEN coupon.discount_percentage = 15"},
+	}
+
+	for _, policy := range []evaluation.ResolutionPolicy{evaluation.PolicyBestForCustomer, evaluation.PolicyHighestPriority, evaluation.PolicyExclusiveGroups} {
+		t.Run("releases the reservations of the rules that lose under "+string(policy), func(t *testing.T) {
+			store := NewCountingCouponStore()
+			service := evaluation.NewService(map[string]evaluation.EvaluationStrategy{"COUPONS": strategies.NewCouponsStrategy(store, time.Minute)})
+			resolution := map[string]evaluation.ResolutionConfig{"COUPONS": {Policy: policy, ValueKey: "discount_amount"}}
+			handler := application.NewEvaluateCategoryHandler(service, resolution, nil)
+
+			result, err := handler.Handle(ctx, application.EvaluateCategoryCommand{RuleCategory: "COUPONS", Rules: rules, Context: evalContext})
+			require.NoError(t, err)
+
+			require.Len(t, result.Resolution.Applied, 1)
+			applied := result.Resolution.Applied[0]
+			assert.Equal(t, "welcome-15", applied.RuleID)
+			require.Len(t, result.Resolution.Suppressed, 1)
+			assert.Equal(t, 2, store.Reserved)
+			assert.Equal(t, []string{result.Resolution.Suppressed[0].Result["reservation_id"].(string)}, store.Released)
+			assert.NoError(t, store.Commit(ctx, applied.Result["reservation_id"].(string)), "the applied rule keeps its reservation")
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+)
+
+func TestExplainRule(t *testing.T) {
+	ctx := context.Background()
+	store := NewCountingCouponStore()
+	service := evaluation.NewService(map[string]evaluation.EvaluationStrategy{"COUPONS": strategies.NewCouponsStrategy(store, time.Minute)})
+	service.SetClock(evaluation.FixedClock{Time: time.Date(2026, 12, 15, 10, 0, 0, 0, time.UTC)})
+	logger := &CapturingLogger{}
+	handler := application.NewExplainRuleHandler(application.NewEvaluateRuleHandler(service, application.NewDecisionRecorder(logger, "")))
+
+	cmd := application.EvaluateRuleCommand{
+		RuleCategory: "COUPONS",
+		DSLContent:   "IF coupon.code = 'XMAS' AND between(now(), '2026-12-01', '2026-12-24') THEN coupon.discount_percentage = 10",
+		Context: evaluation.Context{
+			"coupon": map[string]interface{}{"code": "XMAS"},
+			"order":  map[string]interface{}{"amount": 80.0},
+		},
+	}
+
+	t.Run("should explain the evaluation that produced the result", func(t *testing.T) {
+		explained, err := handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+
+		assert.True(t, explained.Result.IsEligible())
+		assert.Equal(t, 8.0, explained.Result["discount_amount"])
+		assert.True(t, explained.Explanation.Matched)
+		assert.Equal(t, map[string]interface{}{"coupon.discount_percentage": 10.0}, explained.Explanation.Actions)
+		require.Len(t, explained.Explanation.Fields, 1)
+		assert.Equal(t, "coupon.code", explained.Explanation.Fields[0].Path)
+	})
+
+	t.Run("should evaluate at the as_of time", func(t *testing.T) {
+		asOf := time.Date(2026, 12, 26, 0, 0, 0, 0, time.UTC)
+		late := cmd
+		late.AsOf = &asOf
+
+		explained, err := handler.Handle(ctx, late)
+		require.NoError(t, err)
+
+		assert.False(t, explained.Result.IsEligible())
+		assert.False(t, explained.Explanation.Matched)
+	})
+
+	t.Run("should neither reserve the coupon nor log a decision", func(t *testing.T) {
+		assert.Zero(t, store.Reserved)
+		assert.Empty(t, logger.Records)
+	})
+}
@@ -0,0 +1,121 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+)
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	service := evaluation.NewService(map[string]evaluation.EvaluationStrategy{"PROMOTIONS": strategies.NewGenericStrategy()})
+	blackFriday := time.Date(2026, 11, 27, 10, 0, 0, 0, time.UTC)
+	service.SetClock(evaluation.FixedClock{Time: blackFriday})
+
+	logger := &CapturingLogger{}
+	handler := application.NewEvaluateRuleHandler(service, application.NewDecisionRecorder(logger, ""))
+	cmd := application.EvaluateRuleCommand{
+		RuleID:       "black-friday",
+		RuleVersion:  7,
+		RuleCategory: "PROMOTIONS",
+		DSLContent:   "IF between(now(), '2026-11-27', '2026-11-30') THEN discount.percentage = 20",
+		Context:      evaluation.Context{},
+	}
+
+	original, err := handler.Handle(ctx, cmd)
+	require.NoError(t, err)
+	assert.True(t, original.Result.IsEligible())
+	assert.Equal(t, blackFriday, original.AsOf)
+	require.Len(t, logger.Records, 1)
+	assert.Equal(t, blackFriday, logger.Records[0].AsOf)
+
+	t.Run("the clock has moved on", func(t *testing.T) {
+		service.SetClock(evaluation.FixedClock{Time: blackFriday.AddDate(0, 1, 0)})
+
+		result, err := handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+		assert.False(t, result.Result.IsEligible())
+	})
+
+	t.Run("replaying with the logged as_of reproduces the decision", func(t *testing.T) {
+		service.SetClock(evaluation.FixedClock{Time: blackFriday.AddDate(0, 1, 0)})
+		replay := cmd
+		asOf := logger.Records[0].AsOf
+		replay.AsOf = &asOf
+
+		result, err := handler.Handle(ctx, replay)
+		require.NoError(t, err)
+		assert.Equal(t, original.Result, result.Result)
+		assert.Equal(t, blackFriday, result.AsOf)
+	})
+}
+
+func TestReplayCoupon(t *testing.T) {
+	ctx := context.Background()
+	store := NewCountingCouponStore()
+	service := evaluation.NewService(map[string]evaluation.EvaluationStrategy{"COUPONS": strategies.NewCouponsStrategy(store, time.Minute)})
+	service.SetClock(evaluation.FixedClock{Time: time.Date(2026, 12, 15, 10, 0, 0, 0, time.UTC)})
+	handler := application.NewEvaluateRuleHandler(service, nil)
+	cmd := application.EvaluateRuleCommand{
+		RuleCategory: "COUPONS",
+		DSLContent:   "IF coupon.code = 'XMAS' THEN coupon.discount_percentage = 10, coupon.valid_until = '2026-12-24', coupon.global_limit = 1",
+		Context: evaluation.Context{
+			"coupon": map[string]interface{}{"code": "XMAS"},
+			"order":  map[string]interface{}{"amount": 80.0},
+		},
+	}
+
+	original, err := handler.Handle(ctx, cmd)
+	require.NoError(t, err)
+	require.True(t, original.Result.IsEligible())
+	require.Equal(t, 1, store.Reserved)
+
+	service.SetClock(evaluation.FixedClock{Time: time.Date(2027, 1, 10, 0, 0, 0, 0, time.UTC)})
+	replay := cmd
+	replay.AsOf = &original.AsOf
+	first, err := handler.Handle(ctx, replay)
+	require.NoError(t, err)
+	second, err := handler.Handle(ctx, replay)
+	require.NoError(t, err)
+
+	expected := evaluation.Result{}
+	for key, value := range original.Result {
+		expected[key] = value
+	}
+	delete(expected, "reservation_id")
+	delete(expected, "reservation_expires_at")
+	assert.Equal(t, expected, first.Result, "a replay validates the coupon without reserving it")
+	assert.Equal(t, first.Result, second.Result)
+	assert.Equal(t, 1, store.Reserved, "replays do not reserve the coupon, despite its global limit of one")
+}
+
+func TestDryRunCoupon(t *testing.T) {
+	ctx := context.Background()
+	store := NewCountingCouponStore()
+	service := evaluation.NewService(map[string]evaluation.EvaluationStrategy{"COUPONS": strategies.NewCouponsStrategy(store, time.Minute)})
+	handler := application.NewEvaluateRuleHandler(service, nil)
+	cmd := application.EvaluateRuleCommand{
+		RuleCategory: "COUPONS",
+		DSLContent:   "IF coupon.code = 'XMAS' THEN coupon.discount_percentage = 10, coupon.global_limit = 1",
+		Context: evaluation.Context{
+			"coupon": map[string]interface{}{"code": "XMAS"},
+			"order":  map[string]interface{}{"amount": 80.0},
+		},
+		DryRun: true,
+	}
+
+	for i := 0; i < 2; i++ {
+		result, err := handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+		assert.True(t, result.Result.IsEligible())
+		assert.NotContains(t, result.Result, "reservation_id")
+	}
+	assert.Equal(t, 0, store.Reserved, "dry runs do not reserve the coupon")
+}
@@ -0,0 +1,138 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/coupon"
+	"rules-evaluation-service/internal/domain/decision"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/persistence/memory"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+)
+
+// CountingCouponStore counts the reservations made and released through an in-memory coupon usage store.
+type CountingCouponStore struct {
+	*memory.CouponUsageStore
+	Reserved int
+	Released []string
+}
+
+func NewCountingCouponStore() *CountingCouponStore {
+	return &CountingCouponStore{CouponUsageStore: memory.NewCouponUsageStore()}
+}
+
+func (s *CountingCouponStore) Reserve(ctx context.Context, code, customerID, holder string, limits coupon.Limits, ttl time.Duration) (*coupon.Reservation, error) {
+	s.Reserved++
+	return s.CouponUsageStore.Reserve(ctx, code, customerID, holder, limits, ttl)
+}
+
+func (s *CountingCouponStore) Release(ctx context.Context, reservationID string) error {
+	s.Released = append(s.Released, reservationID)
+	return s.CouponUsageStore.Release(ctx, reservationID)
+}
+
+func TestCandidateRollout(t *testing.T) {
+	ctx := context.Background()
+	service := evaluation.NewService(map[string]evaluation.EvaluationStrategy{"PROMOTIONS": FixedStrategy{
+		"v1": {"eligible": true, "discount_percentage": 10.0},
+		"v2": {"eligible": true, "discount_percentage": 12.0},
+	}})
+	evalContext := evaluation.Context{"customer": map[string]interface{}{"id": "cust-1"}}
+
+	t.Run("shadow mode returns the active result and records the divergence", func(t *testing.T) {
+		logger := &CapturingLogger{}
+		handler := application.NewEvaluateRuleHandler(service, application.NewDecisionRecorder(logger, ""))
+
+		result, err := handler.Handle(ctx, application.EvaluateRuleCommand{
+			RuleID: "r1", RuleVersion: 1, RuleCategory: "PROMOTIONS", DSLContent: "v1", Context: evalContext,
+			Candidate: &evaluation.Candidate{RuleVersion: 2, DSLContent: "v2", Mode: evaluation.RolloutShadow},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 10.0, result.Result["discount_percentage"])
+		require.Len(t, logger.Records, 2)
+		assert.Empty(t, logger.Records[0].Variant)
+		shadow := logger.Records[1]
+		assert.Equal(t, decision.VariantShadow, shadow.Variant)
+		assert.Equal(t, []decision.RuleRef{{ID: "r1", Version: 2}}, shadow.Rules)
+		assert.Equal(t, []string{"discount_percentage"}, shadow.DivergentFields)
+	})
+
+	t.Run("canary mode serves the candidate to customers in the slice", func(t *testing.T) {
+		logger := &CapturingLogger{}
+		handler := application.NewEvaluateRuleHandler(service, application.NewDecisionRecorder(logger, ""))
+		cmd := application.EvaluateRuleCommand{
+			RuleID: "r1", RuleVersion: 1, RuleCategory: "PROMOTIONS", DSLContent: "v1", Context: evalContext,
+			Candidate: &evaluation.Candidate{RuleVersion: 2, DSLContent: "v2", Mode: evaluation.RolloutCanary, Percentage: 100},
+		}
+
+		result, err := handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+		assert.Equal(t, 12.0, result.Result["discount_percentage"])
+		assert.Equal(t, decision.VariantCanary, logger.Records[0].Variant)
+
+		cmd.Candidate.Percentage = 0
+		result, err = handler.Handle(ctx, cmd)
+		require.NoError(t, err)
+		assert.Equal(t, 10.0, result.Result["discount_percentage"])
+	})
+
+	t.Run("category shadow mode compares the combined results", func(t *testing.T) {
+		logger := &CapturingLogger{}
+		resolution := map[string]evaluation.ResolutionConfig{"PROMOTIONS": {Policy: evaluation.PolicyAdditive, ValueKey: "discount_percentage"}}
+		handler := application.NewEvaluateCategoryHandler(service, resolution, application.NewDecisionRecorder(logger, ""))
+
+		result, err := handler.Handle(ctx, application.EvaluateCategoryCommand{
+			RuleCategory: "PROMOTIONS",
+			Rules: []evaluation.RuleSpec{
+				{RuleID: "r1", RuleVersion: 1, DSLContent: "v1", Candidate: &evaluation.Candidate{RuleVersion: 2, DSLContent: "v2", Mode: evaluation.RolloutShadow}},
+				{RuleID: "r2", DSLContent: "v1"},
+			},
+			Context: evalContext,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 20.0, result.Resolution.Total)
+		require.Len(t, logger.Records, 2)
+		assert.Equal(t, decision.VariantShadow, logger.Records[1].Variant)
+		assert.Equal(t, []string{"discount_percentage"}, logger.Records[1].DivergentFields)
+	})
+
+	t.Run("shadow mode does not reserve coupon usage for the candidate", func(t *testing.T) {
+		store := NewCountingCouponStore()
+		coupons := evaluation.NewService(map[string]evaluation.EvaluationStrategy{"COUPONS": strategies.NewCouponsStrategy(store, time.Minute)})
+		handler := application.NewEvaluateRuleHandler(coupons, nil)
+		couponContext := evaluation.Context{
+			"coupon":   map[string]interface{}{"code": "WELCOME10"},
+			"customer": map[string]interface{}{"id": "cust-1"},
+			"order":    map[string]interface{}{"amount": 100.0},
+		}
+
+		result, err := handler.Handle(ctx, application.EvaluateRuleCommand{
+			RuleID: "welcome", RuleVersion: 1, RuleCategory: "COUPONS", Context: couponContext,
+			DSLContent: "IF coupon.code = 'WELCOME10' THEN coupon.discount_percentage = 10, coupon.global_limit = 1",
+			Candidate: &evaluation.Candidate{RuleVersion: 2, Mode: evaluation.RolloutShadow,
+				DSLContent: "IF coupon.code = 'WELCOME10' THEN coupon.discount_percentage = 15, coupon.global_limit = 1"},
+		})
+		require.NoError(t, err)
+
+		assert.True(t, result.Result.IsEligible())
+		assert.Equal(t, 1, store.Reserved)
+	})
+
+	t.Run("should reject an invalid candidate", func(t *testing.T) {
+		handler := application.NewEvaluateRuleHandler(service, nil)
+
+		_, err := handler.Handle(ctx, application.EvaluateRuleCommand{
+			RuleCategory: "PROMOTIONS", DSLContent: "v1", Context: evalContext,
+			Candidate: &evaluation.Candidate{DSLContent: "v2", Mode: "LIVE"},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -1,6 +1,7 @@
 package evaluation_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,7 +15,7 @@ type MockStrategy struct {
 	Name string
 }
 
-func (s *MockStrategy) Evaluate(dslContent string, context evaluation.Context) (evaluation.Result, error) {
+func (s *MockStrategy) Evaluate(ctx context.Context, dslContent string, evalContext evaluation.Context) (evaluation.Result, error) {
 	return evaluation.Result{"strategy": s.Name}, nil
 }
 
@@ -44,4 +45,13 @@ func TestEvaluationService(t *testing.T) {
 		require.Error(t, err)
 		assert.EqualError(t, err, "no evaluation strategy found for category: UNKNOWN")
 	})
+	t.Run("should evaluate unknown categories with the fallback strategy", func(t *testing.T) {
+		fallback := &MockStrategy{Name: "GENERIC"}
+		service := evaluation.NewServiceWithFallback(strategyMap, fallback)
+
+		strategy, err := service.GetStrategyForCategory("SHIPPING")
+		require.NoError(t, err)
+		assert.Equal(t, fallback, strategy)
+		assert.Equal(t, []string{"PROMOTIONS", "TAXES"}, service.Categories())
+	})
 }
@@ -0,0 +1,141 @@
+package evaluation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+)
+
+// StubStrategy returns a canned result for each DSL content.
+type StubStrategy struct {
+	Results map[string]evaluation.Result
+}
+
+func (s *StubStrategy) Evaluate(ctx context.Context, dslContent string, evalContext evaluation.Context) (evaluation.Result, error) {
+	result, ok := s.Results[dslContent]
+	if !ok {
+		return nil, errors.New("invalid dsl")
+	}
+	return result, nil
+}
+
+func TestEvaluateCategory(t *testing.T) {
+	ctx := context.Background()
+	strategy := &StubStrategy{Results: map[string]evaluation.Result{
+		"ten":     {"eligible": true, "discount_percentage": 10.0},
+		"fifteen": {"eligible": true, "discount_percentage": 15.0},
+		"twenty":  {"eligible": true, "discount_percentage": 20.0},
+		"none":    {"eligible": false},
+	}}
+	service := evaluation.NewService(map[string]evaluation.EvaluationStrategy{"PROMOTIONS": strategy})
+
+	rules := []evaluation.RuleSpec{
+		{RuleID: "r-ten", Priority: 3, Group: "seasonal", DSLContent: "ten"},
+		{RuleID: "r-twenty", Priority: 1, Group: "seasonal", DSLContent: "twenty"},
+		{RuleID: "r-fifteen", Priority: 2, DSLContent: "fifteen"},
+		{RuleID: "r-none", Priority: 5, DSLContent: "none"},
+		{RuleID: "r-broken", Priority: 4, DSLContent: "broken"},
+	}
+
+	resolve := func(t *testing.T, cfg evaluation.ResolutionConfig) *evaluation.Resolution {
+		cfg.ValueKey = "discount_percentage"
+		resolution, err := service.EvaluateCategory(ctx, "PROMOTIONS", rules, evaluation.Context{}, cfg)
+		require.NoError(t, err)
+		return resolution
+	}
+
+	appliedIDs := func(r *evaluation.Resolution) []string {
+		var ids []string
+		for _, a := range r.Applied {
+			ids = append(ids, a.RuleID)
+		}
+		return ids
+	}
+
+	reasons := func(r *evaluation.Resolution) map[string]evaluation.SuppressionReason {
+		out := make(map[string]evaluation.SuppressionReason)
+		for _, s := range r.Suppressed {
+			out[s.RuleID] = s.Reason
+		}
+		return out
+	}
+
+	t.Run("highest priority applies only the top matching rule", func(t *testing.T) {
+		r := resolve(t, evaluation.ResolutionConfig{Policy: evaluation.PolicyHighestPriority})
+
+		assert.Equal(t, []string{"r-ten"}, appliedIDs(r))
+		assert.Equal(t, 10.0, r.Total)
+		assert.Equal(t, map[string]evaluation.SuppressionReason{
+			"r-none":    evaluation.ReasonNotEligible,
+			"r-broken":  evaluation.ReasonEvaluationError,
+			"r-fifteen": evaluation.ReasonLowerPriority,
+			"r-twenty":  evaluation.ReasonLowerPriority,
+		}, reasons(r))
+	})
+
+	t.Run("best for customer applies the largest value", func(t *testing.T) {
+		r := resolve(t, evaluation.ResolutionConfig{Policy: evaluation.PolicyBestForCustomer})
+
+		assert.Equal(t, []string{"r-twenty"}, appliedIDs(r))
+		assert.Equal(t, evaluation.Result{"eligible": true, "discount_percentage": 20.0}, r.Result())
+		assert.Equal(t, evaluation.ReasonLessFavourable, reasons(r)["r-ten"])
+	})
+
+	t.Run("best for customer applies the smallest value when minimizing", func(t *testing.T) {
+		r := resolve(t, evaluation.ResolutionConfig{Policy: evaluation.PolicyBestForCustomer, Direction: evaluation.DirectionMinimize})
+
+		assert.Equal(t, []string{"r-ten"}, appliedIDs(r))
+		assert.Equal(t, evaluation.ReasonLessFavourable, reasons(r)["r-twenty"])
+		assert.Equal(t, evaluation.ReasonLessFavourable, reasons(r)["r-fifteen"])
+	})
+
+	t.Run("first match stops evaluating after the first matching rule", func(t *testing.T) {
+		r := resolve(t, evaluation.ResolutionConfig{Policy: evaluation.PolicyFirstMatch})
+
+		assert.Equal(t, []string{"r-ten"}, appliedIDs(r))
+		assert.Equal(t, evaluation.ReasonNotEvaluated, reasons(r)["r-fifteen"])
+		assert.Equal(t, evaluation.ReasonNotEvaluated, reasons(r)["r-twenty"])
+	})
+
+	t.Run("additive stacks values up to the cap", func(t *testing.T) {
+		r := resolve(t, evaluation.ResolutionConfig{Policy: evaluation.PolicyAdditive, Cap: 30})
+
+		assert.Equal(t, []string{"r-ten", "r-fifteen", "r-twenty"}, appliedIDs(r))
+		assert.Equal(t, 30.0, r.Total)
+		assert.True(t, r.Applied[2].Capped)
+		assert.Equal(t, 5.0, r.Applied[2].Value)
+	})
+
+	t.Run("additive honours the maximum number of stacked rules", func(t *testing.T) {
+		r := resolve(t, evaluation.ResolutionConfig{Policy: evaluation.PolicyAdditive, MaxStacked: 1})
+
+		assert.Equal(t, []string{"r-ten"}, appliedIDs(r))
+		assert.Equal(t, evaluation.ReasonCapReached, reasons(r)["r-fifteen"])
+	})
+
+	t.Run("exclusive groups apply one rule per group", func(t *testing.T) {
+		r := resolve(t, evaluation.ResolutionConfig{Policy: evaluation.PolicyExclusiveGroups})
+
+		assert.Equal(t, []string{"r-ten", "r-fifteen"}, appliedIDs(r))
+		assert.Equal(t, 25.0, r.Total)
+		assert.Equal(t, evaluation.ReasonGroupConflict, reasons(r)["r-twenty"])
+	})
+
+	t.Run("should return an error for an unsupported policy", func(t *testing.T) {
+		_, err := service.EvaluateCategory(ctx, "PROMOTIONS", rules, evaluation.Context{}, evaluation.ResolutionConfig{Policy: "RANDOM"})
+		require.Error(t, err)
+	})
+
+	t.Run("should stop when the request is cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := service.EvaluateCategory(cancelled, "PROMOTIONS", rules, evaluation.Context{}, evaluation.ResolutionConfig{Policy: evaluation.PolicyAdditive})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,85 @@
+package evaluation_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+)
+
+func TestCandidate(t *testing.T) {
+	t.Run("should validate the mode and percentage", func(t *testing.T) {
+		assert.NoError(t, (&evaluation.Candidate{DSLContent: "x", Mode: evaluation.RolloutShadow}).Validate())
+		assert.Error(t, (&evaluation.Candidate{DSLContent: "x", Mode: "BLUE_GREEN"}).Validate())
+		assert.Error(t, (&evaluation.Candidate{DSLContent: "x", Mode: evaluation.RolloutCanary, Percentage: 120}).Validate())
+		assert.Error(t, (&evaluation.Candidate{Mode: evaluation.RolloutShadow}).Validate())
+	})
+
+	t.Run("should select a stable slice of customers close to the percentage", func(t *testing.T) {
+		candidate := &evaluation.Candidate{DSLContent: "x", Mode: evaluation.RolloutCanary, Percentage: 20}
+
+		served := 0
+		for i := 0; i < 10000; i++ {
+			customerID := fmt.Sprintf("cust-%d", i)
+			if candidate.ServesCandidate("rule-1", customerID) {
+				served++
+			}
+			assert.Equal(t, candidate.ServesCandidate("rule-1", customerID), candidate.ServesCandidate("rule-1", customerID))
+		}
+		assert.InDelta(t, 2000, served, 200)
+	})
+
+	t.Run("should never serve the candidate without a customer or in shadow mode", func(t *testing.T) {
+		assert.False(t, (&evaluation.Candidate{Mode: evaluation.RolloutCanary, Percentage: 100}).ServesCandidate("rule-1", ""))
+		assert.False(t, (&evaluation.Candidate{Mode: evaluation.RolloutShadow, Percentage: 100}).ServesCandidate("rule-1", "cust-1"))
+	})
+}
+
+func TestCompare(t *testing.T) {
+	t.Run("should report eligibility and amount differences", func(t *testing.T) {
+		divergence := evaluation.Compare(
+			evaluation.Result{"eligible": true, "discount_percentage": 10.0, "label": "a"},
+			evaluation.Result{"eligible": false, "discount_percentage": 15.0, "label": "b", "points": 3},
+		)
+
+		assert.True(t, divergence.Diverged())
+		assert.True(t, divergence.Eligibility)
+		assert.Equal(t, []string{"discount_percentage", "points"}, divergence.Fields)
+	})
+
+	t.Run("should not report equal results", func(t *testing.T) {
+		divergence := evaluation.Compare(
+			evaluation.Result{"eligible": true, "discount_percentage": 10.0},
+			evaluation.Result{"eligible": true, "discount_percentage": 10},
+		)
+
+		assert.False(t, divergence.Diverged())
+	})
+}
+
+func TestRolloutRules(t *testing.T) {
+	rules := []evaluation.RuleSpec{
+		{RuleID: "r1", RuleVersion: 1, DSLContent: "v1", Candidate: &evaluation.Candidate{RuleVersion: 2, DSLContent: "v2", Mode: evaluation.RolloutShadow}},
+		{RuleID: "r2", RuleVersion: 4, DSLContent: "v4", Candidate: &evaluation.Candidate{RuleVersion: 5, DSLContent: "v5", Mode: evaluation.RolloutCanary, Percentage: 100}},
+		{RuleID: "r3", DSLContent: "plain"},
+	}
+
+	t.Run("should substitute the canary candidates for customers in the slice", func(t *testing.T) {
+		served, substituted := evaluation.CanaryRules(rules, "cust-1")
+
+		assert.True(t, substituted)
+		assert.Equal(t, "v1", served[0].DSLContent)
+		assert.Equal(t, evaluation.RuleSpec{RuleID: "r2", RuleVersion: 5, DSLContent: "v5"}, served[1])
+		assert.Equal(t, "v4", rules[1].DSLContent)
+	})
+
+	t.Run("should substitute the shadow candidates", func(t *testing.T) {
+		shadow, substituted := evaluation.ShadowRules(rules)
+
+		assert.True(t, substituted)
+		assert.Equal(t, evaluation.RuleSpec{RuleID: "r1", RuleVersion: 2, DSLContent: "v2"}, shadow[0])
+		assert.Equal(t, "v4", shadow[1].DSLContent)
+	})
+}
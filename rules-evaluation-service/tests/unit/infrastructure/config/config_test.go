@@ -0,0 +1,57 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/config"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("should configure the resolution of every registered category", func(t *testing.T) {
+		t.Setenv("LOYALTY_RESOLUTION_POLICY", "ADDITIVE")
+		t.Setenv("LOYALTY_RESOLUTION_CAP", "500")
+
+		cfg, err := config.Load(strategies.DefaultResolutions())
+		require.NoError(t, err)
+
+		for _, r := range strategies.Registered() {
+			assert.Contains(t, cfg.Resolution, r.Descriptor.Category)
+		}
+		assert.Equal(t, evaluation.ResolutionConfig{Policy: evaluation.PolicyAdditive, ValueKey: "points_earned", Cap: 500}, cfg.Resolution["LOYALTY"])
+		assert.Equal(t, evaluation.ResolutionConfig{Policy: evaluation.PolicyFirstMatch, ValueKey: "value"}, cfg.Resolution["*"])
+	})
+
+	t.Run("should reject invalid resolutions", func(t *testing.T) {
+		t.Setenv("PROMOTIONS_RESOLUTION_POLICY", "RANDOM")
+
+		_, err := config.Load(strategies.DefaultResolutions())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid resolution of PROMOTIONS: unsupported resolution policy: RANDOM")
+	})
+
+	t.Run("should minimize the values of cost categories", func(t *testing.T) {
+		cfg, err := config.Load(strategies.DefaultResolutions())
+		require.NoError(t, err)
+
+		assert.Equal(t, evaluation.DirectionMinimize, cfg.Resolution["PAYMENTS"].Direction)
+	})
+
+	t.Run("should reject unknown directions", func(t *testing.T) {
+		t.Setenv("LOYALTY_RESOLUTION_DIRECTION", "SIDEWAYS")
+
+		_, err := config.Load(strategies.DefaultResolutions())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported resolution direction: SIDEWAYS")
+	})
+
+	t.Run("should reject resolutions without a value key", func(t *testing.T) {
+		_, err := config.Load(map[string]evaluation.ResolutionConfig{"SHIPPING": {Policy: evaluation.PolicyAdditive}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid resolution of SHIPPING")
+	})
+}
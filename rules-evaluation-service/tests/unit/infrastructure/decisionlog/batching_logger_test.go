@@ -0,0 +1,94 @@
+package decisionlog_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"rules-evaluation-service/internal/domain/decision"
+	"rules-evaluation-service/internal/infrastructure/decisionlog"
+)
+
+// FakeSink records the published batches. When block is set, publishing waits until it is closed.
+type FakeSink struct {
+	mu      sync.Mutex
+	batches [][]string
+	calls   atomic.Int32
+	block   chan struct{}
+}
+
+func (s *FakeSink) PublishBatch(ctx context.Context, records []decision.Record) error {
+	s.calls.Add(1)
+	if s.block != nil {
+		<-s.block
+	}
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.DecisionID
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, ids)
+	return nil
+}
+
+func (s *FakeSink) Batches() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string(nil), s.batches...)
+}
+
+func TestBatchingLogger(t *testing.T) {
+	t.Run("should publish full batches and flush the rest on close", func(t *testing.T) {
+		sink := &FakeSink{}
+		logger := decisionlog.NewBatchingLogger(sink, decisionlog.Options{SampleRate: 1, BatchSize: 2, FlushInterval: time.Hour, BufferSize: 10})
+
+		for _, id := range []string{"d1", "d2", "d3"} {
+			logger.Log(decision.Record{DecisionID: id})
+		}
+		assert.Eventually(t, func() bool { return len(sink.Batches()) == 1 }, time.Second, 5*time.Millisecond)
+		logger.Close()
+
+		assert.Equal(t, [][]string{{"d1", "d2"}, {"d3"}}, sink.Batches())
+	})
+
+	t.Run("should flush a partial batch after the flush interval", func(t *testing.T) {
+		sink := &FakeSink{}
+		logger := decisionlog.NewBatchingLogger(sink, decisionlog.Options{SampleRate: 1, BatchSize: 100, FlushInterval: 10 * time.Millisecond, BufferSize: 10})
+		defer logger.Close()
+
+		logger.Log(decision.Record{DecisionID: "d1"})
+
+		assert.Eventually(t, func() bool { return len(sink.Batches()) == 1 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("should not record decisions that are sampled out", func(t *testing.T) {
+		sink := &FakeSink{}
+		logger := decisionlog.NewBatchingLogger(sink, decisionlog.Options{SampleRate: 0, BatchSize: 1, FlushInterval: time.Hour, BufferSize: 10})
+
+		logger.Log(decision.Record{DecisionID: "d1"})
+		logger.Close()
+
+		assert.Empty(t, sink.Batches())
+	})
+
+	t.Run("should drop decisions instead of blocking when the buffer is full", func(t *testing.T) {
+		sink := &FakeSink{block: make(chan struct{})}
+		logger := decisionlog.NewBatchingLogger(sink, decisionlog.Options{SampleRate: 1, BatchSize: 1, FlushInterval: time.Hour, BufferSize: 1})
+
+		logger.Log(decision.Record{DecisionID: "d1"})
+		assert.Eventually(t, func() bool { return sink.calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+		// The loop is blocked on the sink: one decision fits in the buffer, the others are dropped.
+		for _, id := range []string{"d2", "d3", "d4"} {
+			logger.Log(decision.Record{DecisionID: id})
+		}
+		close(sink.block)
+		logger.Close()
+
+		assert.Equal(t, [][]string{{"d1"}, {"d2"}}, sink.Batches())
+	})
+}
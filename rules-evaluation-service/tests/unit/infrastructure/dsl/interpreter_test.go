@@ -0,0 +1,326 @@
+package dsl_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/domain/shared"
+	"rules-evaluation-service/internal/infrastructure/dsl"
+)
+
+func TestProgramRun(t *testing.T) {
+	ctx := context.Background()
+	evalContext := evaluation.Context{
+		"customer":     map[string]interface{}{"tier": "GOLD", "segment": "VIP"},
+		"order_amount": 250.0,
+		"tags":         []interface{}{"new", "mobile"},
+	}
+
+	tests := []struct {
+		name    string
+		dsl     string
+		matched bool
+		actions map[string]interface{}
+	}{
+		{
+			name:    "nested and flat paths with AND",
+			dsl:     "IF customer.tier = 'GOLD' AND order.amount > 200 THEN discount.percentage = 15",
+			matched: true,
+			actions: map[string]interface{}{"discount.percentage": 15.0},
+		},
+		{
+			name:    "IF keyword is optional and == is accepted",
+			dsl:     "customer.segment == 'VIP' THEN shipping.cost = 0",
+			matched: true,
+			actions: map[string]interface{}{"shipping.cost": 0.0},
+		},
+		{
+			name:    "IN lists, NOT and OR",
+			dsl:     "IF customer.tier NOT IN ('SILVER', 'BRONZE') OR order.amount < 10 THEN points.multiplier = 2",
+			matched: true,
+			actions: map[string]interface{}{"points.multiplier": 2.0},
+		},
+		{
+			name:    "CONTAINS on a list and arithmetic actions",
+			dsl:     "IF tags CONTAINS 'mobile' THEN discount.amount = order.amount * 0.1, discount.code = 'APP'",
+			matched: true,
+			actions: map[string]interface{}{"discount.amount": 25.0, "discount.code": "APP"},
+		},
+		{
+			name:    "condition not met",
+			dsl:     "IF order.amount >= 300 THEN discount.percentage = 10",
+			matched: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := dsl.Compile(tt.dsl)
+			require.NoError(t, err)
+
+			outcome, err := program.Run(ctx, evalContext)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matched, outcome.Matched)
+			if tt.matched {
+				assert.Equal(t, tt.actions, outcome.Actions)
+			}
+		})
+	}
+
+	t.Run("should report missing context fields", func(t *testing.T) {
+		program, err := dsl.Compile("IF customer.email ENDS_WITH '@corp.com' THEN discount.percentage = 5")
+		require.NoError(t, err)
+
+		_, err = program.Run(ctx, evalContext)
+		var missing *dsl.MissingFieldError
+		require.ErrorAs(t, err, &missing)
+		assert.Equal(t, "customer.email", missing.Path)
+	})
+
+	t.Run("should read whole number literals", func(t *testing.T) {
+		program, err := dsl.Compile("IF order.amount > 1.5e2 THEN discount.amount = 2.25, discount.cap = 1E-1")
+		require.NoError(t, err)
+
+		outcome, err := program.Run(ctx, evalContext)
+		require.NoError(t, err)
+		assert.True(t, outcome.Matched)
+		assert.Equal(t, map[string]interface{}{"discount.amount": 2.25, "discount.cap": 0.1}, outcome.Actions)
+	})
+
+	t.Run("should reject malformed number literals with their position", func(t *testing.T) {
+		for src, message := range map[string]string{
+			"IF order.amount > 1.2.3 THEN x = 1": `invalid number "1.2.3": unexpected '.' at position 21`,
+			"IF order.amount > 10abc THEN x = 1": `invalid number "10abc": unexpected 'a' at position 20`,
+			"IF order.amount > 1e5x THEN x = 1":  `invalid number "1e5x": unexpected 'x' at position 21`,
+			"IF order.amount > 0x10 THEN x = 1":  `invalid number "0x10": unexpected 'x' at position 19`,
+			"IF order.amount > 1e999 THEN x = 1": `invalid number "1e999" at position 18`,
+		} {
+			_, err := dsl.Compile(src)
+			require.Error(t, err, src)
+			assert.Contains(t, err.Error(), message, src)
+		}
+	})
+
+	t.Run("should reject invalid DSL", func(t *testing.T) {
+		for _, src := range []string{"invalid dsl", "IF order.amount > THEN x = 1", "IF a = 'open THEN x = 1", "IF a = 1 THEN"} {
+			_, err := dsl.Compile(src)
+			assert.Error(t, err, src)
+		}
+	})
+}
+
+func TestLimits(t *testing.T) {
+	ctx := context.Background()
+	evalContext := evaluation.Context{"order_amount": 250.0}
+	limits := dsl.Limits{MaxDepth: 4, MaxNodes: 50, MaxSteps: 100, MaxResultBytes: 64}
+
+	assertLimit := func(t *testing.T, err error, limit string) {
+		t.Helper()
+		var exceeded *shared.LimitExceededError
+		require.ErrorAs(t, err, &exceeded)
+		assert.Equal(t, limit, exceeded.Limit)
+	}
+
+	t.Run("should reject deeply nested expressions", func(t *testing.T) {
+		_, err := dsl.CompileWithLimits("IF (((((order.amount > 1))))) THEN x = 1", limits)
+		assertLimit(t, err, dsl.LimitMaxDepth)
+
+		_, err = dsl.CompileWithLimits("IF NOT NOT NOT NOT NOT order.amount > 1 THEN x = 1", limits)
+		assertLimit(t, err, dsl.LimitMaxDepth)
+
+		_, err = dsl.CompileWithLimits("IF ((order.amount > 1)) THEN x = 1", limits)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should type-check nested calls in linear time", func(t *testing.T) {
+		source := fmt.Sprintf("IF %scustomer.name%s = \"x\" THEN x = 1", strings.Repeat("lower(", 30), strings.Repeat(")", 30))
+
+		start := time.Now()
+		_, err := dsl.CompileWithLimits(source, dsl.Limits{MaxDepth: 64})
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("should reject rules with too many expression nodes", func(t *testing.T) {
+		_, err := dsl.CompileWithLimits(fmt.Sprintf("IF order.amount IN (%s) THEN x = 1", numbers(100)), limits)
+		assertLimit(t, err, dsl.LimitMaxNodes)
+	})
+
+	t.Run("should stop runs that exceed the step budget", func(t *testing.T) {
+		program, err := dsl.CompileWithLimits(fmt.Sprintf("IF order.amount IN (%s) THEN x = 1", numbers(40)), dsl.Limits{MaxSteps: 30})
+		require.NoError(t, err)
+
+		_, err = program.Run(ctx, evalContext)
+		assertLimit(t, err, dsl.LimitMaxSteps)
+	})
+
+	t.Run("should stop runs that exceed the timeout", func(t *testing.T) {
+		program, err := dsl.CompileWithLimits(fmt.Sprintf("IF order.amount IN (%s) THEN x = 1", numbers(5000)), dsl.Limits{Timeout: time.Nanosecond})
+		require.NoError(t, err)
+
+		_, err = program.Run(ctx, evalContext)
+		assertLimit(t, err, dsl.LimitTimeout)
+	})
+
+	t.Run("should reject results above the maximum size", func(t *testing.T) {
+		program, err := dsl.CompileWithLimits(fmt.Sprintf("IF order.amount > 1 THEN note = '%s'", strings.Repeat("a", 100)), limits)
+		require.NoError(t, err)
+
+		_, err = program.Run(ctx, evalContext)
+		assertLimit(t, err, dsl.LimitMaxResultBytes)
+	})
+}
+
+// numbers returns a comma-separated list of n numbers, none of them matching the test order amount.
+func numbers(n int) string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprint(i + 1000)
+	}
+	return strings.Join(items, ", ")
+}
+
+func TestFunctions(t *testing.T) {
+	ctx := context.Background()
+	evalContext := evaluation.Context{
+		"customer": map[string]interface{}{"email": "Jane.Doe@Corp.com", "name": "  Jane "},
+		"order": map[string]interface{}{
+			// A Saturday evening in Madrid, already Sunday in Tokyo.
+			"date": "2026-11-28T19:30:00+01:00",
+		},
+		"items": []interface{}{
+			map[string]interface{}{"sku": "tv", "category": "electronics", "price": 500.0},
+			map[string]interface{}{"sku": "phone", "category": "electronics", "price": 300.0},
+			map[string]interface{}{"sku": "book", "category": "books", "price": 20.0},
+		},
+		"tags": []interface{}{"gift", "express"},
+	}
+
+	tests := []struct {
+		name    string
+		dsl     string
+		matched bool
+		actions map[string]interface{}
+	}{
+		{
+			name:    "date parts as path suffixes",
+			dsl:     "IF order.date.weekday IN ('SAT', 'SUN') AND order.date.hour >= 19 THEN discount.percentage = 5",
+			matched: true,
+		},
+		{
+			name:    "time zone aware date functions",
+			dsl:     "IF weekday(order.date, 'Asia/Tokyo') = 'SUN' AND day(order.date, 'Asia/Tokyo') = 29 THEN discount.percentage = 5",
+			matched: true,
+		},
+		{
+			name:    "between with a date-only upper bound includes the whole day",
+			dsl:     "IF between(date(order.date), '2026-11-27', '2026-11-28') THEN discount.percentage = 5",
+			matched: true,
+		},
+		{
+			name:    "between on numbers",
+			dsl:     "IF between(count(items), 2, 3) THEN discount.percentage = 5",
+			matched: true,
+		},
+		{
+			name:    "string functions",
+			dsl:     "IF lower(customer.email) ENDS_WITH '@corp.com' THEN greeting = concat('Hi ', trim(customer.name), '!'), initials = upper(substring(customer.email, 0, 1))",
+			matched: true,
+			actions: map[string]interface{}{"greeting": "Hi Jane!", "initials": "J"},
+		},
+		{
+			name:    "count with WHERE",
+			dsl:     "IF count(items WHERE category = 'electronics') >= 2 THEN discount.percentage = 10",
+			matched: true,
+		},
+		{
+			name:    "aggregates over projected fields",
+			dsl:     "IF sum(items.price) > 800 THEN total = sum(items.price), cheapest = min(items.price), average = avg(items.price), top = max(items.price)",
+			matched: true,
+			actions: map[string]interface{}{"total": 820.0, "cheapest": 20.0, "average": 820.0 / 3, "top": 500.0},
+		},
+		{
+			name:    "quantifiers",
+			dsl:     "IF any(items WHERE price > 400) AND NOT all(items WHERE category = 'electronics') AND all(items WHERE price > 0) THEN flag = TRUE",
+			matched: true,
+		},
+		{
+			name:    "WHERE over scalar lists with it",
+			dsl:     "IF count(tags WHERE it STARTS_WITH 'ex') = 1 AND length(tags) = 2 THEN flag = TRUE",
+			matched: true,
+		},
+		{
+			name:    "date arithmetic",
+			dsl:     "IF days_between('2026-11-01', add_days(order.date, 2)) = 29 THEN flag = TRUE",
+			matched: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := dsl.Compile(tt.dsl)
+			require.NoError(t, err)
+
+			outcome, err := program.Run(ctx, evalContext)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matched, outcome.Matched)
+			if tt.actions != nil {
+				assert.Equal(t, tt.actions, outcome.Actions)
+			}
+		})
+	}
+
+	t.Run("substring clamps out of range bounds", func(t *testing.T) {
+		long := evaluation.Context{"text": strings.Repeat("a", 2000)}
+		for src, want := range map[string]string{
+			"IF TRUE THEN s = substring(text, 1500, 9223372036854774784)": strings.Repeat("a", 500),
+			"IF TRUE THEN s = substring(text, 1e300, 5)":                  "",
+			"IF TRUE THEN s = substring(text, -1e300, 2)":                 "aa",
+			"IF TRUE THEN s = substring(text, 1999, -1e300)":              "",
+		} {
+			program, err := dsl.Compile(src)
+			require.NoError(t, err, src)
+
+			outcome, err := program.Run(ctx, long)
+			require.NoError(t, err, src)
+			assert.Equal(t, want, outcome.Actions["s"], src)
+		}
+	})
+
+	t.Run("now is read from the evaluation clock", func(t *testing.T) {
+		program, err := dsl.Compile("IF now() = '2026-11-28T10:00:00Z' AND weekday(now()) = 'SAT' THEN flag = TRUE")
+		require.NoError(t, err)
+		pinned := evaluation.WithClock(ctx, evaluation.FixedClock{Time: time.Date(2026, 11, 28, 10, 0, 0, 0, time.UTC)})
+
+		outcome, err := program.Run(pinned, evalContext)
+		require.NoError(t, err)
+		assert.True(t, outcome.Matched)
+
+		outcome, err = program.Run(ctx, evalContext)
+		require.NoError(t, err)
+		assert.False(t, outcome.Matched)
+	})
+
+	t.Run("should type-check function calls at compile time", func(t *testing.T) {
+		for src, message := range map[string]string{
+			"IF shout(customer.name) = 'x' THEN a = 1":                "unknown function shout",
+			"IF lower(1) = 'x' THEN a = 1":                            "lower expects a string as argument 1, got number",
+			"IF lower('a', 'b') = 'x' THEN a = 1":                     "lower expects 1 arguments, got 2",
+			"IF sum('abc') > 1 THEN a = 1":                            "sum expects a list as argument 1, got string",
+			"IF weekday(order.date, 'Mars/Olympus') = 'x' THEN a = 1": "unknown time zone",
+			"IF lower(items WHERE price > 1) = 'x' THEN a = 1":        "lower does not accept WHERE",
+			"IF between(now(), '2026-01-01') THEN a = 1":              "between expects 3 arguments, got 2",
+		} {
+			_, err := dsl.Compile(src)
+			require.Error(t, err, src)
+			assert.Contains(t, err.Error(), message, src)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/infrastructure/ratelimit"
+)
+
+func TestClientLimiter(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewClientLimiter(2, 2, time.Minute, 0)
+	limiter.SetClock(func() time.Time { return now })
+
+	t.Run("allows bursts and then asks the client to wait for the next token", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			ok, _ := limiter.Allow("key:checkout")
+			assert.True(t, ok)
+		}
+		ok, retryAfter := limiter.Allow("key:checkout")
+		assert.False(t, ok)
+		assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+		now = now.Add(500 * time.Millisecond)
+		ok, _ = limiter.Allow("key:checkout")
+		assert.True(t, ok)
+	})
+
+	t.Run("limits each client separately", func(t *testing.T) {
+		ok, _ := limiter.Allow("key:backoffice")
+		assert.True(t, ok)
+	})
+
+	t.Run("clients beyond the maximum share one bucket", func(t *testing.T) {
+		limiter := ratelimit.NewClientLimiter(1, 1, time.Minute, 2)
+		limiter.SetClock(func() time.Time { return now })
+
+		for _, client := range []string{"key:a", "key:b", "key:c"} {
+			ok, _ := limiter.Allow(client)
+			assert.True(t, ok, client)
+		}
+		ok, _ := limiter.Allow("key:d")
+		assert.False(t, ok, "key:d shares the overflow bucket with key:c")
+	})
+}
+
+func TestCredentialKey(t *testing.T) {
+	key := ratelimit.CredentialKey("key", "secret-api-key")
+
+	assert.Equal(t, key, ratelimit.CredentialKey("key", "secret-api-key"))
+	assert.NotEqual(t, key, ratelimit.CredentialKey("key", "other-api-key"))
+	assert.NotEqual(t, key, ratelimit.CredentialKey("token", "secret-api-key"))
+	assert.NotContains(t, key, "secret-api-key")
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	t.Run("sheds requests beyond the limit", func(t *testing.T) {
+		limiter := ratelimit.NewConcurrencyLimiter(ratelimit.ConcurrencyOptions{InitialLimit: 2, MinLimit: 1, MaxLimit: 10, Window: 10})
+
+		first, ok := limiter.Acquire()
+		require.True(t, ok)
+		_, ok = limiter.Acquire()
+		require.True(t, ok)
+		_, ok = limiter.Acquire()
+		assert.False(t, ok)
+
+		first(time.Millisecond)
+		_, ok = limiter.Acquire()
+		assert.True(t, ok)
+	})
+
+	t.Run("reduces the limit while the p99 latency exceeds the target", func(t *testing.T) {
+		limiter := ratelimit.NewConcurrencyLimiter(ratelimit.ConcurrencyOptions{
+			TargetP99: 100 * time.Millisecond, InitialLimit: 50, MinLimit: 40, MaxLimit: 100, Window: 10,
+		})
+		for round := 0; round < 3; round++ {
+			for i := 0; i < 10; i++ {
+				release, ok := limiter.Acquire()
+				require.True(t, ok)
+				release(200 * time.Millisecond)
+			}
+		}
+		assert.Equal(t, 40, limiter.Limit(), "50 -> 45 -> 40 -> bounded by the minimum")
+	})
+
+	t.Run("raises the limit when it is reached and latency is healthy", func(t *testing.T) {
+		limiter := ratelimit.NewConcurrencyLimiter(ratelimit.ConcurrencyOptions{
+			TargetP99: 100 * time.Millisecond, InitialLimit: 2, MinLimit: 1, MaxLimit: 3, Window: 2,
+		})
+		a, _ := limiter.Acquire()
+		b, _ := limiter.Acquire()
+		a(time.Millisecond)
+		b(time.Millisecond)
+		assert.Equal(t, 3, limiter.Limit())
+
+		c, _ := limiter.Acquire()
+		c(time.Millisecond)
+		d, _ := limiter.Acquire()
+		d(time.Millisecond)
+		assert.Equal(t, 3, limiter.Limit(), "not saturated and bounded by the maximum")
+	})
+}
+
+func TestGuard(t *testing.T) {
+	t.Run("a nil guard admits every request", func(t *testing.T) {
+		var guard *ratelimit.Guard
+		release, rejection := guard.Admit("key:a")
+		assert.Nil(t, rejection)
+		release(time.Millisecond)
+	})
+
+	t.Run("reports the reason and the retry delay", func(t *testing.T) {
+		guard := ratelimit.NewGuard(
+			ratelimit.NewClientLimiter(1, 1, 0, 0),
+			ratelimit.NewConcurrencyLimiter(ratelimit.ConcurrencyOptions{InitialLimit: 1, MinLimit: 1, MaxLimit: 1}),
+		)
+		_, rejection := guard.Admit("key:a")
+		require.Nil(t, rejection)
+
+		_, rejection = guard.Admit("key:a")
+		require.NotNil(t, rejection)
+		assert.Equal(t, ratelimit.ReasonRateLimited, rejection.Reason)
+		assert.Equal(t, "1", rejection.RetryAfterSeconds())
+
+		_, rejection = guard.Admit("key:b")
+		require.NotNil(t, rejection)
+		assert.Equal(t, ratelimit.ReasonOverloaded, rejection.Reason)
+	})
+}
@@ -0,0 +1,143 @@
+package strategies_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/domain/coupon"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/persistence/memory"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+)
+
+func TestCouponsStrategy(t *testing.T) {
+	ctx := context.Background()
+	const dsl = "IF coupon.code = 'WELCOME10' THEN coupon.discount_percentage = 10, coupon.min_basket = 50, " +
+		"coupon.eligible_categories = ('books', 'toys'), coupon.per_customer_limit = 1, coupon.global_limit = 3"
+
+	basket := func(customerID string) evaluation.Context {
+		return evaluation.Context{
+			"coupon_code":  "WELCOME10",
+			"customer_id":  customerID,
+			"order_amount": 120.0,
+			"items": []interface{}{
+				map[string]interface{}{"category": "books", "price": 30.0, "quantity": 2.0},
+				map[string]interface{}{"category": "electronics", "price": 60.0},
+			},
+		}
+	}
+
+	t.Run("should discount eligible categories and reserve a usage", func(t *testing.T) {
+		strategy := strategies.NewCouponsStrategy(memory.NewCouponUsageStore(), time.Minute)
+
+		result, err := strategy.Evaluate(ctx, dsl, basket("c1"))
+		require.NoError(t, err)
+
+		assert.Equal(t, true, result["eligible"])
+		assert.Equal(t, 60.0, result["eligible_amount"])
+		assert.Equal(t, 6.0, result["discount_amount"])
+		assert.NotEmpty(t, result["reservation_id"])
+	})
+
+	t.Run("should keep the reservation of a checkout evaluated again", func(t *testing.T) {
+		strategy := strategies.NewCouponsStrategy(memory.NewCouponUsageStore(), time.Minute)
+
+		first, err := strategy.Evaluate(ctx, dsl, basket("c1"))
+		require.NoError(t, err)
+		second, err := strategy.Evaluate(ctx, dsl, basket("c1"))
+		require.NoError(t, err)
+
+		assert.Equal(t, true, second["eligible"])
+		assert.Equal(t, first["reservation_id"], second["reservation_id"])
+	})
+
+	t.Run("should enforce the per-customer limit", func(t *testing.T) {
+		strategy := strategies.NewCouponsStrategy(memory.NewCouponUsageStore(), time.Minute)
+		checkout := func(id string) evaluation.Context {
+			evalContext := basket("c1")
+			evalContext["checkout_id"] = id
+			return evalContext
+		}
+
+		_, err := strategy.Evaluate(ctx, dsl, checkout("k1"))
+		require.NoError(t, err)
+		result, err := strategy.Evaluate(ctx, dsl, checkout("k2"))
+		require.NoError(t, err)
+
+		assert.Equal(t, evaluation.Result{
+			"eligible": false,
+			"reason":   "coupon WELCOME10 has reached its customer usage limit of 1",
+		}, result)
+	})
+
+	t.Run("should free the usage when a reservation is released", func(t *testing.T) {
+		store := memory.NewCouponUsageStore()
+		strategy := strategies.NewCouponsStrategy(store, time.Minute)
+
+		result, err := strategy.Evaluate(ctx, dsl, basket("c1"))
+		require.NoError(t, err)
+		require.NoError(t, store.Release(context.Background(), result["reservation_id"].(string)))
+
+		result, err = strategy.Evaluate(ctx, dsl, basket("c1"))
+		require.NoError(t, err)
+		assert.Equal(t, true, result["eligible"])
+		assert.ErrorIs(t, store.Commit(context.Background(), "unknown"), coupon.ErrReservationNotFound)
+	})
+
+	t.Run("should never over-redeem under concurrent checkouts", func(t *testing.T) {
+		strategy := strategies.NewCouponsStrategy(memory.NewCouponUsageStore(), time.Minute)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		redeemed := 0
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				result, err := strategy.Evaluate(ctx, dsl, basket(string(rune('a'+i))))
+				require.NoError(t, err)
+				if result["eligible"] == true {
+					mu.Lock()
+					redeemed++
+					mu.Unlock()
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, 3, redeemed)
+	})
+
+	t.Run("should reject baskets below the minimum or outside the validity window", func(t *testing.T) {
+		strategy := strategies.NewCouponsStrategy(memory.NewCouponUsageStore(), time.Minute)
+
+		small := basket("c1")
+		small["order_amount"] = 20.0
+		result, err := strategy.Evaluate(ctx, dsl, small)
+		require.NoError(t, err)
+		assert.Equal(t, false, result["eligible"])
+
+		expired := "IF coupon.code = 'WELCOME10' THEN coupon.discount_amount = 5, coupon.valid_until = '2020-01-31'"
+		result, err = strategy.Evaluate(ctx, expired, basket("c1"))
+		require.NoError(t, err)
+		assert.Equal(t, evaluation.Result{"eligible": false, "reason": "coupon expired on 2020-01-31"}, result)
+	})
+
+	t.Run("should reject a single-use code once it is reserved", func(t *testing.T) {
+		strategy := strategies.NewCouponsStrategy(memory.NewCouponUsageStore(), time.Minute)
+		singleUse := "IF coupon.code = 'WELCOME10' THEN coupon.discount_amount = 5, coupon.single_use = TRUE"
+
+		first, err := strategy.Evaluate(ctx, singleUse, basket("c1"))
+		require.NoError(t, err)
+		second, err := strategy.Evaluate(ctx, singleUse, basket("c2"))
+		require.NoError(t, err)
+
+		assert.Equal(t, true, first["eligible"])
+		assert.Equal(t, false, second["eligible"])
+	})
+}
@@ -0,0 +1,96 @@
+package strategies_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+)
+
+func TestLoyaltyStrategy(t *testing.T) {
+	ctx := context.Background()
+	strategy := strategies.NewLoyaltyStrategy()
+
+	t.Run("should apply tier and category multipliers to earned points", func(t *testing.T) {
+		dsl := "IF customer.tier IN ('GOLD', 'PLATINUM') THEN earn.points_per_unit = 1, " +
+			"earn.tier_multiplier.GOLD = 2, earn.category_multiplier.electronics = 3"
+		context := evaluation.Context{
+			"customer_tier": "GOLD",
+			"order_amount":  150.0,
+			"items": []interface{}{
+				map[string]interface{}{"category": "electronics", "price": 50.0, "quantity": 1.0},
+				map[string]interface{}{"category": "books", "price": 50.0, "quantity": 2.0},
+			},
+		}
+
+		result, err := strategy.Evaluate(ctx, dsl, context)
+		require.NoError(t, err)
+
+		// (50*3 + 100*1) * 2 = 500 points over a 150 order.
+		assert.Equal(t, true, result["eligible"])
+		assert.Equal(t, int64(500), result["points_earned"])
+		assert.Equal(t, 3.33, result["multiplier_applied"])
+	})
+
+	t.Run("should redeem points within the configured limits", func(t *testing.T) {
+		dsl := "IF order.amount > 0 THEN redeem.rate = 0.01, redeem.min_points = 500, redeem.max_points = 2000"
+		context := evaluation.Context{
+			"order_amount":            100.0,
+			"customer_points_balance": 5000.0,
+			"loyalty_redeem_points":   3000.0,
+		}
+
+		result, err := strategy.Evaluate(ctx, dsl, context)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]interface{}{
+			"allowed":         true,
+			"points_redeemed": int64(2000),
+			"value":           20.0,
+		}, result["redemption"])
+	})
+
+	t.Run("should refuse redemption under the minimum", func(t *testing.T) {
+		dsl := "IF order.amount > 0 THEN redeem.rate = 0.01, redeem.min_points = 500"
+		context := evaluation.Context{"order_amount": 100.0, "loyalty_redeem_points": 100.0}
+
+		result, err := strategy.Evaluate(ctx, dsl, context)
+		require.NoError(t, err)
+
+		redemption := result["redemption"].(map[string]interface{})
+		assert.Equal(t, false, redemption["allowed"])
+		assert.Equal(t, "at least 500 points must be redeemed", redemption["reason"])
+	})
+
+	t.Run("should report progress towards the next tier", func(t *testing.T) {
+		dsl := "IF order.amount > 0 THEN tier.SILVER = 1000, tier.GOLD = 5000, tier.PLATINUM = 20000"
+		context := evaluation.Context{"order_amount": 200.0, "customer_lifetime_points": 2800.0}
+
+		result, err := strategy.Evaluate(ctx, dsl, context)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]interface{}{
+			"current_tier":     "SILVER",
+			"next_tier":        "GOLD",
+			"points":           int64(3000),
+			"points_to_next":   int64(2000),
+			"progress_to_next": 0.5,
+		}, result["tier_progress"])
+	})
+
+	t.Run("should return not eligible for missing context", func(t *testing.T) {
+		result, err := strategy.Evaluate(ctx, "IF customer.tier = 'GOLD' THEN earn.multiplier = 2", evaluation.Context{})
+		require.NoError(t, err)
+
+		assert.Equal(t, evaluation.Result{"eligible": false, "reason": "Missing customer.tier in context"}, result)
+	})
+
+	t.Run("should return an error for invalid DSL", func(t *testing.T) {
+		_, err := strategy.Evaluate(ctx, "invalid dsl", evaluation.Context{})
+		require.Error(t, err)
+	})
+}
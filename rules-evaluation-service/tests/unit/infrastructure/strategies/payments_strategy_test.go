@@ -0,0 +1,84 @@
+package strategies_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+)
+
+func TestPaymentsStrategy(t *testing.T) {
+	ctx := context.Background()
+	strategy := strategies.NewPaymentsStrategy()
+	const dsl = "IF order.amount > 0 THEN payment.CARD.surcharge_percentage = 1.5, payment.CARD.acquirer = 'adyen', " +
+		"payment.COD.max_amount = 300, payment.COD.countries = ('ES', 'PT'), " +
+		"payment.BNPL.min_amount = 50, payment.BNPL.blocked_risk_flags = ('CHARGEBACK'), payment.BNPL.priority = 10, " +
+		"payment.TRANSFER.discount_percentage = 2"
+
+	methodNames := func(result evaluation.Result) []string {
+		var names []string
+		for _, m := range result["methods"].([]map[string]interface{}) {
+			names = append(names, m["method"].(string))
+		}
+		return names
+	}
+
+	t.Run("should rank allowed methods by priority and then by total", func(t *testing.T) {
+		context := evaluation.Context{"order_amount": 200.0, "customer_country": "ES"}
+
+		result, err := strategy.Evaluate(ctx, dsl, context)
+		require.NoError(t, err)
+
+		assert.Equal(t, true, result["eligible"])
+		assert.Equal(t, []string{"BNPL", "TRANSFER", "COD", "CARD"}, methodNames(result))
+		assert.Equal(t, "BNPL", result["preferred_method"])
+		assert.Equal(t, 200.0, result["total"])
+		assert.Equal(t, map[string]interface{}{"CARD": "adyen"}, result["routing"])
+
+		card := result["methods"].([]map[string]interface{})[3]
+		assert.Equal(t, 3.0, card["surcharge"])
+		assert.Equal(t, 203.0, card["total"])
+		assert.Equal(t, []string{"surcharge of 3.00", "routed to adyen"}, card["reasons"])
+	})
+
+	t.Run("should rank disallowed methods last with their reasons", func(t *testing.T) {
+		context := evaluation.Context{
+			"order_amount": 400.0,
+			"customer":     map[string]interface{}{"country": "FR", "risk_flags": []interface{}{"CHARGEBACK"}},
+		}
+
+		result, err := strategy.Evaluate(ctx, dsl, context)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"TRANSFER", "CARD", "BNPL", "COD"}, methodNames(result))
+		methods := result["methods"].([]map[string]interface{})
+		assert.Equal(t, false, methods[2]["allowed"])
+		assert.Equal(t, []string{"blocked by risk flag CHARGEBACK"}, methods[2]["reasons"])
+		assert.Equal(t, []string{"amount 400.00 exceeds the maximum of 300.00", `not available in country "FR"`}, methods[3]["reasons"])
+	})
+
+	t.Run("should only consider the methods offered in the context", func(t *testing.T) {
+		context := evaluation.Context{
+			"order_amount": 100.0,
+			"payment":      map[string]interface{}{"methods": []interface{}{"CARD", "PAYPAL"}},
+		}
+
+		result, err := strategy.Evaluate(ctx, dsl+", payment.preferred = 'PAYPAL'", context)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"PAYPAL", "CARD"}, methodNames(result))
+		assert.Equal(t, "PAYPAL", result["preferred_method"])
+	})
+
+	t.Run("should not be eligible when every method is disallowed", func(t *testing.T) {
+		result, err := strategy.Evaluate(ctx, "IF order.amount > 0 THEN payment.CARD.allowed = FALSE", evaluation.Context{"order_amount": 10.0})
+		require.NoError(t, err)
+
+		assert.Equal(t, false, result["eligible"])
+		assert.Equal(t, "", result["preferred_method"])
+	})
+}
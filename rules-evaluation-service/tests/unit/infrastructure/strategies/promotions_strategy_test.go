@@ -1,7 +1,9 @@
 package strategies_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -11,13 +13,14 @@ import (
 )
 
 func TestPromotionsStrategy(t *testing.T) {
+	ctx := context.Background()
 	strategy := strategies.NewPromotionsStrategy()
 
 	t.Run("should return eligible and discount when amount is over threshold", func(t *testing.T) {
 		dsl := "IF order.amount > 100 THEN discount.percentage = 10"
 		context := evaluation.Context{"order_amount": 150.0}
 
-		result, err := strategy.Evaluate(dsl, context)
+		result, err := strategy.Evaluate(ctx, dsl, context)
 		require.NoError(t, err)
 
 		assert.Equal(t, evaluation.Result{"eligible": true, "discount_percentage": 10.0}, result)
@@ -27,7 +30,7 @@ func TestPromotionsStrategy(t *testing.T) {
 		dsl := "IF order.amount > 100 THEN discount.percentage = 10"
 		context := evaluation.Context{"order_amount": 50.0}
 
-		result, err := strategy.Evaluate(dsl, context)
+		result, err := strategy.Evaluate(ctx, dsl, context)
 		require.NoError(t, err)
 
 		assert.Equal(t, evaluation.Result{"eligible": false}, result)
@@ -37,17 +40,45 @@ func TestPromotionsStrategy(t *testing.T) {
 		dsl := "invalid dsl"
 		context := evaluation.Context{"order_amount": 150.0}
 
-		_, err := strategy.Evaluate(dsl, context)
+		_, err := strategy.Evaluate(ctx, dsl, context)
 		require.Error(t, err)
 	})
 
 	t.Run("should return not eligible for missing context", func(t *testing.T) {
 		dsl := "IF order.amount > 100 THEN discount.percentage = 10"
-		context := evaluation.Context{} // Missing order_amount
+		context := evaluation.Context{} // Missing order.amount
 
-		result, err := strategy.Evaluate(dsl, context)
+		result, err := strategy.Evaluate(ctx, dsl, context)
 		require.NoError(t, err)
 
-		assert.Equal(t, evaluation.Result{"eligible": false, "reason": "Missing order_amount in context"}, result)
+		assert.Equal(t, evaluation.Result{"eligible": false, "reason": "Missing order.amount in context"}, result)
+	})
+
+	t.Run("should evaluate conditions with the whole DSL on the nested context", func(t *testing.T) {
+		dsl := "IF lower(customer.email) ENDS_WITH '@corp.com' AND count(items WHERE category = 'toys') >= 2 " +
+			"AND sum(items.price) > order.amount / 2 THEN discount.percentage = 15, discount.code = 'TOYS'"
+		context := evaluation.Context{
+			"order":    map[string]interface{}{"amount": 100.0},
+			"customer": map[string]interface{}{"email": "Jane@Corp.com"},
+			"items": []interface{}{
+				map[string]interface{}{"category": "toys", "price": 40.0},
+				map[string]interface{}{"category": "toys", "price": 30.0},
+			},
+		}
+
+		result, err := strategy.Evaluate(ctx, dsl, context)
+		require.NoError(t, err)
+
+		assert.Equal(t, evaluation.Result{"eligible": true, "discount_percentage": 15.0, "discount_code": "TOYS"}, result)
+	})
+
+	t.Run("should read the clock of the evaluation", func(t *testing.T) {
+		dsl := "IF between(now(), '2026-11-27', '2026-11-30') THEN discount.percentage = 20"
+		blackFriday := evaluation.WithClock(ctx, evaluation.FixedClock{Time: time.Date(2026, 11, 27, 12, 0, 0, 0, time.UTC)})
+
+		result, err := strategy.Evaluate(blackFriday, dsl, evaluation.Context{})
+		require.NoError(t, err)
+
+		assert.Equal(t, evaluation.Result{"eligible": true, "discount_percentage": 20.0}, result)
 	})
 }
@@ -0,0 +1,84 @@
+package strategies_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/domain/shared"
+	"rules-evaluation-service/internal/infrastructure/dsl"
+	"rules-evaluation-service/internal/infrastructure/persistence/memory"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+)
+
+func TestRegistry(t *testing.T) {
+	deps := strategies.Dependencies{
+		CouponStore:          memory.NewCouponUsageStore(),
+		CouponReservationTTL: time.Minute,
+	}
+
+	t.Run("should build every self-registered strategy except the disabled ones", func(t *testing.T) {
+		built, descriptors, err := strategies.Build(deps, []string{"PAYMENTS"})
+		require.NoError(t, err)
+
+		categories := make([]string, len(descriptors))
+		for i, d := range descriptors {
+			categories[i] = d.Category
+			assert.Contains(t, built, d.Category)
+		}
+		assert.Equal(t, []string{"COUPONS", "LOYALTY", "PROMOTIONS", "TAXES"}, categories)
+		assert.NotContains(t, built, "PAYMENTS")
+	})
+
+	t.Run("should compile rules within the DSL limits of the dependencies", func(t *testing.T) {
+		limited := deps
+		limited.Limits = &dsl.Limits{MaxNodes: 3}
+		built, _, err := strategies.Build(limited, nil)
+		require.NoError(t, err)
+
+		_, err = built["PROMOTIONS"].Evaluate(context.Background(), "IF order.amount > 50 AND customer.tier = 'gold' THEN discount.percentage = 10", evaluation.Context{})
+		var limitErr *shared.LimitExceededError
+		require.ErrorAs(t, err, &limitErr)
+
+		defaults, _, err := strategies.Build(deps, nil)
+		require.NoError(t, err)
+		_, err = defaults["PROMOTIONS"].Evaluate(context.Background(), "IF order.amount > 50 AND customer.tier = 'gold' THEN discount.percentage = 10", evaluation.Context{})
+		assert.NoError(t, err, "strategies built without limits use the default limits")
+	})
+
+	t.Run("should fail when a strategy dependency is missing", func(t *testing.T) {
+		_, _, err := strategies.Build(strategies.Dependencies{}, nil)
+		assert.EqualError(t, err, "failed to build COUPONS strategy: coupon usage store is required")
+	})
+
+	t.Run("should reject registering a category twice", func(t *testing.T) {
+		assert.Panics(t, func() {
+			strategies.Register(strategies.Registration{
+				Descriptor: evaluation.StrategyDescriptor{Category: "TAXES"},
+				Factory: func(strategies.Dependencies) (evaluation.EvaluationStrategy, error) {
+					return strategies.NewGenericStrategy(), nil
+				},
+			})
+		})
+	})
+}
+
+func TestGenericStrategy(t *testing.T) {
+	ctx := context.Background()
+	strategy := strategies.NewGenericStrategy()
+
+	result, err := strategy.Evaluate(ctx, "IF order.amount > 50 THEN shipping.cost = 0, shipping.carrier = 'UPS'", evaluation.Context{"order_amount": 75.0})
+	require.NoError(t, err)
+	assert.Equal(t, evaluation.Result{
+		"eligible": true,
+		"actions":  map[string]interface{}{"shipping.cost": 0.0, "shipping.carrier": "UPS"},
+	}, result)
+
+	result, err = strategy.Evaluate(ctx, "IF order.amount > 50 THEN shipping.cost = 0", evaluation.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, evaluation.Result{"eligible": false, "reason": "Missing order.amount in context"}, result)
+}
@@ -0,0 +1,122 @@
+package strategies_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/domain/money"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+)
+
+func TestTaxesStrategy(t *testing.T) {
+	ctx := context.Background()
+	strategy := strategies.NewTaxesStrategy(map[string]strategies.Rounding{
+		"JPY": {Mode: strategies.RoundHalfEven, Decimals: 0},
+	})
+
+	t.Run("should break down tax-exclusive lines by tax class and add fees", func(t *testing.T) {
+		dsl := "IF jurisdiction.country = 'ES' THEN tax.name = 'IVA', tax.percentage = 21, tax.class.reduced = 10, " +
+			"fee.fixed.eco = 0.5, fee.percentage.handling = 1"
+		context := evaluation.Context{
+			"jurisdiction": map[string]interface{}{"country": "ES", "region": "MD"},
+			"currency":     "EUR",
+			"items": []interface{}{
+				map[string]interface{}{"sku": "A", "price": 100.0, "quantity": 1.0},
+				map[string]interface{}{"sku": "B", "price": 20.0, "quantity": 2.0, "tax_class": "reduced"},
+			},
+		}
+
+		result, err := strategy.Evaluate(ctx, dsl, context)
+		require.NoError(t, err)
+
+		assert.Equal(t, true, result["taxable"])
+		assert.Equal(t, []map[string]interface{}{
+			{"sku": "A", "tax_class": "", "net": 100.0, "tax_rate": 21.0, "tax": 21.0, "compound_tax": 0.0, "gross": 121.0},
+			{"sku": "B", "tax_class": "reduced", "net": 40.0, "tax_rate": 10.0, "tax": 4.0, "compound_tax": 0.0, "gross": 44.0},
+		}, result["lines"])
+		assert.Equal(t, map[string]interface{}{"net": 140.0, "tax": 25.0, "fees": 1.9, "gross": 166.9}, result["totals"])
+		assert.Equal(t, map[string]interface{}{"country": "ES", "region": "MD"}, result["jurisdiction"])
+
+		amounts := result["amounts"].(map[string]money.Money)
+		assert.Equal(t, "EUR", amounts["total_gross"].Currency)
+		assert.Equal(t, "166.9", amounts["total_gross"].Amount.String())
+	})
+
+	t.Run("should only report plain amounts when the currency is unknown", func(t *testing.T) {
+		context := evaluation.Context{"jurisdiction_country": "ES", "order_amount": 100.0}
+
+		result, err := strategy.Evaluate(ctx, "IF jurisdiction.country = 'ES' THEN tax.percentage = 21", context)
+		require.NoError(t, err)
+
+		assert.Equal(t, 21.0, result["total_tax"])
+		assert.NotContains(t, result, "amounts")
+	})
+
+	t.Run("should derive the net amount from tax-inclusive prices", func(t *testing.T) {
+		context := evaluation.Context{"jurisdiction_country": "ES", "order_amount": 121.0, "pricing_tax_inclusive": true}
+
+		result, err := strategy.Evaluate(ctx, "IF jurisdiction.country = 'ES' THEN tax.percentage = 21", context)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]interface{}{"net": 100.0, "tax": 21.0, "fees": 0.0, "gross": 121.0}, result["totals"])
+	})
+
+	t.Run("should apply compound taxes on top of the base tax", func(t *testing.T) {
+		context := evaluation.Context{"customer_region": "QC", "order_amount": 100.0}
+
+		result, err := strategy.Evaluate(ctx, "IF customer.region = 'QC' THEN tax.percentage = 10, tax.compound.provincial = 5", context)
+		require.NoError(t, err)
+
+		assert.Equal(t, 15.5, result["total_tax"])
+	})
+
+	t.Run("should exempt configured customer types", func(t *testing.T) {
+		context := evaluation.Context{"jurisdiction_country": "ES", "customer_type": "DIPLOMAT", "order_amount": 100.0}
+
+		result, err := strategy.Evaluate(ctx, "IF jurisdiction.country = 'ES' THEN tax.percentage = 21, tax.exempt_customer_types = ('DIPLOMAT', 'NGO')", context)
+		require.NoError(t, err)
+
+		assert.Equal(t, false, result["taxable"])
+		assert.Equal(t, true, result["exempt"])
+		assert.Equal(t, 0.0, result["total_tax"])
+	})
+
+	t.Run("should round with the currency's rounding mode", func(t *testing.T) {
+		context := evaluation.Context{"jurisdiction_country": "JP", "currency": "JPY", "order_amount": 105.0}
+
+		result, err := strategy.Evaluate(ctx, "IF jurisdiction.country = 'JP' THEN tax.percentage = 10", context)
+		require.NoError(t, err)
+
+		// 10.5 rounds half to even.
+		assert.Equal(t, 10.0, result["total_tax"])
+	})
+
+	t.Run("should return not taxable when the jurisdiction does not match", func(t *testing.T) {
+		result, err := strategy.Evaluate(ctx, "IF customer.region == 'CA' THEN tax.percentage = 9.5", evaluation.Context{"customer_region": "NY"})
+		require.NoError(t, err)
+
+		assert.Equal(t, evaluation.Result{"eligible": false, "taxable": false}, result)
+	})
+}
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		rounding strategies.Rounding
+		amount   float64
+		expected float64
+	}{
+		{strategies.Rounding{Mode: strategies.RoundHalfUp, Decimals: 2}, 2.675, 2.68},
+		{strategies.Rounding{Mode: strategies.RoundHalfEven, Decimals: 2}, 2.665, 2.66},
+		{strategies.Rounding{Mode: strategies.RoundUp, Decimals: 2}, 1.001, 1.01},
+		{strategies.Rounding{Mode: strategies.RoundDown, Decimals: 2}, 1.009, 1.0},
+		{strategies.Rounding{Mode: strategies.RoundHalfUp, Decimals: 0}, 10.5, 11},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.rounding.Round(tt.amount), "%s %v", tt.rounding.Mode, tt.amount)
+	}
+}
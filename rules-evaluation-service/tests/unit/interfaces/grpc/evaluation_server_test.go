@@ -0,0 +1,200 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	evaluationv1 "rules-evaluation-service/api/proto/gen"
+	"rules-evaluation-service/internal/application"
+	"rules-evaluation-service/internal/domain/evaluation"
+	"rules-evaluation-service/internal/infrastructure/ratelimit"
+	"rules-evaluation-service/internal/infrastructure/strategies"
+	"rules-evaluation-service/internal/interfaces/grpc/server"
+)
+
+func newClient(t *testing.T) evaluationv1.EvaluationServiceClient {
+	service := evaluation.NewService(map[string]evaluation.EvaluationStrategy{
+		"LOYALTY": strategies.NewLoyaltyStrategy(),
+	})
+	evaluateRuleHandler := application.NewEvaluateRuleHandler(service, nil)
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	evaluationv1.RegisterEvaluationServiceServer(grpcServer, server.NewEvaluationServer(evaluateRuleHandler, application.NewExplainRuleHandler(evaluateRuleHandler)))
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return evaluationv1.NewEvaluationServiceClient(conn)
+}
+
+func request(t *testing.T, category string, amount float64) *evaluationv1.EvaluateRuleRequest {
+	context, err := structpb.NewStruct(map[string]interface{}{"order_amount": amount, "customer_tier": "GOLD"})
+	require.NoError(t, err)
+	return &evaluationv1.EvaluateRuleRequest{
+		RuleCategory: category,
+		DslContent:   "IF order.amount > 50 THEN earn.points_per_unit = 1, earn.tier_multiplier.GOLD = 2",
+		Context:      context,
+	}
+}
+
+func TestEvaluationServer(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t)
+
+	t.Run("should evaluate a rule", func(t *testing.T) {
+		resp, err := client.EvaluateRule(ctx, request(t, "LOYALTY", 120))
+		require.NoError(t, err)
+
+		assert.Equal(t, true, resp.Result.AsMap()["eligible"])
+		assert.Equal(t, 240.0, resp.Result.AsMap()["points_earned"])
+	})
+
+	t.Run("should reject unknown categories as invalid arguments", func(t *testing.T) {
+		_, err := client.EvaluateRule(ctx, request(t, "SHIPPING", 120))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("should honour the caller's deadline", func(t *testing.T) {
+		expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+		defer cancel()
+
+		_, err := client.EvaluateRule(expired, request(t, "LOYALTY", 120))
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	})
+
+	t.Run("should stream batch results and report failures per request", func(t *testing.T) {
+		stream, err := client.BatchEvaluate(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, stream.Send(&evaluationv1.BatchEvaluateRequest{RequestId: "a", Rule: request(t, "LOYALTY", 120)}))
+		require.NoError(t, stream.Send(&evaluationv1.BatchEvaluateRequest{RequestId: "b", Rule: request(t, "SHIPPING", 120)}))
+		require.NoError(t, stream.Send(&evaluationv1.BatchEvaluateRequest{RequestId: "c", Rule: request(t, "LOYALTY", 10)}))
+		require.NoError(t, stream.CloseSend())
+
+		var responses []*evaluationv1.BatchEvaluateResponse
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				break
+			}
+			responses = append(responses, resp)
+		}
+
+		require.Len(t, responses, 3)
+		assert.Equal(t, 240.0, responses[0].Result.AsMap()["points_earned"])
+		assert.Equal(t, "no evaluation strategy found for category: SHIPPING", responses[1].Error)
+		assert.Equal(t, false, responses[2].Result.AsMap()["eligible"])
+	})
+
+	t.Run("should explain which context values the rule read", func(t *testing.T) {
+		resp, err := client.Explain(ctx, request(t, "LOYALTY", 120))
+		require.NoError(t, err)
+
+		assert.True(t, resp.Matched)
+		require.Len(t, resp.Fields, 1)
+		assert.Equal(t, "order.amount", resp.Fields[0].Path)
+		assert.Equal(t, 120.0, resp.Fields[0].Value.GetNumberValue())
+		assert.Equal(t, map[string]interface{}{"earn.points_per_unit": 1.0, "earn.tier_multiplier.GOLD": 2.0}, resp.Actions.AsMap())
+	})
+}
+
+// panickingStrategy fails the way a bug in a strategy would.
+type panickingStrategy struct{}
+
+func (panickingStrategy) Evaluate(context.Context, string, evaluation.Context) (evaluation.Result, error) {
+	panic("strategy bug")
+}
+
+func TestRecovery(t *testing.T) {
+	ctx := context.Background()
+	service := evaluation.NewService(map[string]evaluation.EvaluationStrategy{
+		"LOYALTY": strategies.NewLoyaltyStrategy(),
+		"PANIC":   panickingStrategy{},
+	})
+	evaluateRuleHandler := application.NewEvaluateRuleHandler(service, nil)
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(server.RecoveryUnaryInterceptor()),
+		grpc.StreamInterceptor(server.RecoveryStreamInterceptor()),
+	)
+	evaluationv1.RegisterEvaluationServiceServer(grpcServer, server.NewEvaluationServer(evaluateRuleHandler, application.NewExplainRuleHandler(evaluateRuleHandler)))
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := evaluationv1.NewEvaluationServiceClient(conn)
+
+	t.Run("should turn a panic into an internal error and keep serving", func(t *testing.T) {
+		_, err := client.EvaluateRule(ctx, request(t, "PANIC", 120))
+		assert.Equal(t, codes.Internal, status.Code(err))
+
+		stream, err := client.BatchEvaluate(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&evaluationv1.BatchEvaluateRequest{RequestId: "a", Rule: request(t, "PANIC", 120)}))
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Internal, status.Code(err))
+
+		resp, err := client.EvaluateRule(ctx, request(t, "LOYALTY", 120))
+		require.NoError(t, err)
+		assert.Equal(t, true, resp.Result.AsMap()["eligible"])
+	})
+}
+
+func TestLimits(t *testing.T) {
+	ctx := context.Background()
+	service := evaluation.NewService(map[string]evaluation.EvaluationStrategy{
+		"LOYALTY": strategies.NewLoyaltyStrategy(),
+	})
+	evaluateRuleHandler := application.NewEvaluateRuleHandler(service, nil)
+	guard := ratelimit.NewGuard(ratelimit.NewClientLimiter(0.001, 2, time.Minute, 10), nil)
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(grpc.StreamInterceptor(server.LimitsStreamInterceptor(guard)))
+	evaluationv1.RegisterEvaluationServiceServer(grpcServer, server.NewEvaluationServer(evaluateRuleHandler, application.NewExplainRuleHandler(evaluateRuleHandler)))
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := evaluationv1.NewEvaluationServiceClient(conn)
+
+	t.Run("should take a token per request of a stream", func(t *testing.T) {
+		stream, err := client.BatchEvaluate(ctx)
+		require.NoError(t, err)
+
+		for _, id := range []string{"a", "b"} {
+			require.NoError(t, stream.Send(&evaluationv1.BatchEvaluateRequest{RequestId: id, Rule: request(t, "LOYALTY", 120)}))
+			resp, err := stream.Recv()
+			require.NoError(t, err)
+			assert.Equal(t, id, resp.RequestId)
+		}
+		require.NoError(t, stream.Send(&evaluationv1.BatchEvaluateRequest{RequestId: "c", Rule: request(t, "LOYALTY", 120)}))
+		_, err = stream.Recv()
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.NotEmpty(t, stream.Trailer().Get("retry-after"))
+	})
+}
@@ -0,0 +1,36 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"rules-evaluation-service/internal/infrastructure/ratelimit"
+	"rules-evaluation-service/internal/interfaces/rest/middleware"
+)
+
+func TestLimitsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	guard := ratelimit.NewGuard(ratelimit.NewClientLimiter(1, 1, 0, 0), nil)
+	router := gin.New()
+	router.POST("/v1/evaluate", middleware.Limits(guard), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/evaluate", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, send("checkout").Code)
+
+	limited := send("checkout")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "1", limited.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, send("backoffice").Code, "other clients keep their own budget")
+}